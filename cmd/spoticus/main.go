@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
 
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/health"
+	"github.com/flacatus/spoticus/internal/kube"
+	"github.com/flacatus/spoticus/internal/leader"
 	"github.com/flacatus/spoticus/internal/slack"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+	"github.com/flacatus/spoticus/internal/tracing"
 )
 
 func main() {
@@ -19,6 +28,47 @@ func main() {
 		log.Fatal("FATAL: SLACK_APP_TOKEN environment variable is not set.")
 	}
 
+	// Load the bot configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("FATAL: invalid configuration: %v", err)
+	}
+	config.Set(cfg)
+
+	if cfg.Tracing.Enabled {
+		shutdown, err := tracing.Setup(context.Background(), cfg.Tracing.Endpoint)
+		if err != nil {
+			log.Fatalf("FATAL: could not set up tracing: %v", err)
+		}
+		defer func() {
+			if err := shutdown(context.Background()); err != nil {
+				log.Printf("Error flushing traces: %v", err)
+			}
+		}()
+	}
+
+	// Make sure the namespace MAPT resources live in is usable before accepting commands
+	restConfig, err := kube.RestConfig(cfg.KubeContext)
+	if err != nil {
+		log.Fatalf("FATAL: could not load kubernetes config: %v", err)
+	}
+	if err := kube.EnsureNamespace(context.Background(), restConfig, cfg.Namespace, cfg.AutoCreateNamespace); err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	if err := commands.CheckMaptInstalled(restConfig); err != nil {
+		log.Printf("WARNING: %v; commands will fail until its CRDs are installed", err)
+	}
+
+	// Serve the health endpoints and keep the backend readiness they and the
+	// command gating share up to date, on standbys too.
+	go commands.WatchBackend(context.Background())
+	if cfg.HealthAddr != "" {
+		go func() {
+			err := http.ListenAndServe(cfg.HealthAddr, health.Handler(health.Default()))
+			log.Printf("Health endpoints stopped: %v", err)
+		}()
+	}
+
 	// Create a new Slack bot instance
 	slackBot, err := slack.New(botToken, appToken)
 	if err != nil {
@@ -26,5 +76,41 @@ func main() {
 	}
 
 	log.Println("✅ Bot is starting...")
-	slackBot.Run()
+
+	if !cfg.LeaderElection.Enabled {
+		exitOnError(slackBot.Run())
+		return
+	}
+
+	// Only the elected replica connects to Slack; standbys wait for the lease.
+	run := func(ctx context.Context) {
+		exitOnError(slackBot.RunContext(ctx))
+	}
+	err = leader.Run(context.Background(), cfg.LeaderElection, run, func() {
+		log.Fatal("FATAL: leadership lost, exiting so a standby can take over.")
+	})
+	if err != nil {
+		log.Fatalf("FATAL: leader election failed: %v", err)
+	}
+}
+
+// exitOnError exits with a clear message when the bot stopped because of an
+// error, so that the supervisor (e.g. Kubernetes) restarts it. A restart also
+// re-reads the tokens, which recovers from a rotation once the new tokens are
+// in the environment or the mounted secret.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, slack.ErrInvalidAuth) {
+		log.Fatalf("FATAL: %v. The Slack tokens were likely rotated or revoked; "+
+			"update SLACK_BOT_TOKEN/SLACK_APP_TOKEN and restart.", err)
+	}
+	if errors.Is(err, slack.ErrAppRevoked) {
+		log.Fatalf("FATAL: %v. Reinstall the app in the workspace and update the tokens before restarting.", err)
+	}
+	if errors.Is(err, slack.ErrReconnectTimeout) {
+		log.Fatalf("FATAL: %v. Exiting so that the supervisor restarts the bot.", err)
+	}
+	log.Fatalf("FATAL: %v", err)
 }
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// ChannelDefaults overrides the launch defaults for launches requested in a
+// Slack channel. Zero fields fall back to the global configuration.
+type ChannelDefaults struct {
+	// Namespace is where the channel's clusters are created.
+	Namespace string `json:"namespace,omitempty"`
+
+	// DefaultTTL is the lifetime recorded on the channel's clusters.
+	DefaultTTL Duration `json:"defaultTTL,omitempty"`
+
+	// Size is used when a launch does not name a size.
+	Size string `json:"size,omitempty"`
+}
+
+// LaunchDefaults are the defaults resolved for a launch in a given channel.
+type LaunchDefaults struct {
+	Namespace string
+	TTL       time.Duration
+
+	// Size is empty unless the channel sets a default size. It only applies
+	// to launches that name no size, so it is not part of Applied.
+	Size string
+
+	// Applied describes the namespace and TTL overrides of the channel that
+	// took effect, e.g. "namespace `ci`". It is empty when the channel has none.
+	Applied []string
+}
+
+// DefaultsFor merges the channel's overrides over the global defaults of a
+// launch of the cluster type, whose namespace may be set per type.
+func (c *Config) DefaultsFor(channel, clusterType string) LaunchDefaults {
+	defaults := LaunchDefaults{Namespace: c.NamespaceFor(clusterType), TTL: c.TTL()}
+	override, ok := c.Channels[channel]
+	if !ok {
+		return defaults
+	}
+	if override.Namespace != "" {
+		defaults.Namespace = override.Namespace
+		defaults.Applied = append(defaults.Applied, fmt.Sprintf("namespace `%s`", override.Namespace))
+	}
+	if override.DefaultTTL > 0 {
+		defaults.TTL = time.Duration(override.DefaultTTL)
+		defaults.Applied = append(defaults.Applied, fmt.Sprintf("TTL `%s`", messages.FormatDuration(defaults.TTL)))
+	}
+	defaults.Size = override.Size
+	return defaults
+}
+
+// ClusterNamespaces returns every namespace clusters may be created in: the
+// global namespace and those of the cluster types and channel overrides,
+// sorted and deduplicated.
+func (c *Config) ClusterNamespaces() []string {
+	namespaces := []string{c.Namespace}
+	namespaces = append(namespaces, slices.Collect(maps.Values(c.TypeNamespaces))...)
+	for _, channel := range slices.Sorted(maps.Keys(c.Channels)) {
+		if ns := c.Channels[channel].Namespace; ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	slices.Sort(namespaces)
+	return slices.Compact(namespaces)
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// channelConfig is the default configuration with overrides for the CI and
+// sizing channels.
+func channelConfig() *Config {
+	cfg := Default()
+	cfg.Channels = map[string]ChannelDefaults{
+		"CCI":   {Namespace: "ci", DefaultTTL: Duration(2 * time.Hour)},
+		"CSIZE": {Size: "large"},
+	}
+	return cfg
+}
+
+func TestDefaultsFor(t *testing.T) {
+	cfg := channelConfig()
+	cfg.TypeNamespaces = map[string]string{"openshift": "ocp"}
+
+	tests := []struct {
+		name          string
+		channel       string
+		clusterType   string
+		wantNamespace string
+		wantTTL       time.Duration
+		wantSize      string
+		wantApplied   []string
+	}{
+		{name: "channel override", channel: "CCI", clusterType: "k8s", wantNamespace: "ci", wantTTL: 2 * time.Hour, wantApplied: []string{"namespace `ci`", "TTL `2h`"}},
+		{name: "channel over type namespace", channel: "CCI", clusterType: "openshift", wantNamespace: "ci", wantTTL: 2 * time.Hour, wantApplied: []string{"namespace `ci`", "TTL `2h`"}},
+		{name: "size only", channel: "CSIZE", clusterType: "k8s", wantNamespace: "default", wantTTL: cfg.TTL(), wantSize: "large"},
+		{name: "fallback to global", channel: "COTHER", clusterType: "k8s", wantNamespace: "default", wantTTL: cfg.TTL()},
+		{name: "fallback to type namespace", channel: "COTHER", clusterType: "openshift", wantNamespace: "ocp", wantTTL: cfg.TTL()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cfg.DefaultsFor(tt.channel, tt.clusterType)
+			if got.Namespace != tt.wantNamespace || got.TTL != tt.wantTTL || got.Size != tt.wantSize {
+				t.Errorf("DefaultsFor(%s, %s) = %+v, want namespace %s, TTL %s, size %q", tt.channel, tt.clusterType, got, tt.wantNamespace, tt.wantTTL, tt.wantSize)
+			}
+			if !slices.Equal(got.Applied, tt.wantApplied) {
+				t.Errorf("applied %q, want %q", got.Applied, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestClusterNamespaces(t *testing.T) {
+	cfg := channelConfig()
+	cfg.TypeNamespaces = map[string]string{"openshift": "ocp", "k8s": "ci"}
+
+	if got, want := cfg.ClusterNamespaces(), []string{"ci", "default", "ocp"}; !slices.Equal(got, want) {
+		t.Errorf("ClusterNamespaces() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateChannelDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults ChannelDefaults
+		wantErr  string
+	}{
+		{name: "invalid namespace", defaults: ChannelDefaults{Namespace: "Not_A_Namespace"}, wantErr: "channels.C1.namespace"},
+		{name: "negative TTL", defaults: ChannelDefaults{DefaultTTL: Duration(-time.Hour)}, wantErr: "must not be negative"},
+		{name: "TTL over the maximum", defaults: ChannelDefaults{DefaultTTL: Duration(1000 * time.Hour)}, wantErr: "exceeds maxTTL"},
+		{name: "unknown size", defaults: ChannelDefaults{Size: "huge"}, wantErr: `unknown size "huge"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Channels = map[string]ChannelDefaults{"C1": tt.defaults}
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNamespaceFor(t *testing.T) {
+	cfg := Default()
+	cfg.TypeNamespaces = map[string]string{"openshift": "ocp"}
+
+	if got := cfg.NamespaceFor("openshift"); got != "ocp" {
+		t.Errorf("NamespaceFor(openshift) = %q, want the type's namespace", got)
+	}
+	if got := cfg.NamespaceFor("k8s"); got != cfg.Namespace {
+		t.Errorf("NamespaceFor(k8s) = %q, want the global namespace %q", got, cfg.Namespace)
+	}
+}
+
+func TestValidateTypeNamespaces(t *testing.T) {
+	cfg := Default()
+	cfg.TypeNamespaces = map[string]string{"openshift": "ocp"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want a valid type namespace accepted", err)
+	}
+	cfg.TypeNamespaces["k8s"] = "Not_A_Namespace"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `typeNamespaces.k8s: invalid namespace "Not_A_Namespace"`) {
+		t.Errorf("Validate() = %v, want the invalid namespace rejected", err)
+	}
+}
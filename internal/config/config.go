@@ -0,0 +1,1014 @@
+// Package config holds the runtime configuration of the bot.
+//
+// The configuration is built from built-in defaults, an optional YAML file
+// (SPOTICUS_CONFIG) and SPOTICUS_* environment variables, in that order of
+// precedence. It is shared with the command handlers through Get and can be
+// swapped atomically at runtime with Reload.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// defaultHistorySize is the number of recent commands kept in memory
+// when no history size is configured.
+const defaultHistorySize = 50
+
+// defaultListMaxResults is the number of clusters `list` shows when no
+// maximum is configured.
+const defaultListMaxResults = 50
+
+// defaultTopResults is the number of clusters `top` ranks when neither the
+// command nor the configuration gives a count.
+const defaultTopResults = 5
+
+// defaultTTL is the lifetime recorded on new clusters when no TTL is configured.
+const defaultTTL = 8 * time.Hour
+
+// defaultMaxTTL is the longest lifetime clusters may be launched with when
+// no maximum is configured.
+const defaultMaxTTL = 7 * 24 * time.Hour
+
+// minPollInterval bounds how often watched clusters may be polled.
+const minPollInterval = 5 * time.Second
+
+// minProgressInterval bounds how often launch progress messages may be edited,
+// to stay well clear of Slack's rate limits on chat.update.
+const minProgressInterval = 10 * time.Second
+
+// SizeSpec defines the resource specifications for a given cluster size.
+type SizeSpec struct {
+	// CPUs and MemoryGB are the numeric values requested on the MAPT spec.
+	CPUs     int `json:"cpus"`
+	MemoryGB int `json:"memoryGB"`
+
+	// Nodes is the number of nodes the cluster is provisioned with.
+	Nodes int `json:"nodes"`
+
+	// HourlyCost is the estimated spot price of the cluster, in USD per hour.
+	HourlyCost float64 `json:"hourlyCost"`
+
+	// ProvisionTimeout is how long a launch of this size is watched before
+	// the user is told it is taking too long. Zero uses the default of 45
+	// minutes.
+	ProvisionTimeout Duration `json:"provisionTimeout,omitempty"`
+
+	// MaxTTL is the longest lifetime a cluster of this size may be launched
+	// with, so that expensive sizes are not kept for days. Zero uses the
+	// global maxTTL.
+	MaxTTL Duration `json:"maxTTL,omitempty"`
+}
+
+// CPU returns the user-facing CPU description, e.g. "8 CPUs".
+func (s SizeSpec) CPU() string {
+	return fmt.Sprintf("%d CPUs", s.CPUs)
+}
+
+// RAM returns the user-facing memory description, e.g. "32 GB RAM".
+func (s SizeSpec) RAM() string {
+	return fmt.Sprintf("%d GB RAM", s.MemoryGB)
+}
+
+// Config describes the tunable, non-secret settings of the bot.
+type Config struct {
+	// Admins lists the Slack user IDs allowed to run admin-only commands.
+	Admins []string `json:"admins,omitempty"`
+
+	// DefaultOwner is the Slack user ID (e.g. U012ABCDEF) or user group ID
+	// (e.g. S012ABCDEF) that owns the clusters launched without a user, e.g.
+	// by automation, so that quotas and notifications still have a target.
+	// Empty leaves those clusters without an owner.
+	DefaultOwner string `json:"defaultOwner,omitempty"`
+
+	// HistorySize is the capacity of the in-memory command history buffer.
+	HistorySize int `json:"historySize,omitempty"`
+
+	// ListMaxResults is how many clusters `list` shows per page, longer
+	// lists being paged with buttons. Zero shows every cluster at once.
+	ListMaxResults int `json:"listMaxResults,omitempty"`
+
+	// DeleteGracePeriod delays the deletion of the clusters marked with
+	// `done`, leaving time to take it back with `cancel-delete`. Zero deletes
+	// them right away.
+	DeleteGracePeriod Duration `json:"deleteGracePeriod,omitempty"`
+
+	// TopResults is how many clusters `top` ranks when no count is given.
+	TopResults int `json:"topResults,omitempty"`
+
+	// DefaultTTL is the expected lifetime recorded on newly launched clusters.
+	DefaultTTL Duration `json:"defaultTTL,omitempty"`
+
+	// MaxTTL is the longest lifetime a cluster may be launched with, for
+	// the sizes without a maxTTL of their own and explicit instance types.
+	// Zero leaves the lifetime unbounded.
+	MaxTTL Duration `json:"maxTTL,omitempty"`
+
+	// Sizes defines the cluster sizes users can launch, keyed by size label.
+	Sizes map[string]SizeSpec `json:"sizes,omitempty"`
+
+	// InstanceTypes lists, per cloud provider, the instance types users may request with --instance-type.
+	InstanceTypes map[string][]string `json:"instanceTypes,omitempty"`
+
+	// InstanceTypeSpecs gives the CPUs, memory and hourly cost of instance
+	// types, keyed by instance type, so that launches with --instance-type
+	// count against the budget cap and ask for confirmation like size tiers.
+	// Their nodes, provisionTimeout and maxTTL are not used. While a budget
+	// cap or confirmCPUs is set, instance types without a spec are refused.
+	InstanceTypeSpecs map[string]SizeSpec `json:"instanceTypeSpecs,omitempty"`
+
+	// ConsoleURLs are, per cloud provider, the templates of the console URL
+	// `console` links to. They are Go templates rendered with .Provider,
+	// .Region, .Name and .Namespace.
+	ConsoleURLs map[string]string `json:"consoleURLs,omitempty"`
+
+	// Regions restricts, per cloud provider, the regions launches may be
+	// pinned to with `--set region=...`, e.g. for compliance. Providers
+	// without a policy allow every region.
+	Regions map[string]RegionPolicy `json:"regions,omitempty"`
+
+	// OpenshiftVersions lists the OpenShift versions users may request with --version.
+	OpenshiftVersions []string `json:"openshiftVersions,omitempty"`
+
+	// DefaultOpenshiftVersion is installed when no --version is given.
+	DefaultOpenshiftVersion string `json:"defaultOpenshiftVersion,omitempty"`
+
+	// K8sVersions lists the Kubernetes versions users may request for k8s
+	// clusters with --k8s-version.
+	K8sVersions []string `json:"k8sVersions,omitempty"`
+
+	// DefaultK8sVersion is installed on k8s clusters when no --k8s-version is given.
+	DefaultK8sVersion string `json:"defaultK8sVersion,omitempty"`
+
+	// Cleanup selects which clusters the admin `cleanup` command removes.
+	Cleanup CleanupCriteria `json:"cleanup,omitempty"`
+
+	// LeaderElection makes replicas compete for a Lease so only one processes events.
+	LeaderElection LeaderElection `json:"leaderElection,omitempty"`
+
+	// MaptOperator locates the MAPT operator deployment whose version the
+	// `version` command reports.
+	MaptOperator MaptOperator `json:"maptOperator,omitempty"`
+
+	// Tracing exports OpenTelemetry traces of command handling.
+	Tracing Tracing `json:"tracing,omitempty"`
+
+	// EventWorkers is the number of Slack events handled concurrently.
+	EventWorkers int `json:"eventWorkers,omitempty"`
+
+	// EventQueueSize bounds how many events may wait for a worker before new ones are dropped.
+	EventQueueSize int `json:"eventQueueSize,omitempty"`
+
+	// KubeContext selects the kubeconfig context of the cluster MAPT runs in.
+	// Empty uses the current context, or the in-cluster configuration.
+	KubeContext string `json:"kubeContext,omitempty"`
+
+	// Namespace is where MAPT resources are created, listed and deleted.
+	Namespace string `json:"namespace,omitempty"`
+
+	// AutoCreateNamespace creates Namespace at startup if it does not exist.
+	AutoCreateNamespace bool `json:"autoCreateNamespace,omitempty"`
+
+	// HealthAddr is the address, e.g. ":8081", the /healthz and /readyz
+	// endpoints are served on. Empty disables them.
+	HealthAddr string `json:"healthAddr,omitempty"`
+
+	// Quota limits how many clusters each user may own at once.
+	Quota Quota `json:"quota,omitempty"`
+
+	// Maintenance starts the bot in maintenance mode, refusing launches and
+	// deletions, unless an admin toggled the mode since with `maintenance`.
+	Maintenance bool `json:"maintenance,omitempty"`
+
+	// DisableWelcome stops the bot from introducing itself when it is added
+	// to a channel. The introduction itself is the `welcome` message.
+	DisableWelcome bool `json:"disableWelcome,omitempty"`
+
+	// AnnounceChannels lists the Slack channel IDs the admin `announce`
+	// command posts to.
+	AnnounceChannels []string `json:"announceChannels,omitempty"`
+
+	// Budget caps the estimated spend of all clusters together.
+	Budget Budget `json:"budget,omitempty"`
+
+	// Currency formats the estimated costs in messages, "$1,234.50" by default.
+	Currency messages.Currency `json:"currency"`
+
+	// RefPattern is a regular expression `launch --ref` values must match in
+	// full, e.g. `[A-Z]+-[0-9]+` for Jira keys. Empty accepts any reference.
+	RefPattern string `json:"refPattern,omitempty"`
+
+	// LenientArgs makes `launch` ignore arguments it does not recognize
+	// instead of rejecting the command.
+	LenientArgs bool `json:"lenientArgs,omitempty"`
+
+	// ConfirmCPUs is the CPU count from which a size tier is considered
+	// expensive enough that launching it must be confirmed with
+	// `launch confirm`. Zero launches every size immediately.
+	ConfirmCPUs int `json:"confirmCPUs,omitempty"`
+
+	// TenantIsolation scopes every command to the Slack workspace it was sent
+	// from, for bots installed in several workspaces: clusters are labelled
+	// with the workspace's team ID, and other workspaces' clusters are
+	// neither listed nor reachable.
+	TenantIsolation bool `json:"tenantIsolation,omitempty"`
+
+	// Teams maps team names to the Slack user IDs of their members. Clusters
+	// launched by a team member are labelled with the team, and only members
+	// of that team (and admins) may operate on them.
+	Teams map[string][]string `json:"teams,omitempty"`
+
+	// TypeSizes restricts the sizes each cluster type may be launched with,
+	// keyed by cluster type (e.g. "openshift"). Types without an entry allow
+	// every size.
+	TypeSizes map[string][]string `json:"typeSizes,omitempty"`
+
+	// TypeNamespaces sets the namespace each cluster type is created in,
+	// keyed by cluster type (e.g. "openshift"). Types without an entry use
+	// Namespace; a channel's namespace takes precedence over both.
+	TypeNamespaces map[string]string `json:"typeNamespaces,omitempty"`
+
+	// Channels overrides the launch defaults (namespace, TTL, size) for
+	// launches requested in a channel, keyed by Slack channel ID.
+	Channels map[string]ChannelDefaults `json:"channels,omitempty"`
+
+	// CircuitBreaker short-circuits commands while the Kubernetes backend is failing.
+	CircuitBreaker CircuitBreaker `json:"circuitBreaker,omitempty"`
+
+	// Polling sets how often launching and followed clusters are polled.
+	Polling Polling `json:"polling,omitempty"`
+
+	// NotificationWebhooks are HTTP endpoints the cluster lifecycle events
+	// (launched, ready, failed, deleted) are posted to as JSON, in addition
+	// to Slack.
+	NotificationWebhooks []string `json:"notificationWebhooks,omitempty"`
+
+	// NotificationWindow coalesces the notifications about a cluster: one
+	// identical to a notification posted about the same cluster within the
+	// window is dropped, so that a flapping cluster does not flood its
+	// channel. Zero disables it.
+	NotificationWindow Duration `json:"notificationWindow"`
+
+	// Reconnect sets how the bot retries failed connections to Slack.
+	Reconnect Reconnect `json:"reconnect,omitempty"`
+
+	// Uploads bounds the files the bot uploads to Slack, e.g. `export`.
+	Uploads Uploads `json:"uploads,omitempty"`
+
+	// ProgressInterval is how often the launch thread's progress message is
+	// refreshed with the elapsed time while a cluster provisions. Phase
+	// changes are reported at the next poll regardless.
+	ProgressInterval Duration `json:"progressInterval,omitempty"`
+
+	// Messages overrides the user-facing message templates.
+	Messages messages.Messages `json:"messages,omitempty"`
+
+	// Branding customizes the bot name and emojis used in every message.
+	Branding messages.Branding `json:"branding,omitempty"`
+}
+
+// Quota limits the number of clusters a user may own at once. Zero means unlimited.
+type Quota struct {
+	// Default applies to every user without a specific limit.
+	Default int `json:"default"`
+
+	// Users sets per-user limits keyed by Slack user ID.
+	Users map[string]int `json:"users,omitempty"`
+
+	// NoticeRemaining makes the ready message of a launch tell the user how
+	// many launches they have left once that many or fewer remain. Zero
+	// disables the notice.
+	NoticeRemaining int `json:"noticeRemaining,omitempty"`
+}
+
+// Budget caps the aggregate estimated cost of the running clusters.
+type Budget struct {
+	// HourlyCap is the maximum total hourly cost, in dollars. Launches that
+	// would exceed it are rejected unless an admin overrides it. Zero disables the cap.
+	HourlyCap float64 `json:"hourlyCap,omitempty"`
+
+	// NoticeRatio makes the ready message of a launch report the spend once
+	// it reaches this fraction of the cap (0.8 is 80%). Zero disables the
+	// notice.
+	NoticeRatio float64 `json:"noticeRatio,omitempty"`
+}
+
+// CircuitBreaker configures the breaker guarding calls to the Kubernetes backend.
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failures that opens the breaker.
+	Threshold int `json:"threshold"`
+
+	// Cooldown is how long the breaker stays open before probing the backend again.
+	Cooldown Duration `json:"cooldown"`
+}
+
+// Polling configures how the status watchers poll the Kubernetes API.
+type Polling struct {
+	// Interval is the base time between two polls.
+	Interval Duration `json:"interval"`
+
+	// Jitter randomly spreads each wait by up to this fraction of it either
+	// way (0.2 is ±20%), so that clusters watched together do not poll in
+	// lockstep.
+	Jitter float64 `json:"jitter"`
+
+	// MaxBackoff caps the interval, which doubles after every consecutive
+	// failed poll. The jitter applies on top of it.
+	MaxBackoff Duration `json:"maxBackoff"`
+}
+
+// Reconnect is the backoff policy applied when the connection to Slack
+// fails. The wait before the next attempt starts at Initial and is multiplied
+// by Multiplier after every consecutive failure, up to MaxInterval.
+type Reconnect struct {
+	// Initial is the wait after the first failed connection.
+	Initial Duration `json:"initial"`
+
+	// MaxInterval caps the wait between two attempts.
+	MaxInterval Duration `json:"maxInterval"`
+
+	// Multiplier grows the wait after every consecutive failure.
+	Multiplier float64 `json:"multiplier"`
+
+	// MaxElapsed is how long the bot keeps retrying without a successful
+	// connection before exiting, so that its supervisor restarts it.
+	MaxElapsed Duration `json:"maxElapsed"`
+}
+
+// Uploads bounds the file uploads to Slack, so that a large file or a slow
+// upload fails with a message instead of hanging the command.
+type Uploads struct {
+	// Timeout is how long a single upload may take.
+	Timeout Duration `json:"timeout"`
+
+	// MaxBytes is the largest file uploaded.
+	MaxBytes int `json:"maxBytes"`
+}
+
+// LeaderElection configures Kubernetes lease-based leader election.
+// It is disabled by default for single-instance deployments.
+type LeaderElection struct {
+	Enabled   bool   `json:"enabled"`
+	Namespace string `json:"namespace,omitempty"`
+	LeaseName string `json:"leaseName,omitempty"`
+}
+
+// MaptOperator identifies the Deployment running the MAPT operator.
+type MaptOperator struct {
+	Namespace  string `json:"namespace"`
+	Deployment string `json:"deployment"`
+}
+
+// Tracing configures the OpenTelemetry trace exporter. It is disabled by default.
+type Tracing struct {
+	Enabled bool `json:"enabled"`
+
+	// Endpoint is the OTLP/HTTP URL spans are sent to, e.g.
+	// "http://otel-collector:4318". Empty uses the standard
+	// OTEL_EXPORTER_OTLP_* environment variables.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// DefaultProvider is the cloud provider clusters are launched on.
+const DefaultProvider = "aws"
+
+// Providers lists the cloud providers clusters can be launched on, which the
+// per-provider settings are keyed by.
+var Providers = []string{DefaultProvider}
+
+// RegionPolicy restricts the regions of a cloud provider.
+type RegionPolicy struct {
+	// Allow lists the regions allowed. Empty allows every region that is
+	// not denied.
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny lists the regions that are never allowed.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Allows reports whether the policy allows the region. Regions are compared
+// case-insensitively.
+func (p RegionPolicy) Allows(region string) bool {
+	matches := func(r string) bool { return strings.EqualFold(r, region) }
+	if slices.ContainsFunc(p.Deny, matches) {
+		return false
+	}
+	return len(p.Allow) == 0 || slices.ContainsFunc(p.Allow, matches)
+}
+
+// Allowed returns the regions the policy allows, or nil when it allows every
+// region that is not denied.
+func (p RegionPolicy) Allowed() []string {
+	var allowed []string
+	for _, region := range p.Allow {
+		if p.Allows(region) {
+			allowed = append(allowed, region)
+		}
+	}
+	return allowed
+}
+
+// CleanupCriteria selects the clusters considered failed or orphaned by `cleanup`.
+// A cluster is selected when it matches any enabled criterion.
+type CleanupCriteria struct {
+	// Failed selects clusters whose MAPT status is Failed.
+	Failed bool `json:"failed"`
+
+	// Orphaned selects clusters with no owner annotation.
+	Orphaned bool `json:"orphaned"`
+
+	// MaxAge selects clusters older than this age. Zero disables the criterion.
+	MaxAge Duration `json:"maxAge,omitempty"`
+}
+
+// Duration is a time.Duration that is written as a string (e.g. "8h") in the config file.
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string such as "90m" or "8h".
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("duration must be a string: %s", data)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(time.Duration(d).String())), nil
+}
+
+var (
+	// current is the configuration shared with the handlers.
+	current atomic.Pointer[Config]
+
+	// reloadMu serializes reloads so concurrent callers observe consistent diffs.
+	reloadMu sync.Mutex
+)
+
+func init() {
+	current.Store(Default())
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() *Config {
+	return &Config{
+		HistorySize:    defaultHistorySize,
+		ListMaxResults: defaultListMaxResults,
+		TopResults:     defaultTopResults,
+		DefaultTTL:     Duration(defaultTTL),
+		MaxTTL:         Duration(defaultMaxTTL),
+		Sizes: map[string]SizeSpec{
+			"medium": {CPUs: 8, MemoryGB: 32, Nodes: 1, HourlyCost: 0.15, ProvisionTimeout: Duration(30 * time.Minute)},
+			"large":  {CPUs: 16, MemoryGB: 64, Nodes: 1, HourlyCost: 0.30, ProvisionTimeout: Duration(45 * time.Minute)},
+			"xlarge": {CPUs: 32, MemoryGB: 128, Nodes: 1, HourlyCost: 0.60, ProvisionTimeout: Duration(time.Hour), MaxTTL: Duration(24 * time.Hour)},
+		},
+		InstanceTypes: map[string][]string{
+			"aws": {"m6i.2xlarge", "m6i.4xlarge", "m6i.8xlarge", "c6i.4xlarge", "r6i.2xlarge"},
+		},
+		InstanceTypeSpecs: map[string]SizeSpec{
+			"m6i.2xlarge": {CPUs: 8, MemoryGB: 32, HourlyCost: 0.15},
+			"m6i.4xlarge": {CPUs: 16, MemoryGB: 64, HourlyCost: 0.30},
+			"m6i.8xlarge": {CPUs: 32, MemoryGB: 128, HourlyCost: 0.60},
+			"c6i.4xlarge": {CPUs: 16, MemoryGB: 32, HourlyCost: 0.27},
+			"r6i.2xlarge": {CPUs: 8, MemoryGB: 64, HourlyCost: 0.20},
+		},
+		ConsoleURLs: map[string]string{
+			"aws":   "https://{{.Region}}.console.aws.amazon.com/ec2/home?region={{.Region}}#Instances:tag:Name={{.Name}}",
+			"azure": "https://portal.azure.com/#view/HubsExtension/BrowseResource/resourceType/Microsoft.Compute%2FVirtualMachines",
+		},
+		OpenshiftVersions:       []string{"4.17.0", "4.18.0", "4.19.0"},
+		DefaultOpenshiftVersion: "4.19.0",
+		K8sVersions:             []string{"1.31", "1.32", "1.33"},
+		DefaultK8sVersion:       "1.33",
+		Cleanup:                 CleanupCriteria{Failed: true},
+		LeaderElection: LeaderElection{
+			Namespace: "default",
+			LeaseName: "spoticus-leader",
+		},
+		MaptOperator: MaptOperator{
+			Namespace:  "mapt-operator-system",
+			Deployment: "mapt-operator-controller-manager",
+		},
+		Namespace:      "default",
+		EventWorkers:   4,
+		EventQueueSize: 100,
+		Quota:          Quota{Default: 3, NoticeRemaining: 1},
+		Budget:         Budget{NoticeRatio: 0.8},
+		Currency:       messages.DefaultCurrency,
+		ConfirmCPUs:    32,
+		CircuitBreaker: CircuitBreaker{Threshold: 5, Cooldown: Duration(30 * time.Second)},
+		Polling:        Polling{Interval: Duration(30 * time.Second), Jitter: 0.2, MaxBackoff: Duration(5 * time.Minute)},
+		Reconnect: Reconnect{
+			Initial:     Duration(time.Second),
+			MaxInterval: Duration(time.Minute),
+			Multiplier:  2,
+			MaxElapsed:  Duration(15 * time.Minute),
+		},
+		Uploads:            Uploads{Timeout: Duration(time.Minute), MaxBytes: 10 << 20},
+		ProgressInterval:   Duration(time.Minute),
+		NotificationWindow: Duration(10 * time.Minute),
+		Messages:           messages.Default(),
+	}
+}
+
+// Load builds a configuration from the defaults, the file named by
+// SPOTICUS_CONFIG (if set) and the SPOTICUS_* environment variables.
+// The result is validated before being returned.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("SPOTICUS_CONFIG"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file: %w", err)
+		}
+		if err := unmarshalFile(data, cfg); err != nil {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	cfg.Messages = cfg.Branding.Apply(cfg.Messages)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// unmarshalFile decodes the config file into cfg. Decoding merges the keys
+// of a map into the map already there, so the maps with defaults are cleared
+// first: a file that sets sizes, instanceTypes, instanceTypeSpecs or
+// consoleURLs replaces their defaults, and only the maps it leaves out keep
+// them.
+func unmarshalFile(data []byte, cfg *Config) error {
+	sizes, instanceTypes, instanceTypeSpecs, consoleURLs := cfg.Sizes, cfg.InstanceTypes, cfg.InstanceTypeSpecs, cfg.ConsoleURLs
+	cfg.Sizes, cfg.InstanceTypes, cfg.InstanceTypeSpecs, cfg.ConsoleURLs = nil, nil, nil, nil
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return err
+	}
+	if cfg.Sizes == nil {
+		cfg.Sizes = sizes
+	}
+	if cfg.InstanceTypes == nil {
+		cfg.InstanceTypes = instanceTypes
+	}
+	if cfg.InstanceTypeSpecs == nil {
+		cfg.InstanceTypeSpecs = instanceTypeSpecs
+	}
+	if cfg.ConsoleURLs == nil {
+		cfg.ConsoleURLs = consoleURLs
+	}
+	return nil
+}
+
+// applyEnv overrides configuration values with the SPOTICUS_* environment variables.
+func applyEnv(cfg *Config) error {
+	if admins := os.Getenv("SPOTICUS_ADMINS"); admins != "" {
+		cfg.Admins = splitList(admins)
+	}
+
+	if size := os.Getenv("SPOTICUS_HISTORY_SIZE"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			return fmt.Errorf("invalid SPOTICUS_HISTORY_SIZE %q: must be an integer", size)
+		}
+		cfg.HistorySize = n
+	}
+
+	if ttl := os.Getenv("SPOTICUS_DEFAULT_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return fmt.Errorf("invalid SPOTICUS_DEFAULT_TTL %q: %w", ttl, err)
+		}
+		cfg.DefaultTTL = Duration(d)
+	}
+
+	if enabled := os.Getenv("SPOTICUS_LEADER_ELECTION"); enabled != "" {
+		b, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return fmt.Errorf("invalid SPOTICUS_LEADER_ELECTION %q: must be a boolean", enabled)
+		}
+		cfg.LeaderElection.Enabled = b
+	}
+
+	if kubeContext := os.Getenv("SPOTICUS_KUBE_CONTEXT"); kubeContext != "" {
+		cfg.KubeContext = kubeContext
+	}
+
+	if namespace := os.Getenv("SPOTICUS_NAMESPACE"); namespace != "" {
+		cfg.Namespace = namespace
+	}
+
+	if create := os.Getenv("SPOTICUS_NAMESPACE_AUTO_CREATE"); create != "" {
+		b, err := strconv.ParseBool(create)
+		if err != nil {
+			return fmt.Errorf("invalid SPOTICUS_NAMESPACE_AUTO_CREATE %q: must be a boolean", create)
+		}
+		cfg.AutoCreateNamespace = b
+	}
+
+	if maintenance := os.Getenv("SPOTICUS_MAINTENANCE"); maintenance != "" {
+		b, err := strconv.ParseBool(maintenance)
+		if err != nil {
+			return fmt.Errorf("invalid SPOTICUS_MAINTENANCE %q: must be a boolean", maintenance)
+		}
+		cfg.Maintenance = b
+	}
+
+	if tracing := os.Getenv("SPOTICUS_TRACING"); tracing != "" {
+		b, err := strconv.ParseBool(tracing)
+		if err != nil {
+			return fmt.Errorf("invalid SPOTICUS_TRACING %q: must be a boolean", tracing)
+		}
+		cfg.Tracing.Enabled = b
+	}
+	if endpoint := os.Getenv("SPOTICUS_TRACING_ENDPOINT"); endpoint != "" {
+		cfg.Tracing.Endpoint = endpoint
+	}
+
+	if namespace := os.Getenv("SPOTICUS_LEADER_ELECTION_NAMESPACE"); namespace != "" {
+		cfg.LeaderElection.Namespace = namespace
+	}
+
+	if addr := os.Getenv("SPOTICUS_HEALTH_ADDR"); addr != "" {
+		cfg.HealthAddr = addr
+	}
+
+	return nil
+}
+
+// Validate checks that the configuration is usable, reporting every problem found.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.HistorySize <= 0 {
+		errs = append(errs, fmt.Errorf("historySize must be positive, got %d", c.HistorySize))
+	}
+	if c.ListMaxResults < 0 {
+		errs = append(errs, fmt.Errorf("listMaxResults must not be negative, got %d", c.ListMaxResults))
+	}
+	if c.DeleteGracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("deleteGracePeriod must not be negative, got %s", time.Duration(c.DeleteGracePeriod)))
+	}
+	if c.TopResults <= 0 {
+		errs = append(errs, fmt.Errorf("topResults must be positive, got %d", c.TopResults))
+	}
+	if c.DefaultTTL <= 0 {
+		errs = append(errs, fmt.Errorf("defaultTTL must be positive, got %s", time.Duration(c.DefaultTTL)))
+	}
+	if c.MaxTTL < 0 {
+		errs = append(errs, fmt.Errorf("maxTTL must not be negative, got %s", time.Duration(c.MaxTTL)))
+	}
+	if c.MaxTTL > 0 && c.DefaultTTL > c.MaxTTL {
+		errs = append(errs, fmt.Errorf("defaultTTL %s exceeds maxTTL %s", time.Duration(c.DefaultTTL), time.Duration(c.MaxTTL)))
+	}
+	if c.HealthAddr != "" {
+		if _, _, err := net.SplitHostPort(c.HealthAddr); err != nil {
+			errs = append(errs, fmt.Errorf("healthAddr %q is not a host:port address: %w", c.HealthAddr, err))
+		}
+	}
+	if len(c.Sizes) == 0 {
+		errs = append(errs, errors.New("at least one size must be defined"))
+	}
+	for name, spec := range c.Sizes {
+		if spec.CPUs <= 0 || spec.MemoryGB <= 0 || spec.Nodes <= 0 {
+			errs = append(errs, fmt.Errorf("size %q must have positive cpus, memoryGB and nodes", name))
+		}
+		if spec.ProvisionTimeout < 0 {
+			errs = append(errs, fmt.Errorf("size %q: provisionTimeout must not be negative, got %s", name, time.Duration(spec.ProvisionTimeout)))
+		}
+		if spec.MaxTTL < 0 {
+			errs = append(errs, fmt.Errorf("size %q: maxTTL must not be negative, got %s", name, time.Duration(spec.MaxTTL)))
+		}
+	}
+	for instanceType, spec := range c.InstanceTypeSpecs {
+		if spec.CPUs <= 0 || spec.MemoryGB <= 0 || spec.HourlyCost < 0 {
+			errs = append(errs, fmt.Errorf("instanceTypeSpecs.%s must have positive cpus and memoryGB and a hourlyCost that is not negative", instanceType))
+		}
+	}
+	if c.Cleanup.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("cleanup.maxAge must not be negative, got %s", time.Duration(c.Cleanup.MaxAge)))
+	}
+	if c.LeaderElection.Enabled && (c.LeaderElection.Namespace == "" || c.LeaderElection.LeaseName == "") {
+		errs = append(errs, errors.New("leaderElection requires a namespace and leaseName"))
+	}
+	if c.Namespace == "" {
+		errs = append(errs, errors.New("namespace must not be empty"))
+	}
+	if c.MaptOperator.Namespace == "" || c.MaptOperator.Deployment == "" {
+		errs = append(errs, errors.New("maptOperator requires a namespace and deployment"))
+	}
+	if c.EventWorkers <= 0 {
+		errs = append(errs, fmt.Errorf("eventWorkers must be positive, got %d", c.EventWorkers))
+	}
+	if c.EventQueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("eventQueueSize must be positive, got %d", c.EventQueueSize))
+	}
+	if c.Quota.Default < 0 {
+		errs = append(errs, fmt.Errorf("quota.default must not be negative, got %d", c.Quota.Default))
+	}
+	for user, limit := range c.Quota.Users {
+		if limit < 0 {
+			errs = append(errs, fmt.Errorf("quota.users.%s must not be negative, got %d", user, limit))
+		}
+	}
+	if c.Quota.NoticeRemaining < 0 {
+		errs = append(errs, fmt.Errorf("quota.noticeRemaining must not be negative, got %d", c.Quota.NoticeRemaining))
+	}
+	if c.Budget.NoticeRatio < 0 || c.Budget.NoticeRatio > 1 {
+		errs = append(errs, fmt.Errorf("budget.noticeRatio must be in [0, 1], got %g", c.Budget.NoticeRatio))
+	}
+	if c.Budget.HourlyCap < 0 {
+		errs = append(errs, fmt.Errorf("budget.hourlyCap must not be negative, got %.2f", c.Budget.HourlyCap))
+	}
+	if c.ConfirmCPUs < 0 {
+		errs = append(errs, fmt.Errorf("confirmCPUs must not be negative, got %d", c.ConfirmCPUs))
+	}
+	if c.CircuitBreaker.Threshold <= 0 {
+		errs = append(errs, fmt.Errorf("circuitBreaker.threshold must be positive, got %d", c.CircuitBreaker.Threshold))
+	}
+	if c.CircuitBreaker.Cooldown <= 0 {
+		errs = append(errs, fmt.Errorf("circuitBreaker.cooldown must be positive, got %s", time.Duration(c.CircuitBreaker.Cooldown)))
+	}
+	if time.Duration(c.Polling.Interval) < minPollInterval {
+		errs = append(errs, fmt.Errorf("polling.interval must be at least %s, got %s", minPollInterval, time.Duration(c.Polling.Interval)))
+	}
+	if c.Polling.Jitter < 0 || c.Polling.Jitter >= 1 {
+		errs = append(errs, fmt.Errorf("polling.jitter must be in [0, 1), got %g", c.Polling.Jitter))
+	}
+	if c.Polling.MaxBackoff < c.Polling.Interval {
+		errs = append(errs, fmt.Errorf("polling.maxBackoff must be at least polling.interval, got %s", time.Duration(c.Polling.MaxBackoff)))
+	}
+	if c.Reconnect.Initial <= 0 {
+		errs = append(errs, fmt.Errorf("reconnect.initial must be positive, got %s", time.Duration(c.Reconnect.Initial)))
+	}
+	if c.Reconnect.MaxInterval < c.Reconnect.Initial {
+		errs = append(errs, fmt.Errorf("reconnect.maxInterval must be at least reconnect.initial, got %s", time.Duration(c.Reconnect.MaxInterval)))
+	}
+	if c.Reconnect.Multiplier < 1 {
+		errs = append(errs, fmt.Errorf("reconnect.multiplier must be at least 1, got %g", c.Reconnect.Multiplier))
+	}
+	if c.Reconnect.MaxElapsed < c.Reconnect.MaxInterval {
+		errs = append(errs, fmt.Errorf("reconnect.maxElapsed must be at least reconnect.maxInterval, got %s", time.Duration(c.Reconnect.MaxElapsed)))
+	}
+	if c.Uploads.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("uploads.timeout must be positive, got %s", time.Duration(c.Uploads.Timeout)))
+	}
+	if c.Uploads.MaxBytes <= 0 {
+		errs = append(errs, fmt.Errorf("uploads.maxBytes must be positive, got %d", c.Uploads.MaxBytes))
+	}
+	if time.Duration(c.ProgressInterval) < minProgressInterval {
+		errs = append(errs, fmt.Errorf("progressInterval must be at least %s, got %s", minProgressInterval, time.Duration(c.ProgressInterval)))
+	}
+	if c.NotificationWindow < 0 {
+		errs = append(errs, fmt.Errorf("notificationWindow must not be negative, got %s", time.Duration(c.NotificationWindow)))
+	}
+	for _, webhook := range c.NotificationWebhooks {
+		if u, err := url.Parse(webhook); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("notificationWebhooks: invalid URL %q", Redact(webhook)))
+		}
+	}
+	for clusterType, namespace := range c.TypeNamespaces {
+		if problems := validation.IsDNS1123Label(namespace); len(problems) > 0 {
+			errs = append(errs, fmt.Errorf("typeNamespaces.%s: invalid namespace %q: %s", clusterType, namespace, strings.Join(problems, "; ")))
+		}
+	}
+	for clusterType, sizes := range c.TypeSizes {
+		if len(sizes) == 0 {
+			errs = append(errs, fmt.Errorf("typeSizes.%s must list at least one size", clusterType))
+		}
+		for _, size := range sizes {
+			if _, ok := c.Sizes[size]; !ok {
+				errs = append(errs, fmt.Errorf("typeSizes.%s: unknown size %q", clusterType, size))
+			}
+		}
+	}
+	for channel, defaults := range c.Channels {
+		if defaults.Namespace != "" {
+			if problems := validation.IsDNS1123Label(defaults.Namespace); len(problems) > 0 {
+				errs = append(errs, fmt.Errorf("channels.%s.namespace: invalid namespace %q: %s", channel, defaults.Namespace, strings.Join(problems, "; ")))
+			}
+		}
+		if defaults.DefaultTTL < 0 {
+			errs = append(errs, fmt.Errorf("channels.%s.defaultTTL must not be negative, got %s", channel, time.Duration(defaults.DefaultTTL)))
+		}
+		if c.MaxTTL > 0 && defaults.DefaultTTL > c.MaxTTL {
+			errs = append(errs, fmt.Errorf("channels.%s.defaultTTL %s exceeds maxTTL %s", channel, time.Duration(defaults.DefaultTTL), time.Duration(c.MaxTTL)))
+		}
+		if _, ok := c.Sizes[defaults.Size]; defaults.Size != "" && !ok {
+			errs = append(errs, fmt.Errorf("channels.%s.size: unknown size %q", channel, defaults.Size))
+		}
+	}
+	for provider, policy := range c.Regions {
+		if !slices.Contains(Providers, provider) {
+			errs = append(errs, fmt.Errorf("regions.%s: unknown provider, want one of %s", provider, strings.Join(Providers, ", ")))
+		}
+		for _, region := range slices.Concat(policy.Allow, policy.Deny) {
+			if strings.TrimSpace(region) == "" {
+				errs = append(errs, fmt.Errorf("regions.%s: region names must not be empty", provider))
+				break
+			}
+		}
+		if len(policy.Allow) > 0 && len(policy.Allowed()) == 0 {
+			errs = append(errs, fmt.Errorf("regions.%s: every allowed region is also denied", provider))
+		}
+	}
+	for provider, text := range c.ConsoleURLs {
+		if text == "" {
+			errs = append(errs, fmt.Errorf("consoleURLs.%s must not be empty", provider))
+		} else if _, err := template.New(provider).Parse(text); err != nil {
+			errs = append(errs, fmt.Errorf("consoleURLs.%s: %w", provider, err))
+		}
+	}
+	if _, err := regexp.Compile(c.RefPattern); err != nil {
+		errs = append(errs, fmt.Errorf("refPattern: %w", err))
+	}
+	if c.DefaultOwner != "" && !IsUserID(c.DefaultOwner) && !IsUserGroupID(c.DefaultOwner) {
+		errs = append(errs, fmt.Errorf("defaultOwner %q is not a Slack user or user group ID", c.DefaultOwner))
+	}
+	for _, channel := range c.AnnounceChannels {
+		if strings.TrimSpace(channel) == "" {
+			errs = append(errs, errors.New("announceChannels must not contain empty channel IDs"))
+			break
+		}
+	}
+	for team := range c.Teams {
+		if problems := validation.IsValidLabelValue(team); team == "" || len(problems) > 0 {
+			errs = append(errs, fmt.Errorf("teams: invalid team name %q: %s", team, strings.Join(problems, "; ")))
+		}
+	}
+	if err := c.Messages.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Branding.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Currency.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if !slices.Contains(c.OpenshiftVersions, c.DefaultOpenshiftVersion) {
+		errs = append(errs, fmt.Errorf("defaultOpenshiftVersion %q is not in openshiftVersions", c.DefaultOpenshiftVersion))
+	}
+	if !slices.Contains(c.K8sVersions, c.DefaultK8sVersion) {
+		errs = append(errs, fmt.Errorf("defaultK8sVersion %q is not in k8sVersions", c.DefaultK8sVersion))
+	}
+	return errors.Join(errs...)
+}
+
+// Get returns the configuration currently in use.
+// The returned value must be treated as read-only.
+func Get() *Config {
+	return current.Load()
+}
+
+// Set replaces the configuration currently in use.
+func Set(cfg *Config) {
+	current.Store(cfg)
+}
+
+// Reload loads the configuration again and, if it is valid, swaps it in atomically.
+// It returns a description of every setting that changed. If the new
+// configuration cannot be loaded, the current one is kept and the error is returned.
+func Reload() ([]string, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := Diff(Get(), cfg)
+	Set(cfg)
+	return changes, nil
+}
+
+// IsAdmin reports whether the given Slack user ID is a bot administrator.
+func (c *Config) IsAdmin(user string) bool {
+	for _, admin := range c.Admins {
+		if admin == user {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnerFor returns the owner of a cluster launched by user: the user, or
+// DefaultOwner for launches without one.
+func (c *Config) OwnerFor(user string) string {
+	if user == "" {
+		return c.DefaultOwner
+	}
+	return user
+}
+
+// slackIDPattern matches the Slack IDs of users (U, W) and user groups (S).
+var slackIDPattern = regexp.MustCompile(`^[UWS][A-Z0-9]{2,}$`)
+
+// IsUserID reports whether id looks like a Slack user ID.
+func IsUserID(id string) bool {
+	return slackIDPattern.MatchString(id) && id[0] != 'S'
+}
+
+// IsUserGroupID reports whether id looks like a Slack user group ID.
+func IsUserGroupID(id string) bool {
+	return slackIDPattern.MatchString(id) && id[0] == 'S'
+}
+
+// TeamOf returns the team the Slack user belongs to, or "" if none.
+// A user listed in several teams is assigned the first one alphabetically.
+func (c *Config) TeamOf(user string) string {
+	teams := slices.Sorted(maps.Keys(c.Teams))
+	for _, team := range teams {
+		if slices.Contains(c.Teams[team], user) {
+			return team
+		}
+	}
+	return ""
+}
+
+// InTeam reports whether the Slack user is a member of the team.
+func (c *Config) InTeam(user, team string) bool {
+	return slices.Contains(c.Teams[team], user)
+}
+
+// AllowsRegion reports whether launches on the provider may be pinned to the
+// region.
+func (c *Config) AllowsRegion(provider, region string) bool {
+	return c.Regions[provider].Allows(region)
+}
+
+// AllowedSizes returns the sorted names of the sizes the cluster type may be
+// launched with.
+func (c *Config) AllowedSizes(clusterType string) []string {
+	if sizes, ok := c.TypeSizes[clusterType]; ok {
+		return slices.Sorted(slices.Values(sizes))
+	}
+	return slices.Sorted(maps.Keys(c.Sizes))
+}
+
+// AllowsSize reports whether the cluster type may be launched with the size.
+func (c *Config) AllowsSize(clusterType, size string) bool {
+	sizes, ok := c.TypeSizes[clusterType]
+	return !ok || slices.Contains(sizes, size)
+}
+
+// NamespaceFor returns the namespace clusters of the type are created in,
+// before any channel override.
+func (c *Config) NamespaceFor(clusterType string) string {
+	if namespace := c.TypeNamespaces[clusterType]; namespace != "" {
+		return namespace
+	}
+	return c.Namespace
+}
+
+// MaxTTLFor returns the longest lifetime a cluster of the size may be
+// launched with: the size's maxTTL, or the global one for sizes without it
+// and for explicit instance types (an empty size). Zero is unbounded.
+func (c *Config) MaxTTLFor(size string) time.Duration {
+	if spec, ok := c.Sizes[size]; ok && spec.MaxTTL > 0 {
+		return time.Duration(spec.MaxTTL)
+	}
+	return time.Duration(c.MaxTTL)
+}
+
+// TTL returns the default cluster lifetime as a time.Duration.
+func (c *Config) TTL() time.Duration {
+	return time.Duration(c.DefaultTTL)
+}
+
+// splitList turns a comma-separated value into a slice, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
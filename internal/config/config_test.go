@@ -0,0 +1,729 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+func TestRegionPolicyAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RegionPolicy
+		region string
+		want   bool
+	}{
+		{name: "no policy allows every region", region: "ap-south-1", want: true},
+		{name: "allowed", policy: RegionPolicy{Allow: []string{"us-east-1", "eu-west-1"}}, region: "eu-west-1", want: true},
+		{name: "allowed ignoring case", policy: RegionPolicy{Allow: []string{"us-east-1"}}, region: "US-East-1", want: true},
+		{name: "not in the allow list", policy: RegionPolicy{Allow: []string{"us-east-1"}}, region: "ap-south-1"},
+		{name: "denied", policy: RegionPolicy{Deny: []string{"us-east-2"}}, region: "us-east-2"},
+		{name: "deny only allows the others", policy: RegionPolicy{Deny: []string{"us-east-2"}}, region: "us-east-1", want: true},
+		{name: "deny wins over allow", policy: RegionPolicy{Allow: []string{"us-east-1", "us-east-2"}, Deny: []string{"us-east-2"}}, region: "us-east-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Allows(tt.region); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegionPolicyAllowed(t *testing.T) {
+	policy := RegionPolicy{Allow: []string{"us-east-1", "us-east-2", "eu-west-1"}, Deny: []string{"us-east-2"}}
+	if got := strings.Join(policy.Allowed(), ","); got != "us-east-1,eu-west-1" {
+		t.Errorf("Allowed() = %s, want us-east-1,eu-west-1", got)
+	}
+	if got := (RegionPolicy{Deny: []string{"us-east-2"}}).Allowed(); got != nil {
+		t.Errorf("Allowed() of a deny-only policy = %v, want nil", got)
+	}
+}
+
+func TestValidateRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		regions map[string]RegionPolicy
+		wantErr string
+	}{
+		{name: "default provider", regions: map[string]RegionPolicy{DefaultProvider: {Allow: []string{"us-east-1"}}}},
+		{name: "unknown provider", regions: map[string]RegionPolicy{"azure": {Allow: []string{"eastus"}}}, wantErr: "regions.azure: unknown provider"},
+		{name: "empty region", regions: map[string]RegionPolicy{DefaultProvider: {Deny: []string{" "}}}, wantErr: "region names must not be empty"},
+		{name: "everything denied", regions: map[string]RegionPolicy{DefaultProvider: {Allow: []string{"us-east-1"}, Deny: []string{"us-east-1"}}}, wantErr: "every allowed region is also denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Regions = tt.regions
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsRegionsOfUnknownProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("regions:\n  gcp:\n    allow: [us-central1]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SPOTICUS_CONFIG", path)
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "regions.gcp: unknown provider") {
+		t.Errorf("Load() error = %v, want the unknown provider rejected", err)
+	}
+}
+
+// useConfigFile points SPOTICUS_CONFIG at a file holding data until the test
+// ends, and returns its path.
+func useConfigFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SPOTICUS_CONFIG", path)
+	return path
+}
+
+// useCurrent makes cfg the current configuration until the test ends.
+func useCurrent(t *testing.T, cfg *Config) {
+	t.Helper()
+	previous := Get()
+	Set(cfg)
+	t.Cleanup(func() { Set(previous) })
+}
+
+func TestReloadSwapsValidConfig(t *testing.T) {
+	path := useConfigFile(t, "quota:\n  default: 2\n")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	useCurrent(t, cfg)
+
+	if err := os.WriteFile(path, []byte("quota:\n  default: 5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(changes) != 1 || !strings.HasPrefix(changes[0], "quota: ") {
+		t.Errorf("Reload() changes = %q, want the quota change", changes)
+	}
+	if got := Get().Quota.Default; got != 5 {
+		t.Errorf("quota after reload = %d, want 5", got)
+	}
+}
+
+func TestReloadUnchanged(t *testing.T) {
+	useConfigFile(t, "quota:\n  default: 2\n")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	useCurrent(t, cfg)
+
+	if changes, err := Reload(); err != nil || len(changes) != 0 {
+		t.Errorf("Reload() = %q, %v, want no changes", changes, err)
+	}
+}
+
+func TestReloadKeepsConfigWhenInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid setting", data: "quota:\n  default: 2\ndefaultOwner: not-a-user\n"},
+		{name: "unknown field", data: "quotas:\n  default: 2\n"},
+		{name: "malformed yaml", data: "quota: [\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useConfigFile(t, "quota:\n  default: 2\n")
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			useCurrent(t, cfg)
+
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Reload(); err == nil {
+				t.Errorf("Reload() accepted an invalid configuration")
+			}
+			if Get() != cfg {
+				t.Errorf("the configuration was replaced, want the previous one kept")
+			}
+		})
+	}
+}
+
+func TestReloadIsSafeForConcurrentUse(t *testing.T) {
+	useConfigFile(t, "quota:\n  default: 2\n")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	useCurrent(t, cfg)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 20 {
+			if _, err := Reload(); err != nil {
+				t.Errorf("Reload: %v", err)
+			}
+		}
+	}()
+	for range 200 {
+		if Get().Quota.Default != 2 {
+			t.Fatalf("read a partial configuration")
+		}
+	}
+	<-done
+}
+
+func TestLoadOverridesMessageTemplates(t *testing.T) {
+	useConfigFile(t, "messages:\n  listEmpty: \"Nothing running.\"\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Messages.ListEmpty != "Nothing running." {
+		t.Errorf("listEmpty = %q, want the override", cfg.Messages.ListEmpty)
+	}
+	if cfg.Messages.ListFailed != messages.Default().ListFailed {
+		t.Errorf("listFailed = %q, want the default kept", cfg.Messages.ListFailed)
+	}
+}
+
+func TestLoadReplacesDefaultMaps(t *testing.T) {
+	useConfigFile(t, "sizes:\n  small: {cpus: 4, memoryGB: 16, nodes: 1, hourlyCost: 0.08}\n"+
+		"consoleURLs:\n  aws: \"https://console.example.com/{{.Name}}\"\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, ok := cfg.Sizes["small"]; !ok || len(cfg.Sizes) != 1 {
+		t.Errorf("sizes = %v, want only small", cfg.Sizes)
+	}
+	if len(cfg.ConsoleURLs) != 1 || cfg.ConsoleURLs["aws"] != "https://console.example.com/{{.Name}}" {
+		t.Errorf("consoleURLs = %v, want only the file's", cfg.ConsoleURLs)
+	}
+	if want := Default().InstanceTypes; !slices.Equal(cfg.InstanceTypes["aws"], want["aws"]) || len(cfg.InstanceTypes) != len(want) {
+		t.Errorf("instanceTypes = %v, want the defaults kept", cfg.InstanceTypes)
+	}
+}
+
+func TestLoadRejectsInvalidMessageTemplate(t *testing.T) {
+	useConfigFile(t, "messages:\n  listEmpty: \"{{.Count\"\n")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "messages.listEmpty") {
+		t.Errorf("Load() error = %v, want the invalid template reported", err)
+	}
+}
+
+func TestLoadKubeContextFromEnv(t *testing.T) {
+	t.Setenv("SPOTICUS_CONFIG", "")
+	t.Setenv("SPOTICUS_KUBE_CONTEXT", "staging")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.KubeContext != "staging" {
+		t.Errorf("kubeContext = %q, want staging", cfg.KubeContext)
+	}
+}
+
+func TestLoadNamespaceFromEnv(t *testing.T) {
+	t.Setenv("SPOTICUS_CONFIG", "")
+	t.Setenv("SPOTICUS_NAMESPACE", "spoticus")
+	t.Setenv("SPOTICUS_NAMESPACE_AUTO_CREATE", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Namespace != "spoticus" {
+		t.Errorf("namespace = %q, want spoticus", cfg.Namespace)
+	}
+	if !cfg.AutoCreateNamespace {
+		t.Errorf("autoCreateNamespace = false, want true")
+	}
+}
+
+func TestLoadNamespaceDefault(t *testing.T) {
+	t.Setenv("SPOTICUS_CONFIG", "")
+	t.Setenv("SPOTICUS_NAMESPACE", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Namespace != "default" || cfg.AutoCreateNamespace {
+		t.Errorf("namespace = %q, autoCreateNamespace = %v, want default without auto-creation", cfg.Namespace, cfg.AutoCreateNamespace)
+	}
+}
+
+func TestLoadRejectsInvalidNamespaceAutoCreate(t *testing.T) {
+	t.Setenv("SPOTICUS_CONFIG", "")
+	t.Setenv("SPOTICUS_NAMESPACE_AUTO_CREATE", "sometimes")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SPOTICUS_NAMESPACE_AUTO_CREATE") {
+		t.Errorf("Load() error = %v, want the invalid boolean reported", err)
+	}
+}
+
+func TestLoadAppliesBranding(t *testing.T) {
+	useConfigFile(t, "branding:\n  name: Clusterbot\n  emojis:\n    list: \":clipboard:\"\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !strings.HasPrefix(cfg.Messages.ListEmpty, ":clipboard:") {
+		t.Errorf("listEmpty = %q, want the custom list emoji", cfg.Messages.ListEmpty)
+	}
+	if !strings.Contains(cfg.Messages.HelpHeader, "Clusterbot") {
+		t.Errorf("helpHeader = %q, want the custom bot name", cfg.Messages.HelpHeader)
+	}
+}
+
+func TestLoadRejectsUnknownBrandingEmoji(t *testing.T) {
+	useConfigFile(t, "branding:\n  emojis:\n    lunch: \":sandwich:\"\n")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "branding.emojis") {
+		t.Errorf("Load() error = %v, want the unknown emoji reported", err)
+	}
+}
+
+func TestTeamOf(t *testing.T) {
+	cfg := Default()
+	cfg.Teams = map[string][]string{
+		"platform": {"U1", "U2"},
+		"payments": {"U2", "U3"},
+	}
+	tests := []struct {
+		user string
+		want string
+	}{
+		{user: "U1", want: "platform"},
+		{user: "U3", want: "payments"},
+		{user: "U2", want: "payments"},
+		{user: "U4", want: ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.TeamOf(tt.user); got != tt.want {
+			t.Errorf("TeamOf(%s) = %q, want %q", tt.user, got, tt.want)
+		}
+	}
+	if !cfg.InTeam("U2", "platform") || cfg.InTeam("U3", "platform") {
+		t.Errorf("InTeam does not follow the team members")
+	}
+}
+
+func TestAllowedSizes(t *testing.T) {
+	cfg := Default()
+	cfg.TypeSizes = map[string][]string{"openshift": {"xlarge", "large"}}
+
+	if got := cfg.AllowedSizes("openshift"); !slices.Equal(got, []string{"large", "xlarge"}) {
+		t.Errorf("AllowedSizes(openshift) = %v, want the restricted sizes, sorted", got)
+	}
+	if got := cfg.AllowedSizes("k8s"); !slices.Equal(got, []string{"large", "medium", "xlarge"}) {
+		t.Errorf("AllowedSizes(k8s) = %v, want every size", got)
+	}
+	if cfg.AllowsSize("openshift", "medium") {
+		t.Error("AllowsSize(openshift, medium) = true, want the size restricted")
+	}
+	if !cfg.AllowsSize("openshift", "large") || !cfg.AllowsSize("k8s", "medium") {
+		t.Error("AllowsSize() = false for an allowed combination")
+	}
+}
+
+func TestValidateTypeSizes(t *testing.T) {
+	tests := []struct {
+		name      string
+		typeSizes map[string][]string
+		wantErr   string
+	}{
+		{name: "known sizes", typeSizes: map[string][]string{"openshift": {"large", "xlarge"}}},
+		{name: "no sizes", typeSizes: map[string][]string{"openshift": {}}, wantErr: "typeSizes.openshift must list at least one size"},
+		{name: "unknown size", typeSizes: map[string][]string{"k8s": {"huge"}}, wantErr: `typeSizes.k8s: unknown size "huge"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.TypeSizes = tt.typeSizes
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePolling(t *testing.T) {
+	tests := []struct {
+		name    string
+		polling Polling
+		wantErr string
+	}{
+		{name: "default", polling: Default().Polling},
+		{name: "interval too short", polling: Polling{Interval: Duration(time.Second), MaxBackoff: Duration(time.Minute)}, wantErr: "polling.interval must be at least 5s"},
+		{name: "negative jitter", polling: Polling{Interval: Duration(time.Minute), Jitter: -0.1, MaxBackoff: Duration(time.Minute)}, wantErr: "polling.jitter must be in [0, 1)"},
+		{name: "full jitter", polling: Polling{Interval: Duration(time.Minute), Jitter: 1, MaxBackoff: Duration(time.Minute)}, wantErr: "polling.jitter must be in [0, 1)"},
+		{name: "backoff below the interval", polling: Polling{Interval: Duration(time.Minute), MaxBackoff: Duration(30 * time.Second)}, wantErr: "polling.maxBackoff must be at least polling.interval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Polling = tt.polling
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateProvisionTimeout(t *testing.T) {
+	cfg := Default()
+	spec := cfg.Sizes["large"]
+	spec.ProvisionTimeout = Duration(-time.Minute)
+	cfg.Sizes["large"] = spec
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `size "large": provisionTimeout must not be negative`) {
+		t.Errorf("Validate() = %v, want the negative timeout rejected", err)
+	}
+
+	spec.ProvisionTimeout = 0
+	cfg.Sizes["large"] = spec
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want a zero timeout to use the default", err)
+	}
+}
+
+func TestValidateRefPattern(t *testing.T) {
+	cfg := Default()
+	cfg.RefPattern = `[A-Z]+-[0-9]+`
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want a valid pattern accepted", err)
+	}
+	cfg.RefPattern = `[A-Z`
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "refPattern:") {
+		t.Errorf("Validate() = %v, want the invalid pattern rejected", err)
+	}
+}
+
+func TestValidateListMaxResults(t *testing.T) {
+	cfg := Default()
+	cfg.ListMaxResults = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want zero to show every cluster", err)
+	}
+	cfg.ListMaxResults = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "listMaxResults must not be negative") {
+		t.Errorf("Validate() = %v, want the negative maximum rejected", err)
+	}
+}
+
+func TestValidateUploads(t *testing.T) {
+	tests := []struct {
+		name    string
+		uploads Uploads
+		wantErr string
+	}{
+		{name: "default", uploads: Default().Uploads},
+		{name: "no timeout", uploads: Uploads{MaxBytes: 1024}, wantErr: "uploads.timeout must be positive"},
+		{name: "no size limit", uploads: Uploads{Timeout: Duration(time.Minute)}, wantErr: "uploads.maxBytes must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Uploads = tt.uploads
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDefaultK8sVersion(t *testing.T) {
+	cfg := Default()
+	cfg.DefaultK8sVersion = "1.20"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `defaultK8sVersion "1.20" is not in k8sVersions`) {
+		t.Errorf("Validate() = %v, want the default outside the allowlist rejected", err)
+	}
+}
+
+func TestValidateConsoleURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{name: "template", text: "https://console.example.com/{{.Region}}"},
+		{name: "empty", wantErr: "consoleURLs.gcp must not be empty"},
+		{name: "unparsable", text: "https://console.example.com/{{.Region", wantErr: "consoleURLs.gcp:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.ConsoleURLs["gcp"] = tt.text
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReconnect(t *testing.T) {
+	valid := Default().Reconnect
+	tests := []struct {
+		name    string
+		edit    func(*Reconnect)
+		wantErr string
+	}{
+		{name: "default", edit: func(*Reconnect) {}},
+		{name: "no initial wait", edit: func(r *Reconnect) { r.Initial = 0 }, wantErr: "reconnect.initial must be positive"},
+		{name: "interval below the initial wait", edit: func(r *Reconnect) { r.MaxInterval = Duration(time.Millisecond) }, wantErr: "reconnect.maxInterval must be at least reconnect.initial"},
+		{name: "shrinking multiplier", edit: func(r *Reconnect) { r.Multiplier = 0.5 }, wantErr: "reconnect.multiplier must be at least 1"},
+		{name: "elapsed below the interval", edit: func(r *Reconnect) { r.MaxElapsed = Duration(time.Second) }, wantErr: "reconnect.maxElapsed must be at least reconnect.maxInterval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Reconnect = valid
+			tt.edit(&cfg.Reconnect)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTopResults(t *testing.T) {
+	cfg := Default()
+	if cfg.TopResults != 5 {
+		t.Errorf("default topResults = %d, want 5", cfg.TopResults)
+	}
+	cfg.TopResults = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "topResults must be positive") {
+		t.Errorf("Validate() = %v, want a zero count rejected", err)
+	}
+}
+
+func TestValidateDeleteGracePeriod(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil || cfg.DeleteGracePeriod != 0 {
+		t.Errorf("default deleteGracePeriod %s, Validate() = %v, want immediate deletion by default", time.Duration(cfg.DeleteGracePeriod), err)
+	}
+	cfg.DeleteGracePeriod = Duration(-time.Minute)
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "deleteGracePeriod must not be negative") {
+		t.Errorf("Validate() = %v, want a negative grace period rejected", err)
+	}
+}
+
+func TestValidateUsageNoticeThresholds(t *testing.T) {
+	for name, edit := range map[string]func(*Config){
+		"quota.noticeRemaining must not be negative": func(c *Config) { c.Quota.NoticeRemaining = -1 },
+		"budget.noticeRatio must be in [0, 1]":       func(c *Config) { c.Budget.NoticeRatio = 1.5 },
+	} {
+		cfg := Default()
+		edit(cfg)
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("Validate() = %v, want an error containing %q", err, name)
+		}
+	}
+}
+
+func TestValidateNotificationWebhooks(t *testing.T) {
+	cfg := Default()
+	cfg.NotificationWebhooks = []string{"https://events.example.com/hooks/a"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want an https webhook accepted", err)
+	}
+	cfg.NotificationWebhooks = append(cfg.NotificationWebhooks, "ftp://events.example.com/hooks/b")
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "notificationWebhooks: invalid URL") {
+		t.Errorf("Validate() = %v, want a non-HTTP webhook rejected", err)
+	}
+}
+
+func TestOwnerFor(t *testing.T) {
+	cfg := Default()
+	if got := cfg.OwnerFor(""); got != "" {
+		t.Errorf("OwnerFor(\"\") without a default owner = %q, want none", got)
+	}
+	cfg.DefaultOwner = "SPLATFORM"
+	if got := cfg.OwnerFor(""); got != "SPLATFORM" {
+		t.Errorf("OwnerFor(\"\") = %q, want the default owner", got)
+	}
+	if got := cfg.OwnerFor("U1"); got != "U1" {
+		t.Errorf("OwnerFor(U1) = %q, want the user", got)
+	}
+}
+
+func TestSlackIDs(t *testing.T) {
+	tests := []struct {
+		id              string
+		user, userGroup bool
+	}{
+		{id: "U012ABCDEF", user: true},
+		{id: "W012ABCDEF", user: true},
+		{id: "S012ABCDEF", userGroup: true},
+		{id: "C012ABCDEF"},
+		{id: "u012abcdef"},
+		{id: "U1"},
+		{id: ""},
+	}
+	for _, tt := range tests {
+		if got := IsUserID(tt.id); got != tt.user {
+			t.Errorf("IsUserID(%q) = %v, want %v", tt.id, got, tt.user)
+		}
+		if got := IsUserGroupID(tt.id); got != tt.userGroup {
+			t.Errorf("IsUserGroupID(%q) = %v, want %v", tt.id, got, tt.userGroup)
+		}
+	}
+}
+
+func TestValidateDefaultOwner(t *testing.T) {
+	cfg := Default()
+	for _, owner := range []string{"U012ABCDEF", "S012ABCDEF"} {
+		cfg.DefaultOwner = owner
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with defaultOwner %s = %v, want it accepted", owner, err)
+		}
+	}
+	cfg.DefaultOwner = "#platform"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `defaultOwner "#platform" is not a Slack user or user group ID`) {
+		t.Errorf("Validate() = %v, want the invalid owner rejected", err)
+	}
+}
+
+func TestValidateNotificationWindow(t *testing.T) {
+	cfg := Default()
+	if time.Duration(cfg.NotificationWindow) != 10*time.Minute {
+		t.Errorf("default notificationWindow = %s, want 10m", time.Duration(cfg.NotificationWindow))
+	}
+	cfg.NotificationWindow = Duration(-time.Second)
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "notificationWindow must not be negative") {
+		t.Errorf("Validate() = %v, want a negative window rejected", err)
+	}
+}
+
+func TestMaxTTLFor(t *testing.T) {
+	cfg := Default()
+	for size, want := range map[string]time.Duration{
+		"medium": 7 * 24 * time.Hour,
+		"large":  7 * 24 * time.Hour,
+		"xlarge": 24 * time.Hour,
+		"":       7 * 24 * time.Hour,
+	} {
+		if got := cfg.MaxTTLFor(size); got != want {
+			t.Errorf("MaxTTLFor(%q) = %s, want %s", size, got, want)
+		}
+	}
+
+	cfg.MaxTTL = 0
+	if got := cfg.MaxTTLFor("large"); got != 0 {
+		t.Errorf("MaxTTLFor(large) without a global maximum = %s, want unbounded", got)
+	}
+}
+
+func TestValidateMaxTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(*Config)
+		want string
+	}{
+		{name: "negative", edit: func(c *Config) { c.MaxTTL = Duration(-time.Hour) }, want: "maxTTL must not be negative"},
+		{name: "default TTL over the maximum", edit: func(c *Config) { c.MaxTTL = Duration(4 * time.Hour) }, want: "defaultTTL 8h0m0s exceeds maxTTL 4h0m0s"},
+		{name: "negative for a size", edit: func(c *Config) {
+			spec := c.Sizes["large"]
+			spec.MaxTTL = Duration(-time.Hour)
+			c.Sizes["large"] = spec
+		}, want: `size "large": maxTTL must not be negative`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.edit(cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateInstanceTypeSpecs(t *testing.T) {
+	for name, spec := range map[string]SizeSpec{
+		"no cpus":         {MemoryGB: 32, HourlyCost: 0.15},
+		"no memory":       {CPUs: 8, HourlyCost: 0.15},
+		"negative cost":   {CPUs: 8, MemoryGB: 32, HourlyCost: -0.15},
+		"negative memory": {CPUs: 8, MemoryGB: -32},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Default()
+			cfg.InstanceTypeSpecs["m6i.2xlarge"] = spec
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "instanceTypeSpecs.m6i.2xlarge") {
+				t.Errorf("Validate() = %v, want the invalid spec reported", err)
+			}
+		})
+	}
+
+	cfg := Default()
+	cfg.InstanceTypeSpecs["m6i.2xlarge"] = SizeSpec{CPUs: 8, MemoryGB: 32}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() of a free instance type = %v, want no error", err)
+	}
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// Diff describes, in human-readable form, every setting that differs between old and updated.
+// Changes are returned in a stable order so they can be reported directly to users.
+func Diff(old, updated *Config) []string {
+	var changes []string
+
+	if !slices.Equal(old.Admins, updated.Admins) {
+		changes = append(changes, fmt.Sprintf("admins: [%s] → [%s]",
+			strings.Join(old.Admins, ", "), strings.Join(updated.Admins, ", ")))
+	}
+	if old.DefaultOwner != updated.DefaultOwner {
+		changes = append(changes, fmt.Sprintf("defaultOwner: %q → %q", old.DefaultOwner, updated.DefaultOwner))
+	}
+	if old.HistorySize != updated.HistorySize {
+		changes = append(changes, fmt.Sprintf("historySize: %d → %d", old.HistorySize, updated.HistorySize))
+	}
+	if old.ListMaxResults != updated.ListMaxResults {
+		changes = append(changes, fmt.Sprintf("listMaxResults: %d → %d", old.ListMaxResults, updated.ListMaxResults))
+	}
+	if old.DeleteGracePeriod != updated.DeleteGracePeriod {
+		changes = append(changes, fmt.Sprintf("deleteGracePeriod: %s → %s",
+			time.Duration(old.DeleteGracePeriod), time.Duration(updated.DeleteGracePeriod)))
+	}
+	if old.TopResults != updated.TopResults {
+		changes = append(changes, fmt.Sprintf("topResults: %d → %d", old.TopResults, updated.TopResults))
+	}
+	if old.DefaultTTL != updated.DefaultTTL {
+		changes = append(changes, fmt.Sprintf("defaultTTL: %s → %s", old.TTL(), updated.TTL()))
+	}
+	if old.MaxTTL != updated.MaxTTL {
+		changes = append(changes, fmt.Sprintf("maxTTL: %s → %s", time.Duration(old.MaxTTL), time.Duration(updated.MaxTTL)))
+	}
+	if !maps.EqualFunc(old.InstanceTypes, updated.InstanceTypes, slices.Equal[[]string]) {
+		changes = append(changes, fmt.Sprintf("instanceTypes: %v → %v", old.InstanceTypes, updated.InstanceTypes))
+	}
+	if !maps.EqualFunc(old.Regions, updated.Regions, func(a, b RegionPolicy) bool {
+		return slices.Equal(a.Allow, b.Allow) && slices.Equal(a.Deny, b.Deny)
+	}) {
+		changes = append(changes, fmt.Sprintf("regions: %+v → %+v", old.Regions, updated.Regions))
+	}
+	if !maps.Equal(old.ConsoleURLs, updated.ConsoleURLs) {
+		changes = append(changes, fmt.Sprintf("consoleURLs: %v → %v", old.ConsoleURLs, updated.ConsoleURLs))
+	}
+	if !slices.Equal(old.OpenshiftVersions, updated.OpenshiftVersions) {
+		changes = append(changes, fmt.Sprintf("openshiftVersions: [%s] → [%s]",
+			strings.Join(old.OpenshiftVersions, ", "), strings.Join(updated.OpenshiftVersions, ", ")))
+	}
+	if old.DefaultOpenshiftVersion != updated.DefaultOpenshiftVersion {
+		changes = append(changes, fmt.Sprintf("defaultOpenshiftVersion: %s → %s",
+			old.DefaultOpenshiftVersion, updated.DefaultOpenshiftVersion))
+	}
+	if !slices.Equal(old.K8sVersions, updated.K8sVersions) {
+		changes = append(changes, fmt.Sprintf("k8sVersions: [%s] → [%s]",
+			strings.Join(old.K8sVersions, ", "), strings.Join(updated.K8sVersions, ", ")))
+	}
+	if old.DefaultK8sVersion != updated.DefaultK8sVersion {
+		changes = append(changes, fmt.Sprintf("defaultK8sVersion: %s → %s",
+			old.DefaultK8sVersion, updated.DefaultK8sVersion))
+	}
+	if old.EventWorkers != updated.EventWorkers || old.EventQueueSize != updated.EventQueueSize {
+		changes = append(changes, fmt.Sprintf("eventWorkers/eventQueueSize: %d/%d → %d/%d (applies after restart)",
+			old.EventWorkers, old.EventQueueSize, updated.EventWorkers, updated.EventQueueSize))
+	}
+	if old.Namespace != updated.Namespace {
+		changes = append(changes, fmt.Sprintf("namespace: %s → %s", old.Namespace, updated.Namespace))
+	}
+	if old.MaptOperator != updated.MaptOperator {
+		changes = append(changes, fmt.Sprintf("maptOperator: %+v → %+v", old.MaptOperator, updated.MaptOperator))
+	}
+	if old.HealthAddr != updated.HealthAddr {
+		changes = append(changes, fmt.Sprintf("healthAddr: %q → %q (applies after a restart)", old.HealthAddr, updated.HealthAddr))
+	}
+	if old.KubeContext != updated.KubeContext {
+		changes = append(changes, fmt.Sprintf("kubeContext: %q → %q (applies to new connections)", old.KubeContext, updated.KubeContext))
+	}
+	if old.Cleanup != updated.Cleanup {
+		changes = append(changes, fmt.Sprintf("cleanup: %+v → %+v", old.Cleanup, updated.Cleanup))
+	}
+	if old.Quota.Default != updated.Quota.Default || old.Quota.NoticeRemaining != updated.Quota.NoticeRemaining ||
+		!maps.Equal(old.Quota.Users, updated.Quota.Users) {
+		changes = append(changes, fmt.Sprintf("quota: %+v → %+v", old.Quota, updated.Quota))
+	}
+	if old.TenantIsolation != updated.TenantIsolation {
+		changes = append(changes, fmt.Sprintf("tenantIsolation: %t → %t", old.TenantIsolation, updated.TenantIsolation))
+	}
+	if !maps.EqualFunc(old.Teams, updated.Teams, slices.Equal[[]string]) {
+		changes = append(changes, fmt.Sprintf("teams: %v → %v", old.Teams, updated.Teams))
+	}
+	if !maps.Equal(old.TypeNamespaces, updated.TypeNamespaces) {
+		changes = append(changes, fmt.Sprintf("typeNamespaces: %v → %v", old.TypeNamespaces, updated.TypeNamespaces))
+	}
+	if !maps.EqualFunc(old.TypeSizes, updated.TypeSizes, slices.Equal[[]string]) {
+		changes = append(changes, fmt.Sprintf("typeSizes: %v → %v", old.TypeSizes, updated.TypeSizes))
+	}
+	if !maps.Equal(old.Channels, updated.Channels) {
+		changes = append(changes, fmt.Sprintf("channels: %+v → %+v", old.Channels, updated.Channels))
+	}
+	if !slices.Equal(old.AnnounceChannels, updated.AnnounceChannels) {
+		changes = append(changes, fmt.Sprintf("announceChannels: [%s] → [%s]",
+			strings.Join(old.AnnounceChannels, ", "), strings.Join(updated.AnnounceChannels, ", ")))
+	}
+	if old.DisableWelcome != updated.DisableWelcome {
+		changes = append(changes, fmt.Sprintf("disableWelcome: %t → %t", old.DisableWelcome, updated.DisableWelcome))
+	}
+	if old.Maintenance != updated.Maintenance {
+		changes = append(changes, fmt.Sprintf("maintenance: %t → %t (applies at the next start)", old.Maintenance, updated.Maintenance))
+	}
+	if old.Budget.HourlyCap != updated.Budget.HourlyCap {
+		changes = append(changes, fmt.Sprintf("budget.hourlyCap: %s → %s",
+			updated.Currency.Hourly(old.Budget.HourlyCap), updated.Currency.Hourly(updated.Budget.HourlyCap)))
+	}
+	if old.Budget.NoticeRatio != updated.Budget.NoticeRatio {
+		changes = append(changes, fmt.Sprintf("budget.noticeRatio: %g → %g", old.Budget.NoticeRatio, updated.Budget.NoticeRatio))
+	}
+	if old.Currency != updated.Currency {
+		changes = append(changes, fmt.Sprintf("currency: %+v → %+v", old.Currency, updated.Currency))
+	}
+	if old.Tracing != updated.Tracing {
+		changes = append(changes, fmt.Sprintf("tracing: %+v → %+v (applies at the next start)", old.Tracing, updated.Tracing))
+	}
+	if old.RefPattern != updated.RefPattern {
+		changes = append(changes, fmt.Sprintf("refPattern: %q → %q", old.RefPattern, updated.RefPattern))
+	}
+	if old.LenientArgs != updated.LenientArgs {
+		changes = append(changes, fmt.Sprintf("lenientArgs: %t → %t", old.LenientArgs, updated.LenientArgs))
+	}
+	if old.ConfirmCPUs != updated.ConfirmCPUs {
+		changes = append(changes, fmt.Sprintf("confirmCPUs: %d → %d", old.ConfirmCPUs, updated.ConfirmCPUs))
+	}
+	if old.Polling != updated.Polling {
+		changes = append(changes, fmt.Sprintf("polling: %+v → %+v", old.Polling, updated.Polling))
+	}
+	if !slices.Equal(old.NotificationWebhooks, updated.NotificationWebhooks) {
+		// The URLs often embed a token, so only their number is reported.
+		changes = append(changes, fmt.Sprintf("notificationWebhooks: %d → %d URL(s)",
+			len(old.NotificationWebhooks), len(updated.NotificationWebhooks)))
+	}
+	if old.NotificationWindow != updated.NotificationWindow {
+		changes = append(changes, fmt.Sprintf("notificationWindow: %s → %s",
+			time.Duration(old.NotificationWindow), time.Duration(updated.NotificationWindow)))
+	}
+	if old.Reconnect != updated.Reconnect {
+		changes = append(changes, fmt.Sprintf("reconnect: %+v → %+v (applies after a restart)", old.Reconnect, updated.Reconnect))
+	}
+	if old.Uploads != updated.Uploads {
+		changes = append(changes, fmt.Sprintf("uploads: %+v → %+v", old.Uploads, updated.Uploads))
+	}
+	if old.CircuitBreaker != updated.CircuitBreaker {
+		changes = append(changes, fmt.Sprintf("circuitBreaker: %+v → %+v", old.CircuitBreaker, updated.CircuitBreaker))
+	}
+	if old.ProgressInterval != updated.ProgressInterval {
+		changes = append(changes, fmt.Sprintf("progressInterval: %s → %s", time.Duration(old.ProgressInterval), time.Duration(updated.ProgressInterval)))
+	}
+	if old.Branding.Name != updated.Branding.Name || old.Branding.DisableEmojis != updated.Branding.DisableEmojis ||
+		!maps.Equal(old.Branding.Emojis, updated.Branding.Emojis) {
+		changes = append(changes, fmt.Sprintf("branding: %+v → %+v", old.Branding, updated.Branding))
+	}
+	if old.Messages != updated.Messages {
+		changes = append(changes, "messages: templates updated")
+	}
+
+	changes = append(changes, diffSizes(old.Sizes, updated.Sizes, updated.Currency)...)
+	changes = append(changes, diffInstanceTypeSpecs(old.InstanceTypeSpecs, updated.InstanceTypeSpecs, updated.Currency)...)
+	return changes
+}
+
+// diffSizes reports sizes that were added, removed or redefined, with their
+// costs in currency.
+func diffSizes(old, updated map[string]SizeSpec, currency messages.Currency) []string {
+	names := map[string]struct{}{}
+	for name := range old {
+		names[name] = struct{}{}
+	}
+	for name := range updated {
+		names[name] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []string
+	for _, name := range sorted {
+		before, hadBefore := old[name]
+		after, hasAfter := updated[name]
+		switch {
+		case !hadBefore:
+			changes = append(changes, fmt.Sprintf("sizes.%s: added (%s, %s)", name, after.CPU(), after.RAM()))
+		case !hasAfter:
+			changes = append(changes, fmt.Sprintf("sizes.%s: removed", name))
+		case before != after:
+			change := fmt.Sprintf("sizes.%s: %s, %s, %s → %s, %s, %s", name,
+				before.CPU(), before.RAM(), currency.Hourly(before.HourlyCost), after.CPU(), after.RAM(), currency.Hourly(after.HourlyCost))
+			if before.ProvisionTimeout != after.ProvisionTimeout {
+				change += fmt.Sprintf(", provisionTimeout %s → %s",
+					time.Duration(before.ProvisionTimeout), time.Duration(after.ProvisionTimeout))
+			}
+			if before.MaxTTL != after.MaxTTL {
+				change += fmt.Sprintf(", maxTTL %s → %s", time.Duration(before.MaxTTL), time.Duration(after.MaxTTL))
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// diffInstanceTypeSpecs reports instance type specs that were added, removed
+// or redefined, with their costs in currency.
+func diffInstanceTypeSpecs(old, updated map[string]SizeSpec, currency messages.Currency) []string {
+	names := slices.Collect(maps.Keys(old))
+	for name := range updated {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var changes []string
+	for _, name := range names {
+		before, hadBefore := old[name]
+		after, hasAfter := updated[name]
+		switch {
+		case !hadBefore:
+			changes = append(changes, fmt.Sprintf("instanceTypeSpecs.%s: added (%s, %s, %s)", name, after.CPU(), after.RAM(), currency.Hourly(after.HourlyCost)))
+		case !hasAfter:
+			changes = append(changes, fmt.Sprintf("instanceTypeSpecs.%s: removed", name))
+		case before != after:
+			changes = append(changes, fmt.Sprintf("instanceTypeSpecs.%s: %s, %s, %s → %s, %s, %s", name,
+				before.CPU(), before.RAM(), currency.Hourly(before.HourlyCost), after.CPU(), after.RAM(), currency.Hourly(after.HourlyCost)))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+func TestDiffSizesFormatsCosts(t *testing.T) {
+	euro := messages.Currency{Symbol: "€", Precision: 2, GroupSeparator: ".", DecimalSeparator: ","}
+	old := map[string]SizeSpec{
+		"medium": {CPUs: 8, MemoryGB: 32, HourlyCost: 0.15},
+		"small":  {CPUs: 4, MemoryGB: 16, HourlyCost: 0.08},
+	}
+	updated := map[string]SizeSpec{
+		"medium": {CPUs: 8, MemoryGB: 32, HourlyCost: 1234.5, MaxTTL: Duration(24 * time.Hour)},
+		"large":  {CPUs: 16, MemoryGB: 64, HourlyCost: 0.30},
+	}
+
+	tests := []struct {
+		name     string
+		currency messages.Currency
+		want     []string
+	}{
+		{
+			name:     "default currency",
+			currency: messages.DefaultCurrency,
+			want: []string{
+				"sizes.large: added (16 CPUs, 64 GB RAM)",
+				"sizes.medium: 8 CPUs, 32 GB RAM, $0.15/h → 8 CPUs, 32 GB RAM, $1,234.50/h, maxTTL 0s → 24h0m0s",
+				"sizes.small: removed",
+			},
+		},
+		{
+			name:     "configured currency",
+			currency: euro,
+			want: []string{
+				"sizes.large: added (16 CPUs, 64 GB RAM)",
+				"sizes.medium: 8 CPUs, 32 GB RAM, €0,15/h → 8 CPUs, 32 GB RAM, €1.234,50/h, maxTTL 0s → 24h0m0s",
+				"sizes.small: removed",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffSizes(old, updated, tt.currency)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("diffSizes() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffBudgetUsesUpdatedCurrency(t *testing.T) {
+	old := Default()
+	updated := Default()
+	updated.Budget.HourlyCap = 12.5
+	updated.Currency = messages.Currency{Symbol: "€", Precision: 1, DecimalSeparator: ","}
+
+	changes := Diff(old, updated)
+	if !slices.Contains(changes, "budget.hourlyCap: €0,0/h → €12,5/h") {
+		t.Errorf("Diff() = %q, want the budget cap in the updated currency", changes)
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	if changes := Diff(Default(), Default()); len(changes) != 0 {
+		t.Errorf("Diff() of identical configurations = %q, want none", changes)
+	}
+}
+
+func TestDiffSizesReportsProvisionTimeout(t *testing.T) {
+	old := map[string]SizeSpec{"large": {CPUs: 16, MemoryGB: 64, HourlyCost: 0.30, ProvisionTimeout: Duration(45 * time.Minute)}}
+	updated := map[string]SizeSpec{"large": {CPUs: 16, MemoryGB: 64, HourlyCost: 0.30, ProvisionTimeout: Duration(time.Hour)}}
+
+	want := []string{"sizes.large: 16 CPUs, 64 GB RAM, $0.30/h → 16 CPUs, 64 GB RAM, $0.30/h, provisionTimeout 45m0s → 1h0m0s"}
+	if got := diffSizes(old, updated, messages.DefaultCurrency); !slices.Equal(got, want) {
+		t.Errorf("diffSizes() = %q, want %q", got, want)
+	}
+}
+
+func TestDiffTypeNamespaces(t *testing.T) {
+	updated := Default()
+	updated.TypeNamespaces = map[string]string{"openshift": "ocp"}
+
+	if changes := Diff(Default(), updated); !slices.Contains(changes, "typeNamespaces: map[] → map[openshift:ocp]") {
+		t.Errorf("Diff() = %q, want the type namespaces reported", changes)
+	}
+}
+
+func TestDiffNotificationWebhooksHidesURLs(t *testing.T) {
+	updated := Default()
+	updated.NotificationWebhooks = []string{"https://events.example.com/hooks/s3cr3t"}
+
+	changes := Diff(Default(), updated)
+	if !slices.Contains(changes, "notificationWebhooks: 0 → 1 URL(s)") || strings.Contains(strings.Join(changes, "\n"), "s3cr3t") {
+		t.Errorf("Diff() = %q, want only the number of webhooks reported", changes)
+	}
+}
+
+func TestDiffReportsMaxTTL(t *testing.T) {
+	updated := Default()
+	updated.MaxTTL = Duration(72 * time.Hour)
+	spec := updated.Sizes["xlarge"]
+	spec.MaxTTL = Duration(12 * time.Hour)
+	updated.Sizes["xlarge"] = spec
+
+	changes := strings.Join(Diff(Default(), updated), "\n")
+	for _, want := range []string{"maxTTL: 168h0m0s → 72h0m0s", "maxTTL 24h0m0s → 12h0m0s"} {
+		if !strings.Contains(changes, want) {
+			t.Errorf("Diff() = %q, want it to contain %q", changes, want)
+		}
+	}
+}
+
+func TestDiffInstanceTypeSpecs(t *testing.T) {
+	updated := Default()
+	updated.InstanceTypeSpecs["m6i.2xlarge"] = SizeSpec{CPUs: 8, MemoryGB: 32, HourlyCost: 0.18}
+	updated.InstanceTypeSpecs["m7i.2xlarge"] = SizeSpec{CPUs: 8, MemoryGB: 32, HourlyCost: 0.16}
+	delete(updated.InstanceTypeSpecs, "r6i.2xlarge")
+
+	changes := Diff(Default(), updated)
+	want := []string{
+		"instanceTypeSpecs.m6i.2xlarge: 8 CPUs, 32 GB RAM, $0.15/h → 8 CPUs, 32 GB RAM, $0.18/h",
+		"instanceTypeSpecs.m7i.2xlarge: added (8 CPUs, 32 GB RAM, $0.16/h)",
+		"instanceTypeSpecs.r6i.2xlarge: removed",
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("Diff() = %q, want %q", changes, want)
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// secretPattern matches credentials that must never be echoed back to Slack:
+// Slack bot, user, app and webhook tokens, and bearer tokens.
+var secretPattern = regexp.MustCompile(`xox[a-z]-[A-Za-z0-9-]+|xapp-[A-Za-z0-9-]+|hooks\.slack\.com/\S+|(?i:bearer\s+)\S+`)
+
+// redacted replaces matches of secretPattern.
+const redacted = "[REDACTED]"
+
+// Dump renders the configuration as YAML for display.
+//
+// The message templates are left out since they are long and rarely what one
+// is debugging, and anything that looks like a credential is redacted. Only
+// the scheme and host of the notification webhooks are shown, since their
+// paths often embed a token.
+// Tokens are read from the environment and never stored in Config, so the
+// redaction is a safety net for values pasted into the wrong setting.
+func Dump(c *Config) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", err
+	}
+	delete(fields, "messages")
+	if webhooks, ok := fields["notificationWebhooks"].([]any); ok {
+		for i, webhook := range webhooks {
+			webhooks[i] = redactURLPath(fmt.Sprint(webhook))
+		}
+	}
+
+	out, err := yaml.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return Redact(string(out)), nil
+}
+
+// redactURLPath keeps the scheme and host of a URL, replacing the rest.
+func redactURLPath(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	return u.Scheme + "://" + u.Host + "/" + redacted
+}
+
+// Redact replaces anything in s that looks like a credential with a placeholder.
+func Redact(s string) string {
+	return secretPattern.ReplaceAllString(s, redacted)
+}
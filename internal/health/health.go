@@ -0,0 +1,134 @@
+// Package health tracks the runtime state of the bot — uptime, the Slack
+// connection, the events processed and the readiness of the Kubernetes
+// backend — for the `uptime` command, the command gating and the /readyz
+// endpoint.
+package health
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Connection states reported by Tracker.
+const (
+	StateConnecting   = "connecting"
+	StateConnected    = "connected"
+	StateDisconnected = "disconnected"
+)
+
+// Tracker records the bot's connection state and event counters.
+// It is safe for concurrent use.
+type Tracker struct {
+	started time.Time
+
+	mu            sync.Mutex
+	state         string
+	connectedAt   time.Time
+	lastReconnect time.Time
+	reconnects    int
+
+	events atomic.Int64
+
+	backendChecked   bool
+	backendReady     bool
+	backendCheckedAt time.Time
+}
+
+// Snapshot is a point-in-time copy of a Tracker.
+type Snapshot struct {
+	Started       time.Time
+	Uptime        time.Duration
+	State         string
+	ConnectedAt   time.Time
+	LastReconnect time.Time
+	Reconnects    int
+	Events        int64
+
+	// BackendReady is the result of the last Kubernetes backend probe,
+	// taken at BackendCheckedAt. It is true until the first probe.
+	BackendReady     bool
+	BackendCheckedAt time.Time
+}
+
+// defaultTracker tracks the running bot.
+var defaultTracker = NewTracker()
+
+// Default returns the tracker of the running bot.
+func Default() *Tracker {
+	return defaultTracker
+}
+
+// NewTracker creates a tracker for a bot starting now.
+func NewTracker() *Tracker {
+	return &Tracker{started: time.Now(), state: StateDisconnected}
+}
+
+// Connecting records that a connection to Slack is being opened.
+func (t *Tracker) Connecting() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state = StateConnecting
+}
+
+// Connected records a successful connection. Every connection after the
+// first one counts as a reconnect.
+func (t *Tracker) Connected() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if !t.connectedAt.IsZero() {
+		t.reconnects++
+		t.lastReconnect = now
+	}
+	t.state = StateConnected
+	t.connectedAt = now
+}
+
+// Disconnected records that the connection to Slack was lost.
+func (t *Tracker) Disconnected() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state = StateDisconnected
+}
+
+// EventProcessed counts an event handed to the handlers.
+func (t *Tracker) EventProcessed() {
+	t.events.Add(1)
+}
+
+// SetBackend records the result of a Kubernetes backend probe: ready when
+// err is nil.
+func (t *Tracker) SetBackend(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.backendChecked = true
+	t.backendReady = err == nil
+	t.backendCheckedAt = time.Now()
+}
+
+// BackendReady reports whether the last backend probe succeeded. The backend
+// is assumed ready until it has been probed.
+func (t *Tracker) BackendReady() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.backendChecked || t.backendReady
+}
+
+// Snapshot returns the current state.
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Snapshot{
+		Started:       t.started,
+		Uptime:        time.Since(t.started),
+		State:         t.state,
+		ConnectedAt:   t.connectedAt,
+		LastReconnect: t.lastReconnect,
+		Reconnects:    t.reconnects,
+		Events:        t.events.Load(),
+
+		BackendReady:     !t.backendChecked || t.backendReady,
+		BackendCheckedAt: t.backendCheckedAt,
+	}
+}
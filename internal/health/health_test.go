@@ -0,0 +1,90 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrackerCountsReconnectsAndEvents(t *testing.T) {
+	tracker := NewTracker()
+	if got := tracker.Snapshot(); got.State != StateDisconnected || got.Reconnects != 0 || got.Events != 0 {
+		t.Fatalf("new tracker = %+v, want disconnected with no reconnects or events", got)
+	}
+
+	tracker.Connecting()
+	if got := tracker.Snapshot().State; got != StateConnecting {
+		t.Errorf("state = %s, want %s", got, StateConnecting)
+	}
+	tracker.Connected()
+	first := tracker.Snapshot()
+	if first.State != StateConnected || first.Reconnects != 0 || !first.LastReconnect.IsZero() {
+		t.Errorf("after the first connection = %+v, want connected without a reconnect", first)
+	}
+
+	tracker.Disconnected()
+	tracker.Connected()
+	tracker.EventProcessed()
+	tracker.EventProcessed()
+	got := tracker.Snapshot()
+	if got.Reconnects != 1 || got.LastReconnect.IsZero() {
+		t.Errorf("reconnects = %d, last at %v, want one reconnect recorded", got.Reconnects, got.LastReconnect)
+	}
+	if got.Events != 2 {
+		t.Errorf("events = %d, want 2", got.Events)
+	}
+	if got.ConnectedAt.Before(first.ConnectedAt) {
+		t.Errorf("connected at %v, want the reconnection time", got.ConnectedAt)
+	}
+}
+
+func TestTrackerBackendReadiness(t *testing.T) {
+	tracker := NewTracker()
+	if !tracker.BackendReady() {
+		t.Errorf("BackendReady() = false before any probe, want true")
+	}
+
+	tracker.SetBackend(errors.New("connection refused"))
+	if tracker.BackendReady() || tracker.Snapshot().BackendCheckedAt.IsZero() {
+		t.Errorf("after a failed probe the backend is ready or unchecked")
+	}
+	tracker.SetBackend(nil)
+	if !tracker.BackendReady() {
+		t.Errorf("BackendReady() = false after a successful probe")
+	}
+}
+
+func TestHandlerReadiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		connected  bool
+		backendErr error
+		wantCode   int
+	}{
+		{name: "ready", connected: true, wantCode: http.StatusOK},
+		{name: "slack disconnected", wantCode: http.StatusServiceUnavailable},
+		{name: "backend down", connected: true, backendErr: errors.New("connection refused"), wantCode: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker()
+			if tt.connected {
+				tracker.Connected()
+			}
+			tracker.SetBackend(tt.backendErr)
+			handler := Handler(tracker)
+
+			ready := httptest.NewRecorder()
+			handler.ServeHTTP(ready, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if ready.Code != tt.wantCode {
+				t.Errorf("/readyz = %d, want %d", ready.Code, tt.wantCode)
+			}
+			live := httptest.NewRecorder()
+			handler.ServeHTTP(live, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if live.Code != http.StatusOK {
+				t.Errorf("/healthz = %d, want 200", live.Code)
+			}
+		})
+	}
+}
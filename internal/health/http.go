@@ -0,0 +1,33 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Handler serves the liveness and readiness endpoints of the tracker:
+//
+//   - /healthz answers 200 as long as the process is serving.
+//   - /readyz answers 200 while Slack is connected and the Kubernetes backend
+//     is ready, and 503 naming what is not otherwise.
+//
+// The readiness is the same state the command path uses to refuse
+// backend-dependent commands.
+func Handler(t *Tracker) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		snapshot := t.Snapshot()
+		switch {
+		case snapshot.State != StateConnected:
+			http.Error(w, "slack "+snapshot.State, http.StatusServiceUnavailable)
+		case !snapshot.BackendReady:
+			http.Error(w, "kubernetes backend not ready", http.StatusServiceUnavailable)
+		default:
+			fmt.Fprintln(w, "ok")
+		}
+	})
+	return mux
+}
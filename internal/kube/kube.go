@@ -0,0 +1,105 @@
+// Package kube resolves the Kubernetes connection settings shared by the
+// bot's clients, so every component talks to the same cluster.
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// inClusterContext is reported as the active context when no kubeconfig context applies.
+const inClusterContext = "in-cluster"
+
+// RestConfig returns the client configuration for the given kubeconfig context.
+//
+// With an empty context name the standard controller-runtime lookup is used
+// (--kubeconfig, KUBECONFIG, in-cluster, ~/.kube/config). Otherwise the named
+// context is selected from the kubeconfig files through clientcmd.
+func RestConfig(contextName string) (*rest.Config, error) {
+	if contextName == "" {
+		return config.GetConfig()
+	}
+	return clientConfig(contextName).ClientConfig()
+}
+
+// ActiveContext returns the name of the kubeconfig context the bot connects with.
+func ActiveContext(contextName string) string {
+	if contextName != "" {
+		return contextName
+	}
+	raw, err := clientConfig("").RawConfig()
+	if err != nil || raw.CurrentContext == "" {
+		return inClusterContext
+	}
+	return raw.CurrentContext
+}
+
+// clientConfig loads the kubeconfig files, overriding the current context when one is given.
+func clientConfig(contextName string) clientcmd.ClientConfig {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	)
+}
+
+// EnsureNamespace checks that the namespace exists, creating it when create is true.
+func EnsureNamespace(ctx context.Context, restConfig *rest.Config, name string, create bool) error {
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("creating kubernetes client: %w", err)
+	}
+
+	_, err = client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return nil
+	case !apierrors.IsNotFound(err):
+		return fmt.Errorf("checking namespace %q: %w", name, err)
+	case !create:
+		return fmt.Errorf("namespace %q does not exist; create it or set SPOTICUS_NAMESPACE_AUTO_CREATE=true", name)
+	}
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if _, err := client.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("creating namespace %q: %w", name, err)
+	}
+	return nil
+}
+
+// CheckAPIResources verifies that the API server serves the given kinds in
+// groupVersion, e.g. to detect that an operator's CRDs are not installed.
+func CheckAPIResources(restConfig *rest.Config, groupVersion string, kinds ...string) error {
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("creating kubernetes client: %w", err)
+	}
+
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return fmt.Errorf("discovering %s: %w", groupVersion, err)
+	}
+
+	served := make(map[string]bool, len(resources.APIResources))
+	for _, resource := range resources.APIResources {
+		served[resource.Kind] = true
+	}
+	var missing []string
+	for _, kind := range kinds {
+		if !served[kind] {
+			missing = append(missing, kind)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s does not serve %s", groupVersion, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package kube
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// useKubeconfig points KUBECONFIG at the multi-context fixture until the test
+// ends.
+func useKubeconfig(t *testing.T) {
+	t.Helper()
+	path, err := filepath.Abs(filepath.Join("testdata", "kubeconfig.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", path)
+}
+
+func TestRestConfigSelectsContext(t *testing.T) {
+	useKubeconfig(t)
+	tests := []struct {
+		context  string
+		wantHost string
+	}{
+		{context: "", wantHost: "https://dev.example.com:6443"},
+		{context: "dev", wantHost: "https://dev.example.com:6443"},
+		{context: "staging", wantHost: "https://staging.example.com:6443"},
+	}
+	for _, tt := range tests {
+		t.Run("context "+tt.context, func(t *testing.T) {
+			cfg, err := RestConfig(tt.context)
+			if err != nil {
+				t.Fatalf("RestConfig(%q): %v", tt.context, err)
+			}
+			if cfg.Host != tt.wantHost {
+				t.Errorf("RestConfig(%q) host = %s, want %s", tt.context, cfg.Host, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestRestConfigUnknownContext(t *testing.T) {
+	useKubeconfig(t)
+	if _, err := RestConfig("production"); err == nil {
+		t.Errorf("RestConfig(production) succeeded, want the unknown context rejected")
+	}
+}
+
+func TestActiveContext(t *testing.T) {
+	useKubeconfig(t)
+	if got := ActiveContext(""); got != "dev" {
+		t.Errorf("ActiveContext() = %q, want the current context dev", got)
+	}
+	if got := ActiveContext("staging"); got != "staging" {
+		t.Errorf("ActiveContext(staging) = %q, want staging", got)
+	}
+}
+
+func TestActiveContextWithoutKubeconfig(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("HOME", t.TempDir())
+	if got := ActiveContext(""); got != inClusterContext {
+		t.Errorf("ActiveContext() = %q, want %q", got, inClusterContext)
+	}
+}
@@ -0,0 +1,103 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// namespaceServer is a fake API server holding namespaces, recording the ones
+// created.
+type namespaceServer struct {
+	mu       sync.Mutex
+	existing map[string]bool
+	created  []string
+}
+
+// start serves the namespaces until the test ends, returning a config to
+// reach them. The server only speaks JSON, not protobuf.
+func (s *namespaceServer) start(t *testing.T) *rest.Config {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(server.Close)
+	return &rest.Config{Host: server.URL, ContentConfig: rest.ContentConfig{ContentType: "application/json"}}
+}
+
+func (s *namespaceServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	name := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")
+	switch {
+	case r.Method == http.MethodGet && s.existing[name]:
+		writeJSON(w, http.StatusOK, &corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		})
+	case r.Method == http.MethodGet:
+		status := apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, name).ErrStatus
+		writeJSON(w, http.StatusNotFound, &status)
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces":
+		var namespace corev1.Namespace
+		if err := json.NewDecoder(r.Body).Decode(&namespace); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.created = append(s.created, namespace.Name)
+		s.existing[namespace.Name] = true
+		writeJSON(w, http.StatusCreated, &namespace)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestEnsureNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    bool
+		create      bool
+		wantErr     string
+		wantCreated bool
+	}{
+		{name: "exists", existing: true},
+		{name: "exists with auto-create", existing: true, create: true},
+		{name: "missing", wantErr: "SPOTICUS_NAMESPACE_AUTO_CREATE"},
+		{name: "missing with auto-create", create: true, wantCreated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &namespaceServer{existing: map[string]bool{}}
+			if tt.existing {
+				server.existing["spoticus"] = true
+			}
+
+			err := EnsureNamespace(context.Background(), server.start(t), "spoticus", tt.create)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("EnsureNamespace() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("EnsureNamespace: %v", err)
+			}
+			if created := len(server.created) == 1 && server.created[0] == "spoticus"; created != tt.wantCreated {
+				t.Errorf("created %v, want the namespace created = %v", server.created, tt.wantCreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package leader provides optional Kubernetes lease-based leader election,
+// so that several replicas of the bot can run for availability while only
+// one of them processes Slack events at a time.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/kube"
+)
+
+// Lease timings, matching the client-go and controller-runtime defaults.
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// Run blocks until ctx is cancelled or leadership is lost.
+//
+// The instance competes for the configured Lease and calls run once it
+// becomes the leader; standbys simply wait for the lease. When an elected
+// instance loses the lease, onLost is called so the process can stop
+// handling events before another replica takes over.
+func Run(ctx context.Context, cfg spoticusConfig.LeaderElection, run func(ctx context.Context), onLost func()) error {
+	restConfig, err := kube.RestConfig(spoticusConfig.Get().KubeContext)
+	if err != nil {
+		return fmt.Errorf("loading kubernetes config: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("creating kubernetes client: %w", err)
+	}
+
+	identity, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("determining leader election identity: %w", err)
+	}
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Name:      cfg.LeaseName,
+				Namespace: cfg.Namespace,
+			},
+			Client:     client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks:       callbacks(identity, run, onLost),
+	})
+	if err != nil {
+		return fmt.Errorf("creating leader elector: %w", err)
+	}
+
+	log.Printf("Waiting to acquire lease %s/%s as %s", cfg.Namespace, cfg.LeaseName, identity)
+	elector.Run(ctx)
+	return nil
+}
+
+// callbacks wires the leader election events to the bot lifecycle.
+//
+// run is started when this instance becomes the leader. onLost is only
+// called if leadership was actually held, since client-go also invokes
+// OnStoppedLeading when an instance exits without ever leading.
+func callbacks(identity string, run func(ctx context.Context), onLost func()) leaderelection.LeaderCallbacks {
+	// OnStartedLeading runs in its own goroutine, concurrently with
+	// OnStoppedLeading.
+	var leading atomic.Bool
+	return leaderelection.LeaderCallbacks{
+		OnStartedLeading: func(ctx context.Context) {
+			leading.Store(true)
+			log.Printf("✅ %s acquired leadership, processing events", identity)
+			run(ctx)
+		},
+		OnStoppedLeading: func() {
+			if !leading.Load() {
+				return
+			}
+			log.Printf("⚠️ %s lost leadership, stopping event processing", identity)
+			onLost()
+		},
+		OnNewLeader: func(current string) {
+			if current != identity {
+				log.Printf("Standing by, current leader is %s", current)
+			}
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package leader
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCallbacksLostAfterLeading(t *testing.T) {
+	started := make(chan struct{})
+	var lost atomic.Int32
+	cb := callbacks("replica-a", func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	}, func() { lost.Add(1) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		cb.OnStartedLeading(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("run was not started on acquiring leadership")
+	}
+	cb.OnStoppedLeading()
+	cancel()
+	<-done
+
+	if got := lost.Load(); got != 1 {
+		t.Errorf("onLost called %d times, want 1", got)
+	}
+}
+
+func TestCallbacksStoppedWithoutLeading(t *testing.T) {
+	ran := false
+	lost := false
+	cb := callbacks("replica-b", func(ctx context.Context) { ran = true }, func() { lost = true })
+
+	cb.OnNewLeader("replica-a")
+	cb.OnStoppedLeading()
+
+	if ran {
+		t.Error("run was started on a standby")
+	}
+	if lost {
+		t.Error("onLost called on a standby that never led")
+	}
+}
@@ -0,0 +1,135 @@
+package messages
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// defaultBotName is the bot name used in the default templates.
+const defaultBotName = "Spoticus"
+
+// defaultEmojis names the emojis used by the default templates, so that
+// Branding can swap them for workspace-specific ones.
+var defaultEmojis = map[string]string{
+	"error":       "❌",
+	"warning":     "⚠️",
+	"denied":      "⛔",
+	"launch":      "🚀",
+	"schedule":    "⏰",
+	"ready":       "✅",
+	"waiting":     "⏳",
+	"timeout":     "⌛",
+	"ping":        "🏓",
+	"health":      "🩺",
+	"list":        "📋",
+	"stats":       "📊",
+	"top":         "💸",
+	"capacity":    "🧮",
+	"status":      "🔎",
+	"diff":        "🔍",
+	"cleanup":     "🧹",
+	"done":        "🗑️",
+	"cancel":      "🛑",
+	"help":        "📖",
+	"history":     "🕘",
+	"diagnostics": "🧰",
+	"permissions": "🔐",
+	"reload":      "🔄",
+	"config":      "⚙️",
+	"maintenance": "🚧",
+	"welcome":     "👋",
+	"announce":    "📣",
+	"pin":         "📌",
+	"preset":      "💾",
+	"rename":      "🏷️",
+
+	// Cluster status indicators in `list`.
+	"healthy":      "🟢",
+	"provisioning": "🟡",
+	"failed":       "🔴",
+	"unknown":      "⚪",
+}
+
+// Branding customizes the bot's personality in every message.
+type Branding struct {
+	// Name replaces the bot name ("Spoticus") in messages.
+	Name string `json:"name,omitempty"`
+
+	// Emojis replaces the default emojis by name, e.g. {"launch": ":rocket:"}.
+	// The names are those of the default emoji table: error, warning, denied,
+	// launch, schedule, ready, waiting, timeout, ping, health, list, stats,
+	// top, capacity, status, diff, cleanup, done, cancel, help, history,
+	// diagnostics, permissions, reload, config, maintenance, welcome, announce,
+	// pin, preset, rename, and the list status indicators healthy,
+	// provisioning, failed and unknown.
+	Emojis map[string]string `json:"emojis,omitempty"`
+
+	// DisableEmojis strips every emoji from the messages. It takes precedence over Emojis.
+	DisableEmojis bool `json:"disableEmojis,omitempty"`
+}
+
+// Validate checks that every emoji override names a known emoji.
+func (b Branding) Validate() error {
+	for name := range b.Emojis {
+		if _, ok := defaultEmojis[name]; !ok {
+			names := make([]string, 0, len(defaultEmojis))
+			for known := range defaultEmojis {
+				names = append(names, known)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("branding.emojis: unknown emoji %q, expected one of %s", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// Apply returns the templates with the branding applied to each of them.
+func (b Branding) Apply(m Messages) Messages {
+	v := reflect.ValueOf(&m).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		v.Field(i).SetString(b.Text(v.Field(i).String()))
+	}
+	return m
+}
+
+// Text applies the branding to a single piece of text.
+func (b Branding) Text(text string) string {
+	if b.Name != "" {
+		text = strings.ReplaceAll(text, defaultBotName, b.Name)
+	}
+	if b.DisableEmojis {
+		return stripEmojis(text)
+	}
+	for name, emoji := range b.Emojis {
+		text = strings.ReplaceAll(text, defaultEmojis[name], emoji)
+	}
+	return text
+}
+
+// stripEmojis removes emojis from text, along with the space following each.
+func stripEmojis(text string) string {
+	var b strings.Builder
+	skipSpace := false
+	for _, r := range text {
+		if isEmoji(r) {
+			skipSpace = true
+			continue
+		}
+		if skipSpace && r == ' ' {
+			skipSpace = false
+			continue
+		}
+		skipSpace = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isEmoji reports whether r is an emoji or one of the invisible characters
+// that combine emojis (variation selectors and zero-width joiners).
+func isEmoji(r rune) bool {
+	return unicode.Is(unicode.So, r) || r == '\uFE0F' || r == '\u200D'
+}
@@ -0,0 +1,56 @@
+package messages
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBrandingText(t *testing.T) {
+	tests := []struct {
+		name     string
+		branding Branding
+		text     string
+		want     string
+	}{
+		{name: "no branding", text: "🚀 Spoticus is launching", want: "🚀 Spoticus is launching"},
+		{name: "bot name", branding: Branding{Name: "Clusterbot"}, text: "📖 *Spoticus commands:*", want: "📖 *Clusterbot commands:*"},
+		{name: "emoji override", branding: Branding{Emojis: map[string]string{"launch": ":rocket:"}}, text: "🚀 Launching", want: ":rocket: Launching"},
+		{name: "other emojis kept", branding: Branding{Emojis: map[string]string{"launch": ":rocket:"}}, text: "❌ Failed", want: "❌ Failed"},
+		{name: "emojis disabled", branding: Branding{DisableEmojis: true}, text: "🗑️ Deleted, ✅ ready", want: "Deleted, ready"},
+		{name: "disabled over overrides", branding: Branding{DisableEmojis: true, Emojis: map[string]string{"launch": ":rocket:"}}, text: "🚀 Launching", want: "Launching"},
+		{name: "name with emojis disabled", branding: Branding{Name: "Clusterbot", DisableEmojis: true}, text: "👋 Hi, I'm Spoticus!", want: "Hi, I'm Clusterbot!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.branding.Text(tt.text); got != tt.want {
+				t.Errorf("Text(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrandingApply(t *testing.T) {
+	branding := Branding{Name: "Clusterbot", Emojis: map[string]string{"list": ":clipboard:"}}
+
+	m := branding.Apply(Default())
+
+	if !strings.HasPrefix(m.ListEmpty, ":clipboard: *Cluster List*") {
+		t.Errorf("ListEmpty = %q, want the custom list emoji", m.ListEmpty)
+	}
+	if !strings.Contains(m.Welcome, "I'm Clusterbot!") {
+		t.Errorf("Welcome = %q, want the custom bot name", m.Welcome)
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("branded messages are invalid: %v", err)
+	}
+}
+
+func TestBrandingValidate(t *testing.T) {
+	if err := (Branding{Emojis: map[string]string{"launch": ":rocket:", "failed": ":x:"}}).Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	err := Branding{Emojis: map[string]string{"lunch": ":sandwich:"}}.Validate()
+	if err == nil || !strings.Contains(err.Error(), `unknown emoji "lunch"`) {
+		t.Errorf("Validate() error = %v, want the unknown emoji reported", err)
+	}
+}
@@ -0,0 +1,130 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCurrencyPrecision bounds the decimals of the amounts in messages.
+const maxCurrencyPrecision = 4
+
+// Currency formats the estimated costs shown in messages.
+type Currency struct {
+	// Symbol is written before the amounts, e.g. "$" or "€".
+	Symbol string `json:"symbol,omitempty"`
+
+	// Precision is the number of decimals shown, from 0 to 4.
+	Precision int `json:"precision"`
+
+	// GroupSeparator separates the thousands, e.g. "," in "$1,234.50".
+	// Empty does not group them.
+	GroupSeparator string `json:"groupSeparator"`
+
+	// DecimalSeparator separates the decimals, e.g. "." or ",".
+	DecimalSeparator string `json:"decimalSeparator,omitempty"`
+}
+
+// DefaultCurrency formats amounts in US dollars, e.g. "$1,234.50".
+var DefaultCurrency = Currency{Symbol: "$", Precision: 2, GroupSeparator: ",", DecimalSeparator: "."}
+
+// Validate checks that the precision is supported and the decimals can be told
+// apart from the thousands.
+func (c Currency) Validate() error {
+	if c.Precision < 0 || c.Precision > maxCurrencyPrecision {
+		return fmt.Errorf("currency.precision must be between 0 and %d, got %d", maxCurrencyPrecision, c.Precision)
+	}
+	if c.Precision > 0 && (c.DecimalSeparator == "" || c.DecimalSeparator == c.GroupSeparator) {
+		return errors.New("currency.decimalSeparator must be set and differ from currency.groupSeparator")
+	}
+	return nil
+}
+
+// Format renders an amount, e.g. "$1,234.50". Amounts that round to zero
+// lose their sign.
+func (c Currency) Format(amount float64) string {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return c.Symbol + strconv.FormatFloat(amount, 'f', -1, 64)
+	}
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', c.Precision, 64)
+	whole, decimals, _ := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		b.WriteString("-")
+	}
+	b.WriteString(c.Symbol)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(c.GroupSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	if decimals != "" {
+		b.WriteString(c.DecimalSeparator + decimals)
+	}
+	return b.String()
+}
+
+// Hourly renders an hourly rate, e.g. "$1.50/h".
+func (c Currency) Hourly(amount float64) string {
+	return c.Format(amount) + "/h"
+}
+
+// durationUnits are the units FormatDuration writes, largest first.
+var durationUnits = []struct {
+	suffix string
+	size   time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// FormatDuration renders a duration with its two largest units, e.g. "1d12h",
+// "2h30m" or "45s", rounding off the rest. Durations under a second are
+// "0s"; negative ones are prefixed with "-".
+func FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+		if d < 0 { // math.MinInt64 has no positive counterpart.
+			d = math.MaxInt64
+		}
+	}
+
+	largest := len(durationUnits) - 1
+	for i, unit := range durationUnits {
+		if d >= unit.size {
+			largest = i
+			break
+		}
+	}
+	smallest := min(largest+1, len(durationUnits)-1)
+	if rounded := d.Round(durationUnits[smallest].size); rounded > 0 {
+		d = rounded
+	}
+	// Rounding up may carry into a larger unit, e.g. 23h59m59s to 1d.
+	if largest > 0 && d >= durationUnits[largest-1].size {
+		largest--
+		smallest--
+	}
+
+	var b strings.Builder
+	for _, unit := range durationUnits[largest : smallest+1] {
+		count := d / unit.size
+		d -= count * unit.size
+		if count > 0 {
+			fmt.Fprintf(&b, "%d%s", count, unit.suffix)
+		}
+	}
+	if b.Len() == 0 {
+		return "0s"
+	}
+	return sign + b.String()
+}
@@ -0,0 +1,90 @@
+package messages
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCurrencyFormat(t *testing.T) {
+	euro := Currency{Symbol: "€", Precision: 2, GroupSeparator: ".", DecimalSeparator: ","}
+	yen := Currency{Symbol: "¥", Precision: 0, GroupSeparator: ","}
+	tests := []struct {
+		name     string
+		currency Currency
+		amount   float64
+		want     string
+	}{
+		{name: "zero", currency: DefaultCurrency, amount: 0, want: "$0.00"},
+		{name: "cents", currency: DefaultCurrency, amount: 0.15, want: "$0.15"},
+		{name: "rounded", currency: DefaultCurrency, amount: 1.005, want: "$1.00"},
+		{name: "thousands", currency: DefaultCurrency, amount: 1234.5, want: "$1,234.50"},
+		{name: "very large", currency: DefaultCurrency, amount: 1234567890.12, want: "$1,234,567,890.12"},
+		{name: "negative", currency: DefaultCurrency, amount: -42.1, want: "-$42.10"},
+		{name: "negative rounding to zero", currency: DefaultCurrency, amount: -0.001, want: "$0.00"},
+		{name: "other separators", currency: euro, amount: 1234.5, want: "€1.234,50"},
+		{name: "no decimals", currency: yen, amount: 1234567.6, want: "¥1,234,568"},
+		{name: "no grouping", currency: Currency{Symbol: "$", Precision: 1, DecimalSeparator: "."}, amount: 9876.54, want: "$9876.5"},
+		{name: "infinite", currency: DefaultCurrency, amount: math.Inf(1), want: "$+Inf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.currency.Format(tt.amount); got != tt.want {
+				t.Errorf("Format(%v) = %q, want %q", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrencyHourly(t *testing.T) {
+	if got, want := DefaultCurrency.Hourly(1.5), "$1.50/h"; got != want {
+		t.Errorf("Hourly(1.5) = %q, want %q", got, want)
+	}
+}
+
+func TestCurrencyValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency Currency
+		wantErr  bool
+	}{
+		{name: "default", currency: DefaultCurrency},
+		{name: "no decimals needs no separator", currency: Currency{Symbol: "¥"}},
+		{name: "negative precision", currency: Currency{Precision: -1, DecimalSeparator: "."}, wantErr: true},
+		{name: "precision too high", currency: Currency{Precision: 5, DecimalSeparator: "."}, wantErr: true},
+		{name: "missing decimal separator", currency: Currency{Precision: 2}, wantErr: true},
+		{name: "same separators", currency: Currency{Precision: 2, GroupSeparator: ",", DecimalSeparator: ","}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.currency.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{400 * time.Millisecond, "0s"},
+		{45 * time.Second, "45s"},
+		{90 * time.Second, "1m30s"},
+		{2*time.Hour + 30*time.Minute, "2h30m"},
+		{2*time.Hour + 30*time.Minute + 40*time.Second, "2h31m"},
+		{time.Hour, "1h"},
+		{36 * time.Hour, "1d12h"},
+		{23*time.Hour + 59*time.Minute + 59*time.Second, "1d"},
+		{400 * 24 * time.Hour, "400d"},
+		{-90 * time.Second, "-1m30s"},
+		{time.Duration(math.MinInt64), "-106751d23h"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
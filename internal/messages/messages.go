@@ -0,0 +1,729 @@
+// Package messages defines the user-facing text the bot sends to Slack.
+//
+// Each message is a text/template. The defaults can be overridden from the
+// configuration file, e.g. to change the wording or to drop the emojis for
+// accessibility. Fields referenced by a template are documented next to it.
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Messages is the set of named templates used by the command handlers.
+type Messages struct {
+	// Errors shared by several commands.
+	ConnectFailed string `json:"connectFailed,omitempty"`
+	ListFailed    string `json:"listFailed,omitempty"`
+	AdminOnly     string `json:"adminOnly,omitempty"` // .Command
+
+	ClusterAccessDenied string `json:"clusterAccessDenied,omitempty"` // .Name .Team
+
+	BackendUnavailable string `json:"backendUnavailable,omitempty"`
+	MaptNotInstalled   string `json:"maptNotInstalled,omitempty"`
+	RequestTimedOut    string `json:"requestTimedOut,omitempty"`
+	RequestCanceled    string `json:"requestCanceled,omitempty"`
+
+	// welcome, posted when the bot is added to a channel
+	Welcome string `json:"welcome,omitempty"`
+
+	// ping
+	Pong string `json:"pong,omitempty"` // .Context
+
+	// version
+	MaptVersion         string `json:"maptVersion,omitempty"`         // .Version .Image .Ready .Namespace .Deployment .APIVersion .Kinds
+	MaptVersionNotFound string `json:"maptVersionNotFound,omitempty"` // .Namespace .Deployment .APIVersion .Kinds
+	MaptVersionFailed   string `json:"maptVersionFailed,omitempty"`
+
+	// uptime
+	Uptime            string `json:"uptime,omitempty"`          // .Started .Since .State .ConnectedAt .Reconnects .LastReconnect .Events .Backend
+	UptimeBackendUp   string `json:"uptimeBackendUp,omitempty"` // .Latency
+	UptimeBackendDown string `json:"uptimeBackendDown,omitempty"`
+	UptimeBackendOpen string `json:"uptimeBackendOpen,omitempty"`
+
+	// launch
+	MissingLaunchArgs   string `json:"missingLaunchArgs,omitempty"`   // .Usage
+	LaunchChooseSize    string `json:"launchChooseSize,omitempty"`    // .Type .Sizes
+	LaunchChooseType    string `json:"launchChooseType,omitempty"`    // .Size .Types
+	UnsupportedType     string `json:"unsupportedType,omitempty"`     // .Type
+	InvalidSize         string `json:"invalidSize,omitempty"`         // .Size .Sizes
+	SizeNotAllowed      string `json:"sizeNotAllowed,omitempty"`      // .Type .Size .Allowed
+	VersionNotSupported string `json:"versionNotSupported,omitempty"` // .Type
+	UnsupportedVersion  string `json:"unsupportedVersion,omitempty"`  // .Version .Allowed
+
+	K8sVersionNotSupported string `json:"k8sVersionNotSupported,omitempty"` // .Type
+	UnsupportedK8sVersion  string `json:"unsupportedK8sVersion,omitempty"`  // .Version .Allowed
+	LaunchFailed           string `json:"launchFailed,omitempty"`
+	LaunchConfirm          string `json:"launchConfirm,omitempty"` // .Name .Type .Size .User .CPU .RAM .Version .InstanceType .Defaults .Ref
+
+	UnrecognizedArgument string `json:"unrecognizedArgument,omitempty"` // .Arg
+	LaunchArgRepeated    string `json:"launchArgRepeated,omitempty"`    // .Key
+
+	InvalidRef            string `json:"invalidRef,omitempty"`       // .Ref .Pattern
+	InvalidTTL            string `json:"invalidTTL,omitempty"`       // .TTL
+	RegionNotAllowed      string `json:"regionNotAllowed,omitempty"` // .Region .Provider .Allowed .Denied
+	TTLTooLong            string `json:"ttlTooLong,omitempty"`       // .TTL .Max .Size
+	InvalidLimit          string `json:"invalidLimit,omitempty"`     // .Flag .Value .Unit .Max .Size
+	LimitWithInstanceType string `json:"limitWithInstanceType,omitempty"`
+
+	InstanceTypeWithSize    string `json:"instanceTypeWithSize,omitempty"`
+	UnsupportedInstanceType string `json:"unsupportedInstanceType,omitempty"` // .InstanceType .Provider .Allowed
+	InstanceTypeUnpriced    string `json:"instanceTypeUnpriced,omitempty"`    // .InstanceType
+
+	InvalidSet   string `json:"invalidSet,omitempty"`   // .Set
+	SetProtected string `json:"setProtected,omitempty"` // .Field
+	SetConflict  string `json:"setConflict,omitempty"`  // .Set
+
+	LaunchConfirmRequired string `json:"launchConfirmRequired,omitempty"` // .Size .InstanceType .CPU .RAM .Cost .Window
+	LaunchNoPending       string `json:"launchNoPending,omitempty"`
+	LaunchProgress        string `json:"launchProgress,omitempty"`        // .Name .Phase .Elapsed
+	LaunchReady           string `json:"launchReady,omitempty"`           // .Name .User .Permalink
+	LaunchProvisionFailed string `json:"launchProvisionFailed,omitempty"` // .Name .User .Permalink
+	LaunchWatchTimeout    string `json:"launchWatchTimeout,omitempty"`    // .Name .Timeout
+
+	// schedule
+	ScheduleConfirm      string `json:"scheduleConfirm,omitempty"`     // .ID .RunAt .In
+	ScheduleInvalidTime  string `json:"scheduleInvalidTime,omitempty"` // .Value
+	ScheduleOutOfRange   string `json:"scheduleOutOfRange,omitempty"`  // .Max
+	ScheduleBoth         string `json:"scheduleBoth,omitempty"`
+	ScheduleFailed       string `json:"scheduleFailed,omitempty"`
+	ScheduleRunFailed    string `json:"scheduleRunFailed,omitempty"` // .ID .User
+	ScheduleStarting     string `json:"scheduleStarting,omitempty"`  // .ID .User .Command
+	ScheduleUsage        string `json:"scheduleUsage,omitempty"`
+	ScheduleListFailed   string `json:"scheduleListFailed,omitempty"`
+	ScheduleNone         string `json:"scheduleNone,omitempty"`
+	ScheduleHeader       string `json:"scheduleHeader,omitempty"`       // .Count
+	ScheduleEntry        string `json:"scheduleEntry,omitempty"`        // .ID .RunAt .In .User .Command
+	ScheduleNotFound     string `json:"scheduleNotFound,omitempty"`     // .ID
+	ScheduleCancelDenied string `json:"scheduleCancelDenied,omitempty"` // .ID .User
+	ScheduleCancelled    string `json:"scheduleCancelled,omitempty"`    // .ID
+
+	// quota
+	QuotaStatus   string `json:"quotaStatus,omitempty"`  // .User .Usage .Limit
+	QuotaUpdated  string `json:"quotaUpdated,omitempty"` // .User .Limit
+	QuotaDenied   string `json:"quotaDenied,omitempty"`
+	QuotaUsage    string `json:"quotaUsage,omitempty"`
+	QuotaExceeded string `json:"quotaExceeded,omitempty"` // .Usage .Limit
+	QuotaNotice   string `json:"quotaNotice,omitempty"`   // .Remaining .Limit
+
+	// budget
+	BudgetExceeded   string `json:"budgetExceeded,omitempty"`   // .Current .Projected .Cap
+	BudgetOverridden string `json:"budgetOverridden,omitempty"` // .Current .Projected .Cap
+	BudgetDenied     string `json:"budgetDenied,omitempty"`
+	BudgetNotice     string `json:"budgetNotice,omitempty"` // .Percent .Current .Cap
+
+	// capacity
+	LaunchLowCapacity  string `json:"launchLowCapacity,omitempty"`  // .Size .Provider .Region .Score .Alternatives
+	LaunchQuotaWarning string `json:"launchQuotaWarning,omitempty"` // .Namespace .Shortfalls
+	QuotaShortfall     string `json:"quotaShortfall,omitempty"`     // .Quota .Resource .Requested .Used .Hard
+	CapacityUsage      string `json:"capacityUsage,omitempty"`
+	CapacityNone       string `json:"capacityNone,omitempty"`   // .Namespace
+	CapacityFailed     string `json:"capacityFailed,omitempty"` // .Namespace
+	Capacity           string `json:"capacity,omitempty"`       // .Namespace .Entries
+	CapacityQuota      string `json:"capacityQuota,omitempty"`  // .Name
+	CapacityEntry      string `json:"capacityEntry,omitempty"`  // .Resource .Used .Hard .Percent .Full
+
+	// preset
+	PresetUsage       string `json:"presetUsage,omitempty"`
+	PresetInvalidName string `json:"presetInvalidName,omitempty"` // .Name
+	PresetSaved       string `json:"presetSaved,omitempty"`       // .Name .Args
+	PresetDeleted     string `json:"presetDeleted,omitempty"`     // .Name
+	PresetNotFound    string `json:"presetNotFound,omitempty"`    // .Name
+	PresetNone        string `json:"presetNone,omitempty"`
+	PresetList        string `json:"presetList,omitempty"`  // .Count .Entries
+	PresetEntry       string `json:"presetEntry,omitempty"` // .Name .Args
+	PresetMultiple    string `json:"presetMultiple,omitempty"`
+	PresetFailed      string `json:"presetFailed,omitempty"`
+
+	// validate
+	ValidateUsage  string `json:"validateUsage,omitempty"`
+	ValidateOK     string `json:"validateOK,omitempty"`     // .Command .Type .Size .InstanceType .CPU .RAM .Version .Cost .Defaults .Confirm .RunAt
+	ValidateFailed string `json:"validateFailed,omitempty"` // .Command .Problems
+
+	// done
+	DoneUsage   string `json:"doneUsage,omitempty"`
+	DoneConfirm string `json:"doneConfirm,omitempty"` // .Name
+	DoneFailed  string `json:"doneFailed,omitempty"`
+
+	DoneAlreadyRemoved  string `json:"doneAlreadyRemoved,omitempty"`  // .Name
+	DoneAlreadyDeleting string `json:"doneAlreadyDeleting,omitempty"` // .Name
+
+	// done during the grace period
+	DoneScheduled        string `json:"doneScheduled,omitempty"`        // .Name .User .DeleteAt
+	DoneScheduledOwner   string `json:"doneScheduledOwner,omitempty"`   // .Name .User .DeleteAt
+	DoneAlreadyScheduled string `json:"doneAlreadyScheduled,omitempty"` // .Name .User .DeleteAt
+	DoneGraceDeleted     string `json:"doneGraceDeleted,omitempty"`     // .Name
+
+	// done --all
+	DoneAllNone        string `json:"doneAllNone,omitempty"`
+	DoneAllEntry       string `json:"doneAllEntry,omitempty"`     // .Name .Type
+	DoneAllSelection   string `json:"doneAllSelection,omitempty"` // .Count .Entries .Window .Phrase
+	DoneAllNoPending   string `json:"doneAllNoPending,omitempty"`
+	DoneAllWrongPhrase string `json:"doneAllWrongPhrase,omitempty"` // .Count
+	DoneAllComplete    string `json:"doneAllComplete,omitempty"`    // .Deleted .Failed
+
+	// cancel
+	CancelUsage           string `json:"cancelUsage,omitempty"`
+	CancelNotInFlight     string `json:"cancelNotInFlight,omitempty"` // .Name .Phase
+	CancelFailed          string `json:"cancelFailed,omitempty"`
+	CancelWatcherStopped  string `json:"cancelWatcherStopped,omitempty"`
+	CancelResourceDeleted string `json:"cancelResourceDeleted,omitempty"` // .Kind .Namespace
+	CancelConfirm         string `json:"cancelConfirm,omitempty"`         // .Name .Cleaned
+	LaunchCancelled       string `json:"launchCancelled,omitempty"`       // .Name .User
+	LaunchDeleted         string `json:"launchDeleted,omitempty"`         // .Name
+
+	// cancel-delete
+	CancelDeleteUsage  string `json:"cancelDeleteUsage,omitempty"`
+	CancelDeleteNone   string `json:"cancelDeleteNone,omitempty"` // .Name
+	CancelDeleteFailed string `json:"cancelDeleteFailed,omitempty"`
+	DeleteCancelled    string `json:"deleteCancelled,omitempty"` // .Name
+
+	// pin / unpin
+	PinUsage   string `json:"pinUsage,omitempty"`
+	UnpinUsage string `json:"unpinUsage,omitempty"`
+	Pinned     string `json:"pinned,omitempty"`    // .Name
+	Unpinned   string `json:"unpinned,omitempty"`  // .Name
+	PinDenied  string `json:"pinDenied,omitempty"` // .Name .Owner
+	PinFailed  string `json:"pinFailed,omitempty"`
+
+	// rename
+	RenameUsage   string `json:"renameUsage,omitempty"`
+	RenameInvalid string `json:"renameInvalid,omitempty"` // .DisplayName .Max
+	RenameDenied  string `json:"renameDenied,omitempty"`  // .Name .Owner
+	RenameFailed  string `json:"renameFailed,omitempty"`
+	Renamed       string `json:"renamed,omitempty"` // .Name .DisplayName
+
+	// follow
+	FollowUsage    string `json:"followUsage,omitempty"`
+	UnfollowUsage  string `json:"unfollowUsage,omitempty"`
+	FollowStarted  string `json:"followStarted,omitempty"`  // .Name .Phase
+	FollowStopped  string `json:"followStopped,omitempty"`  // .Name
+	NotFollowing   string `json:"notFollowing,omitempty"`   // .Name
+	FollowPhase    string `json:"followPhase,omitempty"`    // .Name .Phase
+	FollowExpiring string `json:"followExpiring,omitempty"` // .Name .Expires .Permalink
+	FollowGone     string `json:"followGone,omitempty"`     // .Name
+
+	// diff
+	ClusterNotFound string `json:"clusterNotFound,omitempty"` // .Name
+	DiffUsage       string `json:"diffUsage,omitempty"`
+	DiffUnknownSize string `json:"diffUnknownSize,omitempty"` // .Name
+	DiffHeader      string `json:"diffHeader,omitempty"`      // .Name .From .To
+	DiffLine        string `json:"diffLine,omitempty"`        // .Field .Before .After .Delta
+	DiffDownscale   string `json:"diffDownscale,omitempty"`
+
+	// status
+	StatusUsage   string `json:"statusUsage,omitempty"`
+	StatusDetails string `json:"statusDetails,omitempty"` // .Name .DisplayName .Type .Namespace .Phase .Version .Size .CPU .RAM .Ref .Owner .Age .Created .Limits .TTL .Expires .HourlyCost .Accumulated .APIServer .Console .EndpointsPending .Pinned .Permalink .DeleteAt
+
+	// console
+	ConsoleUsage         string `json:"consoleUsage,omitempty"`
+	ConsoleLink          string `json:"consoleLink,omitempty"`          // .Name .Provider .Region .URL
+	ConsoleMissingRegion string `json:"consoleMissingRegion,omitempty"` // .Name .Provider
+	ConsoleUnsupported   string `json:"consoleUnsupported,omitempty"`   // .Name .Provider .Region
+
+	// stats
+	Stats string `json:"stats,omitempty"` // .Total .ByType .BySize .ByStatus .Nodes .HourlyCost .Accumulated
+
+	// top
+	TopUsage string `json:"topUsage,omitempty"` // .Max
+	TopNone  string `json:"topNone,omitempty"`
+	Top      string `json:"top,omitempty"`      // .Count .Total .Entries
+	TopEntry string `json:"topEntry,omitempty"` // .Rank .Name .Owner .Size .HourlyCost .Accumulated
+
+	// list
+	ListEmpty       string `json:"listEmpty,omitempty"`
+	ListHeader      string `json:"listHeader,omitempty"`     // .Count
+	ListEntry       string `json:"listEntry,omitempty"`      // .Indicator .Phase .Name .DisplayName .Type .Namespace .Age .Created .Pinned
+	ListTypeFailed  string `json:"listTypeFailed,omitempty"` // .Type
+	ListUsage       string `json:"listUsage,omitempty"`
+	ListOwnerHeader string `json:"listOwnerHeader,omitempty"` // .Owner .Count
+	ListPage        string `json:"listPage,omitempty"`        // .First .Last .Total .Page .Pages
+	ListPageDenied  string `json:"listPageDenied,omitempty"`  // .User
+
+	ListAllNamespacesDenied string `json:"listAllNamespacesDenied,omitempty"`
+
+	// failed
+	FailedNone        string `json:"failedNone,omitempty"`
+	FailedHeader      string `json:"failedHeader,omitempty"`   // .Count .Shown
+	FailedEntry       string `json:"failedEntry,omitempty"`    // .Name .Type .Namespace .Owner .Age .Reason
+	FailedNoLonger    string `json:"failedNoLonger,omitempty"` // .Name
+	FailedRetried     string `json:"failedRetried,omitempty"`  // .Name .NewName .User
+	FailedRetryFailed string `json:"failedRetryFailed,omitempty"`
+
+	// export
+	ExportUsage  string `json:"exportUsage,omitempty"`
+	ExportReady  string `json:"exportReady,omitempty"` // .Count
+	ExportSent   string `json:"exportSent,omitempty"`  // .User
+	ExportFailed string `json:"exportFailed,omitempty"`
+
+	// diagnostics
+	DiagnosticsUsage  string `json:"diagnosticsUsage,omitempty"`
+	DiagnosticsReady  string `json:"diagnosticsReady,omitempty"` // .Name .Missing
+	DiagnosticsSent   string `json:"diagnosticsSent,omitempty"`  // .User .Name
+	DiagnosticsFailed string `json:"diagnosticsFailed,omitempty"`
+
+	// permissions
+	PermissionsUsage  string `json:"permissionsUsage,omitempty"`
+	PermissionsFailed string `json:"permissionsFailed,omitempty"` // .Namespace
+	Permissions       string `json:"permissions,omitempty"`       // .Namespace .Entries .Missing .Total
+	PermissionsEntry  string `json:"permissionsEntry,omitempty"`  // .Allowed .Verb .Resource .Namespace .Reason
+
+	// uploads
+	UploadTooLarge string `json:"uploadTooLarge,omitempty"` // .Max
+	UploadTimedOut string `json:"uploadTimedOut,omitempty"` // .Timeout
+
+	// cleanup
+	CleanupNone        string `json:"cleanupNone,omitempty"`
+	CleanupEntry       string `json:"cleanupEntry,omitempty"`     // .Name .Type .Reason
+	CleanupSelection   string `json:"cleanupSelection,omitempty"` // .Count .Entries .Window .Phrase
+	CleanupPreview     string `json:"cleanupPreview,omitempty"`   // .Count .Entries
+	CleanupNoPending   string `json:"cleanupNoPending,omitempty"`
+	CleanupWrongPhrase string `json:"cleanupWrongPhrase,omitempty"` // .Count
+	CleanupComplete    string `json:"cleanupComplete,omitempty"`    // .Deleted .Failed
+
+	// help
+	HelpHeader string `json:"helpHeader,omitempty"`
+	HelpEntry  string `json:"helpEntry,omitempty"` // .Name .Description .Usage
+
+	HelpCommand        string `json:"helpCommand,omitempty"`        // .Name .Description .Usage .Details .Examples
+	HelpUnknownCommand string `json:"helpUnknownCommand,omitempty"` // .Name
+
+	// CommandFailed is shown for failures without a more specific message.
+	CommandFailed string `json:"commandFailed,omitempty"` // .Command
+
+	// history
+	HistoryEmpty        string `json:"historyEmpty,omitempty"`
+	HistoryHeader       string `json:"historyHeader,omitempty"`       // .Count
+	HistoryEntry        string `json:"historyEntry,omitempty"`        // .Time .User .Command .Outcome
+	HistoryInvalidCount string `json:"historyInvalidCount,omitempty"` // .Count
+
+	// errors
+	ErrorsEmpty        string `json:"errorsEmpty,omitempty"`
+	ErrorsHeader       string `json:"errorsHeader,omitempty"`       // .Count
+	ErrorsEntry        string `json:"errorsEntry,omitempty"`        // .Time .Message .Error
+	ErrorsInvalidCount string `json:"errorsInvalidCount,omitempty"` // .Count
+
+	// reliability
+	ReliabilityUsage  string `json:"reliabilityUsage,omitempty"`
+	Reliability       string `json:"reliability,omitempty"`       // .Window .Total .Succeeded .Failed .SuccessRate .AverageReady .Reasons
+	ReliabilityReason string `json:"reliabilityReason,omitempty"` // .Reason .Count
+
+	// announce
+	AnnounceUsage      string `json:"announceUsage,omitempty"`
+	AnnounceNoChannels string `json:"announceNoChannels,omitempty"`
+	Announcement       string `json:"announcement,omitempty"`    // .Message .User
+	AnnounceSent       string `json:"announceSent,omitempty"`    // .Sent .Total
+	AnnounceFailure    string `json:"announceFailure,omitempty"` // .Channel .Error
+
+	// reload
+	ReloadFailed    string `json:"reloadFailed,omitempty"` // .Error
+	ReloadUnchanged string `json:"reloadUnchanged,omitempty"`
+	ReloadChanged   string `json:"reloadChanged,omitempty"` // .Changes
+
+	// maintenance
+	MaintenanceActive string `json:"maintenanceActive,omitempty"` // .Command
+	MaintenanceStatus string `json:"maintenanceStatus,omitempty"` // .Enabled
+	MaintenanceUsage  string `json:"maintenanceUsage,omitempty"`
+	MaintenanceFailed string `json:"maintenanceFailed,omitempty"`
+
+	BackendNotReady string `json:"backendNotReady,omitempty"` // .Command
+	CommandCooldown string `json:"commandCooldown,omitempty"` // .Command .Remaining
+
+	// config
+	ConfigDump   string `json:"configDump,omitempty"` // .Config
+	ConfigFailed string `json:"configFailed,omitempty"`
+}
+
+// Default returns the built-in message templates.
+func Default() Messages {
+	return Messages{
+		ConnectFailed: "❌ Failed to connect to Kubernetes cluster",
+		ListFailed:    "❌ Failed to retrieve cluster list",
+		AdminOnly:     "⛔ The *{{.Command}}* command is restricted to bot administrators.",
+
+		ClusterAccessDenied: "⛔ *{{.Name}}* belongs to team *{{.Team}}*. Only its members or a bot administrator can operate on it.",
+
+		BackendUnavailable: "⏳ The Kubernetes backend is temporarily unavailable, please try again shortly.",
+		MaptNotInstalled: "❌ The MAPT operator doesn't appear to be installed in the target cluster.\n" +
+			"Ask an admin to install mapt-operator and its CRDs, or check that `ping` shows the right Kubernetes context.",
+		RequestTimedOut: "⌛ The request to Kubernetes timed out before it completed, please try again.",
+		RequestCanceled: "⚠️ The request to Kubernetes was cancelled before it completed.",
+
+		Welcome: "👋 Hi, I'm Spoticus! I launch Kubernetes and OpenShift clusters on cheap spot instances, right from Slack.\n" +
+			"Mention me with `help` to see what I can do.",
+
+		Pong: "🏓 Pong! Spoticus is connected to Kubernetes context *{{.Context}}*.",
+
+		MaptVersion: "🔎 *MAPT operator* {{.Version}}\n" +
+			"• Deployment: `{{.Namespace}}/{{.Deployment}}` ({{.Ready}} ready)\n" +
+			"• Image: `{{.Image}}`\n" +
+			"• Supported by Spoticus: `{{.APIVersion}}` {{.Kinds}}",
+		MaptVersionNotFound: "⚠️ The MAPT operator deployment `{{.Namespace}}/{{.Deployment}}` was not found.\n" +
+			"Check `maptOperator` in the configuration. Spoticus supports `{{.APIVersion}}` {{.Kinds}}.",
+		MaptVersionFailed: "❌ Failed to read the MAPT operator deployment.",
+
+		Uptime: "🩺 *Spoticus health*\n" +
+			"• Uptime: started {{.Started}} ({{.Since}})\n" +
+			"• Slack connection: {{.State}}{{if .ConnectedAt}} since {{.ConnectedAt}}{{end}}\n" +
+			"• Reconnects: {{.Reconnects}}{{if .LastReconnect}} (last {{.LastReconnect}}){{end}}\n" +
+			"• Events processed: {{.Events}}\n" +
+			"• Kubernetes backend: {{.Backend}}",
+		UptimeBackendUp:   "reachable ({{.Latency}})",
+		UptimeBackendDown: "unreachable",
+		UptimeBackendOpen: "unreachable, circuit breaker open",
+
+		MissingLaunchArgs:   "❌ Missing arguments.\n\n{{.Usage}}",
+		LaunchChooseSize:    "❌ Missing size: you specified type `{{.Type}}`; now choose a size: {{.Sizes}}\nFor example: `launch {{.Type}} <size>`",
+		LaunchChooseType:    "❌ Missing cluster type: {{if .Size}}you specified size `{{.Size}}`; now {{end}}choose a cluster type: {{.Types}}\nFor example: `launch <type>{{if .Size}} {{.Size}}{{end}}`",
+		UnsupportedType:     "❌ Unsupported cluster type: *{{.Type}}*\nSupported types: `k8s`, `openshift`",
+		InvalidSize:         "❌ Invalid size: *{{.Size}}*\nValid sizes:\n{{.Sizes}}",
+		SizeNotAllowed:      "❌ *{{.Type}}* clusters cannot be launched with size *{{.Size}}*.\nAllowed sizes for {{.Type}}: {{.Allowed}}",
+		VersionNotSupported: "❌ `--version` is only supported for `openshift` clusters",
+		UnsupportedVersion:  "❌ Unsupported OpenShift version: *{{.Version}}*\nAllowed versions: {{.Allowed}}",
+
+		K8sVersionNotSupported: "❌ `--k8s-version` is only supported for `k8s` clusters{{if eq .Type \"openshift\"}}; use `--version` for OpenShift{{end}}",
+		UnsupportedK8sVersion:  "❌ Unsupported Kubernetes version: *{{.Version}}*\nAllowed versions: {{.Allowed}}",
+		LaunchFailed:           "❌ Failed to launch cluster",
+		LaunchConfirm: "{{if .InstanceType}}🚀 Launching *{{.Name}}*, a *{{.Type}}* cluster on *{{.InstanceType}}* for <@{{.User}}>" +
+			"{{else}}🚀 Launching *{{.Name}}*, a *{{.Type}}* cluster of size *{{.Size}}* for <@{{.User}}>\n" +
+			"• CPU: {{.CPU}}\n• Memory: {{.RAM}}{{end}}{{if .Version}}\n• Version: {{.Version}}{{end}}" +
+			"{{if .Defaults}}\n• Channel defaults: {{.Defaults}}{{end}}" +
+			"{{if .Ref}}\n• Ref: {{.Ref}}{{end}}" +
+			"\n_I'll update you in this thread when it's ready._",
+		UnrecognizedArgument: "❌ Unrecognized argument: `{{.Arg}}`\nSend `launch help` for the supported arguments.",
+		LaunchArgRepeated:    "❌ The cluster {{.Key}} is given more than once. Give it either by position or as `{{.Key}}=<value>`.",
+
+		InvalidRef:            "❌ Invalid `--ref={{.Ref}}`{{if .Pattern}}: it must match `{{.Pattern}}`{{else}}: give a ticket reference without spaces, such as `PROJ-123`{{end}}.",
+		InvalidLimit:          "❌ Invalid `--{{.Flag}}={{.Value}}`: size *{{.Size}}* allows a whole number of {{.Unit}} between 1 and {{.Max}}.",
+		InvalidTTL:            "❌ Invalid `--ttl={{.TTL}}`: give a duration such as `12h` or a number of days such as `3d`.",
+		RegionNotAllowed:      "❌ Region `{{.Region}}` is not allowed for {{.Provider}} clusters{{if .Allowed}}. Allowed regions: {{.Allowed}}{{else if .Denied}}. Denied regions: {{.Denied}}{{end}}.",
+		TTLTooLong:            "❌ A TTL of {{.TTL}} is too long{{if .Size}} for a *{{.Size}}* cluster{{end}}: the maximum is {{.Max}}.",
+		LimitWithInstanceType: "❌ `--cpu-limit` and `--mem-limit` lower the resources of a size and cannot be combined with `--instance-type`.",
+
+		InstanceTypeWithSize:    "❌ `--instance-type` and a size are mutually exclusive. Give one or the other.",
+		UnsupportedInstanceType: "❌ Unsupported instance type for {{.Provider}}: *{{.InstanceType}}*\nAllowed types: {{.Allowed}}",
+		InstanceTypeUnpriced:    "❌ The cost of instance type *{{.InstanceType}}* is unknown, so its launch cannot be checked against the budget cap and the launch confirmation. Ask an admin to add it to `instanceTypeSpecs`, or launch a size.",
+
+		InvalidSet:   "❌ Invalid `--set {{.Set}}`: expected `--set path.to.field=value`, with path segments made of letters, digits, `-` and `_`.",
+		SetProtected: "❌ `{{.Field}}` is set by the bot and cannot be overridden with `--set`.",
+		SetConflict:  "❌ `--set {{.Set}}` conflicts with another `--set` on the same path.",
+
+		LaunchConfirmRequired: "⚠️ {{if .InstanceType}}*{{.InstanceType}}* instances{{else}}*{{.Size}}* clusters{{end}} ({{.CPU}}, {{.RAM}}, about {{.Cost}}) are expensive.\n" +
+			"Reply `launch confirm` within {{.Window}} to launch it.",
+		LaunchNoPending:       "❌ Nothing to confirm. Run `launch` first.",
+		LaunchProgress:        "⏳ *{{.Name}}*: {{.Phase}}… {{.Elapsed}} elapsed",
+		LaunchReady:           "✅ <@{{.User}}> *{{.Name}}* is ready! Run `status {{.Name}}` for details.{{if .Permalink}} 🔗 <{{.Permalink}}|Link to this thread> to share or bookmark.{{end}}",
+		LaunchProvisionFailed: "❌ <@{{.User}}> *{{.Name}}* failed to provision. Run `status {{.Name}}` for details.{{if .Permalink}} 🔗 <{{.Permalink}}|Link to this thread> to share.{{end}}",
+		LaunchWatchTimeout:    "⌛ *{{.Name}}* is still not ready after {{.Timeout}}. Check `status {{.Name}}` later.",
+
+		ScheduleConfirm:      "⏰ Launch scheduled for {{.RunAt}} ({{.In}}) with id *{{.ID}}*. Cancel it with `schedule cancel {{.ID}}`.",
+		ScheduleInvalidTime:  "❌ Invalid schedule *{{.Value}}*: use `--in=<duration>` (e.g. `--in=2h`) or `--at=\"YYYY-MM-DD HH:MM\"`.",
+		ScheduleOutOfRange:   "❌ The scheduled time must be in the future and within {{.Max}}.",
+		ScheduleBoth:         "❌ `--at` and `--in` are mutually exclusive. Give one or the other.",
+		ScheduleFailed:       "❌ Failed to update the scheduled launch",
+		ScheduleRunFailed:    "❌ <@{{.User}}> your scheduled launch *{{.ID}}* could not be started, check the logs.",
+		ScheduleStarting:     "⏰ Starting the launch <@{{.User}}> scheduled with id *{{.ID}}*: `{{.Command}}`",
+		ScheduleUsage:        "❌ Usage: `schedule` or `schedule cancel <id>`",
+		ScheduleListFailed:   "❌ Failed to retrieve the scheduled launches",
+		ScheduleNone:         "⏰ *Scheduled Launches*\n\nNo launches are scheduled.",
+		ScheduleHeader:       "⏰ *Scheduled Launches* ({{.Count}})\n\n",
+		ScheduleEntry:        "• *{{.ID}}* at {{.RunAt}} ({{.In}}) by <@{{.User}}>: `{{.Command}}`\n",
+		ScheduleNotFound:     "❌ No scheduled launch with id *{{.ID}}*.",
+		ScheduleCancelDenied: "⛔ Only <@{{.User}}> or a bot administrator can cancel *{{.ID}}*.",
+		ScheduleCancelled:    "🗑️ Scheduled launch *{{.ID}}* cancelled.",
+
+		QuotaStatus:   "📊 Quota for <@{{.User}}>: {{.Usage}} of {{.Limit}} clusters in use.",
+		QuotaUpdated:  "📊 Quota for <@{{.User}}> set to {{.Limit}} clusters (until the bot restarts).",
+		QuotaDenied:   "⛔ Only bot administrators can view or change other users' quotas.",
+		QuotaUsage:    "❌ Usage: `quota [@user] [n]`",
+		QuotaExceeded: "❌ Quota exceeded: you already own {{.Usage}} of {{.Limit}} allowed clusters. Remove one first or ask an admin.",
+		QuotaNotice:   "📊 You have {{.Remaining}} of {{.Limit}} launch{{if ne .Limit 1}}es{{end}} remaining.",
+
+		BudgetExceeded: "❌ Budget cap reached: clusters currently cost {{.Current}} and this launch would bring it to {{.Projected}}, " +
+			"over the {{.Cap}} cap. Remove a cluster first or ask an admin.",
+		BudgetOverridden: "⚠️ Budget cap overridden: spend goes from {{.Current}} to {{.Projected}}, over the {{.Cap}} cap.",
+		BudgetDenied:     "⛔ Only bot administrators can use `--override-budget`.",
+		BudgetNotice:     "⚠️ The team budget is {{.Percent}}% used: clusters cost {{.Current}} of the {{.Cap}} cap.",
+
+		LaunchLowCapacity: "⚠️ Spot capacity for *{{.Size}}* is low {{if .Region}}in {{.Region}} ({{.Score}}){{else}}in every {{.Provider}} region (best {{.Score}}){{end}}: " +
+			"the launch may not be fulfilled.{{if .Alternatives}} Regions with more capacity: {{.Alternatives}}.{{end}}",
+		LaunchQuotaWarning: "⚠️ This launch may not fit the resource quotas of namespace `{{.Namespace}}`:\n{{.Shortfalls}}",
+		QuotaShortfall:     "• `{{.Resource}}` of quota *{{.Quota}}*: {{.Requested}} more would exceed the {{.Used}} of {{.Hard}} used",
+		CapacityUsage:      "❌ Usage: `capacity [cluster_type]`",
+		CapacityNone:       "🧮 Namespace `{{.Namespace}}` has no resource quota: launches are not limited by it.",
+		CapacityFailed:     "❌ Failed to read the resource quotas of namespace `{{.Namespace}}`.",
+		Capacity:           "🧮 *Resource quotas of namespace `{{.Namespace}}`*\n{{.Entries}}",
+		CapacityQuota:      "*{{.Name}}*\n",
+		CapacityEntry:      "• `{{.Resource}}`: {{.Used}} of {{.Hard}} used{{if .Percent}} ({{.Percent}}){{end}}{{if .Full}} ⚠️ full{{end}}\n",
+
+		PresetUsage:       "❌ Usage: `preset save <name> <launch arguments>`, `preset list` or `preset delete <name>`\nExample: `preset save dev k8s large --ref=DEV-1`",
+		PresetInvalidName: "❌ Invalid preset name *{{.Name}}*: use up to 32 lowercase letters, digits and dashes.",
+		PresetSaved:       "💾 Saved preset *{{.Name}}*: `{{.Args}}`. Launch it with `launch --preset={{.Name}}`.",
+		PresetDeleted:     "💾 Deleted preset *{{.Name}}*.",
+		PresetNotFound:    "❌ You have no preset named *{{.Name}}*. Run `preset list` to see yours.",
+		PresetNone:        "💾 You have no presets. Save one with `preset save <name> <launch arguments>`.",
+		PresetList:        "💾 *Your presets* ({{.Count}})\n\n{{.Entries}}",
+		PresetEntry:       "• *{{.Name}}* — `{{.Args}}`\n",
+		PresetMultiple:    "❌ Only one `--preset` can be used per launch.",
+		PresetFailed:      "❌ Failed to access the launch presets",
+
+		ValidateUsage: "❌ Usage: `validate <launch arguments>`\nExample: `validate openshift large --version=4.19.0`",
+		ValidateOK: "✅ `{{.Command}}` is valid. It would launch a *{{.Type}}* cluster " +
+			"{{if .InstanceType}}on instance type *{{.InstanceType}}*{{else}}of size *{{.Size}}* ({{.CPU}}, {{.RAM}}, about {{.Cost}}){{end}}" +
+			"{{if .Version}} at version {{.Version}}{{end}}" +
+			"{{if .RunAt}}, scheduled for {{.RunAt}}{{end}}." +
+			"{{if .Defaults}}\nChannel defaults applied: {{.Defaults}}.{{end}}" +
+			"{{if .Confirm}}\nIt would have to be confirmed with `launch confirm`.{{end}}\nNothing was created.",
+		ValidateFailed: "❌ `{{.Command}}` would fail:\n{{.Problems}}",
+
+		DoneUsage:   "❌ Usage: `done <cluster_name> [--now]` or `done --all`",
+		DoneConfirm: "🗑️ Deleting *{{.Name}}*. Thanks for cleaning up!",
+		DoneFailed:  "❌ Failed to delete cluster",
+
+		DoneAlreadyRemoved:  "🗑️ *{{.Name}}* has already been removed.",
+		DoneAlreadyDeleting: "🗑️ *{{.Name}}* is already being deleted.",
+
+		DoneScheduled:        "⏳ *{{.Name}}* will be deleted {{.DeleteAt}}. Changed your mind? Send `cancel-delete {{.Name}}`, or `done {{.Name}} --now` to delete it right away.",
+		DoneScheduledOwner:   "⏳ <@{{.User}}> marked your cluster *{{.Name}}* for deletion {{.DeleteAt}}. Send `cancel-delete {{.Name}}` to keep it.",
+		DoneAlreadyScheduled: "⏳ *{{.Name}}* is already marked for deletion {{.DeleteAt}}. Send `cancel-delete {{.Name}}` to keep it, or `done {{.Name}} --now` to delete it right away.",
+		DoneGraceDeleted:     "🗑️ The grace period of *{{.Name}}* is over, deleting it.",
+
+		DoneAllNone:  "🗑️ You have no clusters to delete.",
+		DoneAllEntry: "• *{{.Name}}* ({{.Type}})\n",
+		DoneAllSelection: "🗑️ {{.Count}} cluster{{if ne .Count 1}}s{{end}} of yours would be deleted:\n\n" +
+			"{{.Entries}}\nTo delete them, reply `done --all confirm {{.Phrase}}` within {{.Window}}.",
+		DoneAllNoPending: "❌ Nothing to confirm. Run `done --all` first to select your clusters.",
+		DoneAllWrongPhrase: "❌ That is not the confirmation phrase: the deletion of {{.Count}} cluster{{if ne .Count 1}}s{{end}} was aborted and nothing was deleted. " +
+			"Run `done --all` again for a new phrase.",
+		DoneAllComplete: "🗑️ {{.Deleted}} cluster{{if ne .Deleted 1}}s{{end}} deleted. Thanks for cleaning up!" +
+			"{{if .Failed}}\n⚠️ {{.Failed}} cluster{{if ne .Failed 1}}s{{end}} could not be deleted, check the logs.{{end}}",
+
+		CancelUsage:           "❌ Usage: `cancel <cluster_name>`",
+		CancelNotInFlight:     "❌ *{{.Name}}* is no longer being launched ({{.Phase}}). Send `done {{.Name}}` to delete it.",
+		CancelFailed:          "❌ Failed to cancel the launch",
+		CancelWatcherStopped:  "• stopped watching its provisioning",
+		CancelResourceDeleted: "• deleted its {{.Kind}} resource in `{{.Namespace}}`",
+		CancelConfirm:         "🛑 Cancelled the launch of *{{.Name}}*:\n{{if .Cleaned}}{{.Cleaned}}{{else}}• nothing was left to clean up{{end}}",
+		LaunchCancelled:       "🛑 <@{{.User}}> cancelled the launch of *{{.Name}}*.",
+		LaunchDeleted:         "🗑️ *{{.Name}}* was deleted before it was ready.",
+
+		CancelDeleteUsage:  "❌ Usage: `cancel-delete <cluster_name>`",
+		CancelDeleteNone:   "❌ *{{.Name}}* is not marked for deletion.",
+		CancelDeleteFailed: "❌ Failed to update the cluster",
+		DeleteCancelled:    "✅ Kept *{{.Name}}*: its deletion is cancelled.",
+
+		PinUsage:   "❌ Usage: `pin <cluster_name>`",
+		UnpinUsage: "❌ Usage: `unpin <cluster_name>`",
+		Pinned:     "📌 Pinned *{{.Name}}*: `cleanup` will leave it alone until it is unpinned.",
+		Unpinned:   "📌 Unpinned *{{.Name}}*: `cleanup` may select it again.",
+		PinDenied:  "⛔ Only the owner of *{{.Name}}*{{if .Owner}} (<@{{.Owner}}>){{end}} or a bot administrator can pin or unpin it.",
+		PinFailed:  "❌ Failed to update the cluster",
+
+		RenameUsage:   "❌ Usage: `rename <cluster_name> <display name>`",
+		RenameInvalid: "❌ Invalid display name *{{.DisplayName}}*: it must be at most {{.Max}} characters, without `<`, `>`, `&` or formatting characters.",
+		RenameDenied:  "⛔ Only the owner of *{{.Name}}*{{if .Owner}} (<@{{.Owner}}>){{end}} or a bot administrator can rename it.",
+		RenameFailed:  "❌ Failed to update the cluster",
+		Renamed:       "🏷️ *{{.Name}}* is now shown as “{{.DisplayName}}”. Its resource name is unchanged.",
+
+		FollowUsage:    "❌ Usage: `follow <cluster_name>`",
+		UnfollowUsage:  "❌ Usage: `unfollow <cluster_name>`",
+		FollowStarted:  "🔎 Following *{{.Name}}* (currently {{.Phase}}). I'll post its status changes, expiry and deletion in this thread. Stop with `unfollow {{.Name}}`.",
+		FollowStopped:  "🔎 Stopped following *{{.Name}}*.",
+		NotFollowing:   "❌ *{{.Name}}* is not followed here.",
+		FollowPhase:    "🔎 *{{.Name}}* is now *{{.Phase}}*.",
+		FollowExpiring: "⌛ *{{.Name}}* reaches the end of its TTL {{.Expires}}.{{if .Permalink}} 🔗 <{{.Permalink}}|Launch thread>{{end}}",
+		FollowGone:     "🗑️ *{{.Name}}* is gone, no longer following it.",
+
+		ClusterNotFound: "❌ Cluster *{{.Name}}* not found.",
+		DiffUsage:       "❌ Usage: `diff <cluster_name> <size>`",
+		DiffUnknownSize: "❌ The current size of *{{.Name}}* is unknown, so no diff can be computed.",
+		DiffHeader:      "🔍 *Preview for {{.Name}}* ({{.From}} → {{.To}}, nothing applied)\n",
+		DiffLine:        "• {{.Field}}: {{.Before}} → {{.After}} ({{.Delta}})\n",
+		DiffDownscale:   "⚠️ This is a downscale: workloads needing more than the new size may be disrupted.\n",
+
+		StatusUsage: "❌ Usage: `status <cluster_name>`",
+		StatusDetails: "🔎 *{{.Name}}*{{if .DisplayName}} “{{.DisplayName}}”{{end}} ({{.Type}})\n" +
+			"• Status: {{.Phase}}\n" +
+			"{{if .APIServer}}• API server: {{.APIServer}}\n{{end}}" +
+			"{{if .Console}}• Console: {{.Console}}\n{{end}}" +
+			"{{if .EndpointsPending}}• Endpoints: available once the cluster is ready\n{{end}}" +
+			"• Namespace: {{.Namespace}}\n" +
+			"{{if .Version}}• Version: {{.Version}}\n{{end}}" +
+			"{{if .Size}}• Size: {{.Size}}{{if .CPU}} ({{.CPU}}, {{.RAM}}){{end}}\n{{end}}" +
+			"{{if .Limits}}• Limits: {{.Limits}}\n{{end}}" +
+			"{{if .Ref}}• Ref: {{.Ref}}\n{{end}}" +
+			"{{if .Owner}}• Owner: <@{{.Owner}}>\n{{end}}" +
+			"{{if .Permalink}}• Launch thread: <{{.Permalink}}|open in Slack>\n{{end}}" +
+			"• Created: {{.Age}} ({{.Created}})\n" +
+			"{{if .TTL}}• TTL: {{.TTL}} (expires {{.Expires}})\n{{end}}" +
+			"{{if .Accumulated}}• Cost so far: {{.Accumulated}} at {{.HourlyCost}}\n{{end}}" +
+			"{{if .Pinned}}• 📌 Pinned: never selected by `cleanup`\n{{end}}" +
+			"{{if .DeleteAt}}• 🗑️ Marked for deletion {{.DeleteAt}}: `cancel-delete {{.Name}}` keeps it\n{{end}}",
+
+		ConsoleUsage:         "❌ Usage: `console <cluster_name>`",
+		ConsoleLink:          "🔎 <{{.URL}}|Open the {{.Provider}} console for *{{.Name}}*> ({{.Region}})",
+		ConsoleMissingRegion: "⏳ The region of *{{.Name}}* is not known yet. It is available once the cluster reports it.",
+		ConsoleUnsupported:   "❌ No console link is configured for {{.Provider}}, the provider of *{{.Name}}*.",
+
+		TopUsage: "❌ Usage: `top [count]`, with a count from 1 to {{.Max}}",
+		TopNone:  "💸 No running cluster has a recorded cost.",
+		Top:      "💸 *Top {{.Count}} clusters by cost* (of {{.Total}} running)\n{{.Entries}}",
+		TopEntry: "{{.Rank}}. *{{.Name}}* — {{.HourlyCost}}{{if .Accumulated}}, {{.Accumulated}} so far{{end}}{{if .Size}} · {{.Size}}{{end}}{{if .Owner}} · <@{{.Owner}}>{{end}}\n",
+
+		Stats: "📊 *Cluster Stats* ({{.Total}} cluster{{if ne .Total 1}}s{{end}})\n" +
+			"• By type: {{.ByType}}\n" +
+			"• By size: {{.BySize}}\n" +
+			"• By status: {{.ByStatus}}\n" +
+			"• Total nodes: {{.Nodes}}\n" +
+			"• Estimated cost: {{.HourlyCost}}, {{.Accumulated}} since launch",
+
+		ListEmpty:  "📋 *Cluster List*\n\nNo MAPT clusters currently running.",
+		ListHeader: "📋 *Cluster List* ({{.Count}} cluster{{if ne .Count 1}}s{{end}})\n\n",
+		ListEntry: "{{.Indicator}} *{{.Name}}*{{if .DisplayName}} “{{.DisplayName}}”{{end}} ({{.Type}}, {{.Phase}}){{if .Pinned}} 📌{{end}}\n" +
+			"   • Namespace: {{.Namespace}}\n" +
+			"   • Created: {{.Age}} ({{.Created}})\n",
+		ListTypeFailed: "⚠️ Could not retrieve {{.Type}} clusters",
+		ListUsage:      "❌ Usage: `list [--by-owner] [--all-namespaces]`",
+		ListPage:       "📋 Clusters {{.First}}–{{.Last}} of {{.Total}} (page {{.Page}} of {{.Pages}}). Use `export` for the full inventory.",
+		ListPageDenied: "⛔ Only <@{{.User}}>, who ran this `list`, can turn its pages. Run `list` yourself to browse the clusters.",
+
+		ListAllNamespacesDenied: "⛔ Only bot administrators can use `--all-namespaces`.",
+
+		FailedNone:        "✅ No cluster is in a failed state.",
+		FailedHeader:      "🔴 *Failed clusters* ({{.Count}}){{if lt .Shown .Count}}, the {{.Shown}} oldest shown{{end}}",
+		FailedEntry:       "*{{.Name}}* ({{.Type}}, {{.Namespace}}) failed, created {{.Age}}{{if .Owner}} by <@{{.Owner}}>{{end}}\n{{if .Reason}}> {{.Reason}}{{else}}_No reason reported._{{end}}",
+		FailedNoLonger:    "❌ *{{.Name}}* is no longer in a failed state. Run `failed` again for the current list.",
+		FailedRetried:     "🚀 <@{{.User}}> relaunched *{{.Name}}* as *{{.NewName}}*; the failed cluster is being deleted. Run `status {{.NewName}}` to follow it.",
+		FailedRetryFailed: "❌ Failed to relaunch the cluster",
+		ListOwnerHeader:   "👤 {{if .Owner}}<@{{.Owner}}>{{else}}No owner{{end}} ({{.Count}} cluster{{if ne .Count 1}}s{{end}})\n",
+
+		ExportUsage:  "❌ Usage: `export [--mine]`",
+		ExportReady:  "📋 *Cluster export* ({{.Count}} cluster{{if ne .Count 1}}s{{end}})",
+		ExportSent:   "📋 <@{{.User}}> the export was sent to you in a direct message.",
+		ExportFailed: "❌ Failed to export the cluster inventory",
+
+		DiagnosticsUsage:  "❌ Usage: `diagnostics <cluster_name>`",
+		DiagnosticsReady:  "🧰 *Diagnostics of {{.Name}}*: status, resource, events and provisioning logs.{{if .Missing}}\n⚠️ Could not collect {{.Missing}}: see `ERRORS.txt` in the bundle.{{end}}",
+		DiagnosticsSent:   "🧰 <@{{.User}}> the diagnostics of *{{.Name}}* were sent to you in a direct message.",
+		DiagnosticsFailed: "❌ Failed to collect the diagnostics bundle",
+
+		PermissionsUsage:  "❌ Usage: `permissions [namespace]`",
+		PermissionsFailed: "❌ Failed to review the bot's permissions in namespace `{{.Namespace}}`.",
+		Permissions: "🔐 *Kubernetes permissions of the bot* (namespace `{{.Namespace}}`): " +
+			"{{if .Missing}}{{.Missing}} of {{.Total}} missing{{else}}all {{.Total}} granted{{end}}\n{{.Entries}}",
+		PermissionsEntry: "{{if .Allowed}}✅{{else}}❌{{end}} `{{.Verb}}` {{.Resource}} in `{{.Namespace}}`{{if and (not .Allowed) .Reason}} — {{.Reason}}{{end}}\n",
+
+		UploadTooLarge: "❌ The file is larger than the {{.Max}} upload limit. Narrow it down, e.g. with `export --mine`.",
+		UploadTimedOut: "⌛ Uploading the file took longer than {{.Timeout}}. Please try again later.",
+
+		CleanupNone:  "🧹 *Cleanup*\n\nNo clusters match the cleanup criteria.",
+		CleanupEntry: "• *{{.Name}}* ({{.Type}}) — {{.Reason}}\n",
+		CleanupSelection: "🧹 *Cleanup* — {{.Count}} cluster{{if ne .Count 1}}s{{end}} would be deleted:\n\n" +
+			"{{.Entries}}\nTo delete them, reply `cleanup confirm {{.Phrase}}` within {{.Window}}.",
+		CleanupPreview: "🧹 *Cleanup preview* — {{.Count}} cluster{{if ne .Count 1}}s{{end}} would be deleted:\n\n{{.Entries}}\n" +
+			"Nothing was deleted. Run `cleanup` to select them for deletion.",
+		CleanupNoPending: "❌ Nothing to confirm. Run `cleanup` first to select clusters.",
+		CleanupWrongPhrase: "❌ That is not the confirmation phrase: the cleanup of {{.Count}} cluster{{if ne .Count 1}}s{{end}} was aborted and nothing was deleted. " +
+			"Run `cleanup` again for a new phrase.",
+		CleanupComplete: "🧹 Cleanup complete: {{.Deleted}} cluster{{if ne .Deleted 1}}s{{end}} deleted." +
+			"{{if .Failed}}\n⚠️ {{.Failed}} cluster{{if ne .Failed 1}}s{{end}} could not be deleted, check the logs.{{end}}",
+
+		HelpHeader: "📖 *Spoticus commands:*\n",
+		HelpEntry:  "\n• *{{.Name}}* — {{.Description}}\n  _Usage:_ {{.Usage}}\n",
+
+		HelpCommand:        "📖 *{{.Name}}* — {{.Description}}\n_Usage:_ {{.Usage}}\n{{if .Details}}\n{{.Details}}{{end}}{{if .Examples}}\n_Examples:_\n{{range .Examples}}• `{{.}}`\n{{end}}{{end}}",
+		HelpUnknownCommand: "❌ Unknown command: *{{.Name}}*. Send `help` for the list of commands.",
+
+		CommandFailed: "❌ Something went wrong running *{{.Command}}*. Please try again later.",
+
+		HistoryEmpty:        "🕘 *Command History*\n\nNo commands recorded yet.",
+		HistoryHeader:       "🕘 *Command History* (last {{.Count}})\n\n",
+		HistoryEntry:        "• {{.Time}} <@{{.User}}> `{{.Command}}` — {{.Outcome}}\n",
+		HistoryInvalidCount: "❌ Invalid count: *{{.Count}}*\nUsage: `history [n]`",
+
+		ErrorsEmpty:        "⚠️ *Recent Errors*\n\nNo errors recorded since the bot started.",
+		ErrorsHeader:       "⚠️ *Recent Errors* (last {{.Count}})\n\n",
+		ErrorsEntry:        "• {{.Time}} {{.Message}}\n  `{{.Error}}`\n",
+		ErrorsInvalidCount: "❌ Invalid count: *{{.Count}}*\nUsage: `errors [n]`",
+
+		ReliabilityUsage:  "❌ Usage: `reliability [window]`, e.g. `reliability 7d` or `reliability 12h`",
+		Reliability:       "📊 *Launch Reliability* (last {{.Window}})\n\n{{if .Total}}• Launches: *{{.Total}}* — ✅ {{.Succeeded}} ready, ❌ {{.Failed}} failed ({{.SuccessRate}} success)\n{{if .AverageReady}}• Average time to ready: *{{.AverageReady}}*\n{{end}}{{if .Reasons}}• Most common failures:\n{{.Reasons}}{{end}}{{else}}No launches finished in this window.\n{{end}}\n_Counted since the bot started._",
+		ReliabilityReason: "    ◦ {{.Reason}} — {{.Count}}\n",
+
+		AnnounceUsage:      "❌ Usage: `announce <message>`",
+		AnnounceNoChannels: "⚠️ No announcement channels are configured. List them under `announceChannels` in the configuration.",
+		Announcement:       "📣 *Announcement* from <@{{.User}}>\n\n{{.Message}}",
+		AnnounceSent:       "📣 Announcement delivered to {{.Sent}} of {{.Total}} channels.",
+		AnnounceFailure:    "\n⚠️ Could not post in <#{{.Channel}}>: {{.Error}}",
+
+		ReloadFailed:    "❌ Config reload failed, keeping the current configuration:\n```\n{{.Error}}\n```",
+		ReloadUnchanged: "🔄 Configuration reloaded. No changes detected.",
+		ReloadChanged:   "🔄 *Configuration reloaded.* Changes:\n{{range .Changes}}• {{.}}\n{{end}}",
+
+		MaintenanceActive: "🚧 Spoticus is in maintenance mode, so *{{.Command}}* is unavailable for now. Read commands such as `list` and `status` still work.",
+		MaintenanceStatus: "🚧 Maintenance mode is *{{if .Enabled}}on{{else}}off{{end}}*.",
+		MaintenanceUsage:  "❌ Usage: `maintenance [on|off]`",
+		MaintenanceFailed: "❌ Failed to update maintenance mode",
+
+		BackendNotReady: "⏳ The Kubernetes backend is not ready, so *{{.Command}}* is unavailable for now. Check `uptime` and try again shortly.",
+		CommandCooldown: "⏳ You ran *{{.Command}}* moments ago. Please wait {{.Remaining}} before running it again.",
+
+		ConfigDump:   "⚙️ *Effective configuration* (message templates omitted)\n```\n{{.Config}}```",
+		ConfigFailed: "❌ Failed to render the configuration",
+	}
+}
+
+// Data holds the values referenced by a template.
+type Data map[string]any
+
+// templates caches parsed templates by their source text.
+var templates sync.Map
+
+// Render executes the template with the given data.
+// A template that fails to parse or execute is logged and returned verbatim,
+// so a bad override degrades the message rather than silencing the bot.
+func Render(text string, data Data) string {
+	tmpl, err := parse(text)
+	if err != nil {
+		log.Printf("Invalid message template %q: %v", text, err)
+		return text
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		log.Printf("Error rendering message template %q: %v", text, err)
+		return text
+	}
+	return b.String()
+}
+
+// parse returns the parsed template for text, parsing it on first use.
+func parse(text string) (*template.Template, error) {
+	if cached, ok := templates.Load(text); ok {
+		return cached.(*template.Template), nil
+	}
+	tmpl, err := template.New("message").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	templates.Store(text, tmpl)
+	return tmpl, nil
+}
+
+// Validate checks that every template parses, reporting each invalid one by name.
+func (m Messages) Validate() error {
+	var errs []error
+	v := reflect.ValueOf(m)
+	for i := 0; i < v.NumField(); i++ {
+		if _, err := parse(v.Field(i).String()); err != nil {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+			errs = append(errs, fmt.Errorf("messages.%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
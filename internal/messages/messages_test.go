@@ -0,0 +1,43 @@
+package messages
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		data Data
+		want string
+	}{
+		{name: "plain text", text: "hello", want: "hello"},
+		{name: "fields", text: "*{{.Name}}* is ready, <@{{.User}}>", data: Data{"Name": "c1", "User": "U1"}, want: "*c1* is ready, <@U1>"},
+		{name: "conditional", text: "{{.Count}} cluster{{if ne .Count 1}}s{{end}}", data: Data{"Count": 2}, want: "2 clusters"},
+		{name: "invalid template kept verbatim", text: "{{.Name", data: Data{"Name": "c1"}, want: "{{.Name"},
+		{name: "failing template kept verbatim", text: "{{.Name.Field}}", data: Data{"Name": 3}, want: "{{.Name.Field}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.text, tt.data); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultMessagesAreValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Errorf("the default messages do not parse: %v", err)
+	}
+}
+
+func TestValidateNamesInvalidTemplate(t *testing.T) {
+	m := Default()
+	m.LaunchReady = "{{if .Name}}unterminated"
+	err := m.Validate()
+	if err == nil || !strings.Contains(err.Error(), "messages.launchReady") {
+		t.Errorf("Validate() = %v, want launchReady reported", err)
+	}
+}
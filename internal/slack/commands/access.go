@@ -0,0 +1,27 @@
+package commands
+
+import (
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+)
+
+// labelTeam is the label carrying the team a cluster belongs to. Unlike the
+// annotations, it is a label so clusters can be selected by team.
+const labelTeam = "spoticus.io/owner-team"
+
+// canOperate reports whether the user may operate on the cluster.
+//
+// Admins may operate on every cluster, and so may anyone on clusters without
+// a team label. Clusters labelled with a team are restricted to its members,
+// as configured in Config.Teams; the user who launched the cluster keeps
+// access even after leaving the team.
+func canOperate(cfg *spoticusConfig.Config, user string, cluster ClusterInfo) bool {
+	team := cluster.Labels[labelTeam]
+	switch {
+	case team == "", cfg.IsAdmin(user):
+		return true
+	case cluster.Metadata().Owner == user:
+		return true
+	default:
+		return cfg.InTeam(user, team)
+	}
+}
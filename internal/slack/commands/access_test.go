@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// teamConfig is the test configuration with an admin and two teams.
+func teamConfig() *spoticusConfig.Config {
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	cfg.Teams = map[string][]string{
+		"platform": {"UPLAT1", "UPLAT2"},
+		"payments": {"UPAY"},
+	}
+	return cfg
+}
+
+// teamCluster is existingCluster labelled with the team.
+func teamCluster(name, owner, team string) *unstructured.Unstructured {
+	cluster := existingCluster(name, owner, 0)
+	cluster.SetLabels(map[string]string{labelTeam: team})
+	return cluster
+}
+
+func TestCanOperate(t *testing.T) {
+	cfg := teamConfig()
+	tests := []struct {
+		name string
+		user string
+		team string
+		want bool
+	}{
+		{name: "same team", user: "UPLAT2", team: "platform", want: true},
+		{name: "other team", user: "UPAY", team: "platform"},
+		{name: "no team", user: "UNOBODY", team: "platform"},
+		{name: "admin", user: "UADMIN", team: "platform", want: true},
+		{name: "owner who left the team", user: "UOWNER", team: "platform", want: true},
+		{name: "cluster without a team", user: "UPAY", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := teamCluster("spoticus-k8s-a", "UOWNER", tt.team)
+			if tt.team == "" {
+				meta.SetLabels(nil)
+			}
+			cluster := ClusterInfo{Name: meta.GetName(), Labels: meta.GetLabels(), Annotations: meta.GetAnnotations()}
+
+			if got := canOperate(cfg, tt.user, cluster); got != tt.want {
+				t.Errorf("canOperate(%s, team %q) = %v, want %v", tt.user, tt.team, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoneEnforcesTeamAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       string
+		wantDelete bool
+	}{
+		{name: "same team allowed", user: "UPLAT2", wantDelete: true},
+		{name: "cross team denied", user: "UPAY"},
+		{name: "admin override", user: "UADMIN", wantDelete: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, teamConfig())
+			kube := slacktest.NewKube(teamCluster("spoticus-k8s-team", "UPLAT1", "platform"))
+			useKube(t, kube)
+			api, _ := newAPI(t)
+
+			err := HandleDone(context.Background(), api, message(tt.user, "C1", "done spoticus-k8s-team"), []string{"spoticus-k8s-team"})
+			if tt.wantDelete {
+				if err != nil {
+					t.Fatalf("HandleDone: %v", err)
+				}
+			} else if CategoryOf(err) != CategoryAuth {
+				t.Fatalf("HandleDone() = %v, want access denied", err)
+			}
+			if deleted := len(launchedKinds(t, kube)) == 0; deleted != tt.wantDelete {
+				t.Errorf("cluster deleted = %v, want %v", deleted, tt.wantDelete)
+			}
+		})
+	}
+}
+
+func TestStatusDeniedAcrossTeams(t *testing.T) {
+	useConfig(t, teamConfig())
+	useKube(t, slacktest.NewKube(teamCluster("spoticus-k8s-team", "UPLAT1", "platform")))
+	api, _ := newAPI(t)
+
+	err := HandleStatus(context.Background(), api, message("UPAY", "C1", "status spoticus-k8s-team"), []string{"spoticus-k8s-team"})
+	var commandErr *CommandError
+	if CategoryOf(err) != CategoryAuth || !errors.As(err, &commandErr) || !strings.Contains(commandErr.Message, "belongs to team *platform*") {
+		t.Errorf("HandleStatus() = %v, want access denied naming the team", err)
+	}
+}
+
+func TestLaunchLabelsClusterWithTeam(t *testing.T) {
+	useConfig(t, teamConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("UPLAT1", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(kinds))
+	}
+	if got := kinds[0].Labels[labelTeam]; got != "platform" {
+		t.Errorf("cluster team label = %q, want platform", got)
+	}
+}
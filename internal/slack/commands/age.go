@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+)
+
+// timestampLayout is the absolute time format shown next to relative ages.
+const timestampLayout = "2006-01-02 15:04 MST"
+
+// humanizeAge describes how long ago t was, e.g. "just now", "5m ago", "3h ago" or "2d ago".
+// Timestamps in the future are described as "in 3h".
+func humanizeAge(t time.Time) string {
+	return formatAge(time.Since(t))
+}
+
+// formatAge renders an elapsed duration in the style of humanizeAge.
+// Negative durations are in the future.
+func formatAge(d time.Duration) string {
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		amount = fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		amount = fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{age: 0, want: "just now"},
+		{age: 59 * time.Second, want: "just now"},
+		{age: -30 * time.Second, want: "just now"},
+		{age: time.Minute, want: "1m ago"},
+		{age: 59*time.Minute + 59*time.Second, want: "59m ago"},
+		{age: time.Hour, want: "1h ago"},
+		{age: 3*time.Hour + 40*time.Minute, want: "3h ago"},
+		{age: 24 * time.Hour, want: "1d ago"},
+		{age: 50 * time.Hour, want: "2d ago"},
+		{age: 400 * 24 * time.Hour, want: "400d ago"},
+		{age: -5 * time.Minute, want: "in 5m"},
+		{age: -3 * time.Hour, want: "in 3h"},
+		{age: -72 * time.Hour, want: "in 3d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.age.String(), func(t *testing.T) {
+			if got := formatAge(tt.age); got != tt.want {
+				t.Errorf("formatAge(%s) = %q, want %q", tt.age, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHumanizeAge(t *testing.T) {
+	if got := humanizeAge(time.Now().Add(-2 * time.Hour)); got != "2h ago" {
+		t.Errorf("humanizeAge(2h ago) = %q, want 2h ago", got)
+	}
+	if got := humanizeAge(time.Now().Add(90 * time.Minute)); got != "in 1h" {
+		t.Errorf("humanizeAge(in 90m) = %q, want in 1h", got)
+	}
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// HandleAnnounce is the entry point for the admin "announce" Slack command.
+// It posts the message to every configured announcement channel and reports
+// how many of them received it.
+func HandleAnnounce(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	if len(args) == 0 {
+		return invalid(msgs().AnnounceUsage)
+	}
+	channels := spoticusConfig.Get().AnnounceChannels
+	if len(channels) == 0 {
+		respond.Text(api, event.Channel, msgs().AnnounceNoChannels)
+		return nil
+	}
+
+	text := messages.Render(msgs().Announcement, messages.Data{
+		"Message": strings.Join(args, " "),
+		"User":    event.User,
+	})
+	respond.Text(api, event.Channel, announce(api, channels, text))
+	return nil
+}
+
+// announce posts text to each channel and returns the delivery report. A
+// failure in one channel does not stop the others.
+func announce(api *slack.Client, channels []string, text string) string {
+	var failures strings.Builder
+	sent := 0
+	for _, channel := range channels {
+		if _, _, err := respond.Post(api, channel, slack.MsgOptionText(text, false)); err != nil {
+			log.Printf("Error posting announcement to %s: %v", channel, err)
+			failures.WriteString(messages.Render(msgs().AnnounceFailure, messages.Data{
+				"Channel": channel,
+				"Error":   err.Error(),
+			}))
+			continue
+		}
+		sent++
+	}
+
+	log.Printf("Announcement delivered to %d/%d channels", sent, len(channels))
+	return messages.Render(msgs().AnnounceSent, messages.Data{"Sent": sent, "Total": len(channels)}) + failures.String()
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestAnnouncePostsToEveryChannel(t *testing.T) {
+	cfg := testConfig()
+	cfg.AnnounceChannels = []string{"CA", "CB", "CC"}
+	useConfig(t, cfg)
+	api, server := newAPI(t)
+
+	if err := HandleAnnounce(context.Background(), api, message("UADMIN", "C1", "announce maintenance at 5pm"), []string{"maintenance", "at", "5pm"}); err != nil {
+		t.Fatalf("HandleAnnounce: %v", err)
+	}
+
+	report := server.WaitForMessage("Announcement delivered", time.Second)
+	if report.Channel() != "C1" || !strings.Contains(report.Text(), "delivered to 3 of 3 channels") {
+		t.Errorf("reported %q in %s, want 3 of 3 in C1", report.Text(), report.Channel())
+	}
+	for _, channel := range cfg.AnnounceChannels {
+		found := false
+		for _, call := range server.Messages() {
+			if call.Channel() == channel && strings.Contains(call.Text(), "maintenance at 5pm") && strings.Contains(call.Text(), "<@UADMIN>") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("announcement not posted to %s", channel)
+		}
+	}
+}
+
+func TestAnnounceReportsChannelFailures(t *testing.T) {
+	cfg := testConfig()
+	cfg.AnnounceChannels = []string{"CA", "CGONE", "CC"}
+	useConfig(t, cfg)
+	api, server := newAPI(t)
+	server.Handle("chat.postMessage", func(values url.Values) map[string]any {
+		if values.Get("channel") == "CGONE" {
+			return map[string]any{"ok": false, "error": "channel_not_found"}
+		}
+		return nil
+	})
+
+	if err := HandleAnnounce(context.Background(), api, message("UADMIN", "C1", "announce hello"), []string{"hello"}); err != nil {
+		t.Fatalf("HandleAnnounce: %v", err)
+	}
+
+	text := server.WaitForMessage("Announcement delivered", time.Second).Text()
+	if !strings.Contains(text, "delivered to 2 of 3 channels") {
+		t.Errorf("report %q, want 2 of 3 channels", text)
+	}
+	if !strings.Contains(text, "Could not post in <#CGONE>: channel_not_found") {
+		t.Errorf("report %q does not name the failed channel", text)
+	}
+	if _, ok := server.Wait(func(call slacktest.Call) bool { return call.Channel() == "CC" }, time.Second); !ok {
+		t.Error("the failure stopped the announcement to the next channel")
+	}
+}
+
+func TestAnnounceWithoutChannelsOrMessage(t *testing.T) {
+	useConfig(t, testConfig())
+	api, server := newAPI(t)
+
+	if err := HandleAnnounce(context.Background(), api, message("UADMIN", "C1", "announce hello"), []string{"hello"}); err != nil {
+		t.Fatalf("HandleAnnounce: %v", err)
+	}
+	server.WaitForMessage("No announcement channels are configured", time.Second)
+
+	err := HandleAnnounce(context.Background(), api, message("UADMIN", "C1", "announce"), nil)
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Usage: `announce <message>`") {
+		t.Errorf("HandleAnnounce() = %v, want the usage", err)
+	}
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// ErrBackendUnavailable is returned while the backend circuit breaker is open.
+var ErrBackendUnavailable = errors.New("kubernetes backend temporarily unavailable")
+
+// breakerState is the state of a circuitBreaker.
+type breakerState int
+
+const (
+	// breakerClosed lets every call through and counts consecutive failures.
+	breakerClosed breakerState = iota
+	// breakerOpen rejects every call until the cooldown has elapsed.
+	breakerOpen
+	// breakerHalfOpen lets a single probe call through to test recovery.
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker short-circuits backend calls after repeated failures.
+//
+// After the configured number of consecutive failures it opens and rejects
+// calls for the cooldown period. It then half-opens and lets one probe
+// through: success closes it again, failure re-opens it for another cooldown.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	// settings returns the current threshold and cooldown, so reloads apply immediately.
+	settings func() spoticusConfig.CircuitBreaker
+	now      func() time.Time
+}
+
+// backendBreaker guards every call to the Kubernetes backend.
+var backendBreaker = newCircuitBreaker(func() spoticusConfig.CircuitBreaker {
+	return spoticusConfig.Get().CircuitBreaker
+})
+
+// newCircuitBreaker creates a closed breaker using the given settings.
+func newCircuitBreaker(settings func() spoticusConfig.CircuitBreaker) *circuitBreaker {
+	return &circuitBreaker{settings: settings, now: time.Now}
+}
+
+// Allow reports whether a call may proceed, returning ErrBackendUnavailable if not.
+func (b *circuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < time.Duration(b.settings().Cooldown) {
+			return ErrBackendUnavailable
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		log.Printf("Backend circuit breaker half-open, probing the backend")
+		return nil
+	case breakerHalfOpen:
+		if b.probing {
+			return ErrBackendUnavailable
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Check reports whether the breaker is open without consuming the half-open probe.
+func (b *circuitBreaker) Check() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerOpen && b.now().Sub(b.openedAt) < time.Duration(b.settings().Cooldown) {
+		return ErrBackendUnavailable
+	}
+	return nil
+}
+
+// Record updates the breaker with the outcome of a call.
+// Errors caused by the request itself (not found, conflicts, invalid input)
+// or by missing CRDs show the backend is reachable and count as successes.
+func (b *circuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isBackendFailure(err) {
+		if b.state != breakerClosed {
+			log.Printf("Backend circuit breaker closed, backend recovered")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.settings().Threshold {
+		if b.state != breakerOpen {
+			log.Printf("Backend circuit breaker open after %d consecutive failure(s): %v", b.failures, err)
+		}
+		b.state = breakerOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// State returns the current breaker state.
+func (b *circuitBreaker) State() breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// isBackendFailure reports whether err indicates the backend itself is failing.
+func isBackendFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	return !(apierrors.IsNotFound(err) ||
+		apierrors.IsAlreadyExists(err) ||
+		apierrors.IsConflict(err) ||
+		apierrors.IsInvalid(err) ||
+		apierrors.IsBadRequest(err) ||
+		apierrors.IsForbidden(err) ||
+		meta.IsNoMatchError(err) ||
+		errors.Is(err, context.Canceled))
+}
+
+// breakerClient wraps a controller-runtime client so that every call goes
+// through the circuit breaker.
+type breakerClient struct {
+	crclient.Client
+	breaker *circuitBreaker
+}
+
+// guard runs call if the breaker allows it and records the outcome. Each call
+// is traced as a child span of the command span in ctx; name is the object
+// operated on, empty for lists.
+func (c *breakerClient) guard(ctx context.Context, operation, name string, obj runtime.Object, call func(ctx context.Context) error) error {
+	attrs := []attribute.KeyValue{tracing.AttrOperation.String(operation)}
+	if gvk, err := c.Client.GroupVersionKindFor(obj); err == nil {
+		attrs = append(attrs, tracing.AttrKind.String(gvk.Kind))
+	}
+	if name != "" {
+		attrs = append(attrs, tracing.AttrObject.String(name))
+	}
+	ctx, span := tracing.Start(ctx, "kubernetes "+operation, attrs...)
+
+	if err := c.breaker.Allow(); err != nil {
+		tracing.End(span, err)
+		return err
+	}
+	err := call(ctx)
+	c.breaker.Record(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (c *breakerClient) Get(ctx context.Context, key crclient.ObjectKey, obj crclient.Object, opts ...crclient.GetOption) error {
+	return c.guard(ctx, "get", key.Name, obj, func(ctx context.Context) error { return c.Client.Get(ctx, key, obj, opts...) })
+}
+
+func (c *breakerClient) List(ctx context.Context, list crclient.ObjectList, opts ...crclient.ListOption) error {
+	return c.guard(ctx, "list", "", list, func(ctx context.Context) error { return c.Client.List(ctx, list, opts...) })
+}
+
+func (c *breakerClient) Create(ctx context.Context, obj crclient.Object, opts ...crclient.CreateOption) error {
+	return c.guard(ctx, "create", obj.GetName(), obj, func(ctx context.Context) error { return c.Client.Create(ctx, obj, opts...) })
+}
+
+func (c *breakerClient) Delete(ctx context.Context, obj crclient.Object, opts ...crclient.DeleteOption) error {
+	return c.guard(ctx, "delete", obj.GetName(), obj, func(ctx context.Context) error { return c.Client.Delete(ctx, obj, opts...) })
+}
+
+func (c *breakerClient) Update(ctx context.Context, obj crclient.Object, opts ...crclient.UpdateOption) error {
+	return c.guard(ctx, "update", obj.GetName(), obj, func(ctx context.Context) error { return c.Client.Update(ctx, obj, opts...) })
+}
+
+func (c *breakerClient) Patch(ctx context.Context, obj crclient.Object, patch crclient.Patch, opts ...crclient.PatchOption) error {
+	return c.guard(ctx, "patch", obj.GetName(), obj, func(ctx context.Context) error { return c.Client.Patch(ctx, obj, patch, opts...) })
+}
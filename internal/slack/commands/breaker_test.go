@@ -0,0 +1,229 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// testBreaker returns a breaker opening after threshold failures for a
+// minute, and the clock it reads, which the test advances.
+func testBreaker(threshold int) (*circuitBreaker, *time.Time) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(func() spoticusConfig.CircuitBreaker {
+		return spoticusConfig.CircuitBreaker{Threshold: threshold, Cooldown: spoticusConfig.Duration(time.Minute)}
+	})
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	errBackend := errors.New("connection refused")
+
+	// Each step advances the clock, then records an outcome (if any) after
+	// asking whether a call may proceed.
+	type step struct {
+		advance   time.Duration
+		allow     bool // whether Allow is called first
+		allowed   bool // what Allow must report
+		record    bool
+		err       error
+		wantState breakerState
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "closed to open to half-open to closed",
+			steps: []step{
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerOpen},
+				{advance: 30 * time.Second, allow: true, allowed: false, wantState: breakerOpen},
+				{advance: 30 * time.Second, allow: true, allowed: true, wantState: breakerHalfOpen},
+				{record: true, wantState: breakerClosed},
+				{allow: true, allowed: true, wantState: breakerClosed},
+			},
+		},
+		{
+			name: "failed probe re-opens for another cooldown",
+			steps: []step{
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerOpen},
+				{advance: time.Minute, allow: true, allowed: true, wantState: breakerHalfOpen},
+				{record: true, err: errBackend, wantState: breakerOpen},
+				{advance: 59 * time.Second, allow: true, allowed: false, wantState: breakerOpen},
+				{advance: time.Second, allow: true, allowed: true, wantState: breakerHalfOpen},
+			},
+		},
+		{
+			name: "success resets the failure count",
+			steps: []step{
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerClosed},
+			},
+		},
+		{
+			name: "request errors do not count as failures",
+			steps: []step{
+				{allow: true, allowed: true, record: true, err: apierrors.NewNotFound(schema.GroupResource{Resource: "kinds"}, "x"), wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: apierrors.NewConflict(schema.GroupResource{Resource: "kinds"}, "x", nil), wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: context.Canceled, wantState: breakerClosed},
+				{allow: true, allowed: true, record: true, err: errBackend, wantState: breakerClosed},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, now := testBreaker(3)
+			for i, s := range tt.steps {
+				*now = now.Add(s.advance)
+				if s.allow {
+					err := b.Allow()
+					if allowed := err == nil; allowed != s.allowed {
+						t.Fatalf("step %d: Allow() = %v, want allowed %t", i, err, s.allowed)
+					}
+					if err != nil && !errors.Is(err, ErrBackendUnavailable) {
+						t.Fatalf("step %d: Allow() = %v, want ErrBackendUnavailable", i, err)
+					}
+				}
+				if s.record {
+					b.Record(s.err)
+				}
+				if got := b.State(); got != s.wantState {
+					t.Fatalf("step %d: state = %s, want %s", i, got, s.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	b, now := testBreaker(1)
+	b.Record(errors.New("connection refused"))
+	*now = now.Add(time.Minute)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("first call after the cooldown: Allow() = %v, want the probe through", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); !errors.Is(err, ErrBackendUnavailable) {
+			t.Fatalf("call %d during the probe: Allow() = %v, want ErrBackendUnavailable", i, err)
+		}
+	}
+	if err := b.Check(); err != nil {
+		t.Errorf("Check() during the probe = %v, want nil: it must not report the breaker open", err)
+	}
+
+	b.Record(nil)
+	if err := b.Allow(); err != nil {
+		t.Errorf("after a successful probe: Allow() = %v, want nil", err)
+	}
+}
+
+func TestCircuitBreakerCheckDoesNotConsumeProbe(t *testing.T) {
+	b, now := testBreaker(1)
+	b.Record(errors.New("connection refused"))
+	if err := b.Check(); !errors.Is(err, ErrBackendUnavailable) {
+		t.Fatalf("Check() while open = %v, want ErrBackendUnavailable", err)
+	}
+
+	*now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := b.Check(); err != nil {
+			t.Fatalf("Check() after the cooldown = %v, want nil", err)
+		}
+	}
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow() after Check() = %v, want the probe still available", err)
+	}
+}
+
+func TestBreakerClientShortCircuits(t *testing.T) {
+	calls := 0
+	kube := slacktest.NewKubeWithInterceptor(interceptor.Funcs{
+		List: func(ctx context.Context, client crclient.WithWatch, list crclient.ObjectList, opts ...crclient.ListOption) error {
+			calls++
+			return errors.New("connection refused")
+		},
+	})
+	b, _ := testBreaker(2)
+	client := &breakerClient{Client: kube.CrClient, breaker: b}
+
+	for i := 0; i < 4; i++ {
+		err := client.List(context.Background(), &corev1.ConfigMapList{})
+		if i >= 2 && !errors.Is(err, ErrBackendUnavailable) {
+			t.Errorf("call %d: List() = %v, want ErrBackendUnavailable", i, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("backend called %d times, want 2 before the breaker opened", calls)
+	}
+}
+
+func TestBreakerClientTracesCalls(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	b, _ := testBreaker(5)
+	client := &breakerClient{Client: slacktest.NewKube().CrClient, breaker: b}
+	ctx, parent := tracing.Start(context.Background(), "command status")
+	err := client.Get(ctx, crclient.ObjectKey{Namespace: "default", Name: "spoticus-k8s-abcde"}, &corev1.ConfigMap{})
+	parent.End()
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("Get() = %v, want not found", err)
+	}
+
+	var span sdktrace.ReadOnlySpan
+	for _, ended := range recorder.Ended() {
+		if ended.Name() == "kubernetes get" {
+			span = ended
+		}
+	}
+	if span == nil {
+		t.Fatal("no kubernetes get span")
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("kubernetes span is not a child of the command span")
+	}
+	got := map[attribute.Key]string{}
+	for _, attr := range span.Attributes() {
+		got[attr.Key] = attr.Value.AsString()
+	}
+	want := map[attribute.Key]string{
+		tracing.AttrOperation: "get",
+		tracing.AttrKind:      "ConfigMap",
+		tracing.AttrObject:    "spoticus-k8s-abcde",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("span attribute %s = %q, want %q", key, got[key], value)
+		}
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("span status = %v, want the error recorded", span.Status().Code)
+	}
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"math"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// formatCost renders an amount in the configured currency, e.g. "$1,234.50".
+func formatCost(amount float64) string {
+	return spoticusConfig.Get().Currency.Format(amount)
+}
+
+// formatHourlyCost renders an hourly rate in the configured currency, e.g. "$1.50/h".
+func formatHourlyCost(amount float64) string {
+	return spoticusConfig.Get().Currency.Hourly(amount)
+}
+
+// budgetUsage returns the estimated hourly cost of the clusters in the inventory.
+func budgetUsage(inventory clusterInventory) float64 {
+	total := 0.0
+	for _, cluster := range inventory.Clusters {
+		total += cluster.Metadata().HourlyCost
+	}
+	return total
+}
+
+// accumulatedCost estimates what a cluster has cost since it was launched, by
+// multiplying its recorded hourly cost by its age at now. Clusters without a
+// recorded launch time are aged from their creation. The result is never
+// negative, even if the clocks disagree.
+func accumulatedCost(metadata LaunchMetadata, created, now time.Time) float64 {
+	launched := metadata.LaunchedAt
+	if launched.IsZero() {
+		launched = created
+	}
+	if launched.IsZero() || !now.After(launched) {
+		return 0
+	}
+	return metadata.HourlyCost * now.Sub(launched).Hours()
+}
+
+// AccumulatedCost estimates what the cluster has cost since it was launched.
+func (c ClusterInfo) AccumulatedCost(now time.Time) float64 {
+	return accumulatedCost(c.Metadata(), c.Created, now)
+}
+
+// usageNotice returns the notices appended to the ready message of a launch
+// when it leaves the user with few launches (usage of limit, counting the new
+// cluster) or brings the hourly spend near the cap, as set by the quota and
+// budget notice thresholds. It is empty when neither threshold is crossed.
+func usageNotice(cfg *spoticusConfig.Config, usage, limit int, spend float64) string {
+	var notice string
+	if remaining := max(limit-usage, 0); limit > 0 && cfg.Quota.NoticeRemaining > 0 && remaining <= cfg.Quota.NoticeRemaining {
+		notice += "\n" + messages.Render(cfg.Messages.QuotaNotice, messages.Data{
+			"Remaining": remaining,
+			"Limit":     limit,
+		})
+	}
+	if hourlyCap := cfg.Budget.HourlyCap; hourlyCap > 0 && cfg.Budget.NoticeRatio > 0 && spend >= cfg.Budget.NoticeRatio*hourlyCap {
+		notice += "\n" + messages.Render(cfg.Messages.BudgetNotice, messages.Data{
+			"Percent": int(math.Round(100 * spend / hourlyCap)),
+			"Current": formatHourlyCost(spend),
+			"Cap":     formatHourlyCost(hourlyCap),
+		})
+	}
+	return notice
+}
+
+// exceedsBudget reports whether adding a cluster costing cost per hour to the
+// current spend would go over the hourly cap. A zero cap means no limit.
+func exceedsBudget(current, cost, hourlyCap float64) bool {
+	return hourlyCap > 0 && current+cost > hourlyCap
+}
@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestAccumulatedCost(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		metadata LaunchMetadata
+		created  time.Time
+		want     float64
+	}{
+		{name: "hours since launch", metadata: LaunchMetadata{HourlyCost: 0.15, LaunchedAt: now.Add(-4 * time.Hour)}, want: 0.60},
+		{name: "partial hour", metadata: LaunchMetadata{HourlyCost: 0.60, LaunchedAt: now.Add(-90 * time.Minute)}, want: 0.90},
+		{name: "days at a high rate", metadata: LaunchMetadata{HourlyCost: 2.50, LaunchedAt: now.Add(-48 * time.Hour)}, want: 120},
+		{name: "launch time preferred", metadata: LaunchMetadata{HourlyCost: 1, LaunchedAt: now.Add(-time.Hour)}, created: now.Add(-10 * time.Hour), want: 1},
+		{name: "aged from creation", metadata: LaunchMetadata{HourlyCost: 0.30}, created: now.Add(-2 * time.Hour), want: 0.60},
+		{name: "no rate", metadata: LaunchMetadata{LaunchedAt: now.Add(-time.Hour)}},
+		{name: "no launch time", metadata: LaunchMetadata{HourlyCost: 1}},
+		{name: "launched in the future", metadata: LaunchMetadata{HourlyCost: 1, LaunchedAt: now.Add(time.Minute)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accumulatedCost(tt.metadata, tt.created, now); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("accumulatedCost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatClusterStatusShowsCostSoFar(t *testing.T) {
+	useConfig(t, testConfig())
+	cluster := existingCluster("spoticus-k8s-costly", "U1", 0.50)
+	SetLaunchMetadata(cluster, LaunchMetadata{Owner: "U1", HourlyCost: 0.50, LaunchedAt: time.Now().Add(-3 * time.Hour)})
+	info := ClusterInfo{Name: cluster.GetName(), Type: "k8s", Created: time.Now(), Annotations: cluster.GetAnnotations()}
+
+	text := formatClusterStatus(testConfig(), info)
+	if !strings.Contains(text, "Cost so far: $1.50 at $0.50/h") {
+		t.Errorf("status %q, want the cost accumulated over 3 hours", text)
+	}
+
+	info.Annotations = nil
+	if text := formatClusterStatus(testConfig(), info); strings.Contains(text, "Cost so far") {
+		t.Errorf("status %q shows a cost for a cluster without a rate", text)
+	}
+}
+
+func TestExceedsBudget(t *testing.T) {
+	tests := []struct {
+		name                  string
+		current, cost, capped float64
+		want                  bool
+	}{
+		{name: "no cap", current: 100, cost: 1},
+		{name: "under the cap", current: 0.15, cost: 0.15, capped: 1},
+		{name: "reaching the cap", current: 0.50, cost: 0.50, capped: 1},
+		{name: "over the cap", current: 0.90, cost: 0.15, capped: 1, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exceedsBudget(tt.current, tt.cost, tt.capped); got != tt.want {
+				t.Errorf("exceedsBudget(%v, %v, %v) = %v, want %v", tt.current, tt.cost, tt.capped, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLaunchUnderBudgetCap(t *testing.T) {
+	cfg := testConfig()
+	cfg.Budget.HourlyCap = 1
+	cfg.Budget.NoticeRatio = 0
+	useConfig(t, cfg)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady), existingCluster("spoticus-k8s-cheap", "U2", 0.15))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U10", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 2 {
+		t.Errorf("got %d clusters, want the new one launched", len(kinds))
+	}
+}
+
+func TestLaunchOverBudgetCapReportsTotals(t *testing.T) {
+	cfg := testConfig()
+	cfg.Budget.HourlyCap = 0.20
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-costly", "U2", 0.15))
+	useKube(t, kube)
+	api, _ := newAPI(t)
+
+	err := HandleLaunch(context.Background(), api, message("U10", "C1", "launch k8s medium"), []string{"k8s", "medium"})
+	var commandErr *CommandError
+	if !errors.As(err, &commandErr) {
+		t.Fatalf("HandleLaunch() = %v, want the budget exceeded error", err)
+	}
+	for _, want := range []string{"$0.15/h", "$0.30/h"} {
+		if !strings.Contains(commandErr.Message, want) {
+			t.Errorf("error %q does not give the total %s", commandErr.Message, want)
+		}
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want the launch rejected", len(kinds))
+	}
+}
+
+func TestLaunchBudgetOverride(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       string
+		wantLaunch bool
+	}{
+		{name: "admin", user: "UADMIN", wantLaunch: true},
+		{name: "not an admin", user: "U10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Admins = []string{"UADMIN"}
+			cfg.Budget.HourlyCap = 0.20
+			useConfig(t, cfg)
+			kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady), existingCluster("spoticus-k8s-costly", "U2", 0.15))
+			useKube(t, kube)
+			api, server := newAPI(t)
+
+			args := []string{"k8s", "medium", "--override-budget"}
+			err := HandleLaunch(context.Background(), api, message(tt.user, "C1", "launch k8s medium --override-budget"), args)
+			if !tt.wantLaunch {
+				if CategoryOf(err) != CategoryAuth {
+					t.Errorf("HandleLaunch() = %v, want the override denied", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandleLaunch: %v", err)
+			}
+			server.WaitForMessage("Budget cap overridden", 5*time.Second)
+			server.WaitForMessage("is ready", 5*time.Second)
+			if kinds := launchedKinds(t, kube); len(kinds) != 2 {
+				t.Errorf("got %d clusters, want the override launched", len(kinds))
+			}
+		})
+	}
+}
+
+func TestUsageNotice(t *testing.T) {
+	cfg := testConfig()
+	cfg.Quota.NoticeRemaining = 1
+	cfg.Budget.HourlyCap = 1
+	cfg.Budget.NoticeRatio = 0.8
+	useConfig(t, cfg)
+
+	if notice := usageNotice(cfg, 1, 5, 0.30); notice != "" {
+		t.Errorf("usageNotice() = %q far from both limits, want none", notice)
+	}
+	notice := usageNotice(cfg, 4, 5, 0.90)
+	if !strings.Contains(notice, "90%") {
+		t.Errorf("usageNotice() = %q, want the budget at 90%%", notice)
+	}
+	if !strings.Contains(notice, "1 of 5 launches remaining") {
+		t.Errorf("usageNotice() = %q, want the remaining quota", notice)
+	}
+}
+
+func TestUsageNoticeThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		usage   int
+		limit   int
+		spend   float64
+		want    []string
+		notWant []string
+	}{
+		{name: "below both thresholds", usage: 1, limit: 3, spend: 0.50, notWant: []string{"remaining", "budget"}},
+		{name: "last launch left", usage: 2, limit: 3, spend: 0.50, want: []string{"You have 1 of 3 launches remaining."}, notWant: []string{"budget"}},
+		{name: "quota used up", usage: 3, limit: 3, want: []string{"You have 0 of 3 launches remaining."}},
+		{name: "single launch quota", usage: 1, limit: 1, want: []string{"0 of 1 launch remaining."}},
+		{name: "no quota", usage: 10, notWant: []string{"remaining"}},
+		{name: "budget at the ratio", usage: 1, limit: 3, spend: 0.80, want: []string{"The team budget is 80% used: clusters cost $0.80/h of the $1.00/h cap."}, notWant: []string{"remaining"}},
+		{name: "both", usage: 2, limit: 3, spend: 0.95, want: []string{"1 of 3 launches remaining.", "95% used"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Budget.HourlyCap = 1
+			useConfig(t, cfg)
+			got := usageNotice(cfg, tt.usage, tt.limit, tt.spend)
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("usageNotice() = %q, want no notice", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("usageNotice() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("usageNotice() = %q, want it not to contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestUsageNoticeDisabled(t *testing.T) {
+	cfg := testConfig()
+	cfg.Budget.HourlyCap = 1
+	cfg.Quota.NoticeRemaining = 0
+	cfg.Budget.NoticeRatio = 0
+	useConfig(t, cfg)
+	if got := usageNotice(cfg, 3, 3, 1); got != "" {
+		t.Errorf("usageNotice() with the notices disabled = %q, want none", got)
+	}
+
+	cfg.Budget.HourlyCap = 0
+	cfg.Budget.NoticeRatio = 0.8
+	if got := usageNotice(cfg, 0, 0, 100); got != "" {
+		t.Errorf("usageNotice() without a budget cap = %q, want none", got)
+	}
+}
+
+func TestLaunchReadyMentionsNearlyUsedLimits(t *testing.T) {
+	cfg := testConfig()
+	cfg.Budget.HourlyCap = 1
+	useConfig(t, cfg)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady), existingCluster("spoticus-k8s-spender", "U26", 0.70))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U26", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	ready := server.WaitForMessage("is ready", 5*time.Second).Text()
+	for _, want := range []string{"You have 1 of 3 launches remaining.", "The team budget is 85% used"} {
+		if !strings.Contains(ready, want) {
+			t.Errorf("ready message %q does not contain %q", ready, want)
+		}
+	}
+}
+
+func TestLaunchReadyWithoutNotice(t *testing.T) {
+	cfg := testConfig()
+	cfg.Budget.HourlyCap = 1
+	useConfig(t, cfg)
+	useKube(t, slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady)))
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U27", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	ready := server.WaitForMessage("is ready", 5*time.Second).Text()
+	if strings.Contains(ready, "remaining") || strings.Contains(ready, "budget") {
+		t.Errorf("ready message %q has a notice with neither threshold crossed", ready)
+	}
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// launchCancelled is the cause a launch watcher is stopped with by `cancel`.
+type launchCancelled struct {
+	User string
+}
+
+func (c launchCancelled) Error() string {
+	return fmt.Sprintf("launch cancelled by %s", c.User)
+}
+
+var (
+	launchWatchersMu sync.Mutex
+	// launchWatchers maps the names of the clusters being provisioned to
+	// the function stopping their launch watcher.
+	launchWatchers = map[string]context.CancelCauseFunc{}
+)
+
+// watchLaunch registers the watcher of the cluster's launch, returning a
+// context that ends when the launch is cancelled and the function to call
+// once the watch is over.
+func watchLaunch(ctx context.Context, name string) (context.Context, func()) {
+	ctx, stop := context.WithCancelCause(ctx)
+	launchWatchersMu.Lock()
+	launchWatchers[name] = stop
+	launchWatchersMu.Unlock()
+	return ctx, func() {
+		launchWatchersMu.Lock()
+		delete(launchWatchers, name)
+		launchWatchersMu.Unlock()
+		stop(nil)
+	}
+}
+
+// stopLaunchWatcher stops the launch watcher of the cluster on behalf of the
+// user, reporting whether there was one.
+func stopLaunchWatcher(name, user string) bool {
+	launchWatchersMu.Lock()
+	stop, ok := launchWatchers[name]
+	delete(launchWatchers, name)
+	launchWatchersMu.Unlock()
+	if ok {
+		stop(launchCancelled{User: user})
+	}
+	return ok
+}
+
+// HandleCancel is the entry point for the "cancel" Slack command.
+//
+// `cancel <cluster_name>` aborts a launch that is still provisioning: it
+// stops the launch watcher and deletes the partially created MAPT resource,
+// then reports what was cleaned up. Clusters that are already ready or have
+// failed are left to `done`. Like `done`, it is limited to the cluster's
+// team and admins.
+func HandleCancel(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) != 1 {
+		return invalid(cfg.Messages.CancelUsage)
+	}
+	name := args[0]
+	tracing.SetCluster(ctx, name)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+	cluster, err := findCluster(ctx, client, name)
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+	if !canOperate(cfg, event.User, cluster) {
+		return clusterAccessDenied(event.User, "cancel", cluster)
+	}
+
+	switch {
+	case cluster.Deleting:
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.DoneAlreadyDeleting, messages.Data{"Name": name}))
+		return nil
+	case cluster.Phase == phaseReady || cluster.Phase == phaseFailed:
+		return invalid(messages.Render(cfg.Messages.CancelNotInFlight, messages.Data{"Name": name, "Phase": cluster.Phase}))
+	}
+
+	disarmDeletion(cluster)
+	var cleaned []string
+	err = deleteCluster(ctx, client, cluster)
+	switch {
+	case apierrors.IsNotFound(err):
+		log.Printf("Cluster %s/%s was already deleted when %s cancelled its launch", cluster.Namespace, name, event.User)
+	case err != nil:
+		return failure(err, cfg.Messages.CancelFailed, "deleting MAPT cluster %s/%s to cancel its launch", cluster.Namespace, name)
+	default:
+		cleaned = append(cleaned, messages.Render(cfg.Messages.CancelResourceDeleted, messages.Data{
+			"Kind":      clusterKinds[cluster.Type],
+			"Namespace": cluster.Namespace,
+		}))
+	}
+	// The watcher is only stopped once the resource is gone, so that it
+	// keeps reporting on a launch that could not be cancelled.
+	watched := stopLaunchWatcher(name, event.User)
+	if watched {
+		cleaned = append([]string{cfg.Messages.CancelWatcherStopped}, cleaned...)
+	}
+
+	log.Printf("Launch of cluster %s/%s cancelled by %s (watcher stopped: %t)", cluster.Namespace, name, event.User, watched)
+	rememberDeletion(name, time.Now())
+	notify(api, clusterNotification(EventDeleted, cluster, event.User))
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.CancelConfirm, messages.Data{
+		"Name":    name,
+		"Cleaned": strings.Join(cleaned, "\n"),
+	}))
+	return nil
+}
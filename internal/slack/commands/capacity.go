@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+const (
+	// lowCapacityScore is the capacity score below which a region is
+	// unlikely to fulfill a spot request.
+	lowCapacityScore = 0.3
+
+	// capacityCheckTimeout bounds how long a launch waits on the capacity
+	// checker before going ahead without it.
+	capacityCheckTimeout = 10 * time.Second
+
+	// maxCapacityAlternatives is how many alternate regions a capacity
+	// warning suggests.
+	maxCapacityAlternatives = 3
+)
+
+// CapacityRequest describes the spot instances a launch asks for.
+type CapacityRequest struct {
+	Provider string
+
+	// Region is the region the launch was pinned to with `--set region=...`,
+	// or empty when MAPT picks it.
+	Region string
+
+	// InstanceType is set when a specific instance type was requested;
+	// otherwise CPUs and MemoryGB describe the size.
+	InstanceType string
+	CPUs         int
+	MemoryGB     int
+}
+
+// RegionCapacity is the estimated spot capacity of a region.
+type RegionCapacity struct {
+	Region string
+
+	// Score is how likely a spot request is to be fulfilled in the region,
+	// from 0 (unlikely) to 1 (very likely).
+	Score float64
+}
+
+// CapacityChecker estimates the spot capacity of a provider's regions, e.g.
+// from AWS spot placement scores, so that launches unlikely to be fulfilled
+// are warned about before the MAPT resource is created.
+type CapacityChecker interface {
+	// SpotCapacity returns the capacity of the regions able to run the
+	// requested instances. Regions it knows nothing about are left out.
+	SpotCapacity(ctx context.Context, request CapacityRequest) ([]RegionCapacity, error)
+}
+
+// noCapacityCheck is the default CapacityChecker. It knows no region, so
+// launches are never warned about.
+type noCapacityCheck struct{}
+
+// SpotCapacity returns no region.
+func (noCapacityCheck) SpotCapacity(context.Context, CapacityRequest) ([]RegionCapacity, error) {
+	return nil, nil
+}
+
+var (
+	capacityCheckerMu sync.RWMutex
+	capacityChecker   CapacityChecker = noCapacityCheck{}
+)
+
+// SetCapacityChecker replaces the capacity checker consulted by launches.
+// Nil restores the default, which checks nothing.
+func SetCapacityChecker(checker CapacityChecker) {
+	if checker == nil {
+		checker = noCapacityCheck{}
+	}
+	capacityCheckerMu.Lock()
+	defer capacityCheckerMu.Unlock()
+	capacityChecker = checker
+}
+
+// currentCapacityChecker returns the capacity checker consulted by launches.
+func currentCapacityChecker() CapacityChecker {
+	capacityCheckerMu.RLock()
+	defer capacityCheckerMu.RUnlock()
+	return capacityChecker
+}
+
+// Region returns the region the launch was pinned to with `--set region=...`,
+// or empty.
+func (r *LaunchRequest) Region() string {
+	for _, override := range r.Overrides {
+		if len(override.Path) == 1 && override.Path[0] == "region" {
+			if region, ok := override.Value.(string); ok {
+				return region
+			}
+		}
+	}
+	return ""
+}
+
+// capacityRequest describes the spot instances of the launch.
+func capacityRequest(req *LaunchRequest) CapacityRequest {
+	resources := req.Resources()
+	return CapacityRequest{
+		Provider:     req.Provider,
+		Region:       req.Region(),
+		InstanceType: req.InstanceType,
+		CPUs:         resources.CPUs,
+		MemoryGB:     resources.MemoryGB,
+	}
+}
+
+// checkCapacity asks the capacity checker whether the launch is likely to be
+// fulfilled, returning a warning for the user if it is not, or empty. A
+// failing checker is logged and ignored: it must never block a launch.
+func checkCapacity(ctx context.Context, checker CapacityChecker, req *LaunchRequest) string {
+	ctx, cancel := context.WithTimeout(ctx, capacityCheckTimeout)
+	defer cancel()
+
+	request := capacityRequest(req)
+	regions, err := checker.SpotCapacity(ctx, request)
+	if err != nil {
+		log.Printf("Error checking spot capacity for a %s launch: %v", request.Provider, err)
+		return ""
+	}
+	// Never suggest, or judge the launch by, a region it may not use.
+	cfg := spoticusConfig.Get()
+	regions = slices.DeleteFunc(slices.Clone(regions), func(r RegionCapacity) bool {
+		return !cfg.AllowsRegion(request.Provider, r.Region)
+	})
+	size := req.Size
+	if req.InstanceType != "" {
+		size = req.InstanceType
+	}
+	return capacityWarning(request, size, regions)
+}
+
+// capacityWarning returns the warning for a launch whose region has a low
+// capacity, suggesting the regions with the best capacity instead. Without a
+// pinned region, the launch is only warned about when every region is low.
+func capacityWarning(request CapacityRequest, size string, regions []RegionCapacity) string {
+	if len(regions) == 0 {
+		return ""
+	}
+	regions = slices.Clone(regions)
+	slices.SortStableFunc(regions, func(a, b RegionCapacity) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return strings.Compare(a.Region, b.Region)
+	})
+
+	// Without a pinned region MAPT picks one, so the launch is only in
+	// trouble if the best region is.
+	region, score := request.Region, regions[0].Score
+	if region != "" {
+		i := slices.IndexFunc(regions, func(r RegionCapacity) bool { return r.Region == region })
+		if i < 0 {
+			return ""
+		}
+		score = regions[i].Score
+	}
+	if score >= lowCapacityScore {
+		return ""
+	}
+
+	var alternatives []string
+	for _, r := range regions {
+		if r.Region != region && r.Score >= lowCapacityScore && len(alternatives) < maxCapacityAlternatives {
+			alternatives = append(alternatives, fmt.Sprintf("%s (%.0f%%)", r.Region, 100*r.Score))
+		}
+	}
+	return messages.Render(msgs().LaunchLowCapacity, messages.Data{
+		"Size":         size,
+		"Provider":     request.Provider,
+		"Region":       region,
+		"Score":        fmt.Sprintf("%.0f%%", 100*score),
+		"Alternatives": strings.Join(alternatives, ", "),
+	})
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// fixedCapacity is a CapacityChecker reporting the same regions to every
+// request.
+type fixedCapacity []RegionCapacity
+
+func (c fixedCapacity) SpotCapacity(context.Context, CapacityRequest) ([]RegionCapacity, error) {
+	return c, nil
+}
+
+// failingCapacity is a CapacityChecker that cannot reach its provider.
+type failingCapacity struct{}
+
+func (failingCapacity) SpotCapacity(context.Context, CapacityRequest) ([]RegionCapacity, error) {
+	return nil, errors.New("spot placement scores unavailable")
+}
+
+// useCapacityChecker makes launches consult checker, and restores the
+// default when the test ends.
+func useCapacityChecker(t *testing.T, checker CapacityChecker) {
+	t.Helper()
+	previous := currentCapacityChecker()
+	SetCapacityChecker(checker)
+	t.Cleanup(func() { SetCapacityChecker(previous) })
+}
+
+func TestCapacityWarning(t *testing.T) {
+	useConfig(t, testConfig())
+	regions := []RegionCapacity{
+		{Region: "us-east-1", Score: 0.1},
+		{Region: "eu-west-1", Score: 0.6},
+		{Region: "us-west-2", Score: 0.9},
+		{Region: "ap-south-1", Score: 0.5},
+		{Region: "eu-central-1", Score: 0.4},
+	}
+	low := []RegionCapacity{{Region: "us-east-1", Score: 0.1}, {Region: "us-west-2", Score: 0.2}}
+	tests := []struct {
+		name    string
+		region  string
+		regions []RegionCapacity
+		want    []string
+		notWant []string
+	}{
+		{name: "nothing known", region: "us-east-1"},
+		{name: "pinned region with capacity", region: "eu-west-1", regions: regions},
+		{name: "pinned region not reported", region: "sa-east-1", regions: regions},
+		{
+			name:    "pinned region low",
+			region:  "us-east-1",
+			regions: regions,
+			want:    []string{"is low in us-east-1 (10%)", "Regions with more capacity: us-west-2 (90%), eu-west-1 (60%), ap-south-1 (50%)."},
+			notWant: []string{"eu-central-1"},
+		},
+		{name: "unpinned with a region with capacity", regions: regions},
+		{
+			name:    "unpinned with every region low",
+			regions: low,
+			want:    []string{"is low in every aws region (best 20%)", "may not be fulfilled."},
+			notWant: []string{"Regions with more capacity"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := CapacityRequest{Provider: "aws", Region: tt.region, CPUs: 8, MemoryGB: 32}
+			got := capacityWarning(request, "medium", tt.regions)
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("capacityWarning() = %q, want no warning", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("capacityWarning() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("capacityWarning() = %q, want it not to contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckCapacityIgnoresFailingChecker(t *testing.T) {
+	useConfig(t, testConfig())
+	req := &LaunchRequest{Type: "k8s", Provider: defaultProvider, Size: "medium", Spec: testConfig().Sizes["medium"]}
+
+	if warning := checkCapacity(context.Background(), failingCapacity{}, req); warning != "" {
+		t.Errorf("checkCapacity() with a failing checker = %q, want no warning", warning)
+	}
+	if warning := checkCapacity(context.Background(), noCapacityCheck{}, req); warning != "" {
+		t.Errorf("checkCapacity() with the default checker = %q, want no warning", warning)
+	}
+}
+
+func TestLaunchWarnsAboutLowCapacity(t *testing.T) {
+	useConfig(t, testConfig())
+	useCapacityChecker(t, fixedCapacity{{Region: "us-east-1", Score: 0.05}, {Region: "eu-west-1", Score: 0.8}})
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	args := []string{"k8s", "medium", "--set", "region=us-east-1"}
+	if err := HandleLaunch(context.Background(), api, message("U24", "C1", "launch "+strings.Join(args, " ")), args); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+
+	warning := server.WaitForMessage("Spot capacity for *medium* is low in us-east-1 (5%)", time.Second)
+	if !strings.Contains(warning.Text(), "eu-west-1 (80%)") {
+		t.Errorf("warning %q does not suggest eu-west-1", warning.Text())
+	}
+	if warning.Values.Get("thread_ts") == "" {
+		t.Error("warning not posted in the launch thread")
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want the launch to go ahead despite the warning", len(kinds))
+	}
+}
+
+func TestSetCapacityCheckerNilRestoresDefault(t *testing.T) {
+	useCapacityChecker(t, failingCapacity{})
+	SetCapacityChecker(nil)
+	if _, ok := currentCapacityChecker().(noCapacityCheck); !ok {
+		t.Errorf("checker %T after SetCapacityChecker(nil), want the default", currentCapacityChecker())
+	}
+}
+
+func TestCheckCapacitySkipsDisallowedRegions(t *testing.T) {
+	cfg := spoticusConfig.Default()
+	cfg.Regions = map[string]spoticusConfig.RegionPolicy{"aws": {Deny: []string{"us-west-2"}}}
+	useConfig(t, cfg)
+	checker := fixedCapacity{
+		{Region: "us-east-1", Score: 0.1},
+		{Region: "us-west-2", Score: 0.9},
+		{Region: "eu-west-1", Score: 0.6},
+	}
+	overrides, err := parseSpecOverrides([]string{"region=us-east-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &LaunchRequest{Type: "k8s", Provider: defaultProvider, Size: "medium", Spec: cfg.Sizes["medium"], Overrides: overrides}
+
+	warning := checkCapacity(context.Background(), checker, req)
+	if !strings.Contains(warning, "eu-west-1") {
+		t.Errorf("warning %q does not suggest the allowed eu-west-1", warning)
+	}
+	if strings.Contains(warning, "us-west-2") {
+		t.Errorf("warning %q suggests the denied us-west-2", warning)
+	}
+}
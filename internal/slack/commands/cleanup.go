@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// cleanupConfirmWindow is how long a `cleanup` selection stays valid for `cleanup confirm`.
+const cleanupConfirmWindow = 5 * time.Minute
+
+// pendingCleanup is a cleanup selection waiting for confirmation.
+type pendingCleanup struct {
+	clusters []ClusterInfo
+	expires  time.Time
+
+	// phrase is what the user must type after `cleanup confirm`.
+	phrase string
+}
+
+var (
+	pendingCleanupsMu sync.Mutex
+	// pendingCleanups holds the latest unconfirmed selection per requesting user.
+	pendingCleanups = map[string]pendingCleanup{}
+)
+
+// HandleCleanup is the entry point for the admin "cleanup" Slack command.
+//
+// `cleanup` lists the clusters matching the configured cleanup criteria and
+// remembers that selection; nothing is deleted until the same user replies
+// `cleanup confirm <phrase>` within the confirmation window, the phrase being
+// a challenge made of the number of clusters and a random token, so that a
+// mass deletion cannot be confirmed by mistake. `cleanup preview` lists the
+// same selection as a dry run, without remembering it.
+func HandleCleanup(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	preview := false
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "confirm":
+			return confirmCleanup(ctx, api, event, strings.Join(args[1:], " "))
+		case "preview":
+			preview = true
+		}
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	inventory, err := listClusters(ctx, client)
+	if err != nil {
+		return failure(err, msgs().ListFailed, "listing MAPT clusters for cleanup")
+	}
+
+	cfg := spoticusConfig.Get()
+	now := time.Now()
+	candidates, reasons := selectCleanup(inventory.Clusters, cfg.Cleanup, now)
+	var entries strings.Builder
+	for i, cluster := range candidates {
+		entries.WriteString(messages.Render(cfg.Messages.CleanupEntry, messages.Data{
+			"Name":   cluster.Name,
+			"Type":   clusterTypeNames[cluster.Type],
+			"Reason": reasons[i],
+		}))
+	}
+
+	if len(candidates) == 0 {
+		respond.Text(api, event.Channel, cfg.Messages.CleanupNone)
+		return nil
+	}
+
+	if preview {
+		log.Printf("Cleanup preview for %s: %d clusters would be deleted", event.User, len(candidates))
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.CleanupPreview, messages.Data{
+			"Count":   len(candidates),
+			"Entries": entries.String(),
+		}))
+		return nil
+	}
+
+	phrase := newConfirmPhrase(len(candidates))
+	pendingCleanupsMu.Lock()
+	pendingCleanups[event.User] = pendingCleanup{clusters: candidates, expires: now.Add(cleanupConfirmWindow), phrase: phrase}
+	pendingCleanupsMu.Unlock()
+
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.CleanupSelection, messages.Data{
+		"Count":   len(candidates),
+		"Entries": entries.String(),
+		"Window":  cleanupConfirmWindow,
+		"Phrase":  phrase,
+	}))
+	return nil
+}
+
+// selectCleanup returns the clusters matching the cleanup criteria, in order,
+// with the reason each was selected.
+func selectCleanup(clusters []ClusterInfo, criteria spoticusConfig.CleanupCriteria, now time.Time) ([]ClusterInfo, []string) {
+	var selected []ClusterInfo
+	var reasons []string
+	for _, cluster := range clusters {
+		if reason, ok := cleanupReason(cluster, criteria, now); ok {
+			selected = append(selected, cluster)
+			reasons = append(reasons, reason)
+		}
+	}
+	return selected, reasons
+}
+
+// cleanupReason reports whether the cluster matches the cleanup criteria, and
+// why. Pinned clusters never match.
+func cleanupReason(cluster ClusterInfo, criteria spoticusConfig.CleanupCriteria, now time.Time) (string, bool) {
+	switch {
+	case cluster.Pinned():
+		return "", false
+	case criteria.Failed && cluster.Phase == phaseFailed:
+		return "status is Failed", true
+	case criteria.Orphaned && cluster.Metadata().Owner == "":
+		return "no owner recorded", true
+	case criteria.MaxAge > 0 && now.Sub(cluster.Created) > time.Duration(criteria.MaxAge):
+		return fmt.Sprintf("older than %s", time.Duration(criteria.MaxAge)), true
+	}
+	return "", false
+}
+
+// confirmCleanup deletes the clusters selected by the user's last `cleanup`,
+// if it has not expired and the phrase matches its challenge. A wrong phrase
+// discards the selection: the user has to run `cleanup` again.
+func confirmCleanup(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, phrase string) error {
+	pendingCleanupsMu.Lock()
+	pending, ok := pendingCleanups[event.User]
+	delete(pendingCleanups, event.User)
+	pendingCleanupsMu.Unlock()
+
+	if !ok || time.Now().After(pending.expires) {
+		return invalid(msgs().CleanupNoPending)
+	}
+	if !confirmPhraseMatches(pending.phrase, phrase) {
+		log.Printf("Cleanup of %d clusters aborted: %s gave a wrong confirmation phrase", len(pending.clusters), event.User)
+		return invalid(messages.Render(msgs().CleanupWrongPhrase, messages.Data{"Count": len(pending.clusters)}))
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	deleted, failed := 0, 0
+	for _, cluster := range pending.clusters {
+		if err := deleteCluster(ctx, client, cluster); err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("Error deleting MAPT cluster %s/%s during cleanup: %v", cluster.Namespace, cluster.Name, err)
+			failed++
+			continue
+		}
+		deleted++
+		notify(api, clusterNotification(EventDeleted, cluster, event.User))
+	}
+
+	log.Printf("Cleanup confirmed by %s: %d deleted, %d failed", event.User, deleted, failed)
+
+	respond.Text(api, event.Channel, messages.Render(msgs().CleanupComplete, messages.Data{
+		"Deleted": deleted,
+		"Failed":  failed,
+	}))
+	return nil
+}
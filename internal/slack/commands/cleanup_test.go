@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// usePendingCleanup makes the clusters the user's pending cleanup selection,
+// to be confirmed with phrase, until the test ends.
+func usePendingCleanup(t *testing.T, user, phrase string, expires time.Time, clusters ...ClusterInfo) {
+	t.Helper()
+	pendingCleanupsMu.Lock()
+	pendingCleanups[user] = pendingCleanup{clusters: clusters, expires: expires, phrase: phrase}
+	pendingCleanupsMu.Unlock()
+	t.Cleanup(func() {
+		pendingCleanupsMu.Lock()
+		delete(pendingCleanups, user)
+		pendingCleanupsMu.Unlock()
+	})
+}
+
+func TestConfirmCleanup(t *testing.T) {
+	const phrase = "delete-1-k3x9"
+	tests := []struct {
+		name        string
+		typed       string
+		expires     time.Duration
+		wantDeleted bool
+		wantErr     string
+	}{
+		{name: "correct phrase proceeds", typed: phrase, expires: time.Minute, wantDeleted: true},
+		{name: "phrase copied as code proceeds", typed: "`" + phrase + "`", expires: time.Minute, wantDeleted: true},
+		{name: "wrong phrase aborts", typed: "delete-1-aaaa", expires: time.Minute, wantErr: "not the confirmation phrase"},
+		{name: "no phrase aborts", expires: time.Minute, wantErr: "not the confirmation phrase"},
+		{name: "expired selection", typed: phrase, expires: -time.Second, wantErr: "Nothing to confirm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, testConfig())
+			kube := slacktest.NewKube(existingCluster("spoticus-k8s-failed", "U9", 0))
+			useKube(t, kube)
+			api, server := newAPI(t)
+			usePendingCleanup(t, "UADMIN", phrase, time.Now().Add(tt.expires),
+				ClusterInfo{Name: "spoticus-k8s-failed", Namespace: "default", Type: "k8s"})
+
+			err := confirmCleanup(context.Background(), api, message("UADMIN", "C1", "cleanup confirm "+tt.typed), tt.typed)
+			if tt.wantErr != "" {
+				var cmdErr *CommandError
+				if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, tt.wantErr) {
+					t.Errorf("confirmCleanup() = %v, want an error containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("confirmCleanup: %v", err)
+			} else {
+				server.WaitForMessage("Cleanup complete: 1 cluster deleted", time.Second)
+			}
+
+			if got := len(launchedKinds(t, kube)) == 0; got != tt.wantDeleted {
+				t.Errorf("cluster deleted = %v, want %v", got, tt.wantDeleted)
+			}
+			pendingCleanupsMu.Lock()
+			_, pending := pendingCleanups["UADMIN"]
+			pendingCleanupsMu.Unlock()
+			if pending {
+				t.Errorf("selection still pending, want it discarded by the confirmation")
+			}
+		})
+	}
+}
+
+func TestConfirmCleanupWithoutSelection(t *testing.T) {
+	useConfig(t, testConfig())
+	api, _ := newAPI(t)
+
+	err := confirmCleanup(context.Background(), api, message("UNONE", "C1", "cleanup confirm delete-1-k3x9"), "delete-1-k3x9")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Nothing to confirm") {
+		t.Errorf("confirmCleanup() = %v, want nothing to confirm", err)
+	}
+}
+
+func TestCleanupReason(t *testing.T) {
+	now := time.Now()
+	owned := existingCluster("owned", "U1", 0).GetAnnotations()
+	failedOnly := spoticusConfig.CleanupCriteria{Failed: true}
+	tests := []struct {
+		name     string
+		cluster  ClusterInfo
+		criteria spoticusConfig.CleanupCriteria
+		want     bool
+	}{
+		{name: "failed", cluster: ClusterInfo{Phase: phaseFailed, Annotations: owned}, criteria: failedOnly, want: true},
+		{name: "ready", cluster: ClusterInfo{Phase: phaseReady, Annotations: owned}, criteria: failedOnly},
+		{name: "orphaned", cluster: ClusterInfo{Phase: phaseReady}, criteria: spoticusConfig.CleanupCriteria{Orphaned: true}, want: true},
+		{name: "pinned", cluster: ClusterInfo{Phase: phaseFailed, Annotations: map[string]string{annotationNoReap: "true"}}, criteria: failedOnly},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := cleanupReason(tt.cluster, tt.criteria, now); got != tt.want {
+				t.Errorf("cleanupReason() selected = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanupReasonMaxAge(t *testing.T) {
+	now := time.Now()
+	criteria := spoticusConfig.CleanupCriteria{MaxAge: spoticusConfig.Duration(24 * time.Hour)}
+	if _, ok := cleanupReason(ClusterInfo{Created: now.Add(-25 * time.Hour)}, criteria, now); !ok {
+		t.Errorf("a cluster older than the maximum age was not selected")
+	}
+	if _, ok := cleanupReason(ClusterInfo{Created: now.Add(-time.Hour)}, criteria, now); ok {
+		t.Errorf("a recent cluster was selected")
+	}
+}
+
+// cleanupPhraseIn returns the confirmation phrase a `cleanup` reply asks for.
+func cleanupPhraseIn(t *testing.T, text string) string {
+	t.Helper()
+	match := regexp.MustCompile("cleanup confirm (delete-[0-9]+-[a-z0-9]+)").FindStringSubmatch(text)
+	if match == nil {
+		t.Fatalf("no confirmation phrase in %q", text)
+	}
+	return match[1]
+}
+
+func TestCleanupDeletesSelectionOnceConfirmed(t *testing.T) {
+	cfg := testConfig()
+	cfg.Cleanup = spoticusConfig.CleanupCriteria{Orphaned: true}
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-orphan", "", 0), existingCluster("spoticus-k8s-owned", "U1", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleCleanup(context.Background(), api, message("UCLEAN", "C1", "cleanup"), nil); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	selection := server.WaitForMessage("1 cluster would be deleted", time.Second).Text()
+	if !strings.Contains(selection, "spoticus-k8s-orphan") || strings.Contains(selection, "spoticus-k8s-owned") {
+		t.Errorf("selection %q, want only the orphaned cluster", selection)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 2 {
+		t.Fatalf("got %d clusters before the confirmation, want both kept", len(kinds))
+	}
+
+	phrase := cleanupPhraseIn(t, selection)
+	if err := HandleCleanup(context.Background(), api, message("UCLEAN", "C1", "cleanup confirm "+phrase), []string{"confirm", phrase}); err != nil {
+		t.Fatalf("cleanup confirm: %v", err)
+	}
+	server.WaitForMessage("Cleanup complete: 1 cluster deleted", time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 || kinds[0].Name != "spoticus-k8s-owned" {
+		t.Errorf("clusters left %v, want only the owned one", kinds)
+	}
+}
+
+func TestCleanupPreviewCannotBeConfirmed(t *testing.T) {
+	cfg := testConfig()
+	cfg.Cleanup = spoticusConfig.CleanupCriteria{Orphaned: true}
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-orphan", "", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleCleanup(context.Background(), api, message("UPREVIEW", "C1", "cleanup preview"), []string{"preview"}); err != nil {
+		t.Fatalf("cleanup preview: %v", err)
+	}
+	server.WaitForMessage("Nothing was deleted", time.Second)
+
+	err := HandleCleanup(context.Background(), api, message("UPREVIEW", "C1", "cleanup confirm"), []string{"confirm"})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Nothing to confirm") {
+		t.Errorf("confirming a preview = %v, want nothing to confirm", err)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want the cluster kept", len(kinds))
+	}
+}
+
+func TestCleanupWithNothingSelected(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-owned", "U1", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleCleanup(context.Background(), api, message("UNONE", "C1", "cleanup"), nil); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	server.WaitForMessage("No clusters match the cleanup criteria", time.Second)
+}
+
+func TestSelectCleanupGivesReasons(t *testing.T) {
+	now := time.Now()
+	owned := existingCluster("owned", "U1", 0).GetAnnotations()
+	criteria := spoticusConfig.CleanupCriteria{Failed: true, Orphaned: true, MaxAge: spoticusConfig.Duration(24 * time.Hour)}
+	clusters := []ClusterInfo{
+		{Name: "failed", Phase: phaseFailed, Annotations: owned, Created: now},
+		{Name: "healthy", Phase: phaseReady, Annotations: owned, Created: now},
+		{Name: "orphan", Phase: phaseReady, Created: now},
+		{Name: "old", Phase: phaseReady, Annotations: owned, Created: now.Add(-48 * time.Hour)},
+		{Name: "pinned", Phase: phaseFailed, Annotations: map[string]string{annotationNoReap: "true"}, Created: now},
+	}
+
+	selected, reasons := selectCleanup(clusters, criteria, now)
+	var got []string
+	for i, cluster := range selected {
+		got = append(got, cluster.Name+": "+reasons[i])
+	}
+	want := []string{"failed: status is Failed", "orphan: no owner recorded", "old: older than 24h0m0s"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("selectCleanup() = %q, want %q", got, want)
+	}
+}
+
+func TestCleanupPreviewListsSelectionWithoutDeleting(t *testing.T) {
+	cfg := testConfig()
+	cfg.Cleanup = spoticusConfig.CleanupCriteria{Orphaned: true}
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(
+		existingCluster("spoticus-k8s-preview-orphan", "", 0),
+		existingCluster("spoticus-k8s-preview-owned", "U1", 0),
+	)
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleCleanup(context.Background(), api, message("UPREVIEW2", "C1", "cleanup preview"), []string{"preview"}); err != nil {
+		t.Fatalf("cleanup preview: %v", err)
+	}
+	text := server.WaitForMessage("Cleanup preview", time.Second).Text()
+	if !strings.Contains(text, "1 cluster would be deleted") || !strings.Contains(text, "*spoticus-k8s-preview-orphan* (Kubernetes) — no owner recorded") {
+		t.Errorf("preview %q, want the orphan listed with its reason", text)
+	}
+	if strings.Contains(text, "spoticus-k8s-preview-owned") {
+		t.Errorf("preview %q lists a cluster that does not match the criteria", text)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 2 {
+		t.Errorf("got %d clusters after the preview, want both kept", len(kinds))
+	}
+	if calls := server.Calls("chat.postMessage"); len(calls) != 1 {
+		t.Errorf("posted %d messages, want only the preview", len(calls))
+	}
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"sync"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/kube"
+)
+
+var (
+	scheme = runtime.NewScheme()
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(maptApi.AddToScheme(scheme))
+}
+
+// KubernetesClients bundles the clients used to talk to the cluster MAPT runs in.
+// CrClient is guarded by the backend circuit breaker.
+type KubernetesClients struct {
+	KubeClient    kubernetes.Interface
+	CrClient      crclient.Client
+	DynamicClient dynamic.Interface
+}
+
+var (
+	clientFactoryMu sync.RWMutex
+	// clientFactory builds the clients GetKubernetesClient returns.
+	clientFactory = newKubernetesClients
+)
+
+// SetKubernetesClientFactory replaces how the Kubernetes clients are built,
+// e.g. with fake clients in tests. A nil factory restores the default, which
+// connects with the configured kubeconfig context.
+func SetKubernetesClientFactory(factory func() (*KubernetesClients, error)) {
+	if factory == nil {
+		factory = newKubernetesClients
+	}
+	clientFactoryMu.Lock()
+	clientFactory = factory
+	clientFactoryMu.Unlock()
+}
+
+// GetKubernetesClient builds the clients for the cluster MAPT runs in,
+// honouring the configured kubeconfig context.
+//
+// While the backend circuit breaker is open it fails fast with
+// ErrBackendUnavailable instead of attempting to connect.
+func GetKubernetesClient() (*KubernetesClients, error) {
+	if err := backendBreaker.Check(); err != nil {
+		return nil, err
+	}
+
+	clientFactoryMu.RLock()
+	factory := clientFactory
+	clientFactoryMu.RUnlock()
+	clients, err := factory()
+	if err != nil {
+		return nil, err
+	}
+	return &KubernetesClients{
+		KubeClient:    clients.KubeClient,
+		CrClient:      &breakerClient{Client: clients.CrClient, breaker: backendBreaker},
+		DynamicClient: clients.DynamicClient,
+	}, nil
+}
+
+// newKubernetesClients connects to the cluster of the configured kubeconfig
+// context.
+func newKubernetesClients() (*KubernetesClients, error) {
+	cfg, err := kube.RestConfig(spoticusConfig.Get().KubeContext)
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	crClient, err := crclient.New(cfg, crclient.Options{
+		Scheme: scheme,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &KubernetesClients{
+		KubeClient:    client,
+		CrClient:      crClient,
+		DynamicClient: dynamicClient,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestGetKubernetesClientUsesFactory(t *testing.T) {
+	kube := slacktest.NewKube()
+	useKube(t, kube)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		t.Fatalf("GetKubernetesClient: %v", err)
+	}
+	guarded, ok := client.CrClient.(*breakerClient)
+	if !ok {
+		t.Fatalf("CrClient is a %T, want it guarded by the circuit breaker", client.CrClient)
+	}
+	if guarded.Client != kube.CrClient {
+		t.Errorf("CrClient does not wrap the factory's client")
+	}
+	if client.KubeClient != kube.KubeClient {
+		t.Errorf("KubeClient is not the factory's client")
+	}
+}
+
+func TestGetKubernetesClientFactoryError(t *testing.T) {
+	want := errors.New("no kubeconfig")
+	SetKubernetesClientFactory(func() (*KubernetesClients, error) { return nil, want })
+	t.Cleanup(func() { SetKubernetesClientFactory(nil) })
+
+	if _, err := GetKubernetesClient(); !errors.Is(err, want) {
+		t.Errorf("GetKubernetesClient() error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,319 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"time"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+)
+
+// ClusterInfo is a type-agnostic view of a MAPT cluster resource,
+// used by the commands that read or report on existing clusters.
+type ClusterInfo struct {
+	Name        string
+	Namespace   string
+	Type        string
+	Created     time.Time
+	Labels      map[string]string
+	Annotations map[string]string
+
+	// Phase is the provisioning status reported by MAPT, e.g. "Ready" or "Failed".
+	Phase string
+
+	// Reason explains the phase when MAPT reports why, typically why the
+	// cluster failed.
+	Reason string
+
+	// Deleting is set once the resource has been deleted but is still
+	// being finalized.
+	Deleting bool
+
+	// APIServer and Console are the public endpoint URLs reported by MAPT, or
+	// empty until the cluster publishes them.
+	APIServer string
+	Console   string
+
+	// Region is the cloud region reported by MAPT, or empty until the
+	// cluster publishes it.
+	Region string
+}
+
+// clusterInventory is the result of listing every supported cluster type.
+type clusterInventory struct {
+	Clusters []ClusterInfo
+
+	// Failed holds the display names of the cluster types that could not be listed.
+	Failed []string
+}
+
+// Cluster phases reported in ClusterInfo.Phase.
+const (
+	phaseReady        = "Ready"
+	phaseProvisioning = "Provisioning"
+	phaseFailed       = "Failed"
+	phaseUnknown      = "Unknown"
+
+	// phaseCancelled is only shown by the launch progress of a launch
+	// stopped with `cancel`; MAPT never reports it.
+	phaseCancelled = "Cancelled"
+
+	// phaseDeleted is only shown by the launch progress of a launch whose
+	// resource was deleted before it was ready; MAPT never reports it.
+	phaseDeleted = "Deleted"
+)
+
+// clusterTypeNames maps the cluster type keys to their user-facing display names.
+var clusterTypeNames = map[string]string{
+	"k8s":       "Kubernetes",
+	"openshift": "OpenShift",
+}
+
+// openshiftListGVK identifies the MAPT OpenShift list, which is read unstructured.
+var openshiftListGVK = schema.GroupVersionKind{
+	Group:   "mapt.redhat.com",
+	Version: "v1alpha1",
+	Kind:    "OpenshiftList",
+}
+
+// listClusters fetches the MAPT Kind and OpenShift resources in every
+// namespace clusters are launched in: the configured namespace and those of
+// the channel defaults. When ctx is scoped to a workspace, only the clusters
+// launched from it are returned.
+//
+// A failure listing one type does not prevent the other from being returned:
+// the failed type is recorded in clusterInventory.Failed and its error is
+// joined into the returned error. Callers decide whether a partial inventory
+// is good enough to show.
+func listClusters(ctx context.Context, client *KubernetesClients) (clusterInventory, error) {
+	return listClustersIn(ctx, client, spoticusConfig.Get().ClusterNamespaces())
+}
+
+// listAllClusters is listClusters across every namespace of the cluster,
+// including those no channel launches in, with a single cluster-scoped list
+// call per type. It is meant for admins.
+func listAllClusters(ctx context.Context, client *KubernetesClients) (clusterInventory, error) {
+	return listClustersIn(ctx, client, []string{metav1.NamespaceAll})
+}
+
+// listClustersIn lists the clusters of the namespaces as listClusters does;
+// metav1.NamespaceAll lists those of every namespace.
+func listClustersIn(ctx context.Context, client *KubernetesClients, namespaces []string) (clusterInventory, error) {
+	var inventory clusterInventory
+	var errs []error
+	fail := func(clusterType string, err error) {
+		errs = append(errs, err)
+		if name := clusterTypeNames[clusterType]; !slices.Contains(inventory.Failed, name) {
+			inventory.Failed = append(inventory.Failed, name)
+		}
+	}
+
+	for _, namespace := range namespaces {
+		options := append([]crclient.ListOption{crclient.InNamespace(namespace)}, tenantListOptions(ctx)...)
+		scope := "in " + namespace
+		if namespace == metav1.NamespaceAll {
+			scope = "across all namespaces"
+		}
+
+		var kindsList maptApi.KindList
+		if err := client.CrClient.List(ctx, &kindsList, options...); err != nil {
+			fail("k8s", fmt.Errorf("listing MAPT kind clusters %s: %w", scope, err))
+		} else {
+			for _, cluster := range kindsList.Items {
+				info := ClusterInfo{
+					Name:        cluster.Name,
+					Namespace:   cluster.Namespace,
+					Type:        "k8s",
+					Created:     cluster.CreationTimestamp.Time,
+					Labels:      cluster.Labels,
+					Annotations: cluster.Annotations,
+					Phase:       phaseUnknown,
+					Deleting:    cluster.DeletionTimestamp != nil,
+				}
+				if obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&cluster); err == nil {
+					info.Phase = clusterPhase(obj)
+					info.Reason = clusterReason(obj)
+					info.APIServer, info.Console = clusterEndpoints(obj)
+					info.Region = clusterRegion(obj)
+				}
+				inventory.Clusters = append(inventory.Clusters, info)
+			}
+		}
+
+		openshiftsList := &unstructured.UnstructuredList{}
+		openshiftsList.SetGroupVersionKind(openshiftListGVK)
+		if err := client.CrClient.List(ctx, openshiftsList, options...); err != nil {
+			fail("openshift", fmt.Errorf("listing MAPT openshift clusters %s: %w", scope, err))
+		} else {
+			for _, cluster := range openshiftsList.Items {
+				info := ClusterInfo{
+					Name:        cluster.GetName(),
+					Namespace:   cluster.GetNamespace(),
+					Type:        "openshift",
+					Created:     cluster.GetCreationTimestamp().Time,
+					Labels:      cluster.GetLabels(),
+					Annotations: cluster.GetAnnotations(),
+					Phase:       clusterPhase(cluster.Object),
+					Reason:      clusterReason(cluster.Object),
+					Deleting:    cluster.GetDeletionTimestamp() != nil,
+				}
+				info.APIServer, info.Console = clusterEndpoints(cluster.Object)
+				info.Region = clusterRegion(cluster.Object)
+				inventory.Clusters = append(inventory.Clusters, info)
+			}
+		}
+	}
+
+	inventory.Clusters = dedupeClusters(inventory.Clusters)
+	return inventory, errors.Join(errs...)
+}
+
+// dedupeClusters drops the clusters listed more than once, keeping the first
+// entry for each namespace, name and type, so that a resource returned by two
+// list calls is only counted once.
+func dedupeClusters(clusters []ClusterInfo) []ClusterInfo {
+	type clusterKey struct{ namespace, name, clusterType string }
+	seen := make(map[clusterKey]bool, len(clusters))
+	deduped := clusters[:0]
+	for _, cluster := range clusters {
+		key := clusterKey{cluster.Namespace, cluster.Name, cluster.Type}
+		if seen[key] {
+			log.Printf("Ignoring duplicate %s cluster %s/%s in the inventory", cluster.Type, cluster.Namespace, cluster.Name)
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, cluster)
+	}
+	return deduped
+}
+
+// clusterPhase derives the provisioning phase from a MAPT resource's status.
+//
+// It prefers an explicit `status.phase` and falls back to the `Ready`
+// condition, so both reporting styles are understood.
+func clusterPhase(obj map[string]interface{}) string {
+	if phase, found, _ := unstructured.NestedString(obj, "status", "phase"); found && phase != "" {
+		return phase
+	}
+
+	conditions, found, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	if !found {
+		return phaseUnknown
+	}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		switch condition["status"] {
+		case "True":
+			return phaseReady
+		case "False":
+			if condition["reason"] == phaseFailed {
+				return phaseFailed
+			}
+			return phaseProvisioning
+		}
+	}
+	return phaseUnknown
+}
+
+// clusterReason returns the explanation of the phase from a MAPT resource's
+// status: `status.message`, or the message or reason of the `Ready`
+// condition. It is empty when the status gives none.
+func clusterReason(obj map[string]interface{}) string {
+	if message, found, _ := unstructured.NestedString(obj, "status", "message"); found && message != "" {
+		return message
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		for _, key := range []string{"message", "reason"} {
+			if text, ok := condition[key].(string); ok && text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// Status fields MAPT may report the cluster endpoints in, in order of preference.
+var (
+	apiServerFields = [][]string{{"status", "apiServerURL"}, {"status", "endpoints", "apiServer"}}
+	consoleFields   = [][]string{{"status", "consoleURL"}, {"status", "endpoints", "console"}}
+)
+
+// clusterEndpoints returns the API server and console URLs from a MAPT
+// resource's status, stripped of anything that could carry credentials.
+// Either is empty when the status does not report it.
+func clusterEndpoints(obj map[string]interface{}) (apiServer, console string) {
+	return statusURL(obj, apiServerFields), statusURL(obj, consoleFields)
+}
+
+// statusURL returns the first of the fields holding a valid http(s) URL,
+// without user info, query or fragment, so that it is safe to post in a
+// channel. Credentials are never read from the status.
+func statusURL(obj map[string]interface{}, fields [][]string) string {
+	for _, path := range fields {
+		raw, found, _ := unstructured.NestedString(obj, path...)
+		if !found || raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			continue
+		}
+		u.User, u.RawQuery, u.Fragment = nil, "", ""
+		return u.String()
+	}
+	return ""
+}
+
+// patchAnnotation sets an annotation of the cluster, or removes it when value
+// is empty, with a merge patch leaving its other annotations untouched.
+func patchAnnotation(ctx context.Context, client *KubernetesClients, cluster ClusterInfo, key, value string) error {
+	var patchValue interface{} // null removes the annotation
+	if value != "" {
+		patchValue = value
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{key: patchValue},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(maptApi.GroupVersion.WithKind(clusterKinds[cluster.Type]))
+	obj.SetName(cluster.Name)
+	obj.SetNamespace(cluster.Namespace)
+	return client.CrClient.Patch(ctx, obj, crclient.RawPatch(types.MergePatchType, patch))
+}
+
+// deleteCluster removes the MAPT resource backing the given cluster.
+func deleteCluster(ctx context.Context, client *KubernetesClients, cluster ClusterInfo) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(maptApi.GroupVersion.WithKind(clusterKinds[cluster.Type]))
+	obj.SetName(cluster.Name)
+	obj.SetNamespace(cluster.Namespace)
+	return client.CrClient.Delete(ctx, obj)
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestDedupeClusters(t *testing.T) {
+	clusters := []ClusterInfo{
+		{Namespace: "default", Name: "a", Type: "k8s", Phase: phaseReady},
+		{Namespace: "default", Name: "a", Type: "k8s", Phase: phaseFailed},
+		{Namespace: "default", Name: "a", Type: "openshift"},
+		{Namespace: "ci", Name: "a", Type: "k8s"},
+		{Namespace: "default", Name: "b", Type: "k8s"},
+	}
+
+	got := dedupeClusters(clusters)
+	if len(got) != 4 {
+		t.Fatalf("dedupeClusters() kept %d clusters, want 4: %+v", len(got), got)
+	}
+	if got[0].Phase != phaseReady {
+		t.Errorf("kept %+v, want the first entry of the duplicate", got[0])
+	}
+}
+
+// duplicateKinds returns every MAPT Kind cluster twice from list calls, as
+// overlapping list calls would.
+func duplicateKinds() interceptor.Funcs {
+	return interceptor.Funcs{
+		List: func(ctx context.Context, client crclient.WithWatch, list crclient.ObjectList, opts ...crclient.ListOption) error {
+			if err := client.List(ctx, list, opts...); err != nil {
+				return err
+			}
+			if kinds, ok := list.(*maptApi.KindList); ok {
+				kinds.Items = append(kinds.Items, kinds.Items...)
+			}
+			return nil
+		},
+	}
+}
+
+func TestListCountsDuplicatesOnce(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKubeWithInterceptor(duplicateKinds(),
+		existingCluster("spoticus-k8s-dup1", "U1", 0),
+		existingCluster("spoticus-k8s-dup2", "U1", 0),
+	))
+	api, server := newAPI(t)
+
+	if err := HandleList(context.Background(), api, message("U1", "C1", "list"), nil); err != nil {
+		t.Fatalf("HandleList: %v", err)
+	}
+	text := server.WaitForMessage("Cluster List", time.Second).Text()
+	if !strings.Contains(text, "(2 clusters)") {
+		t.Errorf("list %q, want the two clusters counted once", text)
+	}
+	if got := strings.Count(text, "*spoticus-k8s-dup1*"); got != 1 {
+		t.Errorf("list shows spoticus-k8s-dup1 %d times, want once", got)
+	}
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/slack-go/slack"
+
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// ErrorCategory tells why a command failed, so that failures are logged
+// consistently by the dispatcher.
+type ErrorCategory int
+
+const (
+	// CategoryValidation is a command refused for its arguments, or for the
+	// state of the cluster it targets, e.g. an unknown size or cluster.
+	CategoryValidation ErrorCategory = iota
+	// CategoryBackend is a command whose request to Kubernetes, or to Slack,
+	// failed.
+	CategoryBackend
+	// CategoryAuth is a command the user is not allowed to run.
+	CategoryAuth
+	// CategoryTimeout is a command whose request ran out of time.
+	CategoryTimeout
+	// CategoryInternal is a command that failed on the bot's side, e.g. a
+	// file that could not be rendered.
+	CategoryInternal
+)
+
+// String names the category in log messages.
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategoryBackend:
+		return "backend"
+	case CategoryAuth:
+		return "auth"
+	case CategoryTimeout:
+		return "timeout"
+	case CategoryInternal:
+		return "internal"
+	default:
+		return "validation"
+	}
+}
+
+// CommandError is a failed command, as returned by the command handlers:
+// the dispatcher shows Message to the user and logs Log under the Category.
+type CommandError struct {
+	Category ErrorCategory
+
+	// Message is shown to the user as-is.
+	Message string
+
+	// Log describes the failure for the bot's logs. It is empty for failures
+	// that are not worth logging, e.g. usage errors.
+	Log string
+
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Error returns the log message, or the user message if there is none.
+func (e *CommandError) Error() string {
+	if e.Log != "" {
+		return e.Log
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying error.
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// CategoryOf returns the category a failed command is reported under: the
+// one of its *CommandError, or CategoryInternal for other errors.
+func CategoryOf(err error) ErrorCategory {
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Category
+	}
+	return CategoryInternal
+}
+
+// ReportError shows the message of the failed command to the user in
+// channel and logs the failure under its category. Errors other than
+// *CommandError are reported as internal failures.
+func ReportError(api *slack.Client, channel, command, user string, err error) {
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		cmdErr = &CommandError{
+			Category: CategoryInternal,
+			Message:  messages.Render(msgs().CommandFailed, messages.Data{"Command": command}),
+			Log:      err.Error(),
+			Err:      err,
+		}
+	}
+	if cmdErr.Log != "" {
+		log.Printf("Command '%s' from user %s failed (%s): %s", command, user, cmdErr.Category, cmdErr.Log)
+	}
+	respondError(api, channel, cmdErr.Message)
+}
+
+// invalid is a validation failure shown to the user with message.
+func invalid(message string) error {
+	return &CommandError{Category: CategoryValidation, Message: message}
+}
+
+// denied is an authorization failure shown to the user with message and
+// logged with the format and args.
+func denied(message, format string, args ...any) error {
+	return &CommandError{Category: CategoryAuth, Message: message, Log: fmt.Sprintf(format, args...)}
+}
+
+// failure is a failed backend request: the user is shown the message
+// backendError picks for err, the fallback by default, and the format and
+// args describe what was being done in the logs.
+func failure(err error, fallback, format string, args ...any) error {
+	category := CategoryBackend
+	if classifyError(err) == classTimeout {
+		category = CategoryTimeout
+	}
+	return &CommandError{
+		Category: category,
+		Message:  backendError(err, fallback),
+		Log:      fmt.Sprintf(format, args...) + ": " + err.Error(),
+		Err:      err,
+	}
+}
+
+// clusterLookupError is the failure of looking up the cluster name with
+// findCluster.
+func clusterLookupError(err error, name string) error {
+	message := messages.Render(msgs().ClusterNotFound, messages.Data{"Name": name})
+	if errors.Is(err, errClusterNotFound) {
+		return invalid(message)
+	}
+	return failure(err, message, "looking up MAPT cluster %s", name)
+}
+
+// clusterAccessDenied is the failure of a user running action on a cluster
+// of another team.
+func clusterAccessDenied(user, action string, cluster ClusterInfo) error {
+	return denied(messages.Render(msgs().ClusterAccessDenied, messages.Data{
+		"Name": cluster.Name,
+		"Team": cluster.Labels[labelTeam],
+	}), "denied %s of cluster %s (team %s) to user %s", action, cluster.Name, cluster.Labels[labelTeam], user)
+}
+
+// connectError is the failure of getting the Kubernetes client.
+func connectError(err error) error {
+	return failure(err, msgs().ConnectFailed, "getting kubernetes client")
+}
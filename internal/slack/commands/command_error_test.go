@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCommandErrorCategories(t *testing.T) {
+	useConfig(t, testConfig())
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "mapt.redhat.com", Resource: "kinds"}, "spoticus-k8s-abcde")
+
+	tests := []struct {
+		name         string
+		err          error
+		wantCategory ErrorCategory
+		wantMessage  string
+		wantLog      string
+	}{
+		{
+			name:         "validation",
+			err:          invalid("❌ Unknown size"),
+			wantCategory: CategoryValidation,
+			wantMessage:  "❌ Unknown size",
+		},
+		{
+			name:         "denied",
+			err:          denied("⛔ Admins only", "denied %s to user %s", "reload", "U1"),
+			wantCategory: CategoryAuth,
+			wantMessage:  "⛔ Admins only",
+			wantLog:      "denied reload to user U1",
+		},
+		{
+			name:         "backend failure",
+			err:          failure(errors.New("connection refused"), "❌ Failed to list", "listing clusters in %s", "default"),
+			wantCategory: CategoryBackend,
+			wantMessage:  "❌ Failed to list",
+			wantLog:      "listing clusters in default: connection refused",
+		},
+		{
+			name:         "timeout",
+			err:          failure(fmt.Errorf("listing: %w", context.DeadlineExceeded), "❌ Failed to list", "listing clusters"),
+			wantCategory: CategoryTimeout,
+			wantMessage:  msgs().RequestTimedOut,
+			wantLog:      "listing clusters: listing: context deadline exceeded",
+		},
+		{
+			name:         "unknown cluster",
+			err:          clusterLookupError(errClusterNotFound, "spoticus-k8s-abcde"),
+			wantCategory: CategoryValidation,
+			wantMessage:  "not found",
+		},
+		{
+			name:         "lookup failure",
+			err:          clusterLookupError(notFound, "spoticus-k8s-abcde"),
+			wantCategory: CategoryBackend,
+			wantLog:      "looking up MAPT cluster spoticus-k8s-abcde",
+		},
+		{
+			name:         "plain error",
+			err:          errors.New("template: bad"),
+			wantCategory: CategoryInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CategoryOf(tt.err); got != tt.wantCategory {
+				t.Errorf("CategoryOf() = %s, want %s", got, tt.wantCategory)
+			}
+			var cmdErr *CommandError
+			if !errors.As(tt.err, &cmdErr) {
+				return
+			}
+			if !strings.Contains(cmdErr.Message, tt.wantMessage) {
+				t.Errorf("Message = %q, want it to contain %q", cmdErr.Message, tt.wantMessage)
+			}
+			if !strings.Contains(cmdErr.Log, tt.wantLog) || (tt.wantLog == "" && cmdErr.Log != "") {
+				t.Errorf("Log = %q, want %q", cmdErr.Log, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestCommandErrorUnwraps(t *testing.T) {
+	err := failure(ErrBackendUnavailable, "❌ Failed", "listing clusters")
+	if !errors.Is(err, ErrBackendUnavailable) {
+		t.Errorf("failure() does not wrap its cause")
+	}
+	if got := err.Error(); got != "listing clusters: "+ErrBackendUnavailable.Error() {
+		t.Errorf("Error() = %q, want the log message", got)
+	}
+	if got := invalid("❌ Usage").Error(); got != "❌ Usage" {
+		t.Errorf("Error() without a log message = %q, want the user message", got)
+	}
+}
+
+func TestReportError(t *testing.T) {
+	useConfig(t, testConfig())
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "command error", err: invalid("❌ Unknown size *huge*"), want: "❌ Unknown size *huge*"},
+		{name: "plain error", err: errors.New("template: bad"), want: "launch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, server := newAPI(t)
+
+			ReportError(api, "C1", "launch", "U1", tt.err)
+
+			messages := server.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("posted %d messages, want 1", len(messages))
+			}
+			if got := messages[0]; got.Channel() != "C1" || !strings.Contains(got.Text(), tt.want) {
+				t.Errorf("posted %q to %s, want %q in C1", got.Text(), got.Channel(), tt.want)
+			}
+			if strings.Contains(messages[0].Text(), "template: bad") {
+				t.Errorf("internal error details shown to the user: %q", messages[0].Text())
+			}
+		})
+	}
+}
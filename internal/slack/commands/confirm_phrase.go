@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+// confirmTokenLength is the length of the random token of a confirmation
+// phrase.
+const confirmTokenLength = 4
+
+// newConfirmPhrase returns the phrase a user has to type back to confirm a
+// destructive operation on count clusters, e.g. "delete-7-k3x9". The count
+// makes the user read how many clusters are affected, and the random token
+// that the phrase cannot be typed ahead of time.
+func newConfirmPhrase(count int) string {
+	return fmt.Sprintf("delete-%d-%s", count, utilrand.String(confirmTokenLength))
+}
+
+// confirmPhraseMatches reports whether the phrase typed by the user is the
+// expected one, ignoring case, surrounding spaces and the backticks of a
+// phrase copied as code.
+func confirmPhraseMatches(expected, typed string) bool {
+	typed = strings.Trim(strings.TrimSpace(typed), "`")
+	return expected != "" && strings.EqualFold(typed, expected)
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewConfirmPhrase(t *testing.T) {
+	phrase := newConfirmPhrase(7)
+	if !regexp.MustCompile(`^delete-7-[a-z0-9]{4}$`).MatchString(phrase) {
+		t.Errorf("newConfirmPhrase(7) = %q, want delete-7- and a 4 character token", phrase)
+	}
+}
+
+func TestConfirmPhraseMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		typed    string
+		want     bool
+	}{
+		{name: "exact", expected: "delete-3-k3x9", typed: "delete-3-k3x9", want: true},
+		{name: "other case", expected: "delete-3-k3x9", typed: "DELETE-3-K3X9", want: true},
+		{name: "surrounding spaces", expected: "delete-3-k3x9", typed: "  delete-3-k3x9 ", want: true},
+		{name: "copied as code", expected: "delete-3-k3x9", typed: "`delete-3-k3x9`", want: true},
+		{name: "wrong token", expected: "delete-3-k3x9", typed: "delete-3-aaaa"},
+		{name: "wrong count", expected: "delete-3-k3x9", typed: "delete-4-k3x9"},
+		{name: "plain yes", expected: "delete-3-k3x9", typed: "yes"},
+		{name: "nothing typed", expected: "delete-3-k3x9", typed: ""},
+		{name: "no challenge", expected: "", typed: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := confirmPhraseMatches(tt.expected, tt.typed); got != tt.want {
+				t.Errorf("confirmPhraseMatches(%q, %q) = %v, want %v", tt.expected, tt.typed, got, tt.want)
+			}
+		})
+	}
+}
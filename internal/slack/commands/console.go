@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// regionFields are the MAPT resource fields, in order of preference, holding
+// the cloud region the cluster's instances run in.
+var regionFields = [][]string{
+	{"status", "region"},
+	{"spec", "region"},
+}
+
+// clusterRegion returns the cloud region reported on a MAPT resource, or
+// empty if none is.
+func clusterRegion(obj map[string]interface{}) string {
+	for _, path := range regionFields {
+		if region, found, _ := unstructured.NestedString(obj, path...); found && region != "" {
+			return region
+		}
+	}
+	return ""
+}
+
+// consoleURLData is what the console URL templates are rendered with.
+type consoleURLData struct {
+	Provider  string
+	Region    string
+	Name      string
+	Namespace string
+}
+
+// Provider returns the cloud provider the cluster was launched on. Clusters
+// launched before the provider was recorded ran on the default provider.
+func (c ClusterInfo) Provider() string {
+	if provider := c.Metadata().Provider; provider != "" {
+		return provider
+	}
+	return defaultProvider
+}
+
+// errConsoleUnsupported is returned by consoleURL for providers without a
+// console URL template.
+var errConsoleUnsupported = errors.New("no console URL template for the provider")
+
+// consoleURL renders the cloud console URL of the cluster's instances from
+// the template configured for its provider.
+func consoleURL(cfg *spoticusConfig.Config, cluster ClusterInfo) (string, error) {
+	text, ok := cfg.ConsoleURLs[cluster.Provider()]
+	if !ok {
+		return "", errConsoleUnsupported
+	}
+	tmpl, err := template.New("console").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, consoleURLData{
+		Provider:  cluster.Provider(),
+		Region:    url.PathEscape(cluster.Region),
+		Name:      url.QueryEscape(cluster.Name),
+		Namespace: url.QueryEscape(cluster.Namespace),
+	}); err != nil {
+		return "", err
+	}
+	u, err := url.Parse(b.String())
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("console URL %q is not an http(s) URL", b.String())
+	}
+	return u.String(), nil
+}
+
+// HandleConsole is the entry point for the "console" Slack command.
+//
+// It replies with a link to the cloud provider's console showing the
+// instances of a cluster, built from the provider and region recorded on
+// the MAPT resource.
+func HandleConsole(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) != 1 {
+		return invalid(cfg.Messages.ConsoleUsage)
+	}
+	name := args[0]
+	tracing.SetCluster(ctx, name)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	cluster, err := findCluster(ctx, client, name)
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+	if !canOperate(cfg, event.User, cluster) {
+		return clusterAccessDenied(event.User, "console link", cluster)
+	}
+
+	data := messages.Data{
+		"Name":     cluster.Name,
+		"Provider": cluster.Provider(),
+		"Region":   cluster.Region,
+	}
+	if cluster.Region == "" {
+		return invalid(messages.Render(cfg.Messages.ConsoleMissingRegion, data))
+	}
+	link, err := consoleURL(cfg, cluster)
+	if err != nil {
+		message := messages.Render(cfg.Messages.ConsoleUnsupported, data)
+		if errors.Is(err, errConsoleUnsupported) {
+			return invalid(message)
+		}
+		return &CommandError{
+			Category: CategoryValidation,
+			Message:  message,
+			Log:      fmt.Sprintf("building the console URL of cluster %s: %v", name, err),
+			Err:      err,
+		}
+	}
+	data["URL"] = link
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.ConsoleLink, data))
+	return nil
+}
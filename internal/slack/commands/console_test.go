@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestClusterRegion(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  map[string]interface{}
+		want string
+	}{
+		{name: "not reported", obj: map[string]interface{}{"status": map[string]interface{}{"phase": phaseProvisioning}}},
+		{name: "status", obj: map[string]interface{}{"status": map[string]interface{}{"region": "eu-west-1"}}, want: "eu-west-1"},
+		{name: "spec", obj: map[string]interface{}{"spec": map[string]interface{}{"region": "us-east-2"}}, want: "us-east-2"},
+		{
+			name: "status preferred",
+			obj: map[string]interface{}{
+				"spec":   map[string]interface{}{"region": "us-east-2"},
+				"status": map[string]interface{}{"region": "eu-west-1"},
+			},
+			want: "eu-west-1",
+		},
+		{
+			name: "empty status falls back to spec",
+			obj: map[string]interface{}{
+				"spec":   map[string]interface{}{"region": "us-east-2"},
+				"status": map[string]interface{}{"region": ""},
+			},
+			want: "us-east-2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clusterRegion(tt.obj); got != tt.want {
+				t.Errorf("clusterRegion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// onProvider is a cluster in region whose launch recorded provider.
+func onProvider(provider, region string) ClusterInfo {
+	cluster := ClusterInfo{Name: "spoticus-k8s-console", Namespace: "default", Region: region}
+	if provider != "" {
+		cluster.Annotations = LaunchMetadata{Provider: provider}.Annotations()
+	}
+	return cluster
+}
+
+func TestConsoleURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates map[string]string
+		cluster   ClusterInfo
+		want      string
+		wantErr   error
+	}{
+		{
+			name:    "aws",
+			cluster: onProvider("aws", "eu-west-1"),
+			want:    "https://eu-west-1.console.aws.amazon.com/ec2/home?region=eu-west-1#Instances:tag:Name=spoticus-k8s-console",
+		},
+		{
+			name:    "azure",
+			cluster: onProvider("azure", "westeurope"),
+			want:    "https://portal.azure.com/#view/HubsExtension/BrowseResource/resourceType/Microsoft.Compute%2FVirtualMachines",
+		},
+		{
+			name:    "provider not recorded",
+			cluster: onProvider("", "us-east-1"),
+			want:    "https://us-east-1.console.aws.amazon.com/ec2/home?region=us-east-1#Instances:tag:Name=spoticus-k8s-console",
+		},
+		{
+			name:      "configured template",
+			templates: map[string]string{"gcp": "https://console.cloud.google.com/compute/instances?project={{.Namespace}}&zone={{.Region}}&name={{.Name}}"},
+			cluster:   onProvider("gcp", "europe-west1-b"),
+			want:      "https://console.cloud.google.com/compute/instances?project=default&zone=europe-west1-b&name=spoticus-k8s-console",
+		},
+		{
+			name:      "fields escaped",
+			templates: map[string]string{"aws": "https://console.example.com/{{.Region}}/instances?name={{.Name}}"},
+			cluster:   ClusterInfo{Name: "a&b=c", Region: "eu/west?1"},
+			want:      "https://console.example.com/eu%2Fwest%3F1/instances?name=a%26b%3Dc",
+		},
+		{name: "no template", cluster: onProvider("gcp", "europe-west1-b"), wantErr: errConsoleUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			for provider, text := range tt.templates {
+				cfg.ConsoleURLs[provider] = text
+			}
+			got, err := consoleURL(cfg, tt.cluster)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("consoleURL() = %q, %v, want %v", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("consoleURL() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleURLRejectsBadTemplates(t *testing.T) {
+	for name, text := range map[string]string{
+		"not a web URL": "ftp://console.example.com/{{.Region}}",
+		"no host":       "https:///{{.Region}}",
+		"unknown field": "https://console.example.com/{{.Zone}}",
+		"unparsable":    "https://console.example.com/{{.Region",
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.ConsoleURLs["aws"] = text
+			if got, err := consoleURL(cfg, onProvider("aws", "eu-west-1")); err == nil || errors.Is(err, errConsoleUnsupported) {
+				t.Errorf("consoleURL() = %q, %v, want the template rejected", got, err)
+			}
+		})
+	}
+}
+
+// clusterInRegion is an existing OpenShift cluster launched on provider that
+// reports region.
+func clusterInRegion(t *testing.T, name, provider, region string) *unstructured.Unstructured {
+	t.Helper()
+	cluster := existingClusterOf("openshift", name, "U1", 0)
+	SetLaunchMetadata(cluster, LaunchMetadata{Owner: "U1", Provider: provider})
+	if region != "" {
+		if err := unstructured.SetNestedField(cluster.Object, region, "status", "region"); err != nil {
+			t.Fatalf("setting the region: %v", err)
+		}
+	}
+	return cluster
+}
+
+func TestHandleConsoleLinksToInstances(t *testing.T) {
+	useConfig(t, testConfig())
+	name := "spoticus-openshift-console"
+	useKube(t, slacktest.NewKube(clusterInRegion(t, name, "aws", "eu-west-1")))
+	api, server := newAPI(t)
+
+	if err := HandleConsole(context.Background(), api, message("U1", "C1", "console "+name), []string{name}); err != nil {
+		t.Fatalf("HandleConsole: %v", err)
+	}
+
+	text := server.WaitForMessage("Open the aws console", time.Second).Text()
+	for _, want := range []string{"<https://eu-west-1.console.aws.amazon.com/ec2/home?region=eu-west-1#Instances:tag:Name=" + name + "|", "*" + name + "*", "(eu-west-1)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("reply %q does not contain %q", text, want)
+		}
+	}
+}
+
+func TestHandleConsoleWithoutLink(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(
+		clusterInRegion(t, "spoticus-openshift-noregion", "aws", ""),
+		clusterInRegion(t, "spoticus-openshift-gcp", "gcp", "europe-west1-b"),
+	))
+	api, server := newAPI(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "usage", want: "Usage: `console <cluster_name>`"},
+		{name: "region not reported", args: []string{"spoticus-openshift-noregion"}, want: "region of *spoticus-openshift-noregion* is not known yet"},
+		{name: "provider without a template", args: []string{"spoticus-openshift-gcp"}, want: "No console link is configured for gcp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := HandleConsole(context.Background(), api, message("U1", "C1", "console "+strings.Join(tt.args, " ")), tt.args)
+			var cmdErr *CommandError
+			if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, tt.want) {
+				t.Errorf("HandleConsole() = %v, want a validation error containing %q", err, tt.want)
+			}
+		})
+	}
+	if got := len(server.Messages()); got != 0 {
+		t.Errorf("posted %d messages, want no link", got)
+	}
+}
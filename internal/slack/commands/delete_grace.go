@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// annotationDeleteAt records, as an RFC 3339 time, when a cluster marked with
+// `done` during the grace period is deleted. Storing it on the resource lets
+// the deletion survive restarts.
+const annotationDeleteAt = "spoticus.io/delete-at"
+
+var (
+	pendingDeletionsMu sync.Mutex
+	// pendingDeletions holds the timers of the clusters awaiting deletion,
+	// keyed by "<namespace>/<name>".
+	pendingDeletions = map[string]*time.Timer{}
+)
+
+// DeleteAt returns when the cluster is due to be deleted after `done`, or the
+// zero time when no deletion is pending.
+func (c ClusterInfo) DeleteAt() time.Time {
+	at, err := time.Parse(time.RFC3339, c.Annotations[annotationDeleteAt])
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
+
+// pendingDeletionKey identifies a cluster in pendingDeletions.
+func pendingDeletionKey(cluster ClusterInfo) string {
+	return cluster.Namespace + "/" + cluster.Name
+}
+
+// scheduleDeletion marks the cluster for deletion once the grace period is
+// over and arms the timer deleting it.
+func scheduleDeletion(ctx context.Context, api *slack.Client, client *KubernetesClients, cluster ClusterInfo, channel string, at time.Time) error {
+	if err := patchAnnotation(ctx, client, cluster, annotationDeleteAt, at.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	armDeletion(api, cluster, channel, at)
+	return nil
+}
+
+// armDeletion starts the timer deleting the cluster at the given time,
+// replacing any timer already armed for it.
+func armDeletion(api *slack.Client, cluster ClusterInfo, channel string, at time.Time) {
+	pendingDeletionsMu.Lock()
+	defer pendingDeletionsMu.Unlock()
+	key := pendingDeletionKey(cluster)
+	if timer, ok := pendingDeletions[key]; ok {
+		timer.Stop()
+	}
+	pendingDeletions[key] = time.AfterFunc(time.Until(at), func() {
+		runPendingDeletion(api, cluster, channel)
+	})
+}
+
+// disarmDeletion stops the timer deleting the cluster, if any.
+func disarmDeletion(cluster ClusterInfo) {
+	pendingDeletionsMu.Lock()
+	defer pendingDeletionsMu.Unlock()
+	key := pendingDeletionKey(cluster)
+	if timer, ok := pendingDeletions[key]; ok {
+		timer.Stop()
+		delete(pendingDeletions, key)
+	}
+}
+
+// runPendingDeletion deletes the cluster at the end of its grace period,
+// unless the deletion was cancelled in the meantime, and reports it in channel.
+// Deletions due during maintenance are postponed like scheduled launches.
+func runPendingDeletion(api *slack.Client, cluster ClusterInfo, channel string) {
+	if MaintenanceEnabled() {
+		log.Printf("Postponing the deletion of cluster %s/%s during maintenance", cluster.Namespace, cluster.Name)
+		armDeletion(api, cluster, channel, time.Now().Add(maintenanceRetryInterval))
+		return
+	}
+	pendingDeletionsMu.Lock()
+	delete(pendingDeletions, pendingDeletionKey(cluster))
+	pendingDeletionsMu.Unlock()
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		log.Printf("Error getting kubernetes client to delete cluster %s/%s: %v", cluster.Namespace, cluster.Name, err)
+		return
+	}
+	ctx := context.Background()
+	current, err := findCluster(ctx, client, cluster.Name)
+	if err != nil {
+		log.Printf("Not deleting cluster %s/%s at the end of its grace period: %v", cluster.Namespace, cluster.Name, err)
+		return
+	}
+	if current.Namespace != cluster.Namespace || current.DeleteAt().IsZero() {
+		log.Printf("Deletion of cluster %s/%s was cancelled", cluster.Namespace, cluster.Name)
+		return
+	}
+
+	err = deleteCluster(ctx, client, current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Printf("Error deleting MAPT cluster %s/%s at the end of its grace period: %v", cluster.Namespace, cluster.Name, err)
+		respond.Text(api, channel, backendError(err, msgs().DoneFailed))
+		return
+	}
+	log.Printf("Cluster %s/%s deleted at the end of its grace period", cluster.Namespace, cluster.Name)
+	rememberDeletion(cluster.Name, time.Now())
+	notification := clusterNotification(EventDeleted, current, "")
+	notification.Text, notification.Channel = messages.Render(msgs().DoneGraceDeleted, messages.Data{"Name": cluster.Name}), channel
+	notify(api, notification)
+}
+
+// RestorePendingDeletions re-arms the timers of the clusters marked for
+// deletion with `done`, so that a restart does not keep them forever. Their
+// outcome is reported in the channel they were launched from.
+func RestorePendingDeletions(api *slack.Client) {
+	client, err := GetKubernetesClient()
+	if err != nil {
+		log.Printf("Error getting kubernetes client to restore pending deletions: %v", err)
+		return
+	}
+	inventory, err := listClusters(context.TODO(), client)
+	if err != nil {
+		log.Printf("Error listing MAPT clusters to restore pending deletions: %v", err)
+	}
+	restored := 0
+	for _, cluster := range inventory.Clusters {
+		if at := cluster.DeleteAt(); !at.IsZero() && !cluster.Deleting {
+			armDeletion(api, cluster, cluster.Metadata().Channel, at)
+			restored++
+		}
+	}
+	if restored > 0 {
+		log.Printf("Restored %d pending deletion(s)", restored)
+	}
+}
+
+// notifyOwner sends text to the owner of the cluster in a direct message,
+// unless the owner is the user who caused it or is unknown. A user group
+// owning the cluster is mentioned in the channel it was launched from instead.
+func notifyOwner(api *slack.Client, cluster ClusterInfo, user, text string) {
+	metadata := cluster.Metadata()
+	owner := metadata.Owner
+	if owner == "" || owner == user {
+		return
+	}
+	if spoticusConfig.IsUserGroupID(owner) {
+		if metadata.Channel != "" {
+			respond.Text(api, metadata.Channel, "<!subteam^"+owner+"> "+text)
+		}
+		return
+	}
+	dm, _, _, err := api.OpenConversation(&slack.OpenConversationParameters{Users: []string{owner}})
+	if err != nil {
+		log.Printf("Error opening a direct message with %s about cluster %s: %v", owner, cluster.Name, err)
+		return
+	}
+	respond.Text(api, dm.ID, text)
+}
+
+// HandleCancelDelete is the entry point for the "cancel-delete" Slack command.
+// It keeps a cluster marked for deletion with `done` during its grace period.
+func HandleCancelDelete(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) != 1 {
+		return invalid(cfg.Messages.CancelDeleteUsage)
+	}
+	name := args[0]
+	tracing.SetCluster(ctx, name)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	cluster, err := findCluster(ctx, client, name)
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+	if !canOperate(cfg, event.User, cluster) {
+		return clusterAccessDenied(event.User, "cancel-delete", cluster)
+	}
+	if cluster.DeleteAt().IsZero() || cluster.Deleting {
+		return invalid(messages.Render(cfg.Messages.CancelDeleteNone, messages.Data{"Name": name}))
+	}
+
+	if err := patchAnnotation(ctx, client, cluster, annotationDeleteAt, ""); err != nil {
+		return failure(err, cfg.Messages.CancelDeleteFailed, "removing %s from MAPT cluster %s/%s", annotationDeleteAt, cluster.Namespace, cluster.Name)
+	}
+	disarmDeletion(cluster)
+
+	log.Printf("Deletion of cluster %s/%s cancelled by %s", cluster.Namespace, cluster.Name, event.User)
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.DeleteCancelled, messages.Data{"Name": name}))
+	return nil
+}
@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// usePendingDeletions starts the test with no deletion pending, and stops
+// the timers it armed when it ends.
+func usePendingDeletions(t *testing.T) {
+	t.Helper()
+	pendingDeletionsMu.Lock()
+	previous := pendingDeletions
+	pendingDeletions = map[string]*time.Timer{}
+	pendingDeletionsMu.Unlock()
+	t.Cleanup(func() {
+		pendingDeletionsMu.Lock()
+		defer pendingDeletionsMu.Unlock()
+		for _, timer := range pendingDeletions {
+			timer.Stop()
+		}
+		pendingDeletions = previous
+	})
+}
+
+// deletionArmed reports whether a timer deletes the cluster.
+func deletionArmed(namespace, name string) bool {
+	pendingDeletionsMu.Lock()
+	defer pendingDeletionsMu.Unlock()
+	_, ok := pendingDeletions[namespace+"/"+name]
+	return ok
+}
+
+// withGracePeriod is the test configuration with a deleteGracePeriod.
+func withGracePeriod(grace time.Duration) *spoticusConfig.Config {
+	cfg := testConfig()
+	cfg.DeleteGracePeriod = spoticusConfig.Duration(grace)
+	return cfg
+}
+
+func TestDoneSchedulesDeletionAfterGracePeriod(t *testing.T) {
+	useConfig(t, withGracePeriod(100*time.Millisecond))
+	usePendingDeletions(t)
+	name := "spoticus-k8s-graced"
+	kube := slacktest.NewKube(existingCluster(name, "UOWNER", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("UOTHER", "C1", "done "+name), []string{name}); err != nil {
+		t.Fatalf("HandleDone: %v", err)
+	}
+	server.WaitForMessage("*"+name+"* will be deleted", time.Second)
+	owner := server.WaitForMessage("<@UOTHER> marked your cluster *"+name+"* for deletion", time.Second)
+	if owner.Channel() != "DUOWNER" {
+		t.Errorf("owner notified in %s, want a direct message", owner.Channel())
+	}
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 || kinds[0].Annotations[annotationDeleteAt] == "" {
+		t.Fatalf("clusters %v, want the cluster kept and annotated %s", kinds, annotationDeleteAt)
+	}
+	if !deletionArmed("default", name) {
+		t.Error("no deletion armed for the cluster")
+	}
+
+	server.WaitForMessage("The grace period of *"+name+"* is over", 5*time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Errorf("got %d clusters after the grace period, want none", len(kinds))
+	}
+	if deletionArmed("default", name) {
+		t.Error("deletion still armed after it ran")
+	}
+}
+
+func TestDoneWhileDeletionPending(t *testing.T) {
+	useConfig(t, withGracePeriod(time.Hour))
+	usePendingDeletions(t)
+	name := "spoticus-k8s-pending"
+	useKube(t, slacktest.NewKube(existingCluster(name, "U1", 0)))
+	api, server := newAPI(t)
+
+	for range 2 {
+		if err := HandleDone(context.Background(), api, message("U1", "C1", "done "+name), []string{name}); err != nil {
+			t.Fatalf("HandleDone: %v", err)
+		}
+	}
+	server.WaitForMessage("*"+name+"* is already marked for deletion", time.Second)
+	for _, call := range server.Messages() {
+		if strings.Contains(call.Text(), "marked your cluster") {
+			t.Errorf("owner notified of their own deletion: %q", call.Text())
+		}
+	}
+}
+
+func TestCancelDeleteKeepsCluster(t *testing.T) {
+	useConfig(t, withGracePeriod(time.Hour))
+	usePendingDeletions(t)
+	name := "spoticus-k8s-kept"
+	kube := slacktest.NewKube(existingCluster(name, "U1", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("U1", "C1", "done "+name), []string{name}); err != nil {
+		t.Fatalf("HandleDone: %v", err)
+	}
+	if err := HandleCancelDelete(context.Background(), api, message("U1", "C1", "cancel-delete "+name), []string{name}); err != nil {
+		t.Fatalf("HandleCancelDelete: %v", err)
+	}
+	server.WaitForMessage("Kept *"+name+"*", time.Second)
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 {
+		t.Fatalf("got %d clusters, want the cluster kept", len(kinds))
+	}
+	if _, ok := kinds[0].Annotations[annotationDeleteAt]; ok {
+		t.Errorf("annotations %v, want %s removed", kinds[0].Annotations, annotationDeleteAt)
+	}
+	if deletionArmed("default", name) {
+		t.Error("deletion still armed after cancel-delete")
+	}
+
+	err := HandleCancelDelete(context.Background(), api, message("U1", "C1", "cancel-delete "+name), []string{name})
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "is not marked for deletion") {
+		t.Errorf("HandleCancelDelete() without a pending deletion = %v, want a validation error", err)
+	}
+}
+
+func TestPendingDeletionSkipsCancelledCluster(t *testing.T) {
+	useConfig(t, testConfig())
+	usePendingDeletions(t)
+	name := "spoticus-k8s-cancelled"
+	kube := slacktest.NewKube(existingCluster(name, "U1", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	runPendingDeletion(api, ClusterInfo{Name: name, Namespace: "default", Type: "k8s"}, "C1")
+
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want a cluster no longer marked for deletion kept", len(kinds))
+	}
+	if got := len(server.Messages()); got != 0 {
+		t.Errorf("posted %d messages, want none", got)
+	}
+}
+
+func TestDoneNowSkipsGracePeriod(t *testing.T) {
+	useConfig(t, withGracePeriod(time.Hour))
+	usePendingDeletions(t)
+	name := "spoticus-k8s-now"
+	kube := slacktest.NewKube(existingCluster(name, "U1", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("U1", "C1", "done "+name+" --now"), []string{name, "--now"}); err != nil {
+		t.Fatalf("HandleDone: %v", err)
+	}
+	server.WaitForMessage(name, time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Errorf("got %d clusters after done --now, want none", len(kinds))
+	}
+	if deletionArmed("default", name) {
+		t.Error("deletion armed for a cluster deleted right away")
+	}
+}
+
+func TestRestorePendingDeletions(t *testing.T) {
+	useConfig(t, testConfig())
+	usePendingDeletions(t)
+	due := existingCluster("spoticus-k8s-due", "U1", 0)
+	SetLaunchMetadata(due, LaunchMetadata{Channel: "CLAUNCH"})
+	annotations := due.GetAnnotations()
+	annotations[annotationDeleteAt] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	due.SetAnnotations(annotations)
+	kube := slacktest.NewKube(due, existingCluster("spoticus-k8s-unmarked", "U1", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	RestorePendingDeletions(api)
+
+	deleted := server.WaitForMessage("The grace period of *spoticus-k8s-due* is over", 5*time.Second)
+	if deleted.Channel() != "CLAUNCH" {
+		t.Errorf("deletion reported in %s, want the launch channel", deleted.Channel())
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 || kinds[0].Name != "spoticus-k8s-unmarked" {
+		t.Errorf("clusters %v, want only the unmarked cluster left", kinds)
+	}
+}
+
+func TestDoneMentionsOwningUserGroup(t *testing.T) {
+	useConfig(t, withGracePeriod(time.Hour))
+	usePendingDeletions(t)
+	name := "spoticus-k8s-grouped"
+	cluster := existingCluster(name, "SPLATFORM", 0)
+	SetLaunchMetadata(cluster, LaunchMetadata{Channel: "CLAUNCH"})
+	useKube(t, slacktest.NewKube(cluster))
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("U1", "C1", "done "+name), []string{name}); err != nil {
+		t.Fatalf("HandleDone: %v", err)
+	}
+	notice := server.WaitForMessage("<!subteam^SPLATFORM> ⏳ <@U1> marked your cluster *"+name+"* for deletion", time.Second)
+	if notice.Channel() != "CLAUNCH" {
+		t.Errorf("user group notified in %s, want the launch channel", notice.Channel())
+	}
+	if calls := server.Calls("conversations.open"); len(calls) != 0 {
+		t.Errorf("opened %d direct messages, want none with a user group", len(calls))
+	}
+}
@@ -0,0 +1,261 @@
+package commands
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// maxDiagnosticsLogBytes bounds how much of each operator pod's log is read
+// when collecting the provisioning logs of a cluster.
+const maxDiagnosticsLogBytes = 4 << 20
+
+// diagnosticsErrorsFile is the file of a diagnostics bundle listing the parts
+// that could not be collected.
+const diagnosticsErrorsFile = "ERRORS.txt"
+
+// diagnosticsPart is a file of a diagnostics bundle, or the reason it could
+// not be collected.
+type diagnosticsPart struct {
+	Name    string
+	Content []byte
+	Err     error
+}
+
+// diagnosticsBundle zips the parts that were collected, each under the
+// cluster's directory, with a note listing the ones that failed.
+func diagnosticsBundle(cluster string, parts []diagnosticsPart) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	var failures []string
+	for _, part := range parts {
+		if part.Err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", part.Name, part.Err))
+			continue
+		}
+		file, err := archive.Create(cluster + "/" + part.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write(part.Content); err != nil {
+			return nil, err
+		}
+	}
+	if len(failures) > 0 {
+		file, err := archive.Create(cluster + "/" + diagnosticsErrorsFile)
+		if err != nil {
+			return nil, err
+		}
+		note := "These parts could not be collected:\n\n" + strings.Join(failures, "\n") + "\n"
+		if _, err := io.WriteString(file, note); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// collectDiagnostics gathers the parts of the diagnostics bundle of the
+// cluster: its status as `describe` shows it, the MAPT resource, its events
+// and the operator's log lines about it. Each part fails on its own.
+func collectDiagnostics(ctx context.Context, cfg *spoticusConfig.Config, client *KubernetesClients, cluster ClusterInfo) []diagnosticsPart {
+	resource, err := clusterResourceYAML(ctx, client, cluster)
+	parts := []diagnosticsPart{
+		{Name: "status.txt", Content: []byte(formatClusterStatus(cfg, cluster) + "\n")},
+		{Name: "resource.yaml", Content: resource, Err: err},
+	}
+
+	events, err := clusterEvents(ctx, client, cluster)
+	parts = append(parts, diagnosticsPart{Name: "events.txt", Content: events, Err: err})
+
+	logs, err := provisioningLogs(ctx, cfg.MaptOperator, client, cluster)
+	return append(parts, diagnosticsPart{Name: "operator.log", Content: logs, Err: err})
+}
+
+// clusterResourceYAML returns the MAPT resource of the cluster as YAML,
+// without its managed fields.
+func clusterResourceYAML(ctx context.Context, client *KubernetesClients, cluster ClusterInfo) ([]byte, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(maptApi.GroupVersion.WithKind(clusterKinds[cluster.Type]))
+	if err := client.CrClient.Get(ctx, crclient.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, obj); err != nil {
+		return nil, err
+	}
+	obj.SetManagedFields(nil)
+	return yaml.Marshal(obj.Object)
+}
+
+// clusterEvents returns the Kubernetes events about the cluster's resource,
+// oldest first, one per line.
+func clusterEvents(ctx context.Context, client *KubernetesClients, cluster ClusterInfo) ([]byte, error) {
+	list, err := client.KubeClient.CoreV1().Events(cluster.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.name", cluster.Name).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	events := list.Items
+	slices.SortStableFunc(events, func(a, b corev1.Event) int {
+		return eventTime(a).Compare(eventTime(b))
+	})
+
+	var b bytes.Buffer
+	if len(events) == 0 {
+		b.WriteString("No events recorded for this cluster (Kubernetes only keeps them for a limited time).\n")
+	}
+	for _, event := range events {
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s (x%d)\n", eventTime(event).UTC().Format(time.RFC3339),
+			event.Type, event.Reason, event.Message, max(event.Count, 1))
+	}
+	return b.Bytes(), nil
+}
+
+// eventTime returns when the event last happened.
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// provisioningLogs returns the lines of the MAPT operator's manager logs that
+// mention the cluster, since it was created.
+func provisioningLogs(ctx context.Context, operator spoticusConfig.MaptOperator, client *KubernetesClients, cluster ClusterInfo) ([]byte, error) {
+	deployment, err := client.KubeClient.AppsV1().Deployments(operator.Namespace).Get(ctx, operator.Deployment, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.KubeClient.CoreV1().Pods(operator.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no pod of deployment %s/%s is running", operator.Namespace, operator.Deployment)
+	}
+
+	var b bytes.Buffer
+	since := metav1.NewTime(cluster.Created)
+	limit := int64(maxDiagnosticsLogBytes)
+	for _, pod := range pods.Items {
+		stream, err := client.KubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container:  maptManagerContainer,
+			SinceTime:  &since,
+			LimitBytes: &limit,
+			Timestamps: true,
+		}).Stream(ctx)
+		if err != nil {
+			fmt.Fprintf(&b, "# %s: could not read the logs: %v\n", pod.Name, err)
+			continue
+		}
+		fmt.Fprintf(&b, "# %s\n", pod.Name)
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+		for scanner.Scan() {
+			if strings.Contains(scanner.Text(), cluster.Name) {
+				b.WriteString(scanner.Text() + "\n")
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(&b, "# %s: reading the logs stopped: %v\n", pod.Name, err)
+		}
+		stream.Close()
+	}
+	return b.Bytes(), nil
+}
+
+// HandleDiagnostics is the entry point for the admin "diagnostics" Slack
+// command. It uploads a zip of the cluster's status, MAPT resource, events and
+// provisioning logs to a direct message with the requester, for support
+// tickets. Parts that cannot be collected are listed in the bundle instead.
+func HandleDiagnostics(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) != 1 {
+		return invalid(cfg.Messages.DiagnosticsUsage)
+	}
+	name := args[0]
+	tracing.SetCluster(ctx, name)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+	cluster, err := findCluster(ctx, client, name)
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+
+	parts := collectDiagnostics(ctx, cfg, client, cluster)
+	var missing []string
+	for _, part := range parts {
+		if part.Err != nil {
+			log.Printf("Error collecting %s for the diagnostics of cluster %s/%s: %v", part.Name, cluster.Namespace, name, part.Err)
+			missing = append(missing, part.Name)
+		}
+	}
+	bundle, err := diagnosticsBundle(name, parts)
+	if err != nil {
+		return &CommandError{
+			Category: CategoryInternal,
+			Message:  cfg.Messages.DiagnosticsFailed,
+			Log:      "zipping the diagnostics of " + name + ": " + err.Error(),
+			Err:      err,
+		}
+	}
+
+	channel := event.Channel
+	if dm, _, _, err := api.OpenConversation(&slack.OpenConversationParameters{Users: []string{event.User}}); err != nil {
+		log.Printf("Error opening a direct message with %s, uploading diagnostics to %s instead: %v", event.User, channel, err)
+	} else {
+		channel = dm.ID
+	}
+
+	err = upload(ctx, api, slack.UploadFileV2Parameters{
+		Channel:  channel,
+		Content:  string(bundle),
+		Filename: name + "-diagnostics-" + time.Now().UTC().Format("20060102-1504") + ".zip",
+		Title:    "Diagnostics of " + name,
+		InitialComment: messages.Render(cfg.Messages.DiagnosticsReady, messages.Data{
+			"Name":    name,
+			"Missing": formatList(missing),
+		}),
+	})
+	if err != nil {
+		return uploadError(err, cfg.Messages.DiagnosticsFailed, "uploading the diagnostics of %s for user %s", name, event.User)
+	}
+
+	log.Printf("Uploaded the diagnostics of cluster %s/%s for %s (%d of %d parts)", cluster.Namespace, name, event.User, len(parts)-len(missing), len(parts))
+	if channel != event.Channel {
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.DiagnosticsSent, messages.Data{"User": event.User, "Name": name}))
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// unzip returns the files of the zip archive by name.
+func unzip(t *testing.T, archive []byte) map[string]string {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("reading the bundle: %v", err)
+	}
+	files := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("reading %s: %v", file.Name, err)
+		}
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+func TestDiagnosticsBundle(t *testing.T) {
+	bundle, err := diagnosticsBundle("spoticus-k8s-zip", []diagnosticsPart{
+		{Name: "status.txt", Content: []byte("ready\n")},
+		{Name: "events.txt", Err: errors.New("events is forbidden")},
+		{Name: "operator.log", Content: []byte("# pod\n")},
+	})
+	if err != nil {
+		t.Fatalf("diagnosticsBundle: %v", err)
+	}
+
+	files := unzip(t, bundle)
+	want := map[string]string{
+		"spoticus-k8s-zip/status.txt":   "ready\n",
+		"spoticus-k8s-zip/operator.log": "# pod\n",
+		"spoticus-k8s-zip/ERRORS.txt":   "These parts could not be collected:\n\nevents.txt: events is forbidden\n",
+	}
+	if len(files) != len(want) {
+		t.Errorf("bundle files %v, want %v", files, want)
+	}
+	for name, content := range want {
+		if files[name] != content {
+			t.Errorf("%s = %q, want %q", name, files[name], content)
+		}
+	}
+
+	complete, err := diagnosticsBundle("spoticus-k8s-zip", []diagnosticsPart{{Name: "status.txt", Content: []byte("ready\n")}})
+	if err != nil {
+		t.Fatalf("diagnosticsBundle: %v", err)
+	}
+	if _, ok := unzip(t, complete)["spoticus-k8s-zip/"+diagnosticsErrorsFile]; ok {
+		t.Errorf("complete bundle has an %s", diagnosticsErrorsFile)
+	}
+}
+
+// clusterEvent is an event about the cluster that last happened at.
+func clusterEvent(cluster, name, reason string, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Name: cluster, Namespace: "default"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " happened",
+		LastTimestamp:  metav1.NewTime(at),
+		Count:          2,
+	}
+}
+
+func TestCollectDiagnostics(t *testing.T) {
+	cfg := testConfig()
+	useConfig(t, cfg)
+	name := "spoticus-k8s-diagnosed"
+	kube := slacktest.NewKube(existingCluster(name, "U1", 0))
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	for _, event := range []*corev1.Event{
+		clusterEvent(name, "second", "SpotInterrupted", at.Add(time.Minute)),
+		clusterEvent(name, "first", "ProvisionStarted", at),
+	} {
+		if _, err := kube.KubeClient.CoreV1().Events("default").Create(context.Background(), event, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("creating event %s: %v", event.Name, err)
+		}
+	}
+	operator := operatorDeployment("quay.io/redhat-developer/mapt-operator:v0.9.0", 1)
+	operator.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"control-plane": "controller-manager"}}
+	if _, err := kube.KubeClient.AppsV1().Deployments(operator.Namespace).Create(context.Background(), operator, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("creating the operator deployment: %v", err)
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mapt-controller-0", Namespace: operator.Namespace, Labels: map[string]string{"control-plane": "controller-manager"}}}
+	if _, err := kube.KubeClient.CoreV1().Pods(operator.Namespace).Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("creating the operator pod: %v", err)
+	}
+	client := &KubernetesClients{KubeClient: kube.KubeClient, CrClient: kube.CrClient, DynamicClient: kube.DynamicClient}
+	cluster, err := findCluster(context.Background(), client, name)
+	if err != nil {
+		t.Fatalf("findCluster: %v", err)
+	}
+
+	parts := collectDiagnostics(context.Background(), cfg, client, cluster)
+	var names []string
+	content := map[string]string{}
+	for _, part := range parts {
+		if part.Err != nil {
+			t.Errorf("collecting %s: %v", part.Name, part.Err)
+		}
+		names = append(names, part.Name)
+		content[part.Name] = string(part.Content)
+	}
+	if want := []string{"status.txt", "resource.yaml", "events.txt", "operator.log"}; !slices.Equal(names, want) {
+		t.Fatalf("parts %v, want %v", names, want)
+	}
+	if !strings.Contains(content["status.txt"], name) {
+		t.Errorf("status.txt %q does not describe the cluster", content["status.txt"])
+	}
+	if !strings.Contains(content["resource.yaml"], "name: "+name) || strings.Contains(content["resource.yaml"], "managedFields") {
+		t.Errorf("resource.yaml %q, want the resource without its managed fields", content["resource.yaml"])
+	}
+	wantEvents := "2026-03-04T05:06:07Z\tWarning\tProvisionStarted\tProvisionStarted happened (x2)\n" +
+		"2026-03-04T05:07:07Z\tWarning\tSpotInterrupted\tSpotInterrupted happened (x2)\n"
+	if content["events.txt"] != wantEvents {
+		t.Errorf("events.txt = %q, want %q", content["events.txt"], wantEvents)
+	}
+	if !strings.HasPrefix(content["operator.log"], "# mapt-controller-0\n") {
+		t.Errorf("operator.log = %q, want the operator pod's section", content["operator.log"])
+	}
+}
+
+func TestHandleDiagnosticsUploadsPartialBundle(t *testing.T) {
+	useConfig(t, testConfig())
+	name := "spoticus-k8s-support"
+	useKube(t, slacktest.NewKube(existingCluster(name, "U1", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleDiagnostics(context.Background(), api, message("UADMIN", "C1", "diagnostics "+name), []string{name}); err != nil {
+		t.Fatalf("HandleDiagnostics: %v", err)
+	}
+	values := exportComment(t, server)
+	if got := values.Get("channel_id"); got != "DUADMIN" {
+		t.Errorf("diagnostics uploaded to %q, want the requester's direct message", got)
+	}
+	// No operator is deployed, so its logs cannot be collected.
+	if comment := values.Get("initial_comment"); !strings.Contains(comment, "*Diagnostics of "+name+"*") || !strings.Contains(comment, "Could not collect `operator.log`") {
+		t.Errorf("diagnostics comment %q, want the missing operator log reported", comment)
+	}
+	uploads := server.Calls("files.getUploadURLExternal")
+	if len(uploads) != 1 || !strings.HasPrefix(uploads[0].Values.Get("filename"), name+"-diagnostics-") || !strings.HasSuffix(uploads[0].Values.Get("filename"), ".zip") {
+		t.Errorf("uploads %+v, want the cluster's zip", uploads)
+	}
+	server.WaitForMessage("<@UADMIN> the diagnostics of *"+name+"* were sent to you in a direct message", time.Second)
+}
+
+func TestHandleDiagnosticsRejectsArguments(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube())
+	api, _ := newAPI(t)
+
+	err := HandleDiagnostics(context.Background(), api, message("UADMIN", "C1", "diagnostics"), nil)
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Usage: `diagnostics <cluster_name>`") {
+		t.Errorf("HandleDiagnostics() without a cluster = %v, want the usage", err)
+	}
+	if err := HandleDiagnostics(context.Background(), api, message("UADMIN", "C1", "diagnostics spoticus-k8s-missing"), []string{"spoticus-k8s-missing"}); err == nil {
+		t.Error("HandleDiagnostics() of a missing cluster succeeded")
+	}
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// errClusterNotFound is returned by findCluster when no cluster has the requested name.
+var errClusterNotFound = errors.New("cluster not found")
+
+// findCluster returns the cluster with the given name, whatever its type.
+func findCluster(ctx context.Context, client *KubernetesClients, name string) (ClusterInfo, error) {
+	inventory, err := listClusters(ctx, client)
+	for _, cluster := range inventory.Clusters {
+		if cluster.Name == name {
+			return cluster, nil
+		}
+	}
+	if err != nil {
+		return ClusterInfo{}, err
+	}
+	return ClusterInfo{}, errClusterNotFound
+}
+
+// HandleDiff is the entry point for the "diff" Slack command.
+//
+// It previews what resizing a cluster to another size would change,
+// without applying anything.
+func HandleDiff(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) < 2 {
+		return invalid(cfg.Messages.DiffUsage)
+	}
+	name, size := args[0], strings.ToLower(args[1])
+	tracing.SetCluster(ctx, name)
+
+	proposed, ok := cfg.Sizes[size]
+	if !ok {
+		return invalid(messages.Render(cfg.Messages.InvalidSize, messages.Data{
+			"Size":  size,
+			"Sizes": formatSupportedSizes(),
+		}))
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	cluster, err := findCluster(ctx, client, name)
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+	if !canOperate(cfg, event.User, cluster) {
+		return clusterAccessDenied(event.User, "diff", cluster)
+	}
+
+	currentSize := cluster.Metadata().Size
+	current, ok := cfg.Sizes[currentSize]
+	if !ok {
+		return invalid(messages.Render(cfg.Messages.DiffUnknownSize, messages.Data{"Name": name}))
+	}
+
+	respond.Text(api, event.Channel, formatSizeDiff(cfg.Messages, name, currentSize, size, current, proposed))
+	return nil
+}
+
+// formatSizeDiff renders the before→after comparison of two size specifications.
+func formatSizeDiff(templates messages.Messages, name, fromSize, toSize string, from, to spoticusConfig.SizeSpec) string {
+	var b strings.Builder
+	b.WriteString(messages.Render(templates.DiffHeader, messages.Data{"Name": name, "From": fromSize, "To": toSize}))
+
+	line := func(field, before, after, delta string) {
+		b.WriteString(messages.Render(templates.DiffLine, messages.Data{
+			"Field":  field,
+			"Before": before,
+			"After":  after,
+			"Delta":  delta,
+		}))
+	}
+	line("CPU", from.CPU(), to.CPU(), signed(float64(to.CPUs-from.CPUs), "%+.0f"))
+	line("Memory", from.RAM(), to.RAM(), signed(float64(to.MemoryGB-from.MemoryGB), "%+.0f GB"))
+	line("Nodes", fmt.Sprint(from.Nodes), fmt.Sprint(to.Nodes), signed(float64(to.Nodes-from.Nodes), "%+.0f"))
+	costDelta := "unchanged"
+	if delta := to.HourlyCost - from.HourlyCost; delta != 0 {
+		costDelta = formatHourlyCost(delta)
+		if delta > 0 {
+			costDelta = "+" + costDelta
+		}
+	}
+	line("Cost", formatHourlyCost(from.HourlyCost), formatHourlyCost(to.HourlyCost), costDelta)
+	if isDownscale(from, to) {
+		b.WriteString(templates.DiffDownscale)
+	}
+	return b.String()
+}
+
+// isDownscale reports whether going from one size to the other removes CPUs,
+// memory or nodes, which may disrupt the workloads running on the cluster.
+func isDownscale(from, to spoticusConfig.SizeSpec) bool {
+	return to.CPUs < from.CPUs || to.MemoryGB < from.MemoryGB || to.Nodes < from.Nodes
+}
+
+// signed formats a delta with an explicit sign, or "unchanged" when it is zero.
+func signed(delta float64, format string) string {
+	if delta == 0 {
+		return "unchanged"
+	}
+	return fmt.Sprintf(format, delta)
+}
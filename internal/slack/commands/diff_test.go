@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestFormatSizeDiff(t *testing.T) {
+	cfg := testConfig()
+	useConfig(t, cfg)
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+		downsize bool
+	}{
+		{
+			name: "upscale",
+			from: "medium", to: "large",
+			want: []string{
+				"*Preview for c1* (medium → large, nothing applied)\n",
+				"• CPU: 8 CPUs → 16 CPUs (+8)\n",
+				"• Memory: 32 GB RAM → 64 GB RAM (+32 GB)\n",
+				"• Nodes: 1 → 1 (unchanged)\n",
+				"• Cost: $0.15/h → $0.30/h (+$0.15/h)\n",
+			},
+		},
+		{
+			name: "downscale",
+			from: "xlarge", to: "medium",
+			want: []string{
+				"• CPU: 32 CPUs → 8 CPUs (-24)\n",
+				"• Memory: 128 GB RAM → 32 GB RAM (-96 GB)\n",
+				"• Cost: $0.60/h → $0.15/h (-$0.45/h)\n",
+			},
+			downsize: true,
+		},
+		{
+			name: "same size",
+			from: "large", to: "large",
+			want: []string{
+				"• CPU: 16 CPUs → 16 CPUs (unchanged)\n",
+				"• Cost: $0.30/h → $0.30/h (unchanged)\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatSizeDiff(cfg.Messages, "c1", tt.from, tt.to, cfg.Sizes[tt.from], cfg.Sizes[tt.to])
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("diff does not contain %q:\n%s", want, got)
+				}
+			}
+			if warned := strings.Contains(got, "downscale"); warned != tt.downsize {
+				t.Errorf("downscale warning shown = %v, want %v:\n%s", warned, tt.downsize, got)
+			}
+		})
+	}
+}
+
+func TestIsDownscale(t *testing.T) {
+	base := spoticusConfig.SizeSpec{CPUs: 8, MemoryGB: 32, Nodes: 2, HourlyCost: 0.30}
+	tests := []struct {
+		name string
+		to   spoticusConfig.SizeSpec
+		want bool
+	}{
+		{name: "same", to: base},
+		{name: "more of everything", to: spoticusConfig.SizeSpec{CPUs: 16, MemoryGB: 64, Nodes: 3}},
+		{name: "cheaper only", to: spoticusConfig.SizeSpec{CPUs: 8, MemoryGB: 32, Nodes: 2, HourlyCost: 0.10}},
+		{name: "fewer CPUs", to: spoticusConfig.SizeSpec{CPUs: 4, MemoryGB: 64, Nodes: 3}, want: true},
+		{name: "less memory", to: spoticusConfig.SizeSpec{CPUs: 16, MemoryGB: 16, Nodes: 3}, want: true},
+		{name: "fewer nodes", to: spoticusConfig.SizeSpec{CPUs: 16, MemoryGB: 64, Nodes: 1}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDownscale(base, tt.to); got != tt.want {
+				t.Errorf("isDownscale(%+v, %+v) = %v, want %v", base, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffPreviewsWithoutApplying(t *testing.T) {
+	useConfig(t, testConfig())
+	cluster := existingCluster("spoticus-k8s-diff", "U1", 0.15)
+	SetLaunchMetadata(cluster, LaunchMetadata{Size: "medium"})
+	useKube(t, slacktest.NewKube(cluster))
+	api, server := newAPI(t)
+
+	if err := HandleDiff(context.Background(), api, message("U1", "C1", "diff spoticus-k8s-diff large"), []string{"spoticus-k8s-diff", "LARGE"}); err != nil {
+		t.Fatalf("HandleDiff: %v", err)
+	}
+	server.WaitForMessage("(medium → large, nothing applied)", time.Second)
+}
+
+func TestDiffErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing size", args: []string{"spoticus-k8s-diff"}, wantErr: "Usage"},
+		{name: "unknown size", args: []string{"spoticus-k8s-diff", "huge"}, wantErr: "huge"},
+		{name: "unknown cluster", args: []string{"spoticus-k8s-nope", "large"}, wantErr: "not found"},
+		{name: "unknown current size", args: []string{"spoticus-k8s-diff", "large"}, wantErr: "current size of *spoticus-k8s-diff* is unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, testConfig())
+			useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-diff", "U1", 0.15)))
+			api, _ := newAPI(t)
+
+			err := HandleDiff(context.Background(), api, message("U1", "C1", "diff"), tt.args)
+			var cmdErr *CommandError
+			if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, tt.wantErr) {
+				t.Errorf("HandleDiff() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// recentDeletionWindow is how long a deleted cluster is remembered, so that a
+// repeated `done` reports it as already removed rather than unknown.
+const recentDeletionWindow = 10 * time.Minute
+
+var (
+	recentDeletionsMu sync.Mutex
+	// recentDeletions maps the names of the clusters deleted with `done` to
+	// when they were deleted.
+	recentDeletions = map[string]time.Time{}
+)
+
+// rememberDeletion records that the cluster was deleted at now, forgetting
+// deletions older than recentDeletionWindow.
+func rememberDeletion(name string, now time.Time) {
+	recentDeletionsMu.Lock()
+	defer recentDeletionsMu.Unlock()
+	for deleted, at := range recentDeletions {
+		if now.Sub(at) > recentDeletionWindow {
+			delete(recentDeletions, deleted)
+		}
+	}
+	recentDeletions[name] = now
+}
+
+// deletedRecently reports whether the cluster was deleted with `done` within
+// recentDeletionWindow of now.
+func deletedRecently(name string, now time.Time) bool {
+	recentDeletionsMu.Lock()
+	defer recentDeletionsMu.Unlock()
+	at, ok := recentDeletions[name]
+	return ok && now.Sub(at) <= recentDeletionWindow
+}
+
+// HandleDone is the entry point for the "done" Slack command.
+//
+// It deletes the named cluster once the user is finished with it. Clusters
+// belonging to a team may only be deleted by its members or an admin.
+//
+// When a deleteGracePeriod is configured, the cluster is only marked for
+// deletion and deleted once the period is over, unless its deletion is
+// cancelled with `cancel-delete`; `--now` deletes it right away.
+//
+// `done --all` deletes every cluster of the user, once confirmed with a
+// challenge phrase.
+//
+// It is safe to repeat: a cluster that is already being deleted, or that was
+// deleted in the meantime, is reported as removed rather than as an error.
+func HandleDone(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) > 0 && strings.EqualFold(args[0], "--all") {
+		return handleDoneAll(ctx, api, event, args[1:])
+	}
+	immediate := false
+	if len(args) == 2 && args[1] == "--now" {
+		immediate, args = true, args[:1]
+	}
+	if len(args) != 1 {
+		return invalid(cfg.Messages.DoneUsage)
+	}
+	name := args[0]
+	tracing.SetCluster(ctx, name)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	cluster, err := findCluster(ctx, client, name)
+	if errors.Is(err, errClusterNotFound) && deletedRecently(name, time.Now()) {
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.DoneAlreadyRemoved, messages.Data{"Name": name}))
+		return nil
+	}
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+	if !canOperate(cfg, event.User, cluster) {
+		return clusterAccessDenied(event.User, "done", cluster)
+	}
+
+	if cluster.Deleting {
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.DoneAlreadyDeleting, messages.Data{"Name": name}))
+		return nil
+	}
+
+	if grace := time.Duration(cfg.DeleteGracePeriod); grace > 0 && !immediate {
+		data := messages.Data{"Name": name, "User": event.User}
+		if at := cluster.DeleteAt(); !at.IsZero() {
+			data["DeleteAt"] = formatAge(time.Since(at))
+			respond.Text(api, event.Channel, messages.Render(cfg.Messages.DoneAlreadyScheduled, data))
+			return nil
+		}
+		at := time.Now().Add(grace)
+		if err := scheduleDeletion(ctx, api, client, cluster, event.Channel, at); err != nil {
+			return failure(err, cfg.Messages.DoneFailed, "scheduling the deletion of MAPT cluster %s/%s", cluster.Namespace, cluster.Name)
+		}
+		log.Printf("Cluster %s/%s marked for deletion at %s by %s", cluster.Namespace, cluster.Name, at.Format(time.RFC3339), event.User)
+		data["DeleteAt"] = formatAge(-grace)
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.DoneScheduled, data))
+		notifyOwner(api, cluster, event.User, messages.Render(cfg.Messages.DoneScheduledOwner, data))
+		return nil
+	}
+
+	disarmDeletion(cluster)
+	err = deleteCluster(ctx, client, cluster)
+	if err == nil || apierrors.IsNotFound(err) {
+		stopLaunchWatcher(name, event.User)
+	}
+	switch {
+	case apierrors.IsNotFound(err):
+		log.Printf("Cluster %s/%s was already deleted when %s ran done", cluster.Namespace, cluster.Name, event.User)
+		rememberDeletion(name, time.Now())
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.DoneAlreadyRemoved, messages.Data{"Name": name}))
+		return nil
+	case err != nil:
+		return failure(err, cfg.Messages.DoneFailed, "deleting MAPT cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	log.Printf("Cluster %s/%s deleted by %s", cluster.Namespace, cluster.Name, event.User)
+	rememberDeletion(name, time.Now())
+	notify(api, clusterNotification(EventDeleted, cluster, event.User))
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.DoneConfirm, messages.Data{"Name": name}))
+	return nil
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+var (
+	pendingDoneAllMu sync.Mutex
+	// pendingDoneAll holds the latest unconfirmed `done --all` selection per
+	// requesting user, within the workspace it was made in.
+	pendingDoneAll = map[string]pendingCleanup{}
+)
+
+// handleDoneAll implements `done --all`. It selects the clusters the user
+// owns and replies with a challenge phrase made of their number and a random
+// token; `done --all confirm <phrase>` then deletes them, within the same
+// window as `cleanup`. A wrong phrase discards the selection. Confirmed bulk
+// deletions are immediate, whatever the deleteGracePeriod.
+func handleDoneAll(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) > 0 {
+		if !strings.EqualFold(args[0], "confirm") {
+			return invalid(cfg.Messages.DoneUsage)
+		}
+		return confirmDoneAll(ctx, api, event, strings.Join(args[1:], " "))
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+	inventory, err := listClusters(ctx, client)
+	if err != nil {
+		return failure(err, cfg.Messages.ListFailed, "listing MAPT clusters for done --all")
+	}
+
+	owner := cfg.OwnerFor(event.User)
+	var selected []ClusterInfo
+	var entries strings.Builder
+	for _, cluster := range inventory.Clusters {
+		if cluster.Deleting || cluster.Metadata().Owner != owner {
+			continue
+		}
+		selected = append(selected, cluster)
+		entries.WriteString(messages.Render(cfg.Messages.DoneAllEntry, messages.Data{
+			"Name": cluster.Name,
+			"Type": clusterTypeNames[cluster.Type],
+		}))
+	}
+	if len(selected) == 0 {
+		respond.Text(api, event.Channel, cfg.Messages.DoneAllNone)
+		return nil
+	}
+
+	phrase := newConfirmPhrase(len(selected))
+	pendingDoneAllMu.Lock()
+	pendingDoneAll[tenantScoped(ctx, event.User)] = pendingCleanup{clusters: selected, expires: time.Now().Add(cleanupConfirmWindow), phrase: phrase}
+	pendingDoneAllMu.Unlock()
+
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.DoneAllSelection, messages.Data{
+		"Count":   len(selected),
+		"Entries": entries.String(),
+		"Window":  cleanupConfirmWindow,
+		"Phrase":  phrase,
+	}))
+	return nil
+}
+
+// confirmDoneAll deletes the clusters selected by the user's last
+// `done --all` in the workspace, if it has not expired and the phrase
+// matches its challenge.
+func confirmDoneAll(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, phrase string) error {
+	pendingDoneAllMu.Lock()
+	key := tenantScoped(ctx, event.User)
+	pending, ok := pendingDoneAll[key]
+	delete(pendingDoneAll, key)
+	pendingDoneAllMu.Unlock()
+
+	if !ok || time.Now().After(pending.expires) {
+		return invalid(msgs().DoneAllNoPending)
+	}
+	if !confirmPhraseMatches(pending.phrase, phrase) {
+		log.Printf("done --all of %d clusters aborted: %s gave a wrong confirmation phrase", len(pending.clusters), event.User)
+		return invalid(messages.Render(msgs().DoneAllWrongPhrase, messages.Data{"Count": len(pending.clusters)}))
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	deleted, failed := 0, 0
+	for _, cluster := range pending.clusters {
+		disarmDeletion(cluster)
+		if err := deleteCluster(ctx, client, cluster); err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("Error deleting MAPT cluster %s/%s for done --all: %v", cluster.Namespace, cluster.Name, err)
+			failed++
+			continue
+		}
+		stopLaunchWatcher(cluster.Name, event.User)
+		rememberDeletion(cluster.Name, time.Now())
+		deleted++
+		notify(api, clusterNotification(EventDeleted, cluster, event.User))
+	}
+
+	log.Printf("done --all confirmed by %s: %d deleted, %d failed", event.User, deleted, failed)
+	respond.Text(api, event.Channel, messages.Render(msgs().DoneAllComplete, messages.Data{
+		"Deleted": deleted,
+		"Failed":  failed,
+	}))
+	return nil
+}
@@ -0,0 +1,284 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestDoneDeletesCluster(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-done", "U1", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("U1", "C1", "done spoticus-k8s-done"), []string{"spoticus-k8s-done"}); err != nil {
+		t.Fatalf("HandleDone: %v", err)
+	}
+	server.WaitForMessage("spoticus-k8s-done", time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Errorf("got %d clusters after done, want none", len(kinds))
+	}
+}
+
+func TestDoneStopsLaunchWatcher(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-watched", "U1", 0))
+	useKube(t, kube)
+	api, _ := newAPI(t)
+	watched, unwatch := watchLaunch(context.Background(), "spoticus-k8s-watched")
+	defer unwatch()
+
+	if err := HandleDone(context.Background(), api, message("U1", "C1", "done spoticus-k8s-watched"), []string{"spoticus-k8s-watched"}); err != nil {
+		t.Fatalf("HandleDone: %v", err)
+	}
+
+	var cancelled launchCancelled
+	if !errors.As(context.Cause(watched), &cancelled) || cancelled.User != "U1" {
+		t.Errorf("launch watcher cause = %v, want it stopped on behalf of U1", context.Cause(watched))
+	}
+	if stopLaunchWatcher("spoticus-k8s-watched", "U1") {
+		t.Errorf("launch watcher still registered after done")
+	}
+}
+
+// confirmPhraseIn returns the confirmation phrase a `done --all` reply asks
+// for.
+func confirmPhraseIn(t *testing.T, text string) string {
+	t.Helper()
+	match := regexp.MustCompile("done --all confirm (delete-[0-9]+-[a-z0-9]+)").FindStringSubmatch(text)
+	if match == nil {
+		t.Fatalf("no confirmation phrase in %q", text)
+	}
+	return match[1]
+}
+
+func TestDoneAllCorrectPhraseProceeds(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKube(
+		existingCluster("spoticus-k8s-mine1", "UALL", 0),
+		existingCluster("spoticus-k8s-mine2", "UALL", 0),
+		existingCluster("spoticus-k8s-theirs", "UOTHER", 0),
+	)
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("UALL", "C1", "done --all"), []string{"--all"}); err != nil {
+		t.Fatalf("done --all: %v", err)
+	}
+	selection := server.WaitForMessage("2 clusters of yours would be deleted", time.Second)
+	if strings.Contains(selection.Text(), "spoticus-k8s-theirs") {
+		t.Errorf("selection %q includes another user's cluster", selection.Text())
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 3 {
+		t.Fatalf("got %d clusters before the confirmation, want all 3 kept", len(kinds))
+	}
+
+	phrase := confirmPhraseIn(t, selection.Text())
+	if err := HandleDone(context.Background(), api, message("UALL", "C1", "done --all confirm "+phrase), []string{"--all", "confirm", phrase}); err != nil {
+		t.Fatalf("done --all confirm: %v", err)
+	}
+	server.WaitForMessage("2 clusters deleted", time.Second)
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 || kinds[0].Name != "spoticus-k8s-theirs" {
+		t.Errorf("clusters left %v, want only the other user's", kinds)
+	}
+}
+
+func TestDoneAllWrongPhraseAborts(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-kept", "UWRONG", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("UWRONG", "C1", "done --all"), []string{"--all"}); err != nil {
+		t.Fatalf("done --all: %v", err)
+	}
+	phrase := confirmPhraseIn(t, server.WaitForMessage("would be deleted", time.Second).Text())
+
+	err := HandleDone(context.Background(), api, message("UWRONG", "C1", "done --all confirm yes"), []string{"--all", "confirm", "yes"})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "not the confirmation phrase") {
+		t.Fatalf("done --all confirm yes = %v, want the wrong phrase error", err)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters after a wrong phrase, want the cluster kept", len(kinds))
+	}
+
+	// The wrong phrase discarded the challenge: the right one no longer works.
+	err = HandleDone(context.Background(), api, message("UWRONG", "C1", "done --all confirm "+phrase), []string{"--all", "confirm", phrase})
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Nothing to confirm") {
+		t.Errorf("confirming after an abort = %v, want nothing to confirm", err)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want the cluster kept", len(kinds))
+	}
+}
+
+func TestDoneAllKeepsTenantScope(t *testing.T) {
+	cfg := testConfig()
+	cfg.TenantIsolation = true
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(
+		tenantCluster("spoticus-k8s-mine-a", "UTENANT", "TA"),
+		tenantCluster("spoticus-k8s-mine-b", "UTENANT", "TB"),
+		tenantCluster("spoticus-k8s-theirs-a", "UOTHER", "TA"),
+	)
+	useKube(t, kube)
+	api, server := newAPI(t)
+	ctxA, _ := WithTeam(context.Background(), "TA")
+	ctxB, _ := WithTeam(context.Background(), "TB")
+
+	if err := HandleDone(ctxA, api, message("UTENANT", "C1", "done --all"), []string{"--all"}); err != nil {
+		t.Fatalf("done --all: %v", err)
+	}
+	selection := server.WaitForMessage("1 cluster of yours would be deleted", time.Second).Text()
+	if !strings.Contains(selection, "spoticus-k8s-mine-a") || strings.Contains(selection, "spoticus-k8s-mine-b") || strings.Contains(selection, "spoticus-k8s-theirs-a") {
+		t.Errorf("selection %q, want only the caller's cluster of workspace A", selection)
+	}
+	phrase := confirmPhraseIn(t, selection)
+
+	// The selection of workspace A cannot be confirmed from workspace B.
+	err := HandleDone(ctxB, api, message("UTENANT", "C1", "done --all confirm "+phrase), []string{"--all", "confirm", phrase})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Nothing to confirm") {
+		t.Fatalf("confirming from workspace B = %v, want nothing to confirm", err)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 3 {
+		t.Fatalf("got %d clusters after confirming from workspace B, want all 3 kept", len(kinds))
+	}
+
+	if err := HandleDone(ctxA, api, message("UTENANT", "C1", "done --all confirm "+phrase), []string{"--all", "confirm", phrase}); err != nil {
+		t.Fatalf("done --all confirm: %v", err)
+	}
+	server.WaitForMessage("1 cluster deleted", time.Second)
+	var left []string
+	for _, kind := range launchedKinds(t, kube) {
+		left = append(left, kind.Name)
+	}
+	if !slices.Equal(left, []string{"spoticus-k8s-mine-b", "spoticus-k8s-theirs-a"}) {
+		t.Errorf("clusters left %v, want the caller's cluster of workspace B and the other user's", left)
+	}
+}
+
+func TestDoneAllWithoutClusters(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-theirs", "UOTHER", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("UNONE", "C1", "done --all"), []string{"--all"}); err != nil {
+		t.Fatalf("done --all: %v", err)
+	}
+	server.WaitForMessage("no clusters to delete", time.Second)
+}
+
+// forgetDeletion drops the remembered deletion of the cluster when the test
+// ends.
+func forgetDeletion(t *testing.T, name string) {
+	t.Helper()
+	t.Cleanup(func() {
+		recentDeletionsMu.Lock()
+		delete(recentDeletions, name)
+		recentDeletionsMu.Unlock()
+	})
+}
+
+func TestRecentDeletionWindow(t *testing.T) {
+	forgetDeletion(t, "spoticus-k8s-window")
+	forgetDeletion(t, "spoticus-k8s-stale")
+	now := time.Now()
+
+	rememberDeletion("spoticus-k8s-stale", now.Add(-2*recentDeletionWindow))
+	rememberDeletion("spoticus-k8s-window", now)
+
+	if !deletedRecently("spoticus-k8s-window", now.Add(recentDeletionWindow)) {
+		t.Error("deletion not remembered within the window")
+	}
+	if deletedRecently("spoticus-k8s-window", now.Add(recentDeletionWindow+time.Second)) {
+		t.Error("deletion remembered past the window")
+	}
+	recentDeletionsMu.Lock()
+	_, stale := recentDeletions["spoticus-k8s-stale"]
+	recentDeletionsMu.Unlock()
+	if stale {
+		t.Error("stale deletion not forgotten")
+	}
+}
+
+func TestDoneTwiceReportsAlreadyRemoved(t *testing.T) {
+	useConfig(t, testConfig())
+	forgetDeletion(t, "spoticus-k8s-twice")
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-twice", "U1", 0)))
+	api, server := newAPI(t)
+	done := message("U1", "C1", "done spoticus-k8s-twice")
+
+	for range 2 {
+		if err := HandleDone(context.Background(), api, done, []string{"spoticus-k8s-twice"}); err != nil {
+			t.Fatalf("HandleDone: %v", err)
+		}
+	}
+
+	server.WaitForMessage("Deleting *spoticus-k8s-twice*", time.Second)
+	server.WaitForMessage("*spoticus-k8s-twice* has already been removed", time.Second)
+}
+
+func TestDoneMissingCluster(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube())
+	api, _ := newAPI(t)
+
+	err := HandleDone(context.Background(), api, message("U1", "C1", "done spoticus-k8s-never"), []string{"spoticus-k8s-never"})
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "spoticus-k8s-never") {
+		t.Errorf("HandleDone() = %v, want the cluster reported not found", err)
+	}
+}
+
+func TestDoneDeletedConcurrently(t *testing.T) {
+	useConfig(t, testConfig())
+	forgetDeletion(t, "spoticus-k8s-raced")
+	useKube(t, slacktest.NewKubeWithInterceptor(interceptor.Funcs{
+		Delete: func(ctx context.Context, client crclient.WithWatch, obj crclient.Object, opts ...crclient.DeleteOption) error {
+			return apierrors.NewNotFound(schema.GroupResource{Group: "mapt.redhat.com", Resource: "kinds"}, obj.GetName())
+		},
+	}, existingCluster("spoticus-k8s-raced", "U1", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("U1", "C1", "done spoticus-k8s-raced"), []string{"spoticus-k8s-raced"}); err != nil {
+		t.Fatalf("HandleDone() = %v, want the deleted cluster reported removed", err)
+	}
+	server.WaitForMessage("*spoticus-k8s-raced* has already been removed", time.Second)
+	if !deletedRecently("spoticus-k8s-raced", time.Now()) {
+		t.Error("concurrent deletion not remembered")
+	}
+}
+
+func TestDoneClusterBeingDeleted(t *testing.T) {
+	useConfig(t, testConfig())
+	cluster := existingCluster("spoticus-k8s-finalizing", "U1", 0)
+	cluster.SetFinalizers([]string{"mapt.redhat.com/cleanup"})
+	cluster.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	kube := slacktest.NewKube(cluster)
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("U1", "C1", "done spoticus-k8s-finalizing"), []string{"spoticus-k8s-finalizing"}); err != nil {
+		t.Fatalf("HandleDone: %v", err)
+	}
+	server.WaitForMessage("*spoticus-k8s-finalizing* is already being deleted", time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want the one being finalized left alone", len(kinds))
+	}
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+
+	"github.com/flacatus/spoticus/internal/kube"
+)
+
+// errorClass categorizes a failed backend operation.
+type errorClass int
+
+const (
+	// classFailed is a genuine error returned by the API server.
+	classFailed errorClass = iota
+	// classTimeout is a request that ran out of time, on our side or the server's.
+	classTimeout
+	// classCanceled is a request that was cancelled before it completed.
+	classCanceled
+	// classUnavailable is a request refused by the open circuit breaker.
+	classUnavailable
+	// classMaptMissing is a request for a MAPT kind the cluster does not serve.
+	classMaptMissing
+)
+
+// String describes the class in log messages.
+func (c errorClass) String() string {
+	switch c {
+	case classTimeout:
+		return "timed out"
+	case classCanceled:
+		return "cancelled"
+	case classUnavailable:
+		return "backend unavailable"
+	case classMaptMissing:
+		return "MAPT not installed"
+	default:
+		return "failed"
+	}
+}
+
+// classifyError tells timeouts and cancellations apart from genuine API errors.
+func classifyError(err error) errorClass {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err):
+		return classTimeout
+	case errors.Is(err, context.Canceled):
+		return classCanceled
+	case errors.Is(err, ErrBackendUnavailable):
+		return classUnavailable
+	case meta.IsNoMatchError(err):
+		return classMaptMissing
+	default:
+		return classFailed
+	}
+}
+
+// backendError picks the message to show for a failed backend operation.
+//
+// Failures with a well-known cause get a message explaining it: the request
+// timing out or being cancelled, the circuit breaker being open, or the MAPT
+// CRDs not being installed in the target cluster ("no matches for kind"
+// errors). Anything else gets the fallback.
+//
+// The error is also recorded for the `errors` command.
+func backendError(err error, fallback string) string {
+	message := fallback
+	class := classifyError(err)
+	switch class {
+	case classTimeout:
+		message = msgs().RequestTimedOut
+	case classCanceled:
+		message = msgs().RequestCanceled
+	case classUnavailable:
+		message = msgs().BackendUnavailable
+	case classMaptMissing:
+		message = msgs().MaptNotInstalled
+	}
+	if err != nil && class != classFailed {
+		log.Printf("Kubernetes request %s: %v", class, err)
+	}
+	RecordError(message, err)
+	return message
+}
+
+// CheckMaptInstalled verifies that the target cluster serves the MAPT
+// resources the bot creates, so a missing operator is reported at startup
+// rather than on the first command.
+func CheckMaptInstalled(restConfig *rest.Config) error {
+	kinds := make([]string, 0, len(clusterKinds))
+	for _, kind := range clusterKinds {
+		kinds = append(kinds, kind)
+	}
+	if err := kube.CheckAPIResources(restConfig, maptApi.GroupVersion.String(), kinds...); err != nil {
+		return fmt.Errorf("the MAPT operator doesn't appear to be installed in the target cluster: %w", err)
+	}
+	return nil
+}
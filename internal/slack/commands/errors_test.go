@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// noKindMatch is the error of a request for a MAPT kind the cluster does not
+// serve, as when the operator is not installed.
+var noKindMatch = &meta.NoKindMatchError{
+	GroupKind:        schema.GroupKind{Group: maptApi.GroupVersion.Group, Kind: "Kind"},
+	SearchedVersions: []string{maptApi.GroupVersion.Version},
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want errorClass
+	}{
+		{name: "deadline", err: fmt.Errorf("listing: %w", context.DeadlineExceeded), want: classTimeout},
+		{name: "server timeout", err: apierrors.NewServerTimeout(schema.GroupResource{Resource: "kinds"}, "list", 1), want: classTimeout},
+		{name: "canceled", err: context.Canceled, want: classCanceled},
+		{name: "breaker open", err: ErrBackendUnavailable, want: classUnavailable},
+		{name: "no kind match", err: fmt.Errorf("listing: %w", noKindMatch), want: classMaptMissing},
+		{name: "other", err: errors.New("connection refused"), want: classFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err); got != tt.want {
+				t.Errorf("classifyError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendErrorMessages(t *testing.T) {
+	messages := msgs()
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "deadline", err: fmt.Errorf("listing: %w", context.DeadlineExceeded), want: messages.RequestTimedOut},
+		{name: "server timeout", err: apierrors.NewTimeoutError("listing kinds", 1), want: messages.RequestTimedOut},
+		{name: "canceled", err: fmt.Errorf("listing: %w", context.Canceled), want: messages.RequestCanceled},
+		{name: "breaker open", err: ErrBackendUnavailable, want: messages.BackendUnavailable},
+		{name: "no kind match", err: noKindMatch, want: messages.MaptNotInstalled},
+		{name: "other", err: errors.New("connection refused"), want: "fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useRecentErrors(t)
+			if got := backendError(tt.err, "fallback"); got != tt.want {
+				t.Errorf("backendError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListReportsTimeout(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKubeWithInterceptor(interceptor.Funcs{
+		List: func(ctx context.Context, client crclient.WithWatch, list crclient.ObjectList, opts ...crclient.ListOption) error {
+			return context.DeadlineExceeded
+		},
+	}))
+	useRecentErrors(t)
+	api, _ := newAPI(t)
+
+	err := HandleList(context.Background(), api, message("U1", "C1", "list"), nil)
+	var commandErr *CommandError
+	if !errors.As(err, &commandErr) || !strings.Contains(commandErr.Message, "timed out") {
+		t.Errorf("HandleList() = %v, want the request reported timed out", err)
+	}
+}
+
+func TestListReportsMaptNotInstalled(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKubeWithInterceptor(interceptor.Funcs{
+		List: func(ctx context.Context, client crclient.WithWatch, list crclient.ObjectList, opts ...crclient.ListOption) error {
+			return noKindMatch
+		},
+	}))
+	api, _ := newAPI(t)
+
+	err := HandleList(context.Background(), api, message("U1", "C1", "list"), nil)
+	var commandErr *CommandError
+	if !errors.As(err, &commandErr) || !strings.Contains(commandErr.Message, "doesn't appear to be installed") {
+		t.Errorf("HandleList() = %v, want the MAPT operator reported missing", err)
+	}
+}
+
+func TestLaunchReportsMaptNotInstalled(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKubeWithInterceptor(interceptor.Funcs{
+		Create: func(ctx context.Context, client crclient.WithWatch, obj crclient.Object, opts ...crclient.CreateOption) error {
+			return noKindMatch
+		},
+	}))
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U8", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("doesn't appear to be installed", 5*time.Second)
+}
+
+// discoveryServer serves the discovery of the MAPT group version with the
+// given kinds.
+func discoveryServer(t *testing.T, kinds ...string) *rest.Config {
+	t.Helper()
+	resources := &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: maptApi.GroupVersion.String(),
+	}
+	for _, kind := range kinds {
+		resources.APIResources = append(resources.APIResources, metav1.APIResource{Name: strings.ToLower(kind) + "s", Kind: kind, Namespaced: true})
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/"+maptApi.GroupVersion.String() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resources)
+	}))
+	t.Cleanup(server.Close)
+	return &rest.Config{Host: server.URL}
+}
+
+func TestCheckMaptInstalled(t *testing.T) {
+	tests := []struct {
+		name    string
+		kinds   []string
+		wantErr string
+	}{
+		{name: "installed", kinds: []string{"Kind", "Openshift"}},
+		{name: "kind missing", kinds: []string{"Openshift"}, wantErr: "does not serve Kind"},
+		{name: "not installed", wantErr: "doesn't appear to be installed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckMaptInstalled(discoveryServer(t, tt.kinds...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckMaptInstalled: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckMaptInstalled() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
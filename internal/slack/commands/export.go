@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// exportHeader is the first row of the CSV produced by the "export" command.
+var exportHeader = []string{"name", "type", "size", "owner", "created", "status", "hourly_cost"}
+
+// HandleExport is the entry point for the "export" Slack command.
+//
+// It uploads the cluster inventory as a CSV file to a direct message with the
+// requesting user, or to the channel if the direct message cannot be opened.
+// `export --mine` restricts the file to the clusters the user owns.
+func HandleExport(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	mine := false
+	for _, arg := range args {
+		if arg != "--mine" {
+			return invalid(msgs().ExportUsage)
+		}
+		mine = true
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	inventory, err := listClusters(ctx, client)
+	if len(inventory.Failed) == len(clusterTypeNames) {
+		return failure(err, msgs().ListFailed, "listing MAPT clusters for export")
+	}
+	if err != nil {
+		log.Printf("Error listing MAPT clusters for export: %v", err)
+	}
+
+	clusters := inventory.Clusters
+	if mine {
+		clusters = nil
+		for _, cluster := range inventory.Clusters {
+			if cluster.Metadata().Owner == event.User {
+				clusters = append(clusters, cluster)
+			}
+		}
+	}
+
+	content, err := exportCSV(clusters)
+	if err != nil {
+		return &CommandError{
+			Category: CategoryInternal,
+			Message:  msgs().ExportFailed,
+			Log:      "rendering cluster export: " + err.Error(),
+			Err:      err,
+		}
+	}
+
+	comment := messages.Render(msgs().ExportReady, messages.Data{"Count": len(clusters)})
+	for _, failed := range inventory.Failed {
+		comment += "\n\n" + messages.Render(msgs().ListTypeFailed, messages.Data{"Type": failed})
+	}
+
+	channel := event.Channel
+	if dm, _, _, err := api.OpenConversation(&slack.OpenConversationParameters{Users: []string{event.User}}); err != nil {
+		log.Printf("Error opening a direct message with %s, exporting to %s instead: %v", event.User, channel, err)
+	} else {
+		channel = dm.ID
+	}
+
+	err = upload(ctx, api, slack.UploadFileV2Parameters{
+		Channel:        channel,
+		Content:        content,
+		Filename:       "spoticus-clusters-" + time.Now().UTC().Format("20060102-1504") + ".csv",
+		Title:          "Spoticus cluster inventory",
+		InitialComment: comment,
+	})
+	if err != nil {
+		return uploadError(err, msgs().ExportFailed, "uploading cluster export for user %s", event.User)
+	}
+
+	log.Printf("Exported %d MAPT clusters for user %s (mine=%t)", len(clusters), event.User, mine)
+	if channel != event.Channel {
+		respond.Text(api, event.Channel, messages.Render(msgs().ExportSent, messages.Data{"User": event.User}))
+	}
+	return nil
+}
+
+// exportCSV renders the clusters as CSV, one row per cluster after exportHeader.
+// The size column holds the instance type for clusters launched with one, and
+// the cost is the estimated hourly cost in dollars.
+func exportCSV(clusters []ClusterInfo) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(exportHeader); err != nil {
+		return "", err
+	}
+	for _, cluster := range clusters {
+		metadata := cluster.Metadata()
+		size := metadata.Size
+		if metadata.InstanceType != "" {
+			size = metadata.InstanceType
+		}
+		row := []string{
+			cluster.Name,
+			cluster.Type,
+			size,
+			metadata.Owner,
+			cluster.Created.UTC().Format(time.RFC3339),
+			cluster.Phase,
+			strconv.FormatFloat(metadata.HourlyCost, 'f', 2, 64),
+		}
+		if err := w.Write(row); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestExportCSV(t *testing.T) {
+	created := time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)
+	sized := &metav1.ObjectMeta{}
+	SetLaunchMetadata(sized, LaunchMetadata{Owner: "U1", Size: "medium", HourlyCost: 0.15})
+	typed := &metav1.ObjectMeta{}
+	SetLaunchMetadata(typed, LaunchMetadata{Owner: "U2", InstanceType: "m6i.4xlarge", HourlyCost: 0.768})
+	clusters := []ClusterInfo{
+		{Name: "spoticus-k8s-a", Type: "k8s", Phase: phaseReady, Created: created, Annotations: sized.GetAnnotations()},
+		{Name: "spoticus-openshift-b", Type: "openshift", Phase: phaseProvisioning, Created: created.Add(time.Hour), Annotations: typed.GetAnnotations()},
+	}
+
+	content, err := exportCSV(clusters)
+	if err != nil {
+		t.Fatalf("exportCSV: %v", err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		t.Fatalf("parsing the export: %v", err)
+	}
+	want := [][]string{
+		{"name", "type", "size", "owner", "created", "status", "hourly_cost"},
+		{"spoticus-k8s-a", "k8s", "medium", "U1", "2025-06-01T09:30:00Z", "Ready", "0.15"},
+		{"spoticus-openshift-b", "openshift", "m6i.4xlarge", "U2", "2025-06-01T10:30:00Z", "Provisioning", "0.77"},
+	}
+	if !slices.EqualFunc(rows, want, slices.Equal[[]string]) {
+		t.Errorf("export rows = %q, want %q", rows, want)
+	}
+}
+
+func TestExportCSVEscapesFields(t *testing.T) {
+	meta := &metav1.ObjectMeta{}
+	SetLaunchMetadata(meta, LaunchMetadata{Owner: `U1,"quoted"`})
+
+	content, err := exportCSV([]ClusterInfo{{Name: "spoticus-k8s-a", Annotations: meta.GetAnnotations()}})
+	if err != nil {
+		t.Fatalf("exportCSV: %v", err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		t.Fatalf("parsing the export: %v", err)
+	}
+	if got := rows[1][3]; got != `U1,"quoted"` {
+		t.Errorf("owner = %q, want it round-tripped", got)
+	}
+}
+
+// exportComment returns the comment of the uploaded export.
+func exportComment(t *testing.T, server *slacktest.Server) url.Values {
+	t.Helper()
+	calls := server.Calls("files.completeUploadExternal")
+	if len(calls) != 1 {
+		t.Fatalf("completed %d uploads, want the export", len(calls))
+	}
+	return calls[0].Values
+}
+
+func TestExportUploadsToDirectMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantCount string
+	}{
+		{name: "everything", wantCount: "(3 clusters)"},
+		{name: "mine", args: []string{"--mine"}, wantCount: "(2 clusters)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, testConfig())
+			useKube(t, slacktest.NewKube(
+				existingCluster("spoticus-k8s-a", "U1", 0.15),
+				existingClusterOf("openshift", "spoticus-openshift-a", "U1", 1),
+				existingCluster("spoticus-k8s-b", "U2", 0.15),
+			))
+			api, server := newAPI(t)
+
+			if err := HandleExport(context.Background(), api, message("U1", "C1", "export"), tt.args); err != nil {
+				t.Fatalf("HandleExport: %v", err)
+			}
+			values := exportComment(t, server)
+			if got := values.Get("channel_id"); got != "DU1" {
+				t.Errorf("export uploaded to %q, want the direct message DU1", got)
+			}
+			if comment := values.Get("initial_comment"); !strings.Contains(comment, tt.wantCount) {
+				t.Errorf("export comment %q, want %s", comment, tt.wantCount)
+			}
+			server.WaitForMessage("the export was sent to you in a direct message", time.Second)
+		})
+	}
+}
+
+func TestExportFallsBackToChannel(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-a", "U1", 0.15)))
+	api, server := newAPI(t)
+	server.Handle("conversations.open", func(url.Values) map[string]any {
+		return map[string]any{"ok": false, "error": "cannot_dm_bot"}
+	})
+
+	if err := HandleExport(context.Background(), api, message("U1", "C1", "export"), nil); err != nil {
+		t.Fatalf("HandleExport: %v", err)
+	}
+	if got := exportComment(t, server).Get("channel_id"); got != "C1" {
+		t.Errorf("export uploaded to %q, want the channel C1", got)
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("posted %d messages, want only the upload", len(messages))
+	}
+}
+
+func TestExportRejectsUnknownFlag(t *testing.T) {
+	useConfig(t, testConfig())
+	api, _ := newAPI(t)
+
+	if err := HandleExport(context.Background(), api, message("U1", "C1", "export --all"), []string{"--all"}); CategoryOf(err) != CategoryValidation {
+		t.Errorf("HandleExport() = %v, want the usage", err)
+	}
+}
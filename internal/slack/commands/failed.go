@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"strings"
+	"time"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// Action IDs of the `failed` buttons.
+const (
+	ActionFailedRetry  = "failed_retry"
+	ActionFailedDelete = "failed_delete"
+)
+
+// maxFailedShown bounds the clusters `failed` lists, keeping the message
+// within Slack's limit of 50 blocks (two per cluster).
+const maxFailedShown = 20
+
+// selectFailed returns the failed clusters that are not already being
+// deleted, oldest first.
+func selectFailed(clusters []ClusterInfo) []ClusterInfo {
+	var failed []ClusterInfo
+	for _, cluster := range clusters {
+		if cluster.Phase == phaseFailed && !cluster.Deleting {
+			failed = append(failed, cluster)
+		}
+	}
+	sortClusters(failed)
+	return failed
+}
+
+// failedClusterRef encodes a cluster in the value of its `failed` buttons.
+func failedClusterRef(cluster ClusterInfo) string {
+	return cluster.Namespace + "/" + cluster.Name
+}
+
+// failedMessageOptions lays out the failed clusters: a section per cluster,
+// followed by its retry and delete buttons.
+func failedMessageOptions(cfg *spoticusConfig.Config, failed []ClusterInfo, now time.Time) []slack.MsgOption {
+	shown := failed[:min(len(failed), maxFailedShown)]
+	header := messages.Render(cfg.Messages.FailedHeader, messages.Data{
+		"Count": len(failed),
+		"Shown": len(shown),
+	})
+
+	text := header
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, header, false, false), nil, nil),
+	}
+	for _, cluster := range shown {
+		entry := messages.Render(cfg.Messages.FailedEntry, messages.Data{
+			"Name":      cluster.Name,
+			"Type":      clusterTypeNames[cluster.Type],
+			"Namespace": cluster.Namespace,
+			"Owner":     cluster.Metadata().Owner,
+			"Age":       formatAge(now.Sub(cluster.Created)),
+			"Reason":    cluster.Reason,
+		})
+		text += "\n" + entry
+
+		ref := failedClusterRef(cluster)
+		retry := slack.NewButtonBlockElement(ActionFailedRetry, ref, slack.NewTextBlockObject(slack.PlainTextType, "Retry", false, false))
+		retry.Style = slack.StylePrimary
+		remove := slack.NewButtonBlockElement(ActionFailedDelete, ref, slack.NewTextBlockObject(slack.PlainTextType, "Delete", false, false))
+		remove.Style = slack.StyleDanger
+		blocks = append(blocks,
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, entry, false, false), nil, nil),
+			slack.NewActionBlock("failed_"+ref, retry, remove),
+		)
+	}
+	return []slack.MsgOption{slack.MsgOptionText(text, false), slack.MsgOptionBlocks(blocks...)}
+}
+
+// HandleFailed is the entry point for the admin "failed" Slack command.
+// It lists the failed clusters with their failure reason and age, each with
+// buttons to relaunch or delete it.
+func HandleFailed(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+	inventory, err := listClusters(ctx, client)
+	if len(inventory.Failed) == len(clusterTypeNames) {
+		return failure(err, cfg.Messages.ListFailed, "listing MAPT clusters for failed")
+	}
+	if err != nil {
+		log.Printf("Error listing MAPT clusters for failed: %v", err)
+	}
+
+	failed := selectFailed(inventory.Clusters)
+	if len(failed) == 0 {
+		respond.Text(api, event.Channel, cfg.Messages.FailedNone)
+		return nil
+	}
+	if _, _, err := respond.Post(api, event.Channel, failedMessageOptions(cfg, failed, time.Now())...); err != nil {
+		log.Printf("Error posting the failed clusters in %s: %v", event.Channel, err)
+	}
+	return nil
+}
+
+// HandleFailedAction handles a click on a `failed` retry or delete button.
+// Only admins may use them, and not during maintenance; the outcome is
+// posted in the channel.
+func HandleFailedAction(ctx context.Context, api *slack.Client, callback slack.InteractionCallback, action *slack.BlockAction) {
+	cfg := spoticusConfig.Get()
+	channel := callback.Container.ChannelID
+	user := callback.User.ID
+	if !cfg.IsAdmin(user) {
+		log.Printf("Denied %s action to non-admin user %s", action.ActionID, user)
+		respond.Ephemeral(api, channel, user, messages.Render(cfg.Messages.AdminOnly, messages.Data{"Command": "failed"}))
+		return
+	}
+	if MaintenanceEnabled() {
+		respond.Ephemeral(api, channel, user, messages.Render(cfg.Messages.MaintenanceActive, messages.Data{"Command": "failed"}))
+		return
+	}
+	namespace, name, ok := strings.Cut(action.Value, "/")
+	if !ok || name == "" {
+		log.Printf("Ignoring %s action from user %s: malformed cluster %q", action.ActionID, user, action.Value)
+		return
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		log.Printf("Error getting kubernetes client: %v", err)
+		respond.Ephemeral(api, channel, user, backendError(err, cfg.Messages.ConnectFailed))
+		return
+	}
+	cluster, err := findCluster(ctx, client, name)
+	if err == nil && cluster.Namespace != namespace {
+		err = errClusterNotFound
+	}
+	if err != nil {
+		ReportError(api, channel, "failed", user, clusterLookupError(err, name))
+		return
+	}
+	if cluster.Phase != phaseFailed || cluster.Deleting {
+		respond.Ephemeral(api, channel, user, messages.Render(cfg.Messages.FailedNoLonger, messages.Data{"Name": name}))
+		return
+	}
+
+	switch action.ActionID {
+	case ActionFailedRetry:
+		relaunched, err := relaunchCluster(ctx, client, cluster)
+		if err != nil {
+			log.Printf("Error relaunching failed cluster %s/%s: %v", namespace, name, err)
+			respond.Text(api, channel, backendError(err, cfg.Messages.FailedRetryFailed))
+			return
+		}
+		log.Printf("Failed cluster %s/%s relaunched as %s by %s", namespace, name, relaunched, user)
+		respond.Text(api, channel, messages.Render(cfg.Messages.FailedRetried, messages.Data{
+			"Name":    name,
+			"NewName": relaunched,
+			"User":    user,
+		}))
+	case ActionFailedDelete:
+		if err := deleteCluster(ctx, client, cluster); err != nil {
+			log.Printf("Error deleting failed cluster %s/%s: %v", namespace, name, err)
+			respond.Text(api, channel, backendError(err, cfg.Messages.DoneFailed))
+			return
+		}
+		log.Printf("Failed cluster %s/%s deleted by %s", namespace, name, user)
+		rememberDeletion(name, time.Now())
+		notify(api, clusterNotification(EventDeleted, cluster, user))
+		respond.Text(api, channel, messages.Render(cfg.Messages.DoneConfirm, messages.Data{"Name": name}))
+	}
+}
+
+// relaunchCluster replaces a failed cluster with a new one of the same spec,
+// labels and launch metadata under a new name, then deletes the failed one.
+// It returns the name of the new cluster.
+func relaunchCluster(ctx context.Context, client *KubernetesClients, cluster ClusterInfo) (string, error) {
+	original := &unstructured.Unstructured{}
+	original.SetGroupVersionKind(maptApi.GroupVersion.WithKind(clusterKinds[cluster.Type]))
+	if err := client.CrClient.Get(ctx, crclient.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, original); err != nil {
+		return "", err
+	}
+
+	name := generateClusterName(cluster.Type)
+	relaunched := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if spec, found, _ := unstructured.NestedMap(original.Object, "spec"); found {
+		relaunched.Object["spec"] = spec
+	}
+	relaunched.SetGroupVersionKind(original.GroupVersionKind())
+	relaunched.SetName(name)
+	relaunched.SetNamespace(cluster.Namespace)
+	relaunched.SetLabels(original.GetLabels())
+
+	annotations := maps.Clone(original.GetAnnotations())
+	delete(annotations, annotationDeleteAt)
+	relaunched.SetAnnotations(annotations)
+	SetLaunchMetadata(relaunched, LaunchMetadata{LaunchedAt: time.Now()})
+
+	if err := client.CrClient.Create(ctx, relaunched); err != nil {
+		return "", fmt.Errorf("creating %s: %w", name, err)
+	}
+	if err := deleteCluster(ctx, client, cluster); err != nil {
+		log.Printf("Relaunched %s/%s as %s but could not delete it: %v", cluster.Namespace, cluster.Name, name, err)
+	}
+	return name, nil
+}
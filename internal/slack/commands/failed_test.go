@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestClusterReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		status map[string]interface{}
+		want   string
+	}{
+		{name: "none", status: map[string]interface{}{"phase": phaseFailed}},
+		{name: "status message", status: map[string]interface{}{"message": "spot request not fulfilled"}, want: "spot request not fulfilled"},
+		{
+			name: "ready condition message",
+			status: map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Progressing", "message": "creating the VPC"},
+				map[string]interface{}{"type": "Ready", "reason": "ProvisionFailed", "message": "quota exceeded in us-east-1"},
+			}},
+			want: "quota exceeded in us-east-1",
+		},
+		{
+			name: "ready condition reason",
+			status: map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "reason": "ProvisionFailed"},
+			}},
+			want: "ProvisionFailed",
+		},
+		{
+			name: "status message preferred",
+			status: map[string]interface{}{
+				"message":    "stack rolled back",
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "message": "not ready"}},
+			},
+			want: "stack rolled back",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clusterReason(map[string]interface{}{"status": tt.status}); got != tt.want {
+				t.Errorf("clusterReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectFailed(t *testing.T) {
+	now := time.Now()
+	clusters := []ClusterInfo{
+		{Name: "spoticus-k8s-ready", Phase: phaseReady, Created: now.Add(-3 * time.Hour)},
+		{Name: "spoticus-k8s-new-failure", Phase: phaseFailed, Created: now.Add(-time.Hour)},
+		{Name: "spoticus-k8s-deleting", Phase: phaseFailed, Deleting: true, Created: now.Add(-4 * time.Hour)},
+		{Name: "spoticus-k8s-old-failure", Phase: phaseFailed, Created: now.Add(-2 * time.Hour)},
+		{Name: "spoticus-k8s-provisioning", Phase: phaseProvisioning, Created: now},
+	}
+
+	var got []string
+	for _, cluster := range selectFailed(clusters) {
+		got = append(got, cluster.Name)
+	}
+	if want := []string{"spoticus-k8s-old-failure", "spoticus-k8s-new-failure"}; !slices.Equal(got, want) {
+		t.Errorf("selectFailed() = %v, want %v", got, want)
+	}
+}
+
+// renderedFailed returns the form values the `failed` message options post.
+func renderedFailed(t *testing.T, failed []ClusterInfo) (text, blocks string) {
+	t.Helper()
+	_, values, err := slack.UnsafeApplyMsgOptions("", "C1", "", failedMessageOptions(testConfig(), failed, time.Now())...)
+	if err != nil {
+		t.Fatalf("applying the message options: %v", err)
+	}
+	return values.Get("text"), values.Get("blocks")
+}
+
+func TestFailedMessageRendersActionButtons(t *testing.T) {
+	useConfig(t, testConfig())
+	failed := []ClusterInfo{
+		{Name: "spoticus-k8s-broken", Namespace: "team-a", Type: "k8s", Phase: phaseFailed, Reason: "spot request not fulfilled",
+			Created: time.Now().Add(-2 * time.Hour), Annotations: LaunchMetadata{Owner: "U1"}.Annotations()},
+		{Name: "spoticus-openshift-broken", Namespace: "default", Type: "openshift", Phase: phaseFailed, Created: time.Now()},
+	}
+
+	text, blocks := renderedFailed(t, failed)
+	for _, want := range []string{
+		"*Failed clusters* (2)",
+		"*spoticus-k8s-broken* (Kubernetes, team-a) failed, created 2h ago by <@U1>\n> spot request not fulfilled",
+		"*spoticus-openshift-broken* (OpenShift, default) failed",
+		"_No reason reported._",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text %q does not contain %q", text, want)
+		}
+	}
+	for _, ref := range []string{"team-a/spoticus-k8s-broken", "default/spoticus-openshift-broken"} {
+		for _, action := range []string{ActionFailedRetry, ActionFailedDelete} {
+			if want := `"action_id":"` + action + `","value":"` + ref + `"`; !strings.Contains(blocks, want) {
+				t.Errorf("blocks %s have no %s button for %s", blocks, action, ref)
+			}
+		}
+	}
+	if !strings.Contains(blocks, `"style":"danger"`) || !strings.Contains(blocks, `"style":"primary"`) {
+		t.Errorf("blocks %s, want the retry and delete buttons styled", blocks)
+	}
+}
+
+func TestFailedMessageCapsClusters(t *testing.T) {
+	useConfig(t, testConfig())
+	var failed []ClusterInfo
+	for i := range maxFailedShown + 3 {
+		failed = append(failed, ClusterInfo{Name: "spoticus-k8s-f" + string(rune('a'+i)), Namespace: "default", Type: "k8s", Phase: phaseFailed})
+	}
+
+	text, blocks := renderedFailed(t, failed)
+	if !strings.Contains(text, "(23), the 20 oldest shown") {
+		t.Errorf("text %q, want the count and how many are shown", text)
+	}
+	if got := strings.Count(blocks, `"action_id":"`+ActionFailedRetry+`"`); got != maxFailedShown {
+		t.Errorf("got %d retry buttons, want %d", got, maxFailedShown)
+	}
+}
+
+// failedCluster is an existing OpenShift cluster owned by owner that MAPT
+// reports failed for reason.
+func failedCluster(t *testing.T, name, owner, reason string) *unstructured.Unstructured {
+	t.Helper()
+	cluster := existingClusterOf("openshift", name, owner, 0.30)
+	status := map[string]interface{}{"phase": phaseFailed, "message": reason}
+	if err := unstructured.SetNestedMap(cluster.Object, status, "status"); err != nil {
+		t.Fatalf("setting the status: %v", err)
+	}
+	return cluster
+}
+
+func TestHandleFailedListsFailedClusters(t *testing.T) {
+	useConfig(t, testConfig())
+	ready := existingClusterOf("openshift", "spoticus-openshift-fine", "U1", 0)
+	if err := unstructured.SetNestedField(ready.Object, phaseReady, "status", "phase"); err != nil {
+		t.Fatal(err)
+	}
+	useKube(t, slacktest.NewKube(failedCluster(t, "spoticus-openshift-triage", "U1", "bootstrap timed out"), ready))
+	api, server := newAPI(t)
+
+	if err := HandleFailed(context.Background(), api, message("UADMIN", "C1", "failed"), nil); err != nil {
+		t.Fatalf("HandleFailed: %v", err)
+	}
+	posted := server.WaitForMessage("*Failed clusters* (1)", time.Second)
+	if text := posted.Text(); !strings.Contains(text, "> bootstrap timed out") || strings.Contains(text, "spoticus-openshift-fine") {
+		t.Errorf("failed %q, want only the failed cluster with its reason", text)
+	}
+	if blocks := posted.Values.Get("blocks"); !strings.Contains(blocks, "default/spoticus-openshift-triage") {
+		t.Errorf("blocks %s have no buttons for the failed cluster", blocks)
+	}
+}
+
+func TestHandleFailedWithoutFailures(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-healthy", "U1", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleFailed(context.Background(), api, message("UADMIN", "C1", "failed"), nil); err != nil {
+		t.Fatalf("HandleFailed: %v", err)
+	}
+	server.WaitForMessage("No cluster is in a failed state", time.Second)
+}
+
+// failedButton returns a click by user on the button of action for the
+// cluster in C1.
+func failedButton(user, action, ref string) (slack.InteractionCallback, *slack.BlockAction) {
+	callback := slack.InteractionCallback{
+		Type:      slack.InteractionTypeBlockActions,
+		User:      slack.User{ID: user},
+		Container: slack.Container{ChannelID: "C1", MessageTs: "1700000000.000001"},
+	}
+	return callback, &slack.BlockAction{ActionID: action, Value: ref}
+}
+
+func TestFailedDeleteButton(t *testing.T) {
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(failedCluster(t, "spoticus-openshift-gone", "U1", "boom"))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	callback, action := failedButton("UADMIN", ActionFailedDelete, "default/spoticus-openshift-gone")
+	HandleFailedAction(context.Background(), api, callback, action)
+
+	server.WaitForMessage("spoticus-openshift-gone", time.Second)
+	if clusters := openshiftClusters(t, kube); len(clusters) != 0 {
+		t.Errorf("clusters %v left, want the failed cluster deleted", clusters)
+	}
+}
+
+func TestFailedRetryButtonRelaunches(t *testing.T) {
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	failed := failedCluster(t, "spoticus-openshift-retry", "U1", "boom")
+	failed.SetLabels(map[string]string{labelTeam: "payments"})
+	annotations := failed.GetAnnotations()
+	annotations[annotationDeleteAt] = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	failed.SetAnnotations(annotations)
+	kube := slacktest.NewKube(failed)
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	callback, action := failedButton("UADMIN", ActionFailedRetry, "default/spoticus-openshift-retry")
+	HandleFailedAction(context.Background(), api, callback, action)
+
+	text := server.WaitForMessage("<@UADMIN> relaunched *spoticus-openshift-retry* as", time.Second).Text()
+	clusters := openshiftClusters(t, kube)
+	if len(clusters) != 1 || clusters[0].GetName() == "spoticus-openshift-retry" {
+		t.Fatalf("clusters %v, want the failed cluster replaced by a new one", clusters)
+	}
+	relaunched := clusters[0]
+	if !strings.Contains(text, "*"+relaunched.GetName()+"*") {
+		t.Errorf("reply %q does not name the new cluster %s", text, relaunched.GetName())
+	}
+	if relaunched.GetLabels()[labelTeam] != "payments" || ParseLaunchMetadata(relaunched.GetAnnotations()).Owner != "U1" {
+		t.Errorf("relaunched labels %v, annotations %v, want the team and owner kept", relaunched.GetLabels(), relaunched.GetAnnotations())
+	}
+	if _, ok := relaunched.GetAnnotations()[annotationDeleteAt]; ok {
+		t.Errorf("relaunched annotations %v, want no pending deletion carried over", relaunched.GetAnnotations())
+	}
+}
+
+func TestFailedButtonsRestricted(t *testing.T) {
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	ready := existingClusterOf("openshift", "spoticus-openshift-recovered", "U1", 0)
+	if err := unstructured.SetNestedField(ready.Object, phaseReady, "status", "phase"); err != nil {
+		t.Fatal(err)
+	}
+	kube := slacktest.NewKube(failedCluster(t, "spoticus-openshift-kept", "U1", "boom"), ready)
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	callback, action := failedButton("U1", ActionFailedDelete, "default/spoticus-openshift-kept")
+	HandleFailedAction(context.Background(), api, callback, action)
+	denial, ok := server.Wait(func(call slacktest.Call) bool { return call.Method == "chat.postEphemeral" }, time.Second)
+	if !ok || !strings.Contains(denial.Text(), "failed") {
+		t.Errorf("no ephemeral denial for a non-admin, got %+v", denial)
+	}
+
+	callback, action = failedButton("UADMIN", ActionFailedDelete, "default/spoticus-openshift-recovered")
+	HandleFailedAction(context.Background(), api, callback, action)
+	server.WaitForMessage("*spoticus-openshift-recovered* is no longer in a failed state", time.Second)
+
+	if clusters := openshiftClusters(t, kube); len(clusters) != 2 {
+		t.Errorf("got %d clusters, want both kept", len(clusters))
+	}
+}
+
+// openshiftClusters returns the OpenShift clusters in the fake cluster.
+func openshiftClusters(t *testing.T, kube *slacktest.Kube) []unstructured.Unstructured {
+	t.Helper()
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(openshiftListGVK)
+	if err := kube.CrClient.List(context.Background(), list); err != nil {
+		t.Fatalf("listing the openshift clusters: %v", err)
+	}
+	return list.Items
+}
@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+const (
+	// followExpiryWarning is how long before the end of its TTL followers of a
+	// cluster are warned.
+	followExpiryWarning = 30 * time.Minute
+)
+
+// followTarget is a Slack thread subscribed to a cluster's updates.
+type followTarget struct {
+	User     string
+	Channel  string
+	ThreadTS string
+}
+
+// followedCluster is a cluster with at least one subscribed thread, and what
+// its followers were last told about it.
+type followedCluster struct {
+	targets []followTarget
+	phase   string
+	warned  bool
+}
+
+var (
+	followsMu sync.Mutex
+	// follows holds the subscriptions keyed by cluster name. They are kept
+	// in memory and do not survive a restart.
+	follows = map[string]*followedCluster{}
+	// followPolling is set while the poller goroutine runs.
+	followPolling bool
+)
+
+// HandleFollow is the entry point for the "follow" Slack command.
+//
+// It subscribes the thread the command was sent in (or a new thread on the
+// command message) to the cluster's status changes, an expiry warning and
+// its deletion, until `unfollow` is called or the cluster is gone.
+func HandleFollow(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) != 1 {
+		return invalid(cfg.Messages.FollowUsage)
+	}
+	name := args[0]
+	tracing.SetCluster(ctx, name)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	cluster, err := findCluster(ctx, client, name)
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+	if !canOperate(cfg, event.User, cluster) {
+		return clusterAccessDenied(event.User, "follow", cluster)
+	}
+
+	target := followTarget{User: event.User, Channel: event.Channel, ThreadTS: eventThread(event)}
+	if follow(api, cluster, target) {
+		log.Printf("User %s follows cluster %s in %s", event.User, name, event.Channel)
+	}
+	respond.Thread(api, target.Channel, target.ThreadTS, messages.Render(cfg.Messages.FollowStarted, messages.Data{
+		"Name":  name,
+		"Phase": cluster.Phase,
+	}))
+	return nil
+}
+
+// HandleUnfollow is the entry point for the "unfollow" Slack command.
+//
+// In a thread it stops the updates to that thread; elsewhere it stops every
+// subscription of the user to the cluster in the channel.
+func HandleUnfollow(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) != 1 {
+		return invalid(cfg.Messages.UnfollowUsage)
+	}
+	name := args[0]
+
+	if !unfollow(name, event.User, event.Channel, event.ThreadTimeStamp) {
+		respond.Thread(api, event.Channel, event.ThreadTimeStamp, messages.Render(cfg.Messages.NotFollowing, messages.Data{"Name": name}))
+		return nil
+	}
+	log.Printf("User %s unfollowed cluster %s in %s", event.User, name, event.Channel)
+	respond.Thread(api, event.Channel, event.ThreadTimeStamp, messages.Render(cfg.Messages.FollowStopped, messages.Data{"Name": name}))
+	return nil
+}
+
+// eventThread returns the thread the message belongs to, or the message
+// itself so that replies start a thread on it.
+func eventThread(event *slackevents.MessageEvent) string {
+	if event.ThreadTimeStamp != "" {
+		return event.ThreadTimeStamp
+	}
+	return event.TimeStamp
+}
+
+// follow subscribes target to the cluster and starts the poller if needed.
+// It reports whether the target was not already subscribed.
+func follow(api *slack.Client, cluster ClusterInfo, target followTarget) bool {
+	followsMu.Lock()
+	defer followsMu.Unlock()
+
+	followed, ok := follows[cluster.Name]
+	if !ok {
+		followed = &followedCluster{phase: cluster.Phase}
+		follows[cluster.Name] = followed
+	}
+	if slices.Contains(followed.targets, target) {
+		return false
+	}
+	followed.targets = append(followed.targets, target)
+
+	if !followPolling {
+		followPolling = true
+		go pollFollowedClusters(api)
+	}
+	return true
+}
+
+// unfollow removes the subscriptions to the cluster matching the channel and
+// thread, or all of the user's in the channel when threadTS is empty. It
+// reports whether any subscription was removed.
+func unfollow(name, user, channel, threadTS string) bool {
+	followsMu.Lock()
+	defer followsMu.Unlock()
+
+	followed, ok := follows[name]
+	if !ok {
+		return false
+	}
+	before := len(followed.targets)
+	followed.targets = slices.DeleteFunc(followed.targets, func(t followTarget) bool {
+		if t.Channel != channel {
+			return false
+		}
+		if threadTS != "" {
+			return t.ThreadTS == threadTS
+		}
+		return t.User == user
+	})
+	if len(followed.targets) == 0 {
+		delete(follows, name)
+	}
+	return len(followed.targets) < before
+}
+
+// pollFollowedClusters checks the followed clusters until none is left,
+// posting their updates to the subscribed threads. Polls back off while the
+// backend fails.
+func pollFollowedClusters(api *slack.Client) {
+	failures := 0
+	for {
+		<-nextPoll(failures)
+
+		followsMu.Lock()
+		if len(follows) == 0 {
+			followPolling = false
+			followsMu.Unlock()
+			return
+		}
+		followsMu.Unlock()
+
+		client, err := GetKubernetesClient()
+		if err != nil {
+			log.Printf("Error getting kubernetes client to check followed clusters: %v", err)
+			failures++
+			continue
+		}
+		inventory, err := listClusters(context.Background(), client)
+		if err != nil {
+			log.Printf("Error listing MAPT clusters for followers: %v", err)
+		}
+		if len(inventory.Failed) > 0 {
+			// A cluster missing from a partial inventory may still exist.
+			failures++
+			continue
+		}
+		failures = 0
+		notifyFollowers(api, inventory, time.Now())
+	}
+}
+
+// notifyFollowers compares the followed clusters with the inventory and posts
+// phase changes, expiry warnings and deletions to their followers. Clusters
+// that are gone are no longer followed.
+func notifyFollowers(api *slack.Client, inventory clusterInventory, now time.Time) {
+	templates := msgs()
+	byName := map[string]ClusterInfo{}
+	for _, cluster := range inventory.Clusters {
+		byName[cluster.Name] = cluster
+	}
+
+	type update struct {
+		cluster string
+		targets []followTarget
+		text    string
+	}
+	var updates []update
+
+	followsMu.Lock()
+	for name, followed := range follows {
+		targets := slices.Clone(followed.targets)
+		cluster, ok := byName[name]
+		if !ok {
+			delete(follows, name)
+			updates = append(updates, update{name, targets, messages.Render(templates.FollowGone, messages.Data{"Name": name})})
+			continue
+		}
+		if cluster.Phase != followed.phase {
+			followed.phase = cluster.Phase
+			updates = append(updates, update{name, targets, messages.Render(templates.FollowPhase, messages.Data{
+				"Name":  name,
+				"Phase": cluster.Phase,
+			})})
+		}
+		if ttl := cluster.Metadata().TTL; ttl > 0 && !followed.warned {
+			if expires := cluster.Created.Add(ttl); expires.Sub(now) <= followExpiryWarning {
+				followed.warned = true
+				updates = append(updates, update{name, targets, messages.Render(templates.FollowExpiring, messages.Data{
+					"Name":      name,
+					"Expires":   formatAge(now.Sub(expires)),
+					"Permalink": cluster.Metadata().Permalink,
+				})})
+			}
+		}
+	}
+	followsMu.Unlock()
+
+	for _, u := range updates {
+		if !shouldNotify(u.cluster, u.text) {
+			continue
+		}
+		for _, target := range u.targets {
+			respond.Thread(api, target.Channel, target.ThreadTS, u.text)
+		}
+	}
+}
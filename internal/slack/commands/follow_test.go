@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// useFollows starts the test with no subscriptions, and restores them when it
+// ends. The poller is marked running so that the tests drive
+// notifyFollowers themselves.
+func useFollows(t *testing.T) {
+	t.Helper()
+	followsMu.Lock()
+	previous, polling := follows, followPolling
+	follows, followPolling = map[string]*followedCluster{}, true
+	followsMu.Unlock()
+	t.Cleanup(func() {
+		followsMu.Lock()
+		follows, followPolling = previous, polling
+		followsMu.Unlock()
+	})
+}
+
+// followersOf returns the threads subscribed to the cluster.
+func followersOf(name string) []followTarget {
+	followsMu.Lock()
+	defer followsMu.Unlock()
+	if followed, ok := follows[name]; ok {
+		return followed.targets
+	}
+	return nil
+}
+
+func TestFollowAndUnfollow(t *testing.T) {
+	useFollows(t)
+	api, _ := newAPI(t)
+	cluster := ClusterInfo{Name: "spoticus-k8s-follow", Phase: phaseProvisioning}
+	inThread := followTarget{User: "U1", Channel: "C1", ThreadTS: "1.1"}
+	otherThread := followTarget{User: "U1", Channel: "C1", ThreadTS: "2.2"}
+	otherUser := followTarget{User: "U2", Channel: "C1", ThreadTS: "3.3"}
+
+	for _, target := range []followTarget{inThread, otherThread, otherUser} {
+		if !follow(api, cluster, target) {
+			t.Errorf("follow(%+v) = false, want the new subscription added", target)
+		}
+	}
+	if follow(api, cluster, inThread) {
+		t.Error("follow() = true for a thread already subscribed")
+	}
+
+	if !unfollow(cluster.Name, "U2", "C1", "3.3") {
+		t.Error("unfollow() in the thread = false, want its subscription removed")
+	}
+	if unfollow(cluster.Name, "U1", "C2", "") {
+		t.Error("unfollow() in another channel = true, want nothing removed")
+	}
+	if got := followersOf(cluster.Name); len(got) != 2 {
+		t.Fatalf("followers %+v, want the two threads of U1", got)
+	}
+	if !unfollow(cluster.Name, "U1", "C1", "") {
+		t.Error("unfollow() outside a thread = false, want the user's subscriptions removed")
+	}
+	if got := followersOf(cluster.Name); got != nil {
+		t.Errorf("followers %+v after everyone unfollowed, want the cluster dropped", got)
+	}
+	if unfollow(cluster.Name, "U1", "C1", "") {
+		t.Error("unfollow() of a cluster not followed = true")
+	}
+}
+
+func TestNotifyFollowersPostsUpdates(t *testing.T) {
+	useFollows(t)
+	api, server := newAPI(t)
+	name := "spoticus-k8s-notified"
+	targets := []followTarget{{User: "U1", Channel: "C1", ThreadTS: "1.1"}, {User: "U2", Channel: "C2", ThreadTS: "2.2"}}
+	follows[name] = &followedCluster{targets: targets, phase: phaseProvisioning}
+
+	now := time.Now()
+	meta := &metav1.ObjectMeta{}
+	SetLaunchMetadata(meta, LaunchMetadata{TTL: time.Hour})
+	cluster := ClusterInfo{Name: name, Phase: phaseReady, Created: now.Add(-50 * time.Minute), Annotations: meta.Annotations}
+	inventory := clusterInventory{Clusters: []ClusterInfo{cluster}}
+
+	notifyFollowers(api, inventory, now)
+	for _, target := range targets {
+		for _, text := range []string{"is now *Ready*", "reaches the end of its TTL"} {
+			if _, ok := server.Wait(func(call slacktest.Call) bool {
+				return call.Channel() == target.Channel && call.Values.Get("thread_ts") == target.ThreadTS && strings.Contains(call.Text(), text)
+			}, time.Second); !ok {
+				t.Errorf("no %q posted in the thread of %s", text, target.User)
+			}
+		}
+	}
+
+	posted := len(server.Messages())
+	notifyFollowers(api, inventory, now.Add(time.Minute))
+	if got := len(server.Messages()); got != posted {
+		t.Errorf("posted %d more messages without any change, want none", got-posted)
+	}
+
+	notifyFollowers(api, clusterInventory{}, now.Add(2*time.Minute))
+	gone := 0
+	for _, call := range server.Messages() {
+		if strings.Contains(call.Text(), "is gone, no longer following it") {
+			gone++
+		}
+	}
+	if gone != len(targets) {
+		t.Errorf("posted the deletion to %d threads, want %d", gone, len(targets))
+	}
+	if got := followersOf(name); got != nil {
+		t.Errorf("followers %+v of a deleted cluster, want it no longer followed", got)
+	}
+}
+
+func TestFollowExpiryLinksLaunchThread(t *testing.T) {
+	useFollows(t)
+	api, server := newAPI(t)
+	name := "spoticus-k8s-expiring"
+	follows[name] = &followedCluster{targets: []followTarget{{User: "U1", Channel: "C1", ThreadTS: "1.1"}}, phase: phaseReady}
+
+	now := time.Now()
+	meta := LaunchMetadata{TTL: time.Hour, Permalink: "https://slack.test/archives/C9/p1700000000000200"}
+	cluster := ClusterInfo{Name: name, Phase: phaseReady, Created: now.Add(-50 * time.Minute), Annotations: meta.Annotations()}
+	notifyFollowers(api, clusterInventory{Clusters: []ClusterInfo{cluster}}, now)
+
+	text := server.WaitForMessage("reaches the end of its TTL", time.Second).Text()
+	if !strings.Contains(text, "<https://slack.test/archives/C9/p1700000000000200|Launch thread>") {
+		t.Errorf("expiry warning %q does not link the launch thread", text)
+	}
+}
+
+func TestHandleFollowSubscribesThread(t *testing.T) {
+	useConfig(t, testConfig())
+	useFollows(t)
+	name := "spoticus-k8s-followed"
+	useKube(t, slacktest.NewKube(existingCluster(name, "U16", 0)))
+	api, server := newAPI(t)
+
+	event := message("U16", "C1", "follow "+name)
+	event.TimeStamp = "1700000000.000100"
+	if err := HandleFollow(context.Background(), api, event, []string{name}); err != nil {
+		t.Fatalf("HandleFollow: %v", err)
+	}
+	started := server.WaitForMessage("Following *"+name+"*", time.Second)
+	if got := started.Values.Get("thread_ts"); got != event.TimeStamp {
+		t.Errorf("reply posted in thread %q, want a thread on the command %q", got, event.TimeStamp)
+	}
+	want := []followTarget{{User: "U16", Channel: "C1", ThreadTS: event.TimeStamp}}
+	if got := followersOf(name); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("followers %+v, want %+v", got, want)
+	}
+
+	unfollowEvent := message("U16", "C1", "unfollow "+name)
+	unfollowEvent.ThreadTimeStamp = event.TimeStamp
+	for range 2 {
+		if err := HandleUnfollow(context.Background(), api, unfollowEvent, []string{name}); err != nil {
+			t.Fatalf("HandleUnfollow: %v", err)
+		}
+	}
+	server.WaitForMessage("Stopped following *"+name+"*", time.Second)
+	server.WaitForMessage("is not followed here", time.Second)
+	if got := followersOf(name); got != nil {
+		t.Errorf("followers %+v after unfollow, want none", got)
+	}
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// useConfig makes cfg the current configuration until the test ends.
+func useConfig(t *testing.T, cfg *spoticusConfig.Config) {
+	t.Helper()
+	previous := spoticusConfig.Get()
+	spoticusConfig.Set(cfg)
+	t.Cleanup(func() { spoticusConfig.Set(previous) })
+}
+
+// useKube makes GetKubernetesClient return the fake clients until the test
+// ends.
+func useKube(t *testing.T, kube *slacktest.Kube) {
+	t.Helper()
+	SetKubernetesClientFactory(func() (*KubernetesClients, error) {
+		return &KubernetesClients{
+			KubeClient:    kube.KubeClient,
+			CrClient:      kube.CrClient,
+			DynamicClient: kube.DynamicClient,
+		}, nil
+	})
+	t.Cleanup(func() { SetKubernetesClientFactory(nil) })
+}
+
+// fakeClients returns breaker-less clients over the fake ones, for the
+// functions taking them directly.
+func fakeClients(kube *slacktest.Kube) *KubernetesClients {
+	return &KubernetesClients{KubeClient: kube.KubeClient, CrClient: kube.CrClient, DynamicClient: kube.DynamicClient}
+}
+
+// newAPI starts a fake Slack Web API and returns a client talking to it.
+func newAPI(t *testing.T) (*slack.Client, *slacktest.Server) {
+	t.Helper()
+	server := slacktest.NewServer(t)
+	return server.Client(), server
+}
+
+// message returns a message event from the user in the channel.
+func message(user, channel, text string) *slackevents.MessageEvent {
+	return &slackevents.MessageEvent{Type: "message", User: user, Channel: channel, Text: text}
+}
+
+// testConfig is the default configuration, polling fast enough for tests.
+func testConfig() *spoticusConfig.Config {
+	cfg := spoticusConfig.Default()
+	cfg.Polling = spoticusConfig.Polling{Interval: spoticusConfig.Duration(10 * time.Millisecond), MaxBackoff: spoticusConfig.Duration(10 * time.Millisecond)}
+	return cfg
+}
+
+// failList makes the controller-runtime client fail to list the MAPT
+// clusters of the given types.
+func failList(clusterTypes ...string) interceptor.Funcs {
+	return interceptor.Funcs{
+		List: func(ctx context.Context, client crclient.WithWatch, list crclient.ObjectList, opts ...crclient.ListOption) error {
+			kind := list.GetObjectKind().GroupVersionKind().Kind
+			if kind == "" {
+				if gvk, err := client.GroupVersionKindFor(list); err == nil {
+					kind = gvk.Kind
+				}
+			}
+			for _, clusterType := range clusterTypes {
+				if kind == clusterKinds[clusterType]+"List" {
+					return errors.New("the server is currently unable to handle the request")
+				}
+			}
+			return client.List(ctx, list, opts...)
+		},
+	}
+}
+
+// existingCluster returns a MAPT Kind cluster in the default namespace
+// launched by the owner, as the bot would have created it.
+func existingCluster(name, owner string, hourlyCost float64) *unstructured.Unstructured {
+	return existingClusterOf("k8s", name, owner, hourlyCost)
+}
+
+// existingClusterOf is existingCluster for a cluster of the given type.
+func existingClusterOf(clusterType, name, owner string, hourlyCost float64) *unstructured.Unstructured {
+	cluster := newClusterObject(name, &LaunchRequest{Type: clusterType, Namespace: "default"})
+	SetLaunchMetadata(cluster, LaunchMetadata{Owner: owner, HourlyCost: hourlyCost, LaunchedAt: time.Now()})
+	return cluster
+}
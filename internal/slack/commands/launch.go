@@ -2,35 +2,45 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+	"time"
 
 	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/kubernetes"
-	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
-	crclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
 )
 
-const launchUsage = "" +
+// LaunchUsage is the detailed documentation of the launch command, shown by
+// `launch help` and when a launch is missing its arguments.
+const LaunchUsage = "" +
 	"📦 *Launch Command — Detailed Usage*\n\n" +
 	"This command provisions a new cluster using a specified platform and resource tier.\n\n" +
 	"🔧 *Syntax*:\n" +
 	"```\n" +
 	"launch <cluster_type> <size>\n" +
-	"```\n\n" +
+	"launch type=<cluster_type> size=<size>\n" +
+	"```\n" +
+	"Options may be written `--key=value`, `--key value` or `key=value`, and named and positional arguments mixed.\n\n" +
 	"🧪 *Examples*:\n" +
 	"```\n" +
 	"launch k8s large\n" +
 	"launch openshift medium\n" +
+	"launch openshift large --version=4.19.0\n" +
+	"launch k8s large --k8s-version=1.32\n" +
+	"launch k8s --instance-type=m6i.4xlarge\n" +
+	"launch k8s large --set network.airgap=true\n" +
+	"launch openshift large --at=\"2025-06-01 09:00\"\n" +
 	"```\n\n" +
 	"🧱 *Supported Cluster Types*:\n" +
 	"• `k8s` — Standard upstream Kubernetes cluster\n" +
@@ -40,237 +50,372 @@ const launchUsage = "" +
 	"• `medium` — 8 CPUs / 32 GB RAM\n" +
 	"• `large` — 16 CPUs / 64 GB RAM\n" +
 	"• `xlarge` — 32 CPUs / 128 GB RAM\n\n" +
+	"🏷️ *Options*:\n" +
+	"• `--version=<x.y.z>` — OpenShift version to install (openshift only)\n" +
+	"• `--k8s-version=<x.y>` — Kubernetes version to install (k8s only)\n" +
+	"• `--instance-type=<type>` — specific cloud instance type, replaces the size\n" +
+	"• `--cpu-limit=<n>` / `--mem-limit=<n>GB` — request fewer CPUs or less memory than the size provides\n" +
+	"• `--set <path>=<value>` — set a MAPT spec field the bot does not model (repeatable).\n" +
+	"  The path is relative to `spec`; `true`/`false` and integers are typed, quote a value to keep it a string.\n" +
+	"• `--at=\"YYYY-MM-DD HH:MM\"` / `--in=<duration>` — schedule the launch for later; see `schedule`\n" +
+	"• `--ref=<ticket>` — record the ticket the cluster is for, e.g. `--ref=PROJ-123`\n" +
+	"• `--ttl=<duration>` — expected lifetime, e.g. `--ttl=12h` or `--ttl=3d`, up to the size's maximum\n" +
+	"• `--override-budget` — launch even if the budget cap would be exceeded (admins only)\n\n" +
+	"✅ *Confirmation*:\n" +
+	"Expensive sizes (such as `xlarge`) must be confirmed by replying `launch confirm` within two minutes.\n\n" +
 	"💰 *⚡ Spot Instances (Cost Optimization)*:\n" +
 	"All clusters are provisioned using **cloud spot instances** for maximum cost-efficiency.\n"
 
-var (
-	scheme = runtime.NewScheme()
-)
-
-func init() {
-	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
-	utilruntime.Must(maptApi.AddToScheme(scheme))
+// supportedClusterTypes defines the valid cluster types that can be launched.
+// Currently supports Kubernetes and OpenShift.
+// The keys are the cluster type names, and the values are empty structs
+// to allow for efficient O(1) existence checks.
+// TODO!: Check ROSA and Karpenter support in the future.
+var supportedClusterTypes = map[string]struct{}{
+	"k8s":       {},
+	"openshift": {},
 }
 
-type KubernetesClients struct {
-	KubeClient    *kubernetes.Clientset
-	CrClient      crclient.Client
-	DynamicClient dynamic.Interface
+// HandleLaunch is the main entry point for the "launch" Slack command.
+//
+// It expects two positional arguments:
+//  1. cluster type — currently one of: "k8s", "openshift"
+//  2. cluster size — one of the configured sizes, e.g. "medium", "large", "xlarge"
+//
+// OpenShift clusters additionally accept `--version=<x.y.z>`, validated against
+// the configured list of allowed versions. `--instance-type=<type>` may be given
+// instead of a size to request a specific, allowlisted cloud instance type.
+// `--at=<time>` or `--in=<duration>` defer the launch to a later time.
+//
+// Sizes with at least the configured number of CPUs are only launched once the
+// user replies `launch confirm`; see confirmLaunch.
+//
+// If the command is malformed, the user will receive contextual error feedback.
+// Otherwise the cluster name is generated and a confirmation describing the
+// cluster is posted right away, without waiting on the API server. The quota
+// and budget checks, the creation of the MAPT resource and the wait for it to become ready
+// then happen in the background, with progress and errors reported in the
+// thread of the confirmation.
+func HandleLaunch(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	if len(args) == 1 && strings.ToLower(args[0]) == "confirm" {
+		return confirmLaunch(ctx, api, event)
+	}
+	return launchCluster(ctx, api, event, args, false)
 }
 
-func GetKubernetesClient() (*KubernetesClients, error) {
-	cfg, err := config.GetConfig()
+// launchCluster runs a launch command. Expensive sizes are held for
+// confirmation unless confirmed is set.
+func launchCluster(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string, confirmed bool) error {
+	original := args
+	args, err := normalizeLaunchArgs(args)
 	if err != nil {
-		return nil, err
+		return invalid(err.Error())
 	}
-	client, err := kubernetes.NewForConfig(cfg)
+	args, err = expandPreset(ctx, event.User, args)
 	if err != nil {
-		return nil, err
+		return invalid(err.Error())
 	}
-
-	dynamicClient, err := dynamic.NewForConfig(cfg)
+	args, runAt, err := extractSchedule(args, time.Now())
 	if err != nil {
-		return nil, err
+		return invalid(err.Error())
 	}
-	crClient, err := crclient.New(cfg, crclient.Options{
-		Scheme: scheme,
-	})
 
+	req, err := parseLaunchArgs(args, event.Channel)
 	if err != nil {
-		return nil, err
+		return invalid(err.Error())
 	}
 
-	return &KubernetesClients{KubeClient: client, CrClient: crClient, DynamicClient: dynamicClient}, nil
-}
+	if req.OverrideBudget && !spoticusConfig.Get().IsAdmin(event.User) {
+		return denied(msgs().BudgetDenied, "denied the budget override to user %s", event.User)
+	}
 
-// supportedClusterTypes defines the valid cluster types that can be launched.
-// Currently supports Kubernetes and OpenShift.
-// The keys are the cluster type names, and the values are empty structs
-// to allow for efficient O(1) existence checks.
-// TODO!: Check ROSA and Karpenter support in the future.
-var supportedClusterTypes = map[string]struct{}{
-	"k8s":       {},
-	"openshift": {},
-}
+	if !confirmed && requiresConfirmation(spoticusConfig.Get(), req) {
+		requestLaunchConfirmation(api, event, original, req)
+		return nil
+	}
 
-// SizeSpec defines the resource specifications for a given cluster size.
-// This includes the number of CPUs and the amount of RAM.
-type SizeSpec struct {
-	CPU string
-	RAM string
-}
+	if !runAt.IsZero() {
+		return scheduleLaunch(ctx, api, event, args, runAt)
+	}
 
-// supportedSizes defines the available cluster sizes and their specifications.
-// Each entry includes the size label (e.g., "large") and its corresponding resources.
-var supportedSizes = map[string]SizeSpec{
-	"medium": {
-		CPU: "8 CPUs",
-		RAM: "32 GB RAM",
-	},
-	"large": {
-		CPU: "16 CPUs",
-		RAM: "64 GB RAM",
-	},
-	"xlarge": {
-		CPU: "32 CPUs",
-		RAM: "128 GB RAM",
-	},
-}
+	owner := spoticusConfig.Get().OwnerFor(event.User)
+	cluster := newClusterObject(generateClusterName(req.Type), req)
+	SetLaunchMetadata(cluster, LaunchMetadata{
+		Owner:        owner,
+		Channel:      event.Channel,
+		Team:         teamFrom(ctx),
+		Command:      event.Text,
+		Size:         req.Size,
+		Ref:          req.Ref,
+		Version:      req.Version,
+		Provider:     req.Provider,
+		InstanceType: req.InstanceType,
+		CPULimit:     req.CPULimit,
+		MemoryLimit:  req.MemoryLimitGB,
+		LaunchedAt:   time.Now(),
+		TTL:          req.TTL,
+		HourlyCost:   req.Spec.HourlyCost,
+	})
 
-// HandleLaunch is the main entry point for the "launch" Slack command.
-//
-// It expects exactly two arguments:
-//  1. cluster type — currently one of: "k8s", "openshift"
-//  2. cluster size — currently one of: "medium", "large", "xlarge"
-//
-// If the command is malformed, the user will receive contextual error feedback.
-// Otherwise, a confirmation message is sent to the channel describing the requested launch.
-//
-// The function logs the action for auditing/debugging and ensures the user
-// receives structured output with specs.
-func HandleLaunch(api *slack.Client, event *slackevents.MessageEvent, args []string) {
-	var maptList *maptApi.KindList
-	if len(args) < 2 {
-		respondError(api, event.Channel, "❌ Missing arguments.\n\n"+launchUsage)
-		return
+	labels := map[string]string{}
+	if team := spoticusConfig.Get().TeamOf(owner); team != "" {
+		labels[labelTeam] = team
+	}
+	if tenant, ok := tenantFrom(ctx); ok {
+		labels[labelTenant] = tenant
+	}
+	if len(labels) > 0 {
+		cluster.SetLabels(labels)
 	}
 
+	tracing.SetCluster(ctx, cluster.GetName())
+
 	client, err := GetKubernetesClient()
-	log.Printf("Error getting kubernetes clinet: %v", err)
-	maptErr := client.CrClient.List(context.TODO(), maptList)
-	log.Printf("Error getting mapt list: %v", maptErr)
-	clusterType := strings.ToLower(args[0])
-	size := strings.ToLower(args[1])
-
-	// Validate cluster type
-	if !isSupportedClusterType(clusterType) {
-		respondError(api, event.Channel,
-			fmt.Sprintf("❌ Unsupported cluster type: *%s*\nSupported types: `k8s`, `openshift`", clusterType))
-		return
+	if err != nil {
+		return connectError(err)
 	}
-
-	spec, ok := supportedSizes[size]
-	if !ok {
-		respondError(api, event.Channel,
-			fmt.Sprintf("❌ Invalid size: *%s*\nValid sizes:\n%s", size, formatSupportedSizes()))
-		return
+	// The checks and the creation of the cluster are serialized with the
+	// other launches of the owner, whatever their namespace, since the quota
+	// counts the clusters of every namespace: two launches racing each other
+	// cannot both pass them. provisionCluster unlocks once the cluster is
+	// created.
+	unlock := lockLaunches(owner)
+	limits, err := checkLaunchLimits(ctx, client, event.User, owner, req, cluster.GetName())
+	if err != nil {
+		unlock()
+		return err
 	}
 
-	log.Printf("Launching cluster: user=%s type=%s size=%s", event.User, clusterType, size)
+	log.Printf("Launching cluster: user=%s type=%s size=%s instance-type=%s version=%s name=%s",
+		event.User, req.Type, req.Size, req.InstanceType, req.Version, cluster.GetName())
 
 	// Compose confirmation message with detailed spec
-	message := fmt.Sprintf(
-		"🚀 Launching a *%s* cluster of size *%s* for <@%s>\n• CPU: %s\n• Memory: %s",
-		clusterType, size, event.User, spec.CPU, spec.RAM)
+	message := messages.Render(msgs().LaunchConfirm, messages.Data{
+		"Name":         cluster.GetName(),
+		"Type":         req.Type,
+		"Size":         req.Size,
+		"User":         event.User,
+		"CPU":          req.Resources().CPU(),
+		"RAM":          req.Resources().RAM(),
+		"Version":      req.Version,
+		"InstanceType": req.InstanceType,
+		"Defaults":     strings.Join(req.ChannelDefaults, ", "),
+		"Ref":          req.Ref,
+	})
 
-	// Post the result back to Slack
-	if _, _, err := api.PostMessage(event.Channel, slack.MsgOptionText(message, false)); err != nil {
+	// Post the confirmation; its timestamp anchors the thread for the updates.
+	_, threadTS, err := respond.Post(api, event.Channel, slack.MsgOptionText(message, false))
+	if err != nil {
 		log.Printf("Error posting launch message: %v", err)
 	}
+	if limits.Overridden != "" {
+		respond.Thread(api, event.Channel, threadTS, limits.Overridden)
+	}
+
+	go provisionCluster(ctx, api, client, event, req, cluster, threadTS, limits.Notice, unlock)
+	return nil
 }
 
-func HandleList(api *slack.Client, event *slackevents.MessageEvent, args []string) {
-	// Get Kubernetes client
-	client, err := GetKubernetesClient()
-	if err != nil {
-		log.Printf("Error getting kubernetes client: %v", err)
-		respondError(api, event.Channel, "❌ Failed to connect to Kubernetes cluster")
-		return
+var (
+	launchLocksMu sync.Mutex
+	// launchLocks maps an owner to the lock serializing their launches.
+	launchLocks = map[string]*sync.Mutex{}
+)
+
+// lockLaunches locks the launches of the owner, returning the function
+// unlocking them. It may be called more than once.
+func lockLaunches(owner string) func() {
+	launchLocksMu.Lock()
+	lock, ok := launchLocks[owner]
+	if !ok {
+		lock = &sync.Mutex{}
+		launchLocks[owner] = lock
 	}
+	launchLocksMu.Unlock()
+
+	lock.Lock()
+	var once sync.Once
+	return func() { once.Do(lock.Unlock) }
+}
+
+// launchLimits is the outcome of the quota and budget checks of a launch
+// allowed to proceed.
+type launchLimits struct {
+	// Notice is appended to the ready message, see usageNotice.
+	Notice string
+
+	// Overridden is posted in the launch thread when an admin overrode the
+	// budget cap.
+	Overridden string
+}
 
-	// List all MAPT Kind resources
-	var kindsList maptApi.KindList
-	err = client.CrClient.List(context.TODO(), &kindsList)
+// checkLaunchLimits checks that the launch keeps the owner within their
+// quota and the clusters within the hourly budget cap, returning the error
+// to report when it does not.
+func checkLaunchLimits(ctx context.Context, client *KubernetesClients, user, owner string, req *LaunchRequest, name string) (launchLimits, error) {
+	cfg := spoticusConfig.Get()
+	limit := quotaLimit(owner)
+	if limit <= 0 && cfg.Budget.HourlyCap <= 0 {
+		return launchLimits{}, nil
+	}
+
+	inventory, err := listClusters(ctx, client)
 	if err != nil {
-		log.Printf("Error listing MAPT kind clusters: %v", err)
-		respondError(api, event.Channel, "❌ Failed to retrieve cluster list")
-		return
+		return launchLimits{}, failure(err, msgs().ListFailed, "listing MAPT clusters for quota and budget checks")
+	}
+	usage := quotaUsage(inventory, owner)
+	if limit > 0 && usage >= limit {
+		return launchLimits{}, invalid(messages.Render(msgs().QuotaExceeded, messages.Data{
+			"Usage": usage,
+			"Limit": limit,
+		}))
 	}
 
-	// List all MAPT OpenShift resources using unstructured approach
-	openshiftsList := &unstructured.UnstructuredList{}
-	openshiftsList.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   "mapt.redhat.com",
-		Version: "v1alpha1",
-		Kind:    "OpenshiftList",
-	})
-	err = client.CrClient.List(context.TODO(), openshiftsList)
+	var limits launchLimits
+	current := budgetUsage(inventory)
+	if exceedsBudget(current, req.Spec.HourlyCost, cfg.Budget.HourlyCap) {
+		data := messages.Data{
+			"Current":   formatHourlyCost(current),
+			"Projected": formatHourlyCost(current + req.Spec.HourlyCost),
+			"Cap":       formatHourlyCost(cfg.Budget.HourlyCap),
+		}
+		if !req.OverrideBudget {
+			log.Printf("Rejected launch of %s for user %s: budget cap exceeded", name, user)
+			return launchLimits{}, invalid(messages.Render(msgs().BudgetExceeded, data))
+		}
+		log.Printf("Admin %s overrode the budget cap to launch %s", user, name)
+		limits.Overridden = messages.Render(msgs().BudgetOverridden, data)
+	}
+	limits.Notice = usageNotice(cfg, usage+1, limit, current+req.Spec.HourlyCost)
+	return limits, nil
+}
+
+// provisionCluster creates the MAPT resource for a launch, then calls unlock,
+// and waits for it to become ready, reporting the outcome in the launch
+// thread. notice is appended to the ready message.
+func provisionCluster(ctx context.Context, api *slack.Client, client *KubernetesClients, event *slackevents.MessageEvent, req *LaunchRequest, cluster *unstructured.Unstructured, threadTS, notice string, unlock func()) {
+	defer unlock()
+	name := cluster.GetName()
+	ctx, span := tracing.Start(ctx, "provision cluster", tracing.AttrCluster.String(name))
+	defer span.End()
+
+	reply := func(text string) {
+		respond.Thread(api, event.Channel, threadTS, text)
+	}
+	permalink := threadPermalink(api, event.Channel, threadTS)
+	SetLaunchMetadata(cluster, LaunchMetadata{Permalink: permalink})
+
+	if warning := checkCapacity(ctx, currentCapacityChecker(), req); warning != "" {
+		log.Printf("Spot capacity is low for launch %s of user %s", name, event.User)
+		reply(warning)
+	}
+	if warning := checkNamespaceQuota(ctx, client, cluster.GetNamespace(), req); warning != "" {
+		log.Printf("Launch %s of user %s may exceed the resource quotas of namespace %s", name, event.User, cluster.GetNamespace())
+		reply(warning)
+	}
+
+	err := client.CrClient.Create(ctx, cluster)
+	unlock()
 	if err != nil {
-		log.Printf("Error listing MAPT openshift clusters: %v", err)
-		respondError(api, event.Channel, "❌ Failed to retrieve cluster list")
+		log.Printf("Error creating MAPT %s cluster %s: %v", req.Type, name, err)
+		reply(backendError(err, msgs().LaunchFailed))
+		recordLaunchOutcome(launchOutcome{Time: time.Now(), Reason: createFailureReason(err)})
 		return
 	}
+	created := time.Now()
+	info := ClusterInfo{Name: name, Namespace: cluster.GetNamespace(), Type: req.Type, Annotations: cluster.GetAnnotations()}
+	notify(api, clusterNotification(EventLaunched, info, event.User))
+	lifecycle := func(kind, text string) {
+		notification := clusterNotification(kind, info, event.User)
+		notification.Text, notification.Channel, notification.ThreadTS = text, event.Channel, threadTS
+		notify(api, notification)
+	}
 
-	totalClusters := len(kindsList.Items) + len(openshiftsList.Items)
+	timeout := provisionTimeout(req)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ctx, unwatch := watchLaunch(ctx, name)
+	defer unwatch()
 
-	// If no clusters found
-	if totalClusters == 0 {
-		message := "📋 *Cluster List*\n\nNo MAPT clusters currently running."
-		if _, _, err := api.PostMessage(event.Channel, slack.MsgOptionText(message, false)); err != nil {
-			log.Printf("Error posting list message: %v", err)
-		}
-		return
+	progress := startLaunchProgress(api, event.Channel, threadTS, name)
+	phase, err := waitForCluster(ctx, client, cluster, progress.Observe)
+	if err == nil {
+		progress.Finish(phase)
+	}
+	var cancelled launchCancelled
+	switch {
+	case errors.As(context.Cause(ctx), &cancelled):
+		log.Printf("Stopped watching MAPT %s cluster %s: %v", req.Type, name, context.Cause(ctx))
+		progress.Finish(phaseCancelled)
+		reply(messages.Render(msgs().LaunchCancelled, messages.Data{"Name": name, "User": cancelled.User}))
+	case err != nil:
+		log.Printf("Stopped watching MAPT %s cluster %s: %v", req.Type, name, err)
+		reply(messages.Render(msgs().LaunchWatchTimeout, messages.Data{
+			"Name":    name,
+			"Timeout": messages.FormatDuration(timeout),
+		}))
+		recordLaunchOutcome(launchOutcome{Time: time.Now(), Reason: reasonWatchTimeout})
+	case phase == phaseDeleted:
+		log.Printf("MAPT %s cluster %s was deleted while provisioning", req.Type, name)
+		reply(messages.Render(msgs().LaunchDeleted, messages.Data{"Name": name}))
+	case phase == phaseReady:
+		log.Printf("MAPT %s cluster %s is ready", req.Type, name)
+		recordLaunchOutcome(launchOutcome{Time: time.Now(), Ready: true, TimeToReady: time.Since(created)})
+		lifecycle(EventReady, messages.Render(msgs().LaunchReady, messages.Data{"Name": name, "User": event.User, "Permalink": permalink})+notice)
+	default:
+		log.Printf("MAPT %s cluster %s failed to provision", req.Type, name)
+		recordLaunchOutcome(launchOutcome{Time: time.Now(), Reason: reasonProvisionFailed})
+		lifecycle(EventFailed, messages.Render(msgs().LaunchProvisionFailed, messages.Data{"Name": name, "User": event.User, "Permalink": permalink}))
 	}
+}
 
-	// Format the cluster list
-	var message strings.Builder
-	message.WriteString(fmt.Sprintf("📋 *Cluster List* (%d cluster%s)\n\n",
-		totalClusters,
-		func() string {
-			if totalClusters == 1 {
-				return ""
-			} else {
-				return "s"
-			}
-		}()))
-
-	clusterIndex := 0
-
-	// Add Kind clusters
-	for _, cluster := range kindsList.Items {
-		message.WriteString(fmt.Sprintf(
-			"🔸 *%s* (Kubernetes)\n"+
-				"   • Namespace: %s\n"+
-				"   • Created: %s\n",
-			cluster.Name,
-			cluster.Namespace,
-			cluster.CreationTimestamp.Format("2006-01-02 15:04:05"),
-		))
-
-		if clusterIndex < totalClusters-1 {
-			message.WriteString("\n")
-		}
-		clusterIndex++
-	}
-
-	// Add OpenShift clusters
-	for _, cluster := range openshiftsList.Items {
-		name := cluster.GetName()
-		namespace := cluster.GetNamespace()
-		creationTime := cluster.GetCreationTimestamp().Format("2006-01-02 15:04:05")
-
-		message.WriteString(fmt.Sprintf(
-			"🔸 *%s* (OpenShift)\n"+
-				"   • Namespace: %s\n"+
-				"   • Created: %s\n",
-			name,
-			namespace,
-			creationTime,
-		))
-
-		if clusterIndex < totalClusters-1 {
-			message.WriteString("\n")
-		}
-		clusterIndex++
+// threadPermalink returns the permalink to the launch thread started by
+// threadTS, or "" when there is no thread or the link cannot be fetched; the
+// results are then posted without it.
+func threadPermalink(api *slack.Client, channel, threadTS string) string {
+	if threadTS == "" {
+		return ""
 	}
+	permalink, err := respond.Permalink(api, channel, threadTS)
+	if err != nil {
+		log.Printf("Error getting the permalink of launch thread %s in %s: %v", threadTS, channel, err)
+		return ""
+	}
+	return permalink
+}
 
-	log.Printf("Listed %d MAPT clusters (%d kinds, %d openshifts) for user %s",
-		totalClusters, len(kindsList.Items), len(openshiftsList.Items), event.User)
+// clusterKinds maps the cluster type keys to the MAPT resource kind created for them.
+var clusterKinds = map[string]string{
+	"k8s":       "Kind",
+	"openshift": "Openshift",
+}
 
-	// Post the result back to Slack
-	if _, _, err := api.PostMessage(event.Channel, slack.MsgOptionText(message.String(), false)); err != nil {
-		log.Printf("Error posting list message: %v", err)
+// generateClusterName returns a unique, DNS-compatible name for a new cluster.
+func generateClusterName(clusterType string) string {
+	return fmt.Sprintf("spoticus-%s-%s", clusterType, utilrand.String(5))
+}
+
+// newClusterObject builds the MAPT resource for the given launch request.
+// The resource is built unstructured so both cluster types share a single code path.
+func newClusterObject(name string, req *LaunchRequest) *unstructured.Unstructured {
+	spec := map[string]interface{}{"spot": true}
+	if req.InstanceType != "" {
+		spec["instanceType"] = req.InstanceType
+	} else {
+		resources := req.Resources()
+		spec["cpus"] = int64(resources.CPUs)
+		spec["memory"] = int64(resources.MemoryGB)
+	}
+	if req.Version != "" {
+		spec["version"] = req.Version
 	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(maptApi.GroupVersion.WithKind(clusterKinds[req.Type]))
+	obj.SetName(name)
+	obj.SetNamespace(req.Namespace)
+	applySpecOverrides(obj, req.Overrides)
+	return obj
 }
 
 // isSupportedClusterType checks if the provided cluster type is one of the supported ones.
@@ -284,19 +429,24 @@ func isSupportedClusterType(t string) bool {
 // This is used in error messages to inform the user of acceptable input values.
 func formatSupportedSizes() string {
 	var b strings.Builder
-	for name, spec := range supportedSizes {
-		b.WriteString(fmt.Sprintf("• `%s`: %s, %s\n", name, spec.CPU, spec.RAM))
+	for name, spec := range spoticusConfig.Get().Sizes {
+		b.WriteString(fmt.Sprintf("• `%s`: %s, %s\n", name, spec.CPU(), spec.RAM()))
 	}
 	return b.String()
 }
 
+// msgs returns the message templates of the current configuration.
+func msgs() messages.Messages {
+	return spoticusConfig.Get().Messages
+}
+
 // respondError sends a standardized error message to the given Slack channel.
 //
 // This is used to provide consistent and visible feedback to the user
 // when the input is invalid, missing, or unsupported.
 // It logs any failures during Slack message delivery.
 func respondError(api *slack.Client, channel, text string) {
-	if _, _, err := api.PostMessage(channel, slack.MsgOptionText(text, false)); err != nil {
+	if _, _, err := respond.Post(api, channel, slack.MsgOptionText(text, false)); err != nil {
 		log.Printf("Slack error response failed: %v", err)
 	}
 }
@@ -0,0 +1,435 @@
+package commands
+
+import (
+	"errors"
+	"log"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// defaultProvider is the cloud provider clusters are launched on.
+const defaultProvider = spoticusConfig.DefaultProvider
+
+// launchFlags lists the `--key=value` flags parseLaunchArgs understands.
+// `--set`, `--at` and `--in` are extracted before it runs.
+var launchFlags = map[string]bool{
+	"instance-type":   true,
+	"version":         true,
+	"k8s-version":     true,
+	"override-budget": true,
+	"cpu-limit":       true,
+	"mem-limit":       true,
+	"ref":             true,
+	"ttl":             true,
+}
+
+// maxRefLength bounds --ref so that it stays readable in messages.
+const maxRefLength = 64
+
+// LaunchRequest is a parsed and validated "launch" command.
+type LaunchRequest struct {
+	Type     string
+	Provider string
+
+	// Size and Spec describe the requested size tier. Size is empty when
+	// InstanceType is set, since an explicit instance type bypasses the
+	// tiers; Spec is then the instance type's spec, if it has one.
+	Size string
+	Spec spoticusConfig.SizeSpec
+
+	// InstanceType is the cloud instance type requested with --instance-type.
+	InstanceType string
+
+	// CPULimit and MemoryLimitGB lower the resources requested from MAPT
+	// below those of the size tier, from --cpu-limit and --mem-limit. Zero
+	// keeps the tier's value.
+	CPULimit      int
+	MemoryLimitGB int
+
+	// Version is the OpenShift version to install on openshift clusters, or
+	// the Kubernetes version on k8s clusters.
+	Version string
+
+	// Overrides are the --set passthroughs applied onto the MAPT spec.
+	Overrides []SpecOverride
+
+	// Ref is the ticket or work item the launch is for, from --ref.
+	Ref string
+
+	// OverrideBudget launches the cluster even if it would exceed the budget cap.
+	OverrideBudget bool
+
+	// Namespace and TTL are resolved from the channel's defaults, falling
+	// back to the global configuration. --ttl replaces the TTL, within the
+	// maximum of the size.
+	Namespace string
+	TTL       time.Duration
+
+	// ChannelDefaults describes the channel defaults that were applied, for
+	// reporting back to the user.
+	ChannelDefaults []string
+}
+
+// parseLaunchArgs parses the arguments of the "launch" command.
+//
+// The cluster type and size are positional; options are given as
+// `--key=value` flags and may appear anywhere after the command name.
+// `--instance-type` replaces the size, so the two are mutually exclusive.
+// `--set path=value` may be repeated to set arbitrary spec fields.
+// The namespace, TTL and, when no size is given, the size come from the
+// defaults of the channel the launch was requested in; the namespace
+// otherwise comes from the cluster type's.
+// The returned error is suitable to be shown to the user as-is.
+func parseLaunchArgs(args []string, channel string) (*LaunchRequest, error) {
+	args, sets := extractSetFlags(args)
+	positional, flags := splitArgs(args)
+	cfg := spoticusConfig.Get()
+	var clusterType string
+	if len(positional) > 0 {
+		clusterType = strings.ToLower(positional[0])
+	}
+	defaults := cfg.DefaultsFor(channel, clusterType)
+
+	instanceType, hasInstanceType := flags["instance-type"]
+	required := 2
+	if hasInstanceType || defaults.Size != "" {
+		required = 1
+	}
+	if len(positional) < required {
+		return nil, missingLaunchArgs(cfg, positional, hasInstanceType)
+	}
+	if arg := unrecognizedLaunchArg(args, positional); arg != "" {
+		if !cfg.LenientArgs {
+			return nil, errors.New(messages.Render(cfg.Messages.UnrecognizedArgument, messages.Data{"Arg": arg}))
+		}
+		log.Printf("Ignoring unrecognized launch argument %q", arg)
+	}
+
+	req := &LaunchRequest{
+		Type:      clusterType,
+		Provider:  defaultProvider,
+		Namespace: defaults.Namespace,
+		TTL:       defaults.TTL,
+
+		ChannelDefaults: slices.Clone(defaults.Applied),
+	}
+
+	// Validate cluster type
+	if !isSupportedClusterType(req.Type) {
+		if len(positional) == 1 {
+			return nil, missingLaunchArgs(cfg, positional, hasInstanceType)
+		}
+		return nil, errors.New(messages.Render(cfg.Messages.UnsupportedType, messages.Data{"Type": req.Type}))
+	}
+
+	if hasInstanceType {
+		if len(positional) > 1 {
+			return nil, errors.New(cfg.Messages.InstanceTypeWithSize)
+		}
+		allowed := cfg.InstanceTypes[req.Provider]
+		if !slices.Contains(allowed, instanceType) {
+			return nil, errors.New(messages.Render(cfg.Messages.UnsupportedInstanceType, messages.Data{
+				"InstanceType": instanceType,
+				"Provider":     req.Provider,
+				"Allowed":      formatList(allowed),
+			}))
+		}
+		spec, priced := cfg.InstanceTypeSpecs[instanceType]
+		if !priced && (cfg.Budget.HourlyCap > 0 || cfg.ConfirmCPUs > 0) {
+			return nil, errors.New(messages.Render(cfg.Messages.InstanceTypeUnpriced, messages.Data{"InstanceType": instanceType}))
+		}
+		req.InstanceType = instanceType
+		req.Spec = spec
+	} else {
+		if len(positional) > 1 {
+			req.Size = strings.ToLower(positional[1])
+		} else {
+			req.Size = defaults.Size
+			req.ChannelDefaults = append(req.ChannelDefaults, "size `"+req.Size+"`")
+		}
+		spec, ok := cfg.Sizes[req.Size]
+		if !ok {
+			return nil, errors.New(messages.Render(cfg.Messages.InvalidSize, messages.Data{
+				"Size":  req.Size,
+				"Sizes": formatSupportedSizes(),
+			}))
+		}
+		if !cfg.AllowsSize(req.Type, req.Size) {
+			return nil, errors.New(messages.Render(cfg.Messages.SizeNotAllowed, messages.Data{
+				"Type":    req.Type,
+				"Size":    req.Size,
+				"Allowed": formatList(cfg.AllowedSizes(req.Type)),
+			}))
+		}
+		req.Spec = spec
+	}
+
+	version, hasVersion := flags["version"]
+	switch {
+	case hasVersion && req.Type != "openshift":
+		return nil, errors.New(messages.Render(cfg.Messages.VersionNotSupported, messages.Data{"Type": req.Type}))
+	case hasVersion:
+		if !slices.Contains(cfg.OpenshiftVersions, version) {
+			return nil, errors.New(messages.Render(cfg.Messages.UnsupportedVersion, messages.Data{
+				"Version": version,
+				"Allowed": formatList(cfg.OpenshiftVersions),
+			}))
+		}
+		req.Version = version
+	case req.Type == "openshift":
+		req.Version = cfg.DefaultOpenshiftVersion
+	}
+
+	k8sVersion, hasK8sVersion := flags["k8s-version"]
+	switch {
+	case hasK8sVersion && req.Type != "k8s":
+		return nil, errors.New(messages.Render(cfg.Messages.K8sVersionNotSupported, messages.Data{"Type": req.Type}))
+	case hasK8sVersion:
+		if !slices.Contains(cfg.K8sVersions, k8sVersion) {
+			return nil, errors.New(messages.Render(cfg.Messages.UnsupportedK8sVersion, messages.Data{
+				"Version": k8sVersion,
+				"Allowed": formatList(cfg.K8sVersions),
+			}))
+		}
+		req.Version = k8sVersion
+	case req.Type == "k8s":
+		req.Version = cfg.DefaultK8sVersion
+	}
+
+	if err := parseResourceLimits(cfg, req, flags); err != nil {
+		return nil, err
+	}
+
+	if ref, ok := flags["ref"]; ok {
+		if !validRef(cfg, ref) {
+			return nil, errors.New(messages.Render(cfg.Messages.InvalidRef, messages.Data{
+				"Ref":     ref,
+				"Pattern": cfg.RefPattern,
+			}))
+		}
+		req.Ref = ref
+	}
+
+	if _, ok := flags["override-budget"]; ok {
+		req.OverrideBudget = true
+	}
+
+	if err := applyTTL(cfg, req, flags); err != nil {
+		return nil, err
+	}
+
+	overrides, err := parseSpecOverrides(sets)
+	if err != nil {
+		return nil, err
+	}
+	req.Overrides = overrides
+
+	if region := req.Region(); region != "" && !cfg.AllowsRegion(req.Provider, region) {
+		policy := cfg.Regions[req.Provider]
+		return nil, errors.New(messages.Render(cfg.Messages.RegionNotAllowed, messages.Data{
+			"Region":   region,
+			"Provider": req.Provider,
+			"Allowed":  formatList(policy.Allowed()),
+			"Denied":   formatList(policy.Deny),
+		}))
+	}
+
+	return req, nil
+}
+
+// parseResourceLimits applies the --cpu-limit and --mem-limit flags to the
+// request. Limits only apply to size tiers and must lie between 1 and the
+// tier's own CPUs or memory. Memory may be given with a "GB" or "G" suffix.
+func parseResourceLimits(cfg *spoticusConfig.Config, req *LaunchRequest, flags map[string]string) error {
+	cpu, hasCPU := flags["cpu-limit"]
+	mem, hasMem := flags["mem-limit"]
+	if !hasCPU && !hasMem {
+		return nil
+	}
+	if req.InstanceType != "" {
+		return errors.New(cfg.Messages.LimitWithInstanceType)
+	}
+
+	parse := func(flag, value, number, unit string, max int) (int, error) {
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 1 || n > max {
+			return 0, errors.New(messages.Render(cfg.Messages.InvalidLimit, messages.Data{
+				"Flag":  flag,
+				"Value": value,
+				"Unit":  unit,
+				"Max":   max,
+				"Size":  req.Size,
+			}))
+		}
+		return n, nil
+	}
+
+	var err error
+	if hasCPU {
+		if req.CPULimit, err = parse("cpu-limit", cpu, cpu, "CPUs", req.Spec.CPUs); err != nil {
+			return err
+		}
+	}
+	if hasMem {
+		number := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(mem), "B"), "G")
+		if req.MemoryLimitGB, err = parse("mem-limit", mem, number, "GB", req.Spec.MemoryGB); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyTTL applies the --ttl flag to the request and enforces the maximum
+// lifetime of its size: a --ttl beyond it is rejected with the maximum, while
+// a default TTL beyond it is lowered to it. TTLs are Go durations or a number
+// of days, e.g. "12h" or "3d".
+func applyTTL(cfg *spoticusConfig.Config, req *LaunchRequest, flags map[string]string) error {
+	maxTTL := cfg.MaxTTLFor(req.Size)
+	value, ok := flags["ttl"]
+	if !ok {
+		if maxTTL > 0 && req.TTL > maxTTL {
+			log.Printf("Lowering the default TTL %s of a %s launch to the maximum of %s", req.TTL, req.Size, maxTTL)
+			req.TTL = maxTTL
+		}
+		return nil
+	}
+
+	ttl, err := parseTTL(value)
+	if err != nil {
+		return errors.New(messages.Render(cfg.Messages.InvalidTTL, messages.Data{"TTL": value}))
+	}
+	if maxTTL > 0 && ttl > maxTTL {
+		return errors.New(messages.Render(cfg.Messages.TTLTooLong, messages.Data{
+			"TTL":  messages.FormatDuration(ttl),
+			"Max":  messages.FormatDuration(maxTTL),
+			"Size": req.Size,
+		}))
+	}
+	req.TTL = ttl
+	return nil
+}
+
+// parseTTL parses a positive --ttl, given as a Go duration or as a number of
+// days such as "3d".
+func parseTTL(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, errors.New("invalid TTL")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, errors.New("invalid TTL")
+	}
+	return d, nil
+}
+
+// validRef reports whether ref is an acceptable --ref: a non-empty value
+// without spaces of at most maxRefLength characters, matching the configured
+// pattern in full if there is one.
+func validRef(cfg *spoticusConfig.Config, ref string) bool {
+	if ref == "" || len(ref) > maxRefLength || strings.ContainsAny(ref, " \t") {
+		return false
+	}
+	if cfg.RefPattern == "" {
+		return true
+	}
+	pattern, err := regexp.Compile("^(?:" + cfg.RefPattern + ")$")
+	return err == nil && pattern.MatchString(ref)
+}
+
+// Resources returns the CPUs and memory requested from MAPT: those of the
+// size tier, lowered by the resource limits if any were given.
+func (r *LaunchRequest) Resources() spoticusConfig.SizeSpec {
+	spec := r.Spec
+	if r.CPULimit > 0 {
+		spec.CPUs = r.CPULimit
+	}
+	if r.MemoryLimitGB > 0 {
+		spec.MemoryGB = r.MemoryLimitGB
+	}
+	return spec
+}
+
+// unrecognizedLaunchArg returns the first argument of a launch that is
+// neither a known flag nor one of the type and size positional arguments, or
+// "" if there is none. A size given together with `--instance-type` is left
+// for the dedicated error.
+func unrecognizedLaunchArg(args, positional []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		key, _, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if !launchFlags[strings.ToLower(key)] {
+			return arg
+		}
+	}
+
+	if len(positional) > 2 {
+		return positional[2]
+	}
+	return ""
+}
+
+// missingLaunchArgs explains which positional argument of a launch is missing.
+//
+// When the one argument given is a cluster type the user is asked for a size,
+// and when it is a size (or `--instance-type` replaces the size) they are
+// asked for a type. Without anything to go on, the full usage is shown.
+func missingLaunchArgs(cfg *spoticusConfig.Config, positional []string, hasInstanceType bool) error {
+	var given string
+	if len(positional) > 0 {
+		given = strings.ToLower(positional[0])
+	}
+	_, isSize := cfg.Sizes[given]
+
+	switch {
+	case given != "" && isSupportedClusterType(given):
+		return errors.New(messages.Render(cfg.Messages.LaunchChooseSize, messages.Data{
+			"Type":  given,
+			"Sizes": formatList(cfg.AllowedSizes(given)),
+		}))
+	case isSize || (given == "" && hasInstanceType):
+		return errors.New(messages.Render(cfg.Messages.LaunchChooseType, messages.Data{
+			"Size":  given,
+			"Types": formatList(slices.Sorted(maps.Keys(supportedClusterTypes))),
+		}))
+	case given != "":
+		return errors.New(messages.Render(cfg.Messages.UnsupportedType, messages.Data{"Type": given}))
+	}
+	return errors.New(messages.Render(cfg.Messages.MissingLaunchArgs, messages.Data{"Usage": cfg.Branding.Text(LaunchUsage)}))
+}
+
+// splitArgs separates positional arguments from `--key=value` flags.
+// A flag given without a value is recorded with an empty value.
+func splitArgs(args []string) (positional []string, flags map[string]string) {
+	flags = map[string]string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		flags[strings.ToLower(key)] = value
+	}
+	return positional, flags
+}
+
+// formatList renders values as a comma-separated list of inline code spans.
+func formatList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "`" + v + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,555 @@
+package commands
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+)
+
+func TestParseLaunchArgsRegionPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		regions map[string]spoticusConfig.RegionPolicy
+		region  string
+		wantErr string
+	}{
+		{name: "no policy allows any region", region: "ap-south-1"},
+		{name: "no region pinned", regions: map[string]spoticusConfig.RegionPolicy{"aws": {Allow: []string{"us-east-1"}}}},
+		{name: "allowed", regions: map[string]spoticusConfig.RegionPolicy{"aws": {Allow: []string{"us-east-1", "eu-west-1"}}}, region: "eu-west-1"},
+		{
+			name:    "not allowed lists the allowed regions",
+			regions: map[string]spoticusConfig.RegionPolicy{"aws": {Allow: []string{"us-east-1", "us-east-2", "eu-west-1"}, Deny: []string{"us-east-2"}}},
+			region:  "ap-south-1",
+			wantErr: "Allowed regions: `us-east-1`, `eu-west-1`",
+		},
+		{
+			name:    "denied",
+			regions: map[string]spoticusConfig.RegionPolicy{"aws": {Deny: []string{"us-east-2"}}},
+			region:  "us-east-2",
+			wantErr: "Denied regions: `us-east-2`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := spoticusConfig.Default()
+			cfg.Regions = tt.regions
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("invalid test configuration: %v", err)
+			}
+			useConfig(t, cfg)
+			args := []string{"k8s", "medium"}
+			if tt.region != "" {
+				args = append(args, "--set", "region="+tt.region)
+			}
+
+			req, err := parseLaunchArgs(args, "C1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseLaunchArgs() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLaunchArgs: %v", err)
+			}
+			if req.Provider != defaultProvider || req.Region() != tt.region {
+				t.Errorf("launch on %s in %q, want %s in %q", req.Provider, req.Region(), defaultProvider, tt.region)
+			}
+		})
+	}
+}
+
+func TestParseLaunchArgsOpenshiftVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "allowed version", args: []string{"openshift", "medium", "--version=4.17.0"}, want: "4.17.0"},
+		{name: "default version", args: []string{"openshift", "medium"}, want: "4.19.0"},
+		{name: "unsupported version", args: []string{"openshift", "medium", "--version=4.2.0"}, wantErr: "`4.17.0`, `4.18.0`, `4.19.0`"},
+		{name: "version of a k8s cluster", args: []string{"k8s", "medium", "--version=4.17.0"}, wantErr: "--version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, spoticusConfig.Default())
+
+			req, err := parseLaunchArgs(tt.args, "C1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseLaunchArgs() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLaunchArgs: %v", err)
+			}
+			if req.Version != tt.want {
+				t.Errorf("version = %q, want %q", req.Version, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLaunchArgsInstanceType(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "allowed instance type", args: []string{"k8s", "--instance-type=m6i.4xlarge"}},
+		{name: "instance type with a size", args: []string{"k8s", "medium", "--instance-type=m6i.4xlarge"}, wantErr: "--instance-type"},
+		{name: "instance type not allowed", args: []string{"k8s", "--instance-type=p4d.24xlarge"}, wantErr: "`m6i.2xlarge`"},
+		{name: "limits with an instance type", args: []string{"k8s", "--instance-type=m6i.4xlarge", "--cpu-limit=2"}, wantErr: "cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, spoticusConfig.Default())
+
+			req, err := parseLaunchArgs(tt.args, "C1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseLaunchArgs() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLaunchArgs: %v", err)
+			}
+			if req.InstanceType != "m6i.4xlarge" || req.Size != "" {
+				t.Errorf("instance type %q and size %q, want the instance type and no size", req.InstanceType, req.Size)
+			}
+		})
+	}
+}
+
+func TestParseLaunchArgsInstanceTypeSpec(t *testing.T) {
+	cfg := spoticusConfig.Default()
+	cfg.InstanceTypes["aws"] = append(cfg.InstanceTypes["aws"], "p4d.24xlarge")
+	useConfig(t, cfg)
+
+	req, err := parseLaunchArgs([]string{"k8s", "--instance-type=m6i.8xlarge"}, "C1")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs: %v", err)
+	}
+	if req.Spec != cfg.InstanceTypeSpecs["m6i.8xlarge"] {
+		t.Errorf("spec %+v, want the instance type's %+v", req.Spec, cfg.InstanceTypeSpecs["m6i.8xlarge"])
+	}
+
+	// p4d.24xlarge is allowed but has no spec, so its cost is unknown.
+	if _, err := parseLaunchArgs([]string{"k8s", "--instance-type=p4d.24xlarge"}, "C1"); err == nil || !strings.Contains(err.Error(), "cost of instance type *p4d.24xlarge* is unknown") {
+		t.Errorf("parseLaunchArgs() of an unpriced instance type error = %v, want it refused", err)
+	}
+	cfg.ConfirmCPUs = 0
+	cfg.Budget.HourlyCap = 5
+	if _, err := parseLaunchArgs([]string{"k8s", "--instance-type=p4d.24xlarge"}, "C1"); err == nil {
+		t.Error("parseLaunchArgs() of an unpriced instance type under a budget cap succeeded")
+	}
+	cfg.Budget.HourlyCap = 0
+	req, err = parseLaunchArgs([]string{"k8s", "--instance-type=p4d.24xlarge"}, "C1")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs() without a budget cap or confirmation: %v", err)
+	}
+	if req.Spec != (spoticusConfig.SizeSpec{}) {
+		t.Errorf("spec %+v of an unpriced instance type, want none", req.Spec)
+	}
+}
+
+func TestNewClusterObjectInstanceType(t *testing.T) {
+	useConfig(t, spoticusConfig.Default())
+	req, err := parseLaunchArgs([]string{"k8s", "--instance-type=m6i.4xlarge"}, "C1")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs: %v", err)
+	}
+
+	spec := newClusterObject("c1", req).Object["spec"].(map[string]interface{})
+	if spec["instanceType"] != "m6i.4xlarge" {
+		t.Errorf("spec.instanceType = %v, want m6i.4xlarge", spec["instanceType"])
+	}
+	if _, ok := spec["cpus"]; ok {
+		t.Errorf("spec %v has cpus, want the size mapping skipped", spec)
+	}
+	if _, ok := spec["memory"]; ok {
+		t.Errorf("spec %v has memory, want the size mapping skipped", spec)
+	}
+}
+
+func TestNewClusterObjectSize(t *testing.T) {
+	useConfig(t, spoticusConfig.Default())
+	req, err := parseLaunchArgs([]string{"k8s", "large"}, "C1")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs: %v", err)
+	}
+
+	spec := newClusterObject("c1", req).Object["spec"].(map[string]interface{})
+	if spec["cpus"] != int64(16) || spec["memory"] != int64(64) {
+		t.Errorf("spec %v, want the 16 CPUs and 64 GB of large", spec)
+	}
+	if _, ok := spec["instanceType"]; ok {
+		t.Errorf("spec %v has an instance type, want none", spec)
+	}
+}
+
+func TestParseLaunchArgsPartialInput(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		typeSizes map[string][]string
+		want      string
+	}{
+		{name: "nothing", want: "Missing arguments."},
+		{name: "type only", args: []string{"k8s"}, want: "you specified type `k8s`; now choose a size: `large`, `medium`, `xlarge`"},
+		{name: "type with restricted sizes", args: []string{"openshift"}, typeSizes: map[string][]string{"openshift": {"xlarge"}}, want: "now choose a size: `xlarge`\n"},
+		{name: "size only", args: []string{"large"}, want: "you specified size `large`; now choose a cluster type: `k8s`, `openshift`\nFor example: `launch <type> large`"},
+		{name: "instance type only", args: []string{"--instance-type=m6i.2xlarge"}, want: "Missing cluster type: choose a cluster type: `k8s`, `openshift`"},
+		{name: "unknown word", args: []string{"windows"}, want: "Unsupported cluster type: *windows*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := spoticusConfig.Default()
+			cfg.TypeSizes = tt.typeSizes
+			useConfig(t, cfg)
+
+			_, err := parseLaunchArgs(tt.args, "C1")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseLaunchArgs(%q) error = %v, want it to contain %q", tt.args, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLaunchArgsChannelDefaults(t *testing.T) {
+	cfg := spoticusConfig.Default()
+	cfg.Channels = map[string]spoticusConfig.ChannelDefaults{
+		"CCI": {Namespace: "ci", DefaultTTL: spoticusConfig.Duration(2 * time.Hour), Size: "large"},
+	}
+	useConfig(t, cfg)
+
+	req, err := parseLaunchArgs([]string{"k8s"}, "CCI")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs in the CI channel: %v", err)
+	}
+	if req.Namespace != "ci" || req.TTL != 2*time.Hour || req.Size != "large" {
+		t.Errorf("request %+v, want the CI channel's namespace, TTL and size", req)
+	}
+	if want := []string{"namespace `ci`", "TTL `2h`", "size `large`"}; !slices.Equal(req.ChannelDefaults, want) {
+		t.Errorf("channel defaults %q, want %q", req.ChannelDefaults, want)
+	}
+
+	req, err = parseLaunchArgs([]string{"k8s", "medium"}, "CCI")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs with a size: %v", err)
+	}
+	if req.Size != "medium" || slices.Contains(req.ChannelDefaults, "size `large`") {
+		t.Errorf("request size %q with defaults %q, want the given size to win", req.Size, req.ChannelDefaults)
+	}
+
+	req, err = parseLaunchArgs([]string{"k8s", "medium"}, "COTHER")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs in another channel: %v", err)
+	}
+	if req.Namespace != "default" || req.TTL != cfg.TTL() || len(req.ChannelDefaults) != 0 {
+		t.Errorf("request %+v, want the global defaults", req)
+	}
+}
+
+func TestParseLaunchArgsTypeSizes(t *testing.T) {
+	cfg := spoticusConfig.Default()
+	cfg.TypeSizes = map[string][]string{"openshift": {"large", "xlarge"}}
+	useConfig(t, cfg)
+
+	for _, args := range [][]string{{"openshift", "large"}, {"openshift", "xlarge"}, {"k8s", "medium"}} {
+		if _, err := parseLaunchArgs(args, "C1"); err != nil {
+			t.Errorf("parseLaunchArgs(%q): %v", args, err)
+		}
+	}
+
+	_, err := parseLaunchArgs([]string{"openshift", "medium"}, "C1")
+	want := "*openshift* clusters cannot be launched with size *medium*.\nAllowed sizes for openshift: `large`, `xlarge`"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("parseLaunchArgs(openshift medium) error = %v, want it to contain %q", err, want)
+	}
+}
+
+func TestParseLaunchArgsRejectsUnrecognizedArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "extra positional", args: []string{"k8s", "large", "extra", "junk"}, want: "`extra`"},
+		{name: "unknown flag", args: []string{"k8s", "large", "--colour=blue"}, want: "`--colour=blue`"},
+		{name: "unknown flag before the type", args: []string{"--dry-run", "k8s", "large"}, want: "`--dry-run`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, spoticusConfig.Default())
+			_, err := parseLaunchArgs(tt.args, "C1")
+			if err == nil || !strings.Contains(err.Error(), "Unrecognized argument: "+tt.want) {
+				t.Errorf("parseLaunchArgs(%q) error = %v, want %s reported unrecognized", tt.args, err, tt.want)
+			}
+
+			lenient := spoticusConfig.Default()
+			lenient.LenientArgs = true
+			useConfig(t, lenient)
+			req, err := parseLaunchArgs(tt.args, "C1")
+			if err != nil {
+				t.Fatalf("parseLaunchArgs(%q) in lenient mode: %v", tt.args, err)
+			}
+			if req.Type != "k8s" || req.Size != "large" {
+				t.Errorf("lenient request %+v, want a large k8s launch", req)
+			}
+		})
+	}
+}
+
+func TestParseLaunchArgsAcceptsKnownFlags(t *testing.T) {
+	useConfig(t, spoticusConfig.Default())
+	args := []string{"k8s", "large", "--TTL=2h", "--ref=PR-12"}
+	if _, err := parseLaunchArgs(args, "C1"); err != nil {
+		t.Errorf("parseLaunchArgs(%q): %v", args, err)
+	}
+}
+
+func TestParseLaunchArgsResourceLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantCPU int
+		wantMem int
+		wantErr string
+	}{
+		{name: "cpu limit", args: []string{"k8s", "large", "--cpu-limit=4"}, wantCPU: 4},
+		{name: "memory limit with suffix", args: []string{"k8s", "large", "--mem-limit=16GB"}, wantMem: 16},
+		{name: "both at the size bounds", args: []string{"k8s", "large", "--cpu-limit=16", "--mem-limit=64g"}, wantCPU: 16, wantMem: 64},
+		{name: "cpu above the size", args: []string{"k8s", "large", "--cpu-limit=17"}, wantErr: "size *large* allows a whole number of CPUs between 1 and 16"},
+		{name: "memory above the size", args: []string{"k8s", "medium", "--mem-limit=33"}, wantErr: "size *medium* allows a whole number of GB between 1 and 32"},
+		{name: "zero", args: []string{"k8s", "large", "--cpu-limit=0"}, wantErr: "Invalid `--cpu-limit=0`"},
+		{name: "not a number", args: []string{"k8s", "large", "--mem-limit=lots"}, wantErr: "Invalid `--mem-limit=lots`"},
+		{name: "with an instance type", args: []string{"k8s", "--instance-type=m6i.4xlarge", "--cpu-limit=4"}, wantErr: "cannot be combined with `--instance-type`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, spoticusConfig.Default())
+			req, err := parseLaunchArgs(tt.args, "C1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseLaunchArgs(%q) error = %v, want it to contain %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLaunchArgs(%q): %v", tt.args, err)
+			}
+			if req.CPULimit != tt.wantCPU || req.MemoryLimitGB != tt.wantMem {
+				t.Errorf("limits %d CPUs, %d GB, want %d CPUs, %d GB", req.CPULimit, req.MemoryLimitGB, tt.wantCPU, tt.wantMem)
+			}
+		})
+	}
+}
+
+func TestNewClusterObjectResourceLimits(t *testing.T) {
+	useConfig(t, spoticusConfig.Default())
+	req, err := parseLaunchArgs([]string{"k8s", "large", "--cpu-limit=4"}, "C1")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs: %v", err)
+	}
+
+	spec := newClusterObject("c1", req).Object["spec"].(map[string]interface{})
+	if spec["cpus"] != int64(4) || spec["memory"] != int64(64) {
+		t.Errorf("spec %v, want the CPUs lowered to 4 and the 64 GB of large kept", spec)
+	}
+}
+
+func TestParseLaunchArgsRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		ref     string
+		wantErr string
+	}{
+		{name: "any reference", ref: "PROJ-123"},
+		{name: "matching pattern", pattern: `[A-Z]+-[0-9]+`, ref: "PROJ-123"},
+		{name: "pattern matched in full", pattern: `[A-Z]+-[0-9]+`, ref: "PROJ-123-extra", wantErr: "it must match `[A-Z]+-[0-9]+`"},
+		{name: "not matching pattern", pattern: `[A-Z]+-[0-9]+`, ref: "proj-123", wantErr: "it must match `[A-Z]+-[0-9]+`"},
+		{name: "empty", ref: "", wantErr: "give a ticket reference without spaces"},
+		{name: "too long", ref: strings.Repeat("A", maxRefLength+1), wantErr: "give a ticket reference without spaces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := spoticusConfig.Default()
+			cfg.RefPattern = tt.pattern
+			useConfig(t, cfg)
+
+			req, err := parseLaunchArgs([]string{"k8s", "medium", "--ref=" + tt.ref}, "C1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseLaunchArgs() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLaunchArgs: %v", err)
+			}
+			if req.Ref != tt.ref {
+				t.Errorf("Ref = %q, want %q", req.Ref, tt.ref)
+			}
+		})
+	}
+}
+
+func TestParseLaunchArgsK8sVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantVersion string
+		wantErr     string
+	}{
+		{name: "default", args: []string{"k8s", "medium"}, wantVersion: "1.33"},
+		{name: "allowed", args: []string{"k8s", "medium", "--k8s-version=1.31"}, wantVersion: "1.31"},
+		{name: "not allowed", args: []string{"k8s", "medium", "--k8s-version=1.20"}, wantErr: "Unsupported Kubernetes version: *1.20*"},
+		{name: "openshift", args: []string{"openshift", "medium", "--k8s-version=1.31"}, wantErr: "use `--version` for OpenShift"},
+		{name: "openshift default", args: []string{"openshift", "medium"}, wantVersion: "4.19.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, spoticusConfig.Default())
+			req, err := parseLaunchArgs(tt.args, "C1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseLaunchArgs() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLaunchArgs: %v", err)
+			}
+			if req.Version != tt.wantVersion {
+				t.Errorf("Version = %q, want %q", req.Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestNewClusterObjectK8sVersion(t *testing.T) {
+	useConfig(t, spoticusConfig.Default())
+	req, err := parseLaunchArgs([]string{"k8s", "medium", "--k8s-version=1.32"}, "C1")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs: %v", err)
+	}
+
+	obj := newClusterObject("c1", req)
+	if spec := obj.Object["spec"].(map[string]interface{}); spec["version"] != "1.32" || obj.GetKind() != "Kind" {
+		t.Errorf("%s spec %v, want a Kind with version 1.32", obj.GetKind(), spec)
+	}
+}
+
+func TestParseLaunchArgsTypeNamespaces(t *testing.T) {
+	cfg := spoticusConfig.Default()
+	cfg.TypeNamespaces = map[string]string{"openshift": "ocp"}
+	cfg.Channels = map[string]spoticusConfig.ChannelDefaults{"CCI": {Namespace: "ci"}}
+	useConfig(t, cfg)
+
+	tests := []struct {
+		name    string
+		args    []string
+		channel string
+		want    string
+	}{
+		{name: "type namespace", args: []string{"OpenShift", "large"}, channel: "C1", want: "ocp"},
+		{name: "global namespace", args: []string{"k8s", "medium"}, channel: "C1", want: "default"},
+		{name: "channel over type namespace", args: []string{"openshift", "large"}, channel: "CCI", want: "ci"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := parseLaunchArgs(tt.args, tt.channel)
+			if err != nil {
+				t.Fatalf("parseLaunchArgs: %v", err)
+			}
+			if req.Namespace != tt.want {
+				t.Errorf("namespace %q, want %q", req.Namespace, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "12h", want: 12 * time.Hour},
+		{value: "90m", want: 90 * time.Minute},
+		{value: "3d", want: 72 * time.Hour},
+		{value: "0d", wantErr: true},
+		{value: "-2h", wantErr: true},
+		{value: "1.5d", wantErr: true},
+		{value: "forever", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTTL(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTTL(%q) = %s, %v, want %s (error: %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseLaunchArgsEnforcesSizeMaxTTL(t *testing.T) {
+	cfg := spoticusConfig.Default()
+	spec := cfg.Sizes["large"]
+	spec.MaxTTL = spoticusConfig.Duration(72 * time.Hour)
+	cfg.Sizes["large"] = spec
+	useConfig(t, cfg)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    time.Duration
+		wantErr string
+	}{
+		{name: "default TTL", args: []string{"k8s", "medium"}, want: cfg.TTL()},
+		{name: "within the global maximum", args: []string{"k8s", "medium", "--ttl=7d"}, want: 7 * 24 * time.Hour},
+		{name: "over the global maximum", args: []string{"k8s", "medium", "--ttl=8d"}, wantErr: "for a *medium* cluster: the maximum is"},
+		{name: "within the size maximum", args: []string{"k8s", "large", "--ttl=3d"}, want: 72 * time.Hour},
+		{name: "over the size maximum", args: []string{"k8s", "large", "--ttl=4d"}, wantErr: "for a *large* cluster"},
+		{name: "over the default xlarge maximum", args: []string{"k8s", "xlarge", "--ttl=36h"}, wantErr: "for a *xlarge* cluster"},
+		{name: "instance type uses the global maximum", args: []string{"k8s", "--instance-type=m6i.4xlarge", "--ttl=5d"}, want: 5 * 24 * time.Hour},
+		{name: "invalid", args: []string{"k8s", "medium", "--ttl=soon"}, wantErr: "Invalid `--ttl=soon`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := parseLaunchArgs(tt.args, "C1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseLaunchArgs(%q) error = %v, want it to contain %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLaunchArgs(%q): %v", tt.args, err)
+			}
+			if req.TTL != tt.want {
+				t.Errorf("TTL %s, want %s", req.TTL, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLaunchArgsLowersDefaultTTLToSizeMax(t *testing.T) {
+	cfg := spoticusConfig.Default()
+	cfg.DefaultTTL = spoticusConfig.Duration(48 * time.Hour)
+	useConfig(t, cfg)
+
+	req, err := parseLaunchArgs([]string{"k8s", "xlarge"}, "C1")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs: %v", err)
+	}
+	if req.TTL != 24*time.Hour {
+		t.Errorf("TTL %s, want the default lowered to the xlarge maximum of 24h", req.TTL)
+	}
+}
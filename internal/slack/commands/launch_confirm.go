@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// launchConfirmWindow is how long an expensive launch waits for `launch confirm`.
+const launchConfirmWindow = 2 * time.Minute
+
+// pendingLaunch is an expensive launch waiting for confirmation.
+type pendingLaunch struct {
+	args    []string
+	command string
+	expires time.Time
+}
+
+var (
+	pendingLaunchesMu sync.Mutex
+	// pendingLaunches holds the latest unconfirmed launch per requesting user.
+	pendingLaunches = map[string]pendingLaunch{}
+)
+
+// requiresConfirmation reports whether the launch is of a size tier, or an
+// instance type, with at least the configured number of CPUs.
+func requiresConfirmation(cfg *spoticusConfig.Config, req *LaunchRequest) bool {
+	return cfg.ConfirmCPUs > 0 && req.Spec.CPUs >= cfg.ConfirmCPUs
+}
+
+// requestLaunchConfirmation remembers the launch and asks the user to confirm it.
+func requestLaunchConfirmation(api *slack.Client, event *slackevents.MessageEvent, args []string, req *LaunchRequest) {
+	pendingLaunchesMu.Lock()
+	pendingLaunches[event.User] = pendingLaunch{
+		args:    args,
+		command: event.Text,
+		expires: time.Now().Add(launchConfirmWindow),
+	}
+	pendingLaunchesMu.Unlock()
+
+	log.Printf("Holding %s%s launch for user %s until confirmed", req.Size, req.InstanceType, event.User)
+	respondError(api, event.Channel, messages.Render(msgs().LaunchConfirmRequired, messages.Data{
+		"Size":         req.Size,
+		"InstanceType": req.InstanceType,
+		"CPU":          req.Spec.CPU(),
+		"RAM":          req.Spec.RAM(),
+		"Cost":         formatHourlyCost(req.Spec.HourlyCost),
+		"Window":       launchConfirmWindow,
+	}))
+}
+
+// confirmLaunch runs the user's last held launch, if it has not expired.
+// The launch is parsed and checked again, so configuration changes made in
+// the meantime apply.
+func confirmLaunch(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent) error {
+	pendingLaunchesMu.Lock()
+	pending, ok := pendingLaunches[event.User]
+	delete(pendingLaunches, event.User)
+	pendingLaunchesMu.Unlock()
+
+	if !ok || time.Now().After(pending.expires) {
+		return invalid(msgs().LaunchNoPending)
+	}
+
+	log.Printf("User %s confirmed launch %q", event.User, pending.command)
+	confirmed := *event
+	confirmed.Text = pending.command
+	return launchCluster(ctx, api, &confirmed, pending.args, true)
+}
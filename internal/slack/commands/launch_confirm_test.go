@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// pendingLaunchOf returns the launch held for the user, if any, and discards
+// it when the test ends.
+func pendingLaunchOf(t *testing.T, user string) (pendingLaunch, bool) {
+	t.Helper()
+	t.Cleanup(func() {
+		pendingLaunchesMu.Lock()
+		delete(pendingLaunches, user)
+		pendingLaunchesMu.Unlock()
+	})
+	pendingLaunchesMu.Lock()
+	defer pendingLaunchesMu.Unlock()
+	pending, ok := pendingLaunches[user]
+	return pending, ok
+}
+
+func TestRequiresConfirmation(t *testing.T) {
+	cfg := spoticusConfig.Default()
+	tests := []struct {
+		name     string
+		confirm  int
+		req      *LaunchRequest
+		required bool
+	}{
+		{name: "size below the threshold", confirm: 32, req: &LaunchRequest{Size: "large", Spec: cfg.Sizes["large"]}},
+		{name: "size at the threshold", confirm: 32, req: &LaunchRequest{Size: "xlarge", Spec: cfg.Sizes["xlarge"]}, required: true},
+		{name: "lowered threshold", confirm: 16, req: &LaunchRequest{Size: "large", Spec: cfg.Sizes["large"]}, required: true},
+		{name: "confirmation disabled", confirm: 0, req: &LaunchRequest{Size: "xlarge", Spec: cfg.Sizes["xlarge"]}},
+		{name: "instance type below the threshold", confirm: 32, req: &LaunchRequest{InstanceType: "m6i.4xlarge", Spec: cfg.InstanceTypeSpecs["m6i.4xlarge"]}},
+		{name: "instance type at the threshold", confirm: 32, req: &LaunchRequest{InstanceType: "m6i.8xlarge", Spec: cfg.InstanceTypeSpecs["m6i.8xlarge"]}, required: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.ConfirmCPUs = tt.confirm
+			if got := requiresConfirmation(cfg, tt.req); got != tt.required {
+				t.Errorf("requiresConfirmation() = %v, want %v", got, tt.required)
+			}
+		})
+	}
+}
+
+func TestLaunchBelowThresholdProceeds(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U13", "C1", "launch k8s large"), []string{"k8s", "large"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+
+	server.WaitForMessage("is ready", 5*time.Second)
+	if _, ok := pendingLaunchOf(t, "U13"); ok {
+		t.Errorf("large launch held for confirmation, want it launched right away")
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want 1", len(kinds))
+	}
+}
+
+func TestLaunchAboveThresholdWaitsForConfirmation(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U14", "C1", "launch k8s xlarge"), []string{"k8s", "xlarge"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+
+	text := server.WaitForMessage("are expensive", time.Second).Text()
+	if !strings.Contains(text, "launch confirm") {
+		t.Errorf("reply %q does not say how to confirm", text)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Fatalf("got %d clusters before the confirmation, want none", len(kinds))
+	}
+	pending, ok := pendingLaunchOf(t, "U14")
+	if !ok {
+		t.Fatal("no launch held for the user")
+	}
+	if left := time.Until(pending.expires); left <= 0 || left > launchConfirmWindow {
+		t.Errorf("held launch expires in %s, want within %s", left, launchConfirmWindow)
+	}
+
+	if err := HandleLaunch(context.Background(), api, message("U14", "C1", "launch confirm"), []string{"confirm"}); err != nil {
+		t.Fatalf("HandleLaunch(confirm): %v", err)
+	}
+
+	server.WaitForMessage("is ready", 5*time.Second)
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 {
+		t.Fatalf("got %d clusters after the confirmation, want 1", len(kinds))
+	}
+	if metadata := ParseLaunchMetadata(kinds[0].Annotations); metadata.Size != "xlarge" {
+		t.Errorf("launched a %q cluster, want the held xlarge one", metadata.Size)
+	}
+	if _, ok := pendingLaunchOf(t, "U14"); ok {
+		t.Errorf("launch still held after the confirmation")
+	}
+}
+
+func TestLaunchOfLargeInstanceTypeWaitsForConfirmation(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	args := []string{"k8s", "--instance-type=m6i.8xlarge"}
+	if err := HandleLaunch(context.Background(), api, message("U33", "C1", "launch "+strings.Join(args, " ")), args); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+
+	text := server.WaitForMessage("*m6i.8xlarge* instances (32 CPUs, 128 GB RAM", time.Second).Text()
+	if !strings.Contains(text, "launch confirm") {
+		t.Errorf("reply %q does not say how to confirm", text)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Fatalf("got %d clusters before the confirmation, want none", len(kinds))
+	}
+	if _, ok := pendingLaunchOf(t, "U33"); !ok {
+		t.Fatal("no launch held for the user")
+	}
+
+	if err := HandleLaunch(context.Background(), api, message("U33", "C1", "launch confirm"), []string{"confirm"}); err != nil {
+		t.Fatalf("HandleLaunch(confirm): %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 || kinds[0].Spec["instanceType"] != "m6i.8xlarge" {
+		t.Fatalf("launched %+v after the confirmation, want the held m6i.8xlarge cluster", kinds)
+	}
+}
+
+func TestConfirmLaunchRejectsExpiredOrMissing(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKube()
+	useKube(t, kube)
+	api, _ := newAPI(t)
+
+	pendingLaunchOf(t, "U15") // discards the launch held below when the test ends
+	pendingLaunchesMu.Lock()
+	pendingLaunches["U15"] = pendingLaunch{
+		args:    []string{"k8s", "xlarge"},
+		command: "launch k8s xlarge",
+		expires: time.Now().Add(-time.Second),
+	}
+	pendingLaunchesMu.Unlock()
+
+	for _, user := range []string{"U15", "UNONE"} {
+		err := HandleLaunch(context.Background(), api, message(user, "C1", "launch confirm"), []string{"confirm"})
+		var cmdErr *CommandError
+		if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Nothing to confirm") {
+			t.Errorf("confirming for %s = %v, want nothing to confirm", user, err)
+		}
+	}
+	if _, ok := pendingLaunchOf(t, "U15"); ok {
+		t.Errorf("expired launch still held, want it discarded")
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Errorf("got %d clusters, want the expired launch not run", len(kinds))
+	}
+}
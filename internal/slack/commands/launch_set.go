@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// SpecOverride is a `--set path.to.field=value` passthrough onto the MAPT spec,
+// for options the bot does not model itself.
+type SpecOverride struct {
+	// Path is relative to the resource's spec.
+	Path []string
+
+	// Value is a bool, an int64 or a string.
+	Value interface{}
+}
+
+// managedSpecFields are the spec fields the bot sets itself. They cannot be
+// overridden with --set, since that would bypass the size, instance type and
+// version validation.
+var managedSpecFields = map[string]bool{
+	"spot":         true,
+	"cpus":         true,
+	"memory":       true,
+	"instanceType": true,
+	"version":      true,
+}
+
+// pathSegmentPattern matches a single segment of a --set path.
+var pathSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// extractSetFlags removes the --set flags from args, accepting both
+// `--set path=value` and `--set=path=value`, and returns their values in order.
+func extractSetFlags(args []string) (rest, sets []string) {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case strings.EqualFold(arg, "--set"):
+			if i+1 < len(args) {
+				i++
+				sets = append(sets, args[i])
+			} else {
+				sets = append(sets, "")
+			}
+		case len(arg) > len("--set=") && strings.EqualFold(arg[:len("--set=")], "--set="):
+			sets = append(sets, arg[len("--set="):])
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, sets
+}
+
+// parseSpecOverrides parses the --set values. Values are coerced to a bool or
+// an integer when they look like one; wrap a value in double quotes to keep it
+// a string. The returned error is suitable to be shown to the user as-is.
+func parseSpecOverrides(sets []string) ([]SpecOverride, error) {
+	templates := spoticusConfig.Get().Messages
+	overrides := make([]SpecOverride, 0, len(sets))
+	scratch := map[string]interface{}{}
+
+	for _, set := range sets {
+		path, raw, ok := strings.Cut(set, "=")
+		segments := strings.Split(path, ".")
+		if !ok || !validPath(segments) {
+			return nil, errors.New(messages.Render(templates.InvalidSet, messages.Data{"Set": set}))
+		}
+		if managedSpecFields[segments[0]] {
+			return nil, errors.New(messages.Render(templates.SetProtected, messages.Data{"Field": segments[0]}))
+		}
+
+		override := SpecOverride{Path: segments, Value: coerceValue(raw)}
+		// Apply onto a scratch spec to catch overrides that step on each other,
+		// e.g. `--set a=1 --set a.b=2`.
+		if err := unstructured.SetNestedField(scratch, override.Value, segments...); err != nil {
+			return nil, errors.New(messages.Render(templates.SetConflict, messages.Data{"Set": set}))
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+// validPath reports whether every segment of a --set path is well formed.
+func validPath(segments []string) bool {
+	for _, segment := range segments {
+		if !pathSegmentPattern.MatchString(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// coerceValue converts a --set value to a bool, an int64 or a string.
+func coerceValue(raw string) interface{} {
+	// Slack turns straight quotes into curly ones as users type.
+	for _, quotes := range [][2]string{{`"`, `"`}, {"“", "”"}} {
+		if len(raw) >= len(quotes[0])+len(quotes[1]) && strings.HasPrefix(raw, quotes[0]) && strings.HasSuffix(raw, quotes[1]) {
+			return raw[len(quotes[0]) : len(raw)-len(quotes[1])]
+		}
+	}
+	if raw == "true" || raw == "false" {
+		return raw == "true"
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return n
+	}
+	return raw
+}
+
+// applySpecOverrides sets the overrides onto the object's spec. They were
+// validated by parseSpecOverrides and never touch the managed fields, so
+// setting them cannot fail.
+func applySpecOverrides(obj *unstructured.Unstructured, overrides []SpecOverride) {
+	for _, override := range overrides {
+		path := append([]string{"spec"}, override.Path...)
+		_ = unstructured.SetNestedField(obj.Object, override.Value, path...)
+	}
+}
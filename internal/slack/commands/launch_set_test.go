@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+)
+
+func TestExtractSetFlags(t *testing.T) {
+	rest, sets := extractSetFlags([]string{"k8s", "--set", "a.b=1", "large", "--SET=c=true", "--set"})
+
+	if want := []string{"k8s", "large"}; !slices.Equal(rest, want) {
+		t.Errorf("rest = %q, want %q", rest, want)
+	}
+	if want := []string{"a.b=1", "c=true", ""}; !slices.Equal(sets, want) {
+		t.Errorf("sets = %q, want %q", sets, want)
+	}
+}
+
+func TestParseSpecOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		sets    []string
+		want    []SpecOverride
+		wantErr string
+	}{
+		{name: "nested bool", sets: []string{"network.airgap=true"}, want: []SpecOverride{{Path: []string{"network", "airgap"}, Value: true}}},
+		{name: "int", sets: []string{"disk.sizeGB=200"}, want: []SpecOverride{{Path: []string{"disk", "sizeGB"}, Value: int64(200)}}},
+		{name: "string", sets: []string{"region=us-east-1"}, want: []SpecOverride{{Path: []string{"region"}, Value: "us-east-1"}}},
+		{name: "quoted number stays a string", sets: []string{`tag="42"`}, want: []SpecOverride{{Path: []string{"tag"}, Value: "42"}}},
+		{name: "curly quotes", sets: []string{"tag=“true”"}, want: []SpecOverride{{Path: []string{"tag"}, Value: "true"}}},
+		{name: "empty value", sets: []string{"tag="}, want: []SpecOverride{{Path: []string{"tag"}, Value: ""}}},
+		{name: "sibling fields", sets: []string{"a.b=1", "a.c=2"}, want: []SpecOverride{{Path: []string{"a", "b"}, Value: int64(1)}, {Path: []string{"a", "c"}, Value: int64(2)}}},
+		{name: "missing value", sets: []string{"network.airgap"}, wantErr: "Invalid `--set network.airgap`"},
+		{name: "missing flag value", sets: []string{""}, wantErr: "Invalid `--set `"},
+		{name: "empty segment", sets: []string{"network..airgap=true"}, wantErr: "Invalid"},
+		{name: "invalid characters", sets: []string{"network/airgap=true"}, wantErr: "Invalid"},
+		{name: "managed field", sets: []string{"cpus=64"}, wantErr: "`cpus` is set by the bot"},
+		{name: "nested managed field", sets: []string{"version.major=5"}, wantErr: "`version` is set by the bot"},
+		{name: "conflicting paths", sets: []string{"a=1", "a.b=2"}, wantErr: "conflicts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, spoticusConfig.Default())
+
+			got, err := parseSpecOverrides(tt.sets)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseSpecOverrides(%q) error = %v, want it to contain %q", tt.sets, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSpecOverrides(%q): %v", tt.sets, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSpecOverrides(%q) = %v, want %v", tt.sets, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLaunchSetsNestedSpecFields(t *testing.T) {
+	useConfig(t, spoticusConfig.Default())
+	req, err := parseLaunchArgs([]string{"k8s", "medium", "--set", "network.airgap=true", "--set=disk.sizeGB=200"}, "C1")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs: %v", err)
+	}
+
+	obj := newClusterObject("c1", req)
+
+	if got, _, _ := unstructured.NestedBool(obj.Object, "spec", "network", "airgap"); !got {
+		t.Errorf("spec.network.airgap = %v, want true", got)
+	}
+	if got, _, _ := unstructured.NestedInt64(obj.Object, "spec", "disk", "sizeGB"); got != 200 {
+		t.Errorf("spec.disk.sizeGB = %d, want 200", got)
+	}
+	if got, _, _ := unstructured.NestedInt64(obj.Object, "spec", "cpus"); got != 8 {
+		t.Errorf("spec.cpus = %d, want the 8 of medium kept", got)
+	}
+}
+
+func TestLaunchRejectsInvalidSet(t *testing.T) {
+	useConfig(t, spoticusConfig.Default())
+
+	if _, err := parseLaunchArgs([]string{"k8s", "medium", "--set", "memory=512"}, "C1"); err == nil || !strings.Contains(err.Error(), "`memory` is set by the bot") {
+		t.Errorf("parseLaunchArgs() error = %v, want the managed field protected", err)
+	}
+}
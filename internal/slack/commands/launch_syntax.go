@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// launchSlots are the positional arguments of a launch, in order, which may
+// also be named as `type=k8s` or `--size large`.
+var launchSlots = []string{"type", "size"}
+
+// launchValueFlags are the flags outside launchFlags that take a value and
+// so may be written as `key=value` or `--key value` too. `--set` is left
+// alone: its value is itself a `path=value`.
+var launchValueFlags = map[string]bool{
+	"at":     true,
+	"in":     true,
+	"preset": true,
+}
+
+// takesValue reports whether the launch option key takes a value.
+func takesValue(key string) bool {
+	return (launchFlags[key] && key != "override-budget") || launchValueFlags[key] || slices.Contains(launchSlots, key)
+}
+
+// normalizeLaunchArgs rewrites the arguments of a launch into the form the
+// rest of the launch path parses: the cluster type and size first, then the
+// `--key=value` flags.
+//
+// Besides positional arguments and `--key=value`, options may be given as
+// `key=value` or `--key value`, and the type and size may be named, as in
+// `launch type=k8s size=large`. Positional arguments fill the slots left
+// unnamed, in order. The returned error is suitable to be shown to the user
+// as-is.
+func normalizeLaunchArgs(args []string) ([]string, error) {
+	named := map[string]string{}
+	var positional, flags []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.EqualFold(arg, "--set") {
+			flags = append(flags, args[i:min(i+2, len(args))]...)
+			i++
+			continue
+		}
+
+		option, isFlag := strings.CutPrefix(arg, "--")
+		key, value, hasValue := strings.Cut(option, "=")
+		key = strings.ToLower(key)
+		start := i
+		switch {
+		case !isFlag && (!hasValue || !takesValue(key)):
+			positional = append(positional, arg)
+			continue
+		case isFlag && !hasValue && takesValue(key) && i+1 < len(args) && !strings.HasPrefix(args[i+1], "--"):
+			i++
+			value, hasValue = args[i], true
+		}
+		// Keep a quoted value that was split on whitespace in one piece, for
+		// joinQuoted to unquote.
+		if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "“") {
+			for !hasClosingQuote(value) && i+1 < len(args) {
+				i++
+				value += " " + args[i]
+			}
+		}
+
+		if !slices.Contains(launchSlots, key) {
+			if !isFlag || i > start {
+				arg = "--" + key + "=" + value
+			}
+			flags = append(flags, arg)
+			continue
+		}
+		if !hasValue {
+			// A bare `--type` or `--size`: reported as unrecognized.
+			flags = append(flags, arg)
+			continue
+		}
+		if _, ok := named[key]; ok {
+			return nil, errors.New(messages.Render(msgs().LaunchArgRepeated, messages.Data{"Key": key}))
+		}
+		named[key] = value
+	}
+
+	var normalized []string
+	for _, slot := range launchSlots {
+		value, ok := named[slot]
+		if !ok {
+			if len(positional) == 0 {
+				continue
+			}
+			value, positional = positional[0], positional[1:]
+		}
+		normalized = append(normalized, value)
+	}
+	normalized = append(normalized, positional...)
+	return append(normalized, flags...), nil
+}
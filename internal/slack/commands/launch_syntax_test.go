@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLaunchArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "positional", args: []string{"k8s", "large", "--version=1.31"}, want: []string{"k8s", "large", "--version=1.31"}},
+		{name: "named", args: []string{"type=k8s", "size=large"}, want: []string{"k8s", "large"}},
+		{name: "named out of order", args: []string{"size=large", "type=openshift"}, want: []string{"openshift", "large"}},
+		{name: "named as flags", args: []string{"--type", "k8s", "--size=large"}, want: []string{"k8s", "large"}},
+		{name: "key=value options", args: []string{"k8s", "large", "version=1.31", "ref=PROJ-1"}, want: []string{"k8s", "large", "--version=1.31", "--ref=PROJ-1"}},
+		{name: "--key value options", args: []string{"k8s", "large", "--ttl", "2h", "--in", "30m"}, want: []string{"k8s", "large", "--ttl=2h", "--in=30m"}},
+		{name: "mixed", args: []string{"size=medium", "k8s", "--ref", "PROJ-2", "instance-type=m6i.large"}, want: []string{"k8s", "medium", "--ref=PROJ-2", "--instance-type=m6i.large"}},
+		{name: "positional fills the unnamed slot", args: []string{"type=openshift", "xlarge"}, want: []string{"openshift", "xlarge"}},
+		{name: "keys case-insensitive", args: []string{"TYPE=k8s", "Size=large"}, want: []string{"k8s", "large"}},
+		{name: "boolean flag takes no value", args: []string{"k8s", "large", "--override-budget", "extra"}, want: []string{"k8s", "large", "extra", "--override-budget"}},
+		{name: "flag value missing", args: []string{"k8s", "large", "--ttl"}, want: []string{"k8s", "large", "--ttl"}},
+		{name: "set left alone", args: []string{"k8s", "--set", "region=eu-west-1", "size=large"}, want: []string{"k8s", "large", "--set", "region=eu-west-1"}},
+		{name: "unknown key=value kept positional", args: []string{"k8s", "large", "colour=blue"}, want: []string{"k8s", "large", "colour=blue"}},
+		{name: "quoted value kept whole", args: []string{"k8s", "--preset", `"my`, `dev"`}, want: []string{"k8s", `--preset="my dev"`}},
+		{name: "preset only", args: []string{"preset=dev"}, want: []string{"--preset=dev"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeLaunchArgs(tt.args)
+			if err != nil {
+				t.Fatalf("normalizeLaunchArgs(%q): %v", tt.args, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("normalizeLaunchArgs(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeLaunchArgsRejectsRepeatedSlots(t *testing.T) {
+	for _, args := range [][]string{
+		{"type=k8s", "type=openshift"},
+		{"size=large", "--size", "medium", "k8s"},
+	} {
+		if _, err := normalizeLaunchArgs(args); err == nil || !strings.Contains(err.Error(), "is given more than once") {
+			t.Errorf("normalizeLaunchArgs(%q) error = %v, want the repeated slot reported", args, err)
+		}
+	}
+}
+
+func TestLaunchSyntaxesParseAlike(t *testing.T) {
+	useConfig(t, testConfig())
+
+	want, err := parseLaunchArgs([]string{"openshift", "large", "--version=4.19.0", "--ref=PROJ-123"}, "C1")
+	if err != nil {
+		t.Fatalf("parseLaunchArgs: %v", err)
+	}
+	for _, args := range [][]string{
+		{"type=openshift", "size=large", "version=4.19.0", "ref=PROJ-123"},
+		{"--type", "openshift", "--size", "large", "--version", "4.19.0", "--ref", "PROJ-123"},
+		{"openshift", "size=large", "--version", "4.19.0", "ref=PROJ-123"},
+	} {
+		normalized, err := normalizeLaunchArgs(args)
+		if err != nil {
+			t.Fatalf("normalizeLaunchArgs(%q): %v", args, err)
+		}
+		got, err := parseLaunchArgs(normalized, "C1")
+		if err != nil {
+			t.Fatalf("parseLaunchArgs(%q): %v", normalized, err)
+		}
+		if got.Type != want.Type || got.Size != want.Size || got.Version != want.Version || got.Ref != want.Ref {
+			t.Errorf("request of %q = %+v, want %+v", args, got, want)
+		}
+	}
+}
+
+func TestLaunchRejectsRepeatedType(t *testing.T) {
+	useConfig(t, testConfig())
+	api, server := newAPI(t)
+
+	args := []string{"type=k8s", "large", "--type", "openshift"}
+	err := HandleLaunch(context.Background(), api, message("U1", "C1", "launch "+strings.Join(args, " ")), args)
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "The cluster type is given more than once") {
+		t.Errorf("HandleLaunch() = %v, want a validation error", err)
+	}
+	if got := len(server.Messages()); got != 0 {
+		t.Errorf("posted %d messages, want none", got)
+	}
+}
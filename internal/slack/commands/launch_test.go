@@ -0,0 +1,463 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// launchedKinds lists the MAPT Kind clusters in the fake clients.
+func launchedKinds(t *testing.T, kube *slacktest.Kube) []maptApi.Kind {
+	t.Helper()
+	var kinds maptApi.KindList
+	if err := kube.CrClient.List(context.Background(), &kinds); err != nil {
+		t.Fatalf("listing the MAPT clusters: %v", err)
+	}
+	return kinds.Items
+}
+
+func TestLaunchRepliesThenUpdatesThread(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U1", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+
+	launching := server.WaitForMessage("Launching", time.Second)
+	if launching.Values.Get("thread_ts") != "" {
+		t.Errorf("launch reply posted in a thread, want it in the channel")
+	}
+	ready := server.WaitForMessage("is ready", 5*time.Second)
+	if launching.Channel() != "C1" || ready.Channel() != "C1" {
+		t.Errorf("replies posted to %s and %s, want C1", launching.Channel(), ready.Channel())
+	}
+	if got := ready.Values.Get("thread_ts"); got == "" {
+		t.Errorf("ready message not posted in the launch thread")
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want 1", len(kinds))
+	}
+}
+
+func TestLaunchChecksQuotaBeforeReplying(t *testing.T) {
+	cfg := testConfig()
+	cfg.Quota.Default = 1
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-owned", "U1", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	err := HandleLaunch(context.Background(), api, message("U1", "C1", "launch k8s medium"), []string{"k8s", "medium"})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Quota exceeded") {
+		t.Fatalf("HandleLaunch() = %v, want the quota exceeded error", err)
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("posted %d messages before the quota check failed, want none", len(messages))
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want only the existing one", len(kinds))
+	}
+}
+
+func TestLaunchChecksBudgetBeforeReplying(t *testing.T) {
+	cfg := testConfig()
+	cfg.Budget.HourlyCap = 0.20
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-costly", "U2", 0.15))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	err := HandleLaunch(context.Background(), api, message("U1", "C1", "launch k8s medium"), []string{"k8s", "medium"})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Budget cap reached") {
+		t.Fatalf("HandleLaunch() = %v, want the budget exceeded error", err)
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("posted %d messages before the budget check failed, want none", len(messages))
+	}
+}
+
+func TestLaunchOfInstanceTypeChecksBudget(t *testing.T) {
+	cfg := testConfig()
+	cfg.Budget.HourlyCap = 0.40
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-spending", "U2", 0.15))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	args := []string{"k8s", "--instance-type=m6i.4xlarge"}
+	err := HandleLaunch(context.Background(), api, message("U1", "C1", "launch "+strings.Join(args, " ")), args)
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Budget cap reached") {
+		t.Fatalf("HandleLaunch() = %v, want the budget exceeded error", err)
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("posted %d messages before the budget check failed, want none", len(messages))
+	}
+}
+
+func TestLaunchOfInstanceTypeRecordsItsCost(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	args := []string{"k8s", "--instance-type=r6i.2xlarge"}
+	if err := HandleLaunch(context.Background(), api, message("U34", "C1", "launch "+strings.Join(args, " ")), args); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(kinds))
+	}
+	if metadata := ParseLaunchMetadata(kinds[0].Annotations); metadata.HourlyCost != 0.20 {
+		t.Errorf("hourly cost %v recorded, want the r6i.2xlarge's 0.20 counted in the budget", metadata.HourlyCost)
+	}
+}
+
+func TestConcurrentLaunchesRespectQuota(t *testing.T) {
+	cfg := testConfig()
+	cfg.Quota.Default = 1
+	useConfig(t, cfg)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	const launches = 4
+	errs := make([]error, launches)
+	var wg sync.WaitGroup
+	for i := range launches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = HandleLaunch(context.Background(), api, message("U1", "C1", "launch k8s medium"), []string{"k8s", "medium"})
+		}()
+	}
+	wg.Wait()
+
+	accepted := 0
+	for _, err := range errs {
+		if err == nil {
+			accepted++
+		}
+	}
+	if accepted != 1 {
+		t.Errorf("%d launches accepted, want 1: %v", accepted, errs)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want 1", len(kinds))
+	}
+}
+
+func TestConcurrentLaunchesInNamespacesRespectQuota(t *testing.T) {
+	cfg := testConfig()
+	cfg.Quota.Default = 1
+	cfg.TypeNamespaces = map[string]string{"openshift": "ocp"}
+	useConfig(t, cfg)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	// The launches alternate between the default namespace and ocp, which
+	// the owner's quota covers together.
+	const launches = 4
+	errs := make([]error, launches)
+	var wg sync.WaitGroup
+	for i := range launches {
+		args := []string{"k8s", "medium"}
+		if i%2 == 1 {
+			args = []string{"openshift", "medium"}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = HandleLaunch(context.Background(), api, message("U32", "C1", "launch "+strings.Join(args, " ")), args)
+		}()
+	}
+	wg.Wait()
+
+	accepted := 0
+	for _, err := range errs {
+		if err == nil {
+			accepted++
+		}
+	}
+	if accepted != 1 {
+		t.Errorf("%d launches accepted, want 1: %v", accepted, errs)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	if got := len(launchedKinds(t, kube)) + len(openshiftClusters(t, kube)); got != 1 {
+		t.Errorf("got %d clusters, want 1", got)
+	}
+}
+
+func TestLaunchSetsOpenshiftVersionOnResource(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	args := []string{"openshift", "medium", "--version=4.18.0"}
+	if err := HandleLaunch(context.Background(), api, message("UVERSION", "C1", "launch openshift medium --version=4.18.0"), args); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(openshiftListGVK)
+	if err := kube.CrClient.List(context.Background(), list); err != nil {
+		t.Fatalf("listing the OpenShift clusters: %v", err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("got %d OpenShift clusters, want 1", len(list.Items))
+	}
+	created := list.Items[0]
+	if version, _, _ := unstructured.NestedString(created.Object, "spec", "version"); version != "4.18.0" {
+		t.Errorf("spec.version = %q, want 4.18.0", version)
+	}
+	if got := ParseLaunchMetadata(created.GetAnnotations()).Version; got != "4.18.0" {
+		t.Errorf("version annotation = %q, want 4.18.0", got)
+	}
+}
+
+func TestLaunchCreatesClusterInConfiguredNamespace(t *testing.T) {
+	cfg := testConfig()
+	cfg.Namespace = "spoticus"
+	useConfig(t, cfg)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U7", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(kinds))
+	}
+	if kinds[0].Namespace != "spoticus" {
+		t.Errorf("cluster created in namespace %q, want spoticus", kinds[0].Namespace)
+	}
+}
+
+func TestLaunchReportsChannelDefaults(t *testing.T) {
+	cfg := testConfig()
+	cfg.Channels = map[string]spoticusConfig.ChannelDefaults{"CCI": {Namespace: "ci"}}
+	useConfig(t, cfg)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U12", "CCI", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("Channel defaults: namespace `ci`", time.Second)
+	server.WaitForMessage("is ready", 5*time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 || kinds[0].Namespace != "ci" {
+		t.Errorf("clusters %v, want one in the ci namespace", kinds)
+	}
+}
+
+func TestLaunchRecordsRef(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U17", "C1", "launch k8s medium --ref=PROJ-123"), []string{"k8s", "medium", "--ref=PROJ-123"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("Ref: PROJ-123", time.Second)
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 || kinds[0].Annotations[annotationRef] != "PROJ-123" {
+		t.Fatalf("clusters %v, want one annotated %s=PROJ-123", kinds, annotationRef)
+	}
+	info := ClusterInfo{Name: kinds[0].Name, Type: "k8s", Created: time.Now(), Annotations: kinds[0].Annotations}
+	if text := formatClusterStatus(testConfig(), info); !strings.Contains(text, "Ref: PROJ-123") {
+		t.Errorf("status %q does not show the ref", text)
+	}
+}
+
+func TestLaunchRecordsProvider(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U23", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 || kinds[0].Annotations[annotationProvider] != defaultProvider {
+		t.Fatalf("clusters %v, want one annotated %s=%s", kinds, annotationProvider, defaultProvider)
+	}
+	if got := (ClusterInfo{Annotations: kinds[0].Annotations}).Provider(); got != defaultProvider {
+		t.Errorf("Provider() = %q, want %q", got, defaultProvider)
+	}
+}
+
+func TestLaunchLinksThread(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady)))
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U18", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("Launching", time.Second)
+	ready := server.WaitForMessage("is ready", 5*time.Second)
+
+	threadTS := ready.Values.Get("thread_ts")
+	requested := false
+	for _, call := range server.Calls("chat.getPermalink") {
+		requested = requested || call.Values.Get("message_ts") == threadTS
+	}
+	if !requested {
+		t.Errorf("permalink of the launch thread %s not requested", threadTS)
+	}
+	want := "<https://slack.test/archives/C1/p" + strings.ReplaceAll(threadTS, ".", "") + "|Link to this thread>"
+	if !strings.Contains(ready.Text(), want) {
+		t.Errorf("ready reply %q does not contain %q", ready.Text(), want)
+	}
+}
+
+func TestLaunchStoresThreadPermalink(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U25", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	ready := server.WaitForMessage("is ready", 5*time.Second)
+
+	want := "https://slack.test/archives/C1/p" + strings.ReplaceAll(ready.Values.Get("thread_ts"), ".", "")
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 || kinds[0].Annotations[annotationPermalink] != want {
+		t.Fatalf("clusters %v, want one annotated %s=%s", kinds, annotationPermalink, want)
+	}
+}
+
+func TestLaunchWithoutPermalink(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady)))
+	api, server := newAPI(t)
+	server.Handle("chat.getPermalink", func(url.Values) map[string]any {
+		return map[string]any{"ok": false, "error": "message_not_found"}
+	})
+
+	if err := HandleLaunch(context.Background(), api, message("U19", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	ready := server.WaitForMessage("is ready", 5*time.Second)
+	if strings.Contains(ready.Text(), "Link to this thread") {
+		t.Errorf("ready reply %q links a thread whose permalink could not be fetched", ready.Text())
+	}
+}
+
+func TestLaunchSetsK8sVersionOnResource(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	args := []string{"k8s", "medium", "--k8s-version=1.32"}
+	if err := HandleLaunch(context.Background(), api, message("U21", "C1", "launch k8s medium --k8s-version=1.32"), args); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("Version: 1.32", time.Second)
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 || ParseLaunchMetadata(kinds[0].Annotations).Version != "1.32" {
+		t.Fatalf("clusters %v, want one annotated with version 1.32", kinds)
+	}
+	created := kinds[0]
+	info := ClusterInfo{Name: created.GetName(), Type: "k8s", Created: time.Now(), Annotations: created.GetAnnotations()}
+	if text := formatClusterStatus(testConfig(), info); !strings.Contains(text, "Version: 1.32") {
+		t.Errorf("status %q does not show the version", text)
+	}
+}
+
+func TestLaunchWithoutUserUsesDefaultOwner(t *testing.T) {
+	cfg := testConfig()
+	cfg.DefaultOwner = "UPLATFORM"
+	cfg.Teams = map[string][]string{"platform": {"UPLATFORM"}}
+	useConfig(t, cfg)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(kinds))
+	}
+	if owner := ParseLaunchMetadata(kinds[0].Annotations).Owner; owner != "UPLATFORM" {
+		t.Errorf("owner %q, want the default owner", owner)
+	}
+	if team := kinds[0].Labels[labelTeam]; team != "platform" {
+		t.Errorf("team label %q, want the default owner's team", team)
+	}
+}
+
+func TestLaunchByUserIgnoresDefaultOwner(t *testing.T) {
+	cfg := testConfig()
+	cfg.DefaultOwner = "UPLATFORM"
+	useConfig(t, cfg)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U30", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 || ParseLaunchMetadata(kinds[0].Annotations).Owner != "U30" {
+		t.Errorf("clusters %v, want one owned by the requester", kinds)
+	}
+}
+
+func TestLaunchRejectsTTLOverSizeMaximum(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKube()
+	useKube(t, kube)
+	api, _ := newAPI(t)
+
+	args := []string{"k8s", "xlarge", "--ttl=7d"}
+	err := HandleLaunch(context.Background(), api, message("U31", "C1", "launch "+strings.Join(args, " ")), args)
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "too long for a *xlarge* cluster") {
+		t.Errorf("HandleLaunch() = %v, want the TTL rejected with the size's maximum", err)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Errorf("got %d clusters, want none launched", len(kinds))
+	}
+}
@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// HandleList is the entry point for the "list" Slack command.
+//
+// It reports every MAPT cluster of every supported type. If one of the
+// types cannot be listed, the clusters of the other types are still shown
+// together with a warning naming the type that could not be retrieved.
+// `list --by-owner` groups the clusters under their owners, the owners with
+// the most clusters first. Inventories larger than the configured maximum are
+// shown a page at a time, with buttons to the other pages.
+func HandleList(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	byOwner, allNamespaces := false, false
+	for _, arg := range args {
+		switch arg {
+		case "--by-owner":
+			byOwner = true
+		case "--all-namespaces":
+			allNamespaces = true
+		default:
+			return invalid(msgs().ListUsage)
+		}
+	}
+	if allNamespaces && !spoticusConfig.Get().IsAdmin(event.User) {
+		return denied(msgs().ListAllNamespacesDenied, "denied an all-namespaces list to user %s", event.User)
+	}
+
+	// Get Kubernetes client
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	list := listClusters
+	if allNamespaces {
+		list = listAllClusters
+	}
+	inventory, err := list(ctx, client)
+	if len(inventory.Failed) == len(clusterTypeNames) {
+		return failure(err, msgs().ListFailed, "listing MAPT clusters")
+	}
+	if err != nil {
+		log.Printf("Error listing MAPT clusters: %v", err)
+	}
+
+	sortClusters(inventory.Clusters)
+	page := listPage{ByOwner: byOwner, AllNamespaces: allNamespaces, User: event.User}
+	message, pages := formatClusterList(inventory, byOwner, 0, spoticusConfig.Get().ListMaxResults)
+
+	log.Printf("Listed %d MAPT clusters for user %s (all namespaces: %t, failed types: %v)",
+		len(inventory.Clusters), event.User, allNamespaces, inventory.Failed)
+
+	// Post the result back to Slack
+	if _, _, err := respond.Post(api, event.Channel, listMessageOptions(message, page, pages)...); err != nil {
+		log.Printf("Error posting list message: %v", err)
+	}
+	return nil
+}
+
+// formatClusterList renders a page of the inventory as a Slack message,
+// grouped by owner if byOwner is set, followed by a warning for each cluster
+// type that could not be retrieved. Pages hold pageSize clusters, or all of
+// them if pageSize is zero; the header still counts them all. It returns the
+// message and the number of pages, page being clamped to the last one.
+func formatClusterList(inventory clusterInventory, byOwner bool, page, pageSize int) (string, int) {
+	var message strings.Builder
+	totalClusters := len(inventory.Clusters)
+	templates := msgs()
+
+	pages := 1
+	if pageSize > 0 && totalClusters > pageSize {
+		pages = (totalClusters + pageSize - 1) / pageSize
+	}
+	page = min(max(page, 0), pages-1)
+	shown := inventory.Clusters
+	first := 0
+	if pages > 1 {
+		first = page * pageSize
+		shown = shown[first:min(first+pageSize, totalClusters)]
+	}
+
+	if totalClusters == 0 {
+		message.WriteString(templates.ListEmpty)
+	} else {
+		message.WriteString(messages.Render(templates.ListHeader, messages.Data{"Count": totalClusters}))
+
+		if byOwner {
+			var groups []string
+			for _, group := range groupByOwner(shown) {
+				groups = append(groups, messages.Render(templates.ListOwnerHeader, messages.Data{
+					"Owner": group.Owner,
+					"Count": len(group.Clusters),
+				})+formatListEntries(group.Clusters))
+			}
+			message.WriteString(strings.Join(groups, "\n"))
+		} else {
+			message.WriteString(formatListEntries(shown))
+		}
+		if pages > 1 {
+			message.WriteString("\n" + messages.Render(templates.ListPage, messages.Data{
+				"First": first + 1,
+				"Last":  first + len(shown),
+				"Total": totalClusters,
+				"Page":  page + 1,
+				"Pages": pages,
+			}))
+		}
+	}
+
+	for _, failed := range inventory.Failed {
+		message.WriteString("\n\n" + messages.Render(templates.ListTypeFailed, messages.Data{"Type": failed}))
+	}
+
+	return message.String(), pages
+}
+
+// sortClusters orders clusters oldest first, by name for equal creation
+// times, so that the list and its pages are deterministic.
+func sortClusters(clusters []ClusterInfo) {
+	sort.SliceStable(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if !a.Created.Equal(b.Created) {
+			return a.Created.Before(b.Created)
+		}
+		return a.Name < b.Name
+	})
+}
+
+// formatListEntries renders clusters as list entries separated by blank lines.
+func formatListEntries(clusters []ClusterInfo) string {
+	entries := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		entries = append(entries, messages.Render(msgs().ListEntry, messages.Data{
+			"Indicator":   statusIndicator(cluster.Phase),
+			"Phase":       cluster.Phase,
+			"Name":        cluster.Name,
+			"DisplayName": cluster.DisplayName(),
+			"Type":        clusterTypeNames[cluster.Type],
+			"Namespace":   cluster.Namespace,
+			"Age":         humanizeAge(cluster.Created),
+			"Created":     cluster.Created.Format(timestampLayout),
+			"Pinned":      cluster.Pinned(),
+		}))
+	}
+	return strings.Join(entries, "\n")
+}
+
+// statusIndicators maps the cluster phases to the emoji prefixing them in
+// `list`. Phases not listed use the unknown indicator.
+var statusIndicators = map[string]string{
+	phaseReady:        "🟢",
+	phaseProvisioning: "🟡",
+	phaseFailed:       "🔴",
+}
+
+// statusIndicator returns the emoji for a cluster phase, with the configured
+// branding applied like to the message templates.
+func statusIndicator(phase string) string {
+	indicator, ok := statusIndicators[phase]
+	if !ok {
+		indicator = "⚪"
+	}
+	return spoticusConfig.Get().Branding.Text(indicator)
+}
+
+// ownerGroup is the clusters of one owner in `list --by-owner`.
+type ownerGroup struct {
+	// Owner is the owner's Slack user ID, or empty for clusters without one.
+	Owner    string
+	Clusters []ClusterInfo
+}
+
+// groupByOwner groups clusters by owner, keeping their order within a group.
+// Owners with more clusters come first, ties sorted by user ID, and the
+// clusters without an owner last.
+func groupByOwner(clusters []ClusterInfo) []ownerGroup {
+	var groups []ownerGroup
+	index := map[string]int{}
+	for _, cluster := range clusters {
+		owner := cluster.Metadata().Owner
+		i, ok := index[owner]
+		if !ok {
+			i = len(groups)
+			index[owner] = i
+			groups = append(groups, ownerGroup{Owner: owner})
+		}
+		groups[i].Clusters = append(groups[i].Clusters, cluster)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Owner == "") != (b.Owner == "") {
+			return b.Owner == ""
+		}
+		if len(a.Clusters) != len(b.Clusters) {
+			return len(a.Clusters) > len(b.Clusters)
+		}
+		return a.Owner < b.Owner
+	})
+	return groups
+}
@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// Action IDs of the `list` page buttons.
+const (
+	ActionListPrev = "list_prev"
+	ActionListNext = "list_next"
+)
+
+// maxSectionText is the longest text Slack accepts in a section block.
+const maxSectionText = 3000
+
+// listPage identifies a page of a `list` and the request it belongs to. It
+// is encoded in the value of the page buttons, since the bot keeps no state
+// about the messages it posted.
+type listPage struct {
+	// Page is the zero-based page shown.
+	Page int
+
+	// ByOwner is the `--by-owner` filter of the request.
+	ByOwner bool
+
+	// AllNamespaces is the admin `--all-namespaces` scope of the request.
+	AllNamespaces bool
+
+	// User is the Slack user ID of the requester, the only one who may turn
+	// the pages.
+	User string
+}
+
+// String encodes the page as a button value.
+func (p listPage) String() string {
+	return fmt.Sprintf("%d|%t|%s|%t", p.Page, p.ByOwner, p.User, p.AllNamespaces)
+}
+
+// parseListPage decodes a button value encoded by listPage.String. Values
+// without the namespace scope, from buttons posted before it was added, are
+// scoped to the launch namespaces.
+func parseListPage(value string) (listPage, error) {
+	fields := strings.Split(value, "|")
+	if (len(fields) != 3 && len(fields) != 4) || fields[2] == "" {
+		return listPage{}, fmt.Errorf("malformed list page %q", value)
+	}
+	allNamespaces := false
+	if len(fields) == 4 {
+		var err error
+		if allNamespaces, err = strconv.ParseBool(fields[3]); err != nil {
+			return listPage{}, fmt.Errorf("malformed list page %q", value)
+		}
+	}
+	page, err := strconv.Atoi(fields[0])
+	if err != nil || page < 0 {
+		return listPage{}, fmt.Errorf("malformed list page %q", value)
+	}
+	byOwner, err := strconv.ParseBool(fields[1])
+	if err != nil {
+		return listPage{}, fmt.Errorf("malformed list page %q", value)
+	}
+	return listPage{Page: page, ByOwner: byOwner, AllNamespaces: allNamespaces, User: fields[2]}, nil
+}
+
+// listMessageOptions returns the options posting a list message. Lists of a
+// single page are plain text; longer ones are laid out in blocks ending with
+// the buttons to the previous and next pages.
+func listMessageOptions(text string, page listPage, pages int) []slack.MsgOption {
+	options := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if pages <= 1 {
+		return options
+	}
+
+	var blocks []slack.Block
+	for _, section := range splitSections(text, maxSectionText) {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, section, false, false), nil, nil))
+	}
+	var buttons []slack.BlockElement
+	if page.Page > 0 {
+		prev := page
+		prev.Page--
+		buttons = append(buttons, slack.NewButtonBlockElement(ActionListPrev, prev.String(),
+			slack.NewTextBlockObject(slack.PlainTextType, "◀ Prev", false, false)))
+	}
+	if page.Page < pages-1 {
+		next := page
+		next.Page++
+		buttons = append(buttons, slack.NewButtonBlockElement(ActionListNext, next.String(),
+			slack.NewTextBlockObject(slack.PlainTextType, "Next ▶", false, false)))
+	}
+	blocks = append(blocks, slack.NewActionBlock("list_pages", buttons...))
+	return append(options, slack.MsgOptionBlocks(blocks...))
+}
+
+// splitSections splits text at blank lines into pieces of at most limit
+// bytes, so that each fits in a section block. A paragraph longer than limit
+// is cut.
+func splitSections(text string, limit int) []string {
+	var sections []string
+	var current string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if len(paragraph) > limit {
+			paragraph = strings.ToValidUTF8(paragraph[:limit], "")
+		}
+		switch {
+		case current == "":
+			current = paragraph
+		case len(current)+2+len(paragraph) <= limit:
+			current += "\n\n" + paragraph
+		default:
+			sections = append(sections, current)
+			current = paragraph
+		}
+	}
+	if strings.TrimSpace(current) != "" {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+// HandleListPage handles a click on a `list` page button: it re-renders the
+// list at the page encoded in the button's value by updating the message in
+// place. Only the user who ran the `list` may turn its pages; anyone else is
+// told so privately.
+func HandleListPage(ctx context.Context, api *slack.Client, callback slack.InteractionCallback, action *slack.BlockAction) {
+	channel := callback.Container.ChannelID
+	timestamp := callback.Container.MessageTs
+
+	page, err := parseListPage(action.Value)
+	if err != nil {
+		log.Printf("Ignoring list page button from user %s: %v", callback.User.ID, err)
+		return
+	}
+	if callback.User.ID != page.User {
+		log.Printf("Denied list page turn to user %s on a list of %s", callback.User.ID, page.User)
+		respond.Ephemeral(api, channel, callback.User.ID, messages.Render(msgs().ListPageDenied, messages.Data{"User": page.User}))
+		return
+	}
+	if page.AllNamespaces && !spoticusConfig.Get().IsAdmin(callback.User.ID) {
+		log.Printf("Denied an all-namespaces list page turn to user %s, who is no longer an admin", callback.User.ID)
+		respond.Ephemeral(api, channel, callback.User.ID, msgs().ListAllNamespacesDenied)
+		return
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		log.Printf("Error getting kubernetes client: %v", err)
+		respond.Ephemeral(api, channel, callback.User.ID, backendError(err, msgs().ConnectFailed))
+		return
+	}
+	list := listClusters
+	if page.AllNamespaces {
+		list = listAllClusters
+	}
+	inventory, err := list(ctx, client)
+	if err != nil {
+		log.Printf("Error listing MAPT clusters: %v", err)
+	}
+	if len(inventory.Failed) == len(clusterTypeNames) {
+		respond.Ephemeral(api, channel, callback.User.ID, backendError(err, msgs().ListFailed))
+		return
+	}
+
+	sortClusters(inventory.Clusters)
+	pageSize := spoticusConfig.Get().ListMaxResults
+	message, pages := formatClusterList(inventory, page.ByOwner, page.Page, pageSize)
+	// The inventory may have shrunk since the buttons were rendered.
+	page.Page = min(page.Page, pages-1)
+
+	options := listMessageOptions(message, page, pages)
+	if pages <= 1 {
+		// Drop the blocks, and with them the buttons, of the earlier pages.
+		options = append(options, slack.MsgOptionBlocks([]slack.Block{}...))
+	}
+	if err := respond.UpdateMessage(api, channel, timestamp, options...); err != nil {
+		log.Printf("Error updating list message %s in %s: %v", timestamp, channel, err)
+		return
+	}
+	log.Printf("Showed page %d of %d of the cluster list to user %s", page.Page+1, pages, callback.User.ID)
+}
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestListPageRoundTrip(t *testing.T) {
+	for _, page := range []listPage{
+		{Page: 0, User: "U1"},
+		{Page: 3, ByOwner: true, AllNamespaces: true, User: "UADMIN"},
+	} {
+		got, err := parseListPage(page.String())
+		if err != nil || got != page {
+			t.Errorf("parseListPage(%q) = %+v, %v, want %+v", page.String(), got, err, page)
+		}
+	}
+
+	if got, err := parseListPage("2|true|U1"); err != nil || got != (listPage{Page: 2, ByOwner: true, User: "U1"}) {
+		t.Errorf("parseListPage() of a value without the namespace scope = %+v, %v", got, err)
+	}
+	for _, value := range []string{"", "1|false", "-1|false|U1", "x|false|U1", "1|maybe|U1", "1|false|", "1|false|U1|maybe"} {
+		if _, err := parseListPage(value); err == nil {
+			t.Errorf("parseListPage(%q) accepted a malformed value", value)
+		}
+	}
+}
+
+func TestSplitSections(t *testing.T) {
+	got := splitSections("aaaa\n\nbbbb\n\ncc", 10)
+	if want := []string{"aaaa\n\nbbbb", "cc"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitSections() = %q, want %q", got, want)
+	}
+	if got := splitSections(strings.Repeat("x", 15), 10); len(got) != 1 || len(got[0]) != 10 {
+		t.Errorf("splitSections() of a long paragraph = %q, want it cut to the limit", got)
+	}
+}
+
+// usePagedInventory lists three clusters, one per page, oldest first.
+func usePagedInventory(t *testing.T) {
+	t.Helper()
+	cfg := testConfig()
+	cfg.ListMaxResults = 1
+	useConfig(t, cfg)
+	now := time.Now()
+	var clusters []crclient.Object
+	for i, name := range []string{"spoticus-k8s-page1", "spoticus-k8s-page2", "spoticus-k8s-page3"} {
+		cluster := existingCluster(name, "U1", 0)
+		cluster.SetCreationTimestamp(metav1.NewTime(now.Add(time.Duration(i-3) * time.Hour)))
+		clusters = append(clusters, cluster)
+	}
+	useKube(t, slacktest.NewKube(clusters...))
+}
+
+// pageButton returns a click by user on the page button of action in the
+// list message 1700000000.000001 of C1.
+func pageButton(user, action string, page listPage) (slack.InteractionCallback, *slack.BlockAction) {
+	callback := slack.InteractionCallback{
+		Type:      slack.InteractionTypeBlockActions,
+		User:      slack.User{ID: user},
+		Container: slack.Container{ChannelID: "C1", MessageTs: "1700000000.000001"},
+	}
+	return callback, &slack.BlockAction{ActionID: action, Value: page.String()}
+}
+
+func TestListPostsPageButtons(t *testing.T) {
+	usePagedInventory(t)
+	api, server := newAPI(t)
+
+	if err := HandleList(context.Background(), api, message("U1", "C1", "list"), nil); err != nil {
+		t.Fatalf("HandleList: %v", err)
+	}
+	posted := server.WaitForMessage("Cluster List", time.Second)
+	if text := posted.Text(); !strings.Contains(text, "spoticus-k8s-page1") || strings.Contains(text, "spoticus-k8s-page2") {
+		t.Errorf("first page %q, want only the oldest cluster", text)
+	}
+	blocks := posted.Values.Get("blocks")
+	if !strings.Contains(blocks, ActionListNext) || strings.Contains(blocks, ActionListPrev) {
+		t.Errorf("blocks %s, want only a Next button on the first page", blocks)
+	}
+	if want := (listPage{Page: 1, User: "U1"}).String(); !strings.Contains(blocks, want) {
+		t.Errorf("blocks %s, want the next page encoded as %q", blocks, want)
+	}
+}
+
+func TestListPageTurnsPageForRequester(t *testing.T) {
+	usePagedInventory(t)
+	api, server := newAPI(t)
+
+	callback, action := pageButton("U1", ActionListNext, listPage{Page: 1, User: "U1"})
+	HandleListPage(context.Background(), api, callback, action)
+
+	updates := server.Calls("chat.update")
+	if len(updates) != 1 {
+		t.Fatalf("made %d updates, want the list message updated once", len(updates))
+	}
+	update := updates[0]
+	if update.Values.Get("ts") != "1700000000.000001" || update.Channel() != "C1" {
+		t.Errorf("updated %s in %s, want the list message", update.Values.Get("ts"), update.Channel())
+	}
+	if text := update.Text(); !strings.Contains(text, "spoticus-k8s-page2") || strings.Contains(text, "spoticus-k8s-page1") {
+		t.Errorf("page %q, want only the second cluster", text)
+	}
+	if blocks := update.Values.Get("blocks"); !strings.Contains(blocks, ActionListPrev) || !strings.Contains(blocks, ActionListNext) {
+		t.Errorf("blocks %s, want Prev and Next on a middle page", blocks)
+	}
+}
+
+func TestListPageRestrictedToRequester(t *testing.T) {
+	usePagedInventory(t)
+	api, server := newAPI(t)
+
+	callback, action := pageButton("U2", ActionListNext, listPage{Page: 1, User: "U1"})
+	HandleListPage(context.Background(), api, callback, action)
+
+	if updates := server.Calls("chat.update"); len(updates) != 0 {
+		t.Errorf("made %d updates for another user, want none", len(updates))
+	}
+	denied := server.WaitForMessage("who ran this `list`, can turn its pages", time.Second)
+	if denied.Method != "chat.postEphemeral" || denied.Values.Get("user") != "U2" {
+		t.Errorf("denial sent with %s to %q, want privately to U2", denied.Method, denied.Values.Get("user"))
+	}
+}
+
+func TestListPageIgnoresMalformedValue(t *testing.T) {
+	usePagedInventory(t)
+	api, server := newAPI(t)
+	callback, action := pageButton("U1", ActionListNext, listPage{User: "U1"})
+	action.Value = "garbage"
+
+	HandleListPage(context.Background(), api, callback, action)
+
+	if calls := server.Calls(); len(calls) != 0 {
+		t.Errorf("made %d calls for a malformed button, want none", len(calls))
+	}
+}
+
+func TestListPageKeepsAllNamespacesScope(t *testing.T) {
+	cfg := testConfig()
+	cfg.ListMaxResults = 1
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	stray := existingCluster("spoticus-k8s-page0", "U1", 0)
+	stray.SetNamespace("elsewhere")
+	stray.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-10 * time.Hour)))
+	scoped := existingCluster("spoticus-k8s-page1", "U1", 0)
+	scoped.SetCreationTimestamp(metav1.Now())
+	useKube(t, slacktest.NewKube(stray, scoped))
+	api, server := newAPI(t)
+
+	callback, action := pageButton("UADMIN", ActionListNext, listPage{Page: 0, AllNamespaces: true, User: "UADMIN"})
+	HandleListPage(context.Background(), api, callback, action)
+	updates := server.Calls("chat.update")
+	if len(updates) != 1 || !strings.Contains(updates[0].Text(), "spoticus-k8s-page0") {
+		t.Fatalf("updates %+v, want the cluster of the other namespace listed", updates)
+	}
+
+	demoted := testConfig()
+	demoted.ListMaxResults = 1
+	useConfig(t, demoted)
+	callback, action = pageButton("UADMIN", ActionListNext, listPage{Page: 0, AllNamespaces: true, User: "UADMIN"})
+	HandleListPage(context.Background(), api, callback, action)
+	if updates := server.Calls("chat.update"); len(updates) != 1 {
+		t.Errorf("made %d updates, want none for a user who is no longer an admin", len(updates)-1)
+	}
+	server.WaitForMessage("Only bot administrators can use `--all-namespaces`", time.Second)
+}
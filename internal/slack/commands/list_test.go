@@ -0,0 +1,314 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestListShowsTypesThatDidNotFail(t *testing.T) {
+	tests := []struct {
+		name       string
+		failing    string
+		wantShown  string
+		wantHidden string
+		wantNote   string
+	}{
+		{name: "kind list fails", failing: "k8s", wantShown: "spoticus-openshift-a", wantHidden: "spoticus-k8s-a", wantNote: "Could not retrieve Kubernetes clusters"},
+		{name: "openshift list fails", failing: "openshift", wantShown: "spoticus-k8s-a", wantHidden: "spoticus-openshift-a", wantNote: "Could not retrieve OpenShift clusters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, testConfig())
+			useKube(t, slacktest.NewKubeWithInterceptor(failList(tt.failing),
+				existingCluster("spoticus-k8s-a", "U1", 0),
+				existingClusterOf("openshift", "spoticus-openshift-a", "U1", 0),
+			))
+			api, server := newAPI(t)
+
+			if err := HandleList(context.Background(), api, message("U1", "C1", "list"), nil); err != nil {
+				t.Fatalf("HandleList: %v", err)
+			}
+			text := server.WaitForMessage("Cluster List", time.Second).Text()
+			if !strings.Contains(text, tt.wantShown) {
+				t.Errorf("list %q does not show %s", text, tt.wantShown)
+			}
+			if strings.Contains(text, tt.wantHidden) {
+				t.Errorf("list %q shows %s, whose type failed", text, tt.wantHidden)
+			}
+			if !strings.Contains(text, tt.wantNote) {
+				t.Errorf("list %q does not warn %q", text, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestListFailsWhenEveryTypeFails(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKubeWithInterceptor(failList("k8s", "openshift")))
+	api, server := newAPI(t)
+
+	err := HandleList(context.Background(), api, message("U1", "C1", "list"), nil)
+	if CategoryOf(err) != CategoryBackend {
+		t.Errorf("HandleList() = %v, want a backend failure", err)
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("posted %d messages, want the error left to the dispatcher", len(messages))
+	}
+}
+
+func TestListClustersJoinsErrors(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(failList("openshift"), existingCluster("spoticus-k8s-a", "U1", 0))
+
+	inventory, err := listClusters(context.Background(), fakeClients(kube))
+	if err == nil || !strings.Contains(err.Error(), "listing MAPT openshift clusters in default") {
+		t.Errorf("listClusters() error = %v, want the openshift list error", err)
+	}
+	if len(inventory.Clusters) != 1 || len(inventory.Failed) != 1 || inventory.Failed[0] != "OpenShift" {
+		t.Errorf("inventory %+v, want the kind cluster and OpenShift failed", inventory)
+	}
+}
+
+func TestListUsesCustomTemplate(t *testing.T) {
+	cfg := testConfig()
+	cfg.Messages.ListEmpty = "Nothing running."
+	useConfig(t, cfg)
+	useKube(t, slacktest.NewKube())
+	api, server := newAPI(t)
+
+	if err := HandleList(context.Background(), api, message("U1", "C1", "list"), nil); err != nil {
+		t.Fatalf("HandleList: %v", err)
+	}
+	if got := server.WaitForMessage("Nothing running.", time.Second).Values.Get("text"); got != "Nothing running." {
+		t.Errorf("posted %q, want only the custom template", got)
+	}
+}
+
+func TestListUsesBrandedStatusIndicators(t *testing.T) {
+	cfg := testConfig()
+	cfg.Branding = messages.Branding{Emojis: map[string]string{"list": ":clipboard:", "unknown": ":grey_question:"}}
+	cfg.Messages = cfg.Branding.Apply(cfg.Messages)
+	useConfig(t, cfg)
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-a", "U1", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleList(context.Background(), api, message("U1", "C1", "list"), nil); err != nil {
+		t.Fatalf("HandleList: %v", err)
+	}
+	text := server.WaitForMessage(":clipboard: *Cluster List*", time.Second).Text()
+	if !strings.Contains(text, ":grey_question: *spoticus-k8s-a*") {
+		t.Errorf("list %q does not show the branded indicator", text)
+	}
+}
+
+func TestGroupByOwner(t *testing.T) {
+	owned := func(name, owner string) ClusterInfo {
+		return ClusterInfo{Name: name, Annotations: LaunchMetadata{Owner: owner}.Annotations()}
+	}
+	clusters := []ClusterInfo{
+		owned("a1", "UA"),
+		owned("orphan", ""),
+		owned("c1", "UC"),
+		owned("b1", "UB"),
+		owned("c2", "UC"),
+		owned("a2", "UA"),
+		owned("c3", "UC"),
+	}
+
+	var got []string
+	for _, group := range groupByOwner(clusters) {
+		var names []string
+		for _, cluster := range group.Clusters {
+			names = append(names, cluster.Name)
+		}
+		got = append(got, group.Owner+":"+strings.Join(names, ","))
+	}
+	want := "UC:c1,c2,c3 UA:a1,a2 UB:b1 :orphan"
+	if strings.Join(got, " ") != want {
+		t.Errorf("groupByOwner() = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestListByOwner(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(
+		existingCluster("spoticus-k8s-bob", "UBOB", 0),
+		existingCluster("spoticus-k8s-alice1", "UALICE", 0),
+		existingCluster("spoticus-k8s-alice2", "UALICE", 0),
+	))
+	api, server := newAPI(t)
+
+	if err := HandleList(context.Background(), api, message("U1", "C1", "list --by-owner"), []string{"--by-owner"}); err != nil {
+		t.Fatalf("HandleList: %v", err)
+	}
+	text := server.WaitForMessage("Cluster List", time.Second).Text()
+
+	alice := strings.Index(text, "<@UALICE> (2 clusters)")
+	bob := strings.Index(text, "<@UBOB> (1 cluster)")
+	if alice < 0 || bob < 0 || alice > bob {
+		t.Fatalf("list %q, want UALICE with 2 clusters before UBOB with 1", text)
+	}
+	for _, name := range []string{"spoticus-k8s-alice1", "spoticus-k8s-alice2"} {
+		if i := strings.Index(text, name); i < alice || i > bob {
+			t.Errorf("list %q does not show %s under its owner", text, name)
+		}
+	}
+	if i := strings.Index(text, "spoticus-k8s-bob"); i < bob {
+		t.Errorf("list %q does not show spoticus-k8s-bob under its owner", text)
+	}
+}
+
+func TestListRejectsUnknownFlag(t *testing.T) {
+	useConfig(t, testConfig())
+	api, _ := newAPI(t)
+
+	err := HandleList(context.Background(), api, message("U1", "C1", "list --by-size"), []string{"--by-size"})
+	if CategoryOf(err) != CategoryValidation {
+		t.Errorf("HandleList() = %v, want the usage", err)
+	}
+}
+
+func TestSortClustersIsDeterministic(t *testing.T) {
+	now := time.Now()
+	clusters := []ClusterInfo{
+		{Name: "spoticus-k8s-c", Created: now},
+		{Name: "spoticus-k8s-b", Created: now.Add(-time.Hour)},
+		{Name: "spoticus-k8s-a", Created: now},
+	}
+	sortClusters(clusters)
+
+	var got []string
+	for _, cluster := range clusters {
+		got = append(got, cluster.Name)
+	}
+	if want := "spoticus-k8s-b spoticus-k8s-a spoticus-k8s-c"; strings.Join(got, " ") != want {
+		t.Errorf("sortClusters() = %q, want oldest first, then by name: %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestFormatClusterListCapsResults(t *testing.T) {
+	useConfig(t, testConfig())
+	now := time.Now()
+	var inventory clusterInventory
+	for i, name := range []string{"spoticus-k8s-first", "spoticus-k8s-second", "spoticus-k8s-third"} {
+		inventory.Clusters = append(inventory.Clusters, ClusterInfo{Name: name, Type: "k8s", Created: now.Add(time.Duration(i) * time.Minute)})
+	}
+
+	text, _ := formatClusterList(inventory, false, 0, 2)
+	if !strings.Contains(text, "spoticus-k8s-first") || !strings.Contains(text, "spoticus-k8s-second") || strings.Contains(text, "spoticus-k8s-third") {
+		t.Errorf("list %q, want only the first 2 clusters", text)
+	}
+	if !strings.Contains(text, "*Cluster List* (3 clusters)") {
+		t.Errorf("list %q, want the header to count every cluster", text)
+	}
+	if !strings.Contains(text, "Clusters 1–2 of 3") {
+		t.Errorf("list %q does not warn that only 2 of 3 clusters are shown", text)
+	}
+
+	text, _ = formatClusterList(inventory, false, 0, 0)
+	if !strings.Contains(text, "spoticus-k8s-third") || strings.Contains(text, " of 3 (page") {
+		t.Errorf("list %q with no maximum, want every cluster without a warning", text)
+	}
+}
+
+func TestStatusIndicator(t *testing.T) {
+	useConfig(t, testConfig())
+	tests := []struct {
+		phase string
+		want  string
+	}{
+		{phase: phaseReady, want: "🟢"},
+		{phase: phaseProvisioning, want: "🟡"},
+		{phase: phaseFailed, want: "🔴"},
+		{phase: "", want: "⚪"},
+		{phase: "Hibernating", want: "⚪"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phase, func(t *testing.T) {
+			if got := statusIndicator(tt.phase); got != tt.want {
+				t.Errorf("statusIndicator(%q) = %q, want %q", tt.phase, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCoversTypeNamespaces(t *testing.T) {
+	cfg := testConfig()
+	cfg.TypeNamespaces = map[string]string{"openshift": "ocp"}
+	useConfig(t, cfg)
+	typed := existingClusterOf("openshift", "spoticus-openshift-typed", "U1", 0)
+	typed.SetNamespace("ocp")
+	stray := existingCluster("spoticus-k8s-stray", "U1", 0)
+	stray.SetNamespace("elsewhere")
+	useKube(t, slacktest.NewKube(typed, stray, existingCluster("spoticus-k8s-global", "U1", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleList(context.Background(), api, message("U1", "C1", "list"), nil); err != nil {
+		t.Fatalf("HandleList: %v", err)
+	}
+	text := server.WaitForMessage("Cluster List", time.Second).Text()
+	for _, name := range []string{"spoticus-openshift-typed", "spoticus-k8s-global"} {
+		if !strings.Contains(text, name) {
+			t.Errorf("list %q does not show %s", text, name)
+		}
+	}
+	if strings.Contains(text, "spoticus-k8s-stray") {
+		t.Errorf("list %q shows a cluster outside the configured namespaces", text)
+	}
+
+	name := "spoticus-openshift-typed"
+	if err := HandleStatus(context.Background(), api, message("U1", "C1", "status "+name), []string{name}); err != nil {
+		t.Fatalf("HandleStatus: %v", err)
+	}
+	server.WaitForMessage("*"+name+"* ("+clusterTypeNames["openshift"]+")", time.Second)
+}
+
+func TestListAllNamespaces(t *testing.T) {
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	stray := existingCluster("spoticus-k8s-elsewhere", "U1", 0)
+	stray.SetNamespace("elsewhere")
+	useKube(t, slacktest.NewKube(stray, existingCluster("spoticus-k8s-scoped", "U1", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleList(context.Background(), api, message("UADMIN", "C1", "list"), nil); err != nil {
+		t.Fatalf("HandleList: %v", err)
+	}
+	if text := server.WaitForMessage("Cluster List", time.Second).Text(); strings.Contains(text, "spoticus-k8s-elsewhere") {
+		t.Errorf("scoped list %q shows a cluster outside the configured namespaces", text)
+	}
+
+	if err := HandleList(context.Background(), api, message("UADMIN", "C1", "list --all-namespaces --by-owner"), []string{"--all-namespaces", "--by-owner"}); err != nil {
+		t.Fatalf("HandleList --all-namespaces: %v", err)
+	}
+	text := server.Messages()[1].Text()
+	for _, want := range []string{"spoticus-k8s-elsewhere", "spoticus-k8s-scoped", "<@U1> (2 clusters)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("list across all namespaces %q does not contain %q", text, want)
+		}
+	}
+}
+
+func TestListAllNamespacesRestrictedToAdmins(t *testing.T) {
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-private", "U1", 0)))
+	api, server := newAPI(t)
+
+	err := HandleList(context.Background(), api, message("U1", "C1", "list --all-namespaces"), []string{"--all-namespaces"})
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryAuth || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Only bot administrators can use `--all-namespaces`") {
+		t.Errorf("HandleList() by a non-admin = %v, want access denied", err)
+	}
+	if got := len(server.Messages()); got != 0 {
+		t.Errorf("posted %d messages, want none", got)
+	}
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+const (
+	// stateConfigMapName is the ConfigMap persisting the bot's runtime state across restarts.
+	stateConfigMapName = "spoticus-state"
+
+	// stateKeyMaintenance records whether maintenance mode is on.
+	stateKeyMaintenance = "maintenance"
+)
+
+// maintenanceMode is on while mutating commands are refused.
+var maintenanceMode atomic.Bool
+
+// MaintenanceEnabled reports whether the bot is in maintenance mode.
+func MaintenanceEnabled() bool {
+	return maintenanceMode.Load()
+}
+
+// RestoreMaintenance sets maintenance mode from its persisted state, falling
+// back to the configured default when it was never toggled.
+func RestoreMaintenance() {
+	cfg := spoticusConfig.Get()
+	maintenanceMode.Store(cfg.Maintenance)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		log.Printf("Error getting kubernetes client to restore maintenance mode: %v", err)
+		return
+	}
+	cm := &corev1.ConfigMap{}
+	key := crclient.ObjectKey{Namespace: cfg.Namespace, Name: stateConfigMapName}
+	if err := client.CrClient.Get(context.TODO(), key, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Printf("Error reading persisted maintenance mode: %v", err)
+		}
+		return
+	}
+	if enabled, err := strconv.ParseBool(cm.Data[stateKeyMaintenance]); err == nil {
+		maintenanceMode.Store(enabled)
+	}
+	if MaintenanceEnabled() {
+		log.Printf("Maintenance mode is on")
+	}
+}
+
+// persistMaintenance records the maintenance mode in the state ConfigMap.
+func persistMaintenance(ctx context.Context, client *KubernetesClients, enabled bool) error {
+	cm := &corev1.ConfigMap{}
+	key := crclient.ObjectKey{Namespace: spoticusConfig.Get().Namespace, Name: stateConfigMapName}
+	err := client.CrClient.Get(ctx, key, cm)
+	switch {
+	case apierrors.IsNotFound(err):
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Data:       map[string]string{stateKeyMaintenance: strconv.FormatBool(enabled)},
+		}
+		return client.CrClient.Create(ctx, cm)
+	case err != nil:
+		return err
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[stateKeyMaintenance] = strconv.FormatBool(enabled)
+	return client.CrClient.Update(ctx, cm)
+}
+
+// HandleMaintenance is the entry point for the admin "maintenance" Slack command.
+//
+// `maintenance on` makes the mutating commands refuse to run, e.g. during an
+// upgrade, while read commands keep working; `maintenance off` lifts it and
+// `maintenance` shows the current mode. The mode is persisted so that it
+// survives restarts.
+func HandleMaintenance(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) == 0 {
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.MaintenanceStatus, messages.Data{"Enabled": MaintenanceEnabled()}))
+		return nil
+	}
+
+	var enabled bool
+	switch strings.ToLower(args[0]) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return invalid(cfg.Messages.MaintenanceUsage)
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+	if err := persistMaintenance(ctx, client, enabled); err != nil {
+		return failure(err, cfg.Messages.MaintenanceFailed, "persisting maintenance mode")
+	}
+	maintenanceMode.Store(enabled)
+
+	log.Printf("Maintenance mode set to %t by %s", enabled, event.User)
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.MaintenanceStatus, messages.Data{"Enabled": enabled}))
+	return nil
+}
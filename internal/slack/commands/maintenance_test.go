@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// useMaintenance starts the test with maintenance mode set as given, and
+// restores it when the test ends.
+func useMaintenance(t *testing.T, enabled bool) {
+	t.Helper()
+	previous := maintenanceMode.Load()
+	maintenanceMode.Store(enabled)
+	t.Cleanup(func() { maintenanceMode.Store(previous) })
+}
+
+// persistedMaintenance returns the maintenance mode recorded in the state
+// ConfigMap, or "" if there is none.
+func persistedMaintenance(t *testing.T, kube *slacktest.Kube) string {
+	t.Helper()
+	cm := &corev1.ConfigMap{}
+	if err := kube.CrClient.Get(context.Background(), crclient.ObjectKey{Namespace: "default", Name: stateConfigMapName}, cm); err != nil {
+		return ""
+	}
+	return cm.Data[stateKeyMaintenance]
+}
+
+// stateConfigMap is the state ConfigMap recording the maintenance mode.
+func stateConfigMap(maintenance string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: stateConfigMapName, Namespace: "default"},
+		Data:       map[string]string{stateKeyMaintenance: maintenance},
+	}
+}
+
+func TestMaintenanceToggle(t *testing.T) {
+	useConfig(t, testConfig())
+	useMaintenance(t, false)
+	kube := slacktest.NewKube()
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleMaintenance(context.Background(), api, message("UADMIN", "C1", "maintenance on"), []string{"on"}); err != nil {
+		t.Fatalf("HandleMaintenance on: %v", err)
+	}
+	server.WaitForMessage("Maintenance mode is *on*", time.Second)
+	if !MaintenanceEnabled() || persistedMaintenance(t, kube) != "true" {
+		t.Errorf("maintenance on: enabled %v, persisted %q, want it on and persisted", MaintenanceEnabled(), persistedMaintenance(t, kube))
+	}
+
+	if err := HandleMaintenance(context.Background(), api, message("UADMIN", "C1", "maintenance off"), []string{"OFF"}); err != nil {
+		t.Fatalf("HandleMaintenance off: %v", err)
+	}
+	server.WaitForMessage("Maintenance mode is *off*", time.Second)
+	if MaintenanceEnabled() || persistedMaintenance(t, kube) != "false" {
+		t.Errorf("maintenance off: enabled %v, persisted %q, want it off and persisted", MaintenanceEnabled(), persistedMaintenance(t, kube))
+	}
+}
+
+func TestMaintenanceShowsStatus(t *testing.T) {
+	useConfig(t, testConfig())
+	useMaintenance(t, true)
+	api, server := newAPI(t)
+
+	if err := HandleMaintenance(context.Background(), api, message("UADMIN", "C1", "maintenance"), nil); err != nil {
+		t.Fatalf("HandleMaintenance: %v", err)
+	}
+	server.WaitForMessage("Maintenance mode is *on*", time.Second)
+}
+
+func TestMaintenanceRejectsUnknownMode(t *testing.T) {
+	useConfig(t, testConfig())
+	useMaintenance(t, false)
+	api, _ := newAPI(t)
+
+	err := HandleMaintenance(context.Background(), api, message("UADMIN", "C1", "maintenance maybe"), []string{"maybe"})
+	if CategoryOf(err) != CategoryValidation {
+		t.Errorf("HandleMaintenance() = %v, want the usage", err)
+	}
+	if MaintenanceEnabled() {
+		t.Errorf("maintenance mode turned on by an invalid mode")
+	}
+}
+
+func TestRestoreMaintenance(t *testing.T) {
+	tests := []struct {
+		name      string
+		fromEnv   bool
+		persisted string
+		want      bool
+	}{
+		{name: "never toggled"},
+		{name: "configured on", fromEnv: true, want: true},
+		{name: "persisted on", persisted: "true", want: true},
+		{name: "persisted off over the configuration", fromEnv: true, persisted: "false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Maintenance = tt.fromEnv
+			useConfig(t, cfg)
+			useMaintenance(t, false)
+			var objects []crclient.Object
+			if tt.persisted != "" {
+				objects = append(objects, stateConfigMap(tt.persisted))
+			}
+			useKube(t, slacktest.NewKube(objects...))
+
+			RestoreMaintenance()
+
+			if got := MaintenanceEnabled(); got != tt.want {
+				t.Errorf("MaintenanceEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotation keys used to record launch metadata on MAPT resources.
+// Storing this on the resource itself means it survives bot restarts.
+const (
+	annotationOwner      = "spoticus.io/owner"
+	annotationChannel    = "spoticus.io/channel"
+	annotationTeam       = "spoticus.io/team"
+	annotationCommand    = "spoticus.io/command"
+	annotationLaunchedAt = "spoticus.io/launched-at"
+	annotationTTL        = "spoticus.io/ttl"
+	annotationHourlyCost = "spoticus.io/hourly-cost"
+	annotationSize       = "spoticus.io/size"
+	annotationInstance   = "spoticus.io/instance-type"
+	annotationCPULimit   = "spoticus.io/cpu-limit"
+	annotationMemLimit   = "spoticus.io/mem-limit"
+	annotationRef        = "spoticus.io/ref"
+	annotationVersion    = "spoticus.io/version"
+	annotationProvider   = "spoticus.io/provider"
+	annotationPermalink  = "spoticus.io/thread-permalink"
+)
+
+// LaunchMetadata describes who launched a cluster, from where, and with what expectations.
+type LaunchMetadata struct {
+	Owner      string
+	Channel    string
+	Team       string
+	Command    string
+	Size       string
+	Ref        string
+	LaunchedAt time.Time
+
+	// Provider is the cloud provider the cluster was launched on.
+	Provider string
+
+	// Permalink links to the Slack thread the cluster was launched from, so
+	// later notifications can point back to the conversation.
+	Permalink string
+
+	// Version is the OpenShift or Kubernetes version the cluster was launched with.
+	Version string
+
+	// InstanceType is set instead of Size when a specific instance type was requested.
+	InstanceType string
+
+	// CPULimit and MemoryLimit (in GB) are the --cpu-limit and --mem-limit
+	// the cluster was launched with, or zero.
+	CPULimit    int
+	MemoryLimit int
+
+	TTL        time.Duration
+	HourlyCost float64
+}
+
+// Annotations encodes the metadata as resource annotations.
+// Zero values are omitted so they do not overwrite anything meaningful.
+func (m LaunchMetadata) Annotations() map[string]string {
+	annotations := map[string]string{}
+	set := func(key, value string) {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+
+	set(annotationOwner, m.Owner)
+	set(annotationChannel, m.Channel)
+	set(annotationTeam, m.Team)
+	set(annotationCommand, m.Command)
+	set(annotationSize, m.Size)
+	set(annotationInstance, m.InstanceType)
+	set(annotationRef, m.Ref)
+	set(annotationVersion, m.Version)
+	set(annotationProvider, m.Provider)
+	set(annotationPermalink, m.Permalink)
+	if !m.LaunchedAt.IsZero() {
+		set(annotationLaunchedAt, m.LaunchedAt.UTC().Format(time.RFC3339))
+	}
+	if m.TTL > 0 {
+		set(annotationTTL, m.TTL.String())
+	}
+	if m.CPULimit > 0 {
+		set(annotationCPULimit, strconv.Itoa(m.CPULimit))
+	}
+	if m.MemoryLimit > 0 {
+		set(annotationMemLimit, strconv.Itoa(m.MemoryLimit))
+	}
+	if m.HourlyCost > 0 {
+		set(annotationHourlyCost, strconv.FormatFloat(m.HourlyCost, 'f', 2, 64))
+	}
+	return annotations
+}
+
+// SetLaunchMetadata merges the metadata annotations into the object's existing annotations.
+func SetLaunchMetadata(obj metav1.Object, m LaunchMetadata) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range m.Annotations() {
+		annotations[key] = value
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// ParseLaunchMetadata decodes launch metadata from resource annotations.
+// Missing or malformed values are left at their zero value.
+func ParseLaunchMetadata(annotations map[string]string) LaunchMetadata {
+	m := LaunchMetadata{
+		Owner:   annotations[annotationOwner],
+		Channel: annotations[annotationChannel],
+		Team:    annotations[annotationTeam],
+		Command: annotations[annotationCommand],
+		Size:    annotations[annotationSize],
+		Ref:     annotations[annotationRef],
+		Version: annotations[annotationVersion],
+
+		Provider:     annotations[annotationProvider],
+		Permalink:    annotations[annotationPermalink],
+		InstanceType: annotations[annotationInstance],
+	}
+	if t, err := time.Parse(time.RFC3339, annotations[annotationLaunchedAt]); err == nil {
+		m.LaunchedAt = t
+	}
+	if d, err := time.ParseDuration(annotations[annotationTTL]); err == nil {
+		m.TTL = d
+	}
+	if n, err := strconv.Atoi(annotations[annotationCPULimit]); err == nil {
+		m.CPULimit = n
+	}
+	if n, err := strconv.Atoi(annotations[annotationMemLimit]); err == nil {
+		m.MemoryLimit = n
+	}
+	if c, err := strconv.ParseFloat(annotations[annotationHourlyCost], 64); err == nil {
+		m.HourlyCost = c
+	}
+	return m
+}
+
+// Metadata returns the launch metadata recorded on the cluster.
+func (c ClusterInfo) Metadata() LaunchMetadata {
+	return ParseLaunchMetadata(c.Annotations)
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// fullMetadata sets every field of the launch metadata.
+func fullMetadata() LaunchMetadata {
+	return LaunchMetadata{
+		Owner:        "U1",
+		Channel:      "C1",
+		Team:         "T1",
+		Command:      "launch k8s medium --ttl=1d",
+		Size:         "medium",
+		Ref:          "PROJ-123",
+		LaunchedAt:   time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC),
+		Provider:     "aws",
+		Permalink:    "https://slack.test/archives/C1/p1700000000000001",
+		Version:      "4.16",
+		InstanceType: "m6i.2xlarge",
+		CPULimit:     4,
+		MemoryLimit:  16,
+		TTL:          24 * time.Hour,
+		HourlyCost:   0.15,
+	}
+}
+
+func TestLaunchMetadataRoundTrip(t *testing.T) {
+	want := fullMetadata()
+	if got := ParseLaunchMetadata(want.Annotations()); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLaunchMetadata(Annotations()) = %+v, want %+v", got, want)
+	}
+}
+
+func TestLaunchMetadataRoundTripsThroughCreateAndGet(t *testing.T) {
+	kube := slacktest.NewKube()
+	want := fullMetadata()
+	cluster := newClusterObject("spoticus-k8s-meta", &LaunchRequest{Type: "k8s", Namespace: "default"})
+	SetLaunchMetadata(cluster, want)
+	if err := kube.CrClient.Create(context.Background(), cluster); err != nil {
+		t.Fatalf("creating the cluster: %v", err)
+	}
+
+	read := &unstructured.Unstructured{}
+	read.SetGroupVersionKind(cluster.GroupVersionKind())
+	if err := kube.CrClient.Get(context.Background(), crclient.ObjectKeyFromObject(cluster), read); err != nil {
+		t.Fatalf("getting the cluster: %v", err)
+	}
+	if got := ParseLaunchMetadata(read.GetAnnotations()); !reflect.DeepEqual(got, want) {
+		t.Errorf("metadata read back = %+v, want %+v", got, want)
+	}
+}
+
+func TestLaunchMetadataOmitsZeroValues(t *testing.T) {
+	if annotations := (LaunchMetadata{Owner: "U1"}).Annotations(); len(annotations) != 1 || annotations[annotationOwner] != "U1" {
+		t.Errorf("Annotations() = %v, want only the owner", annotations)
+	}
+}
+
+func TestSetLaunchMetadataMerges(t *testing.T) {
+	cluster := existingCluster("spoticus-k8s-merge", "U1", 0.15)
+	annotations := cluster.GetAnnotations()
+	annotations["example.com/other"] = "kept"
+	cluster.SetAnnotations(annotations)
+
+	SetLaunchMetadata(cluster, LaunchMetadata{Permalink: "https://slack.test/p1"})
+
+	annotations = cluster.GetAnnotations()
+	if annotations["example.com/other"] != "kept" {
+		t.Errorf("foreign annotation lost: %v", annotations)
+	}
+	got := ParseLaunchMetadata(annotations)
+	if got.Owner != "U1" || got.HourlyCost != 0.15 || got.Permalink != "https://slack.test/p1" {
+		t.Errorf("metadata after merge = %+v, want the owner and cost kept and the permalink added", got)
+	}
+}
+
+func TestParseLaunchMetadataIgnoresMalformedValues(t *testing.T) {
+	got := ParseLaunchMetadata(map[string]string{
+		annotationOwner:      "U1",
+		annotationLaunchedAt: "yesterday",
+		annotationTTL:        "a while",
+		annotationHourlyCost: "cheap",
+		annotationCPULimit:   "four",
+	})
+	if want := (LaunchMetadata{Owner: "U1"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLaunchMetadata() = %+v, want %+v", got, want)
+	}
+}
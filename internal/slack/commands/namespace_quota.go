@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// clusterCountResource returns the ResourceQuota object count of the MAPT
+// resources created for the cluster type, e.g. `count/kinds.<group>`.
+func clusterCountResource(clusterType string) corev1.ResourceName {
+	return corev1.ResourceName(fmt.Sprintf("count/%ss.%s", strings.ToLower(clusterKinds[clusterType]), maptApi.GroupVersion.Group))
+}
+
+// launchDemand is what a launch adds to the usage of its namespace's resource
+// quotas: one MAPT resource and, for quotas limiting them, the CPUs and
+// memory of the cluster.
+func launchDemand(clusterType string, resources spoticusConfig.SizeSpec) corev1.ResourceList {
+	demand := corev1.ResourceList{
+		clusterCountResource(clusterType): *resource.NewQuantity(1, resource.DecimalSI),
+	}
+	if resources.CPUs > 0 {
+		cpu := *resource.NewQuantity(int64(resources.CPUs), resource.DecimalSI)
+		demand[corev1.ResourceCPU] = cpu
+		demand[corev1.ResourceRequestsCPU] = cpu
+		demand[corev1.ResourceLimitsCPU] = cpu
+	}
+	if resources.MemoryGB > 0 {
+		memory := *resource.NewQuantity(int64(resources.MemoryGB)<<30, resource.BinarySI)
+		demand[corev1.ResourceMemory] = memory
+		demand[corev1.ResourceRequestsMemory] = memory
+		demand[corev1.ResourceLimitsMemory] = memory
+	}
+	return demand
+}
+
+// namespaceQuotas returns the resource quotas of the namespace, sorted by name.
+func namespaceQuotas(ctx context.Context, kube kubernetes.Interface, namespace string) ([]corev1.ResourceQuota, error) {
+	quotas, err := kube.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(quotas.Items, func(a, b corev1.ResourceQuota) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return quotas.Items, nil
+}
+
+// quotaShortfalls describes, one per line, the quota limits the demand does
+// not fit in. Resources a quota does not limit are not checked.
+func quotaShortfalls(quotas []corev1.ResourceQuota, demand corev1.ResourceList) []string {
+	var shortfalls []string
+	for _, quota := range quotas {
+		for _, name := range slices.Sorted(maps.Keys(demand)) {
+			hard, limited := quota.Status.Hard[name]
+			if !limited {
+				continue
+			}
+			requested := demand[name]
+			projected := quota.Status.Used[name]
+			projected.Add(requested)
+			if projected.Cmp(hard) <= 0 {
+				continue
+			}
+			used := quota.Status.Used[name]
+			shortfalls = append(shortfalls, messages.Render(msgs().QuotaShortfall, messages.Data{
+				"Quota":     quota.Name,
+				"Resource":  string(name),
+				"Requested": requested.String(),
+				"Used":      used.String(),
+				"Hard":      hard.String(),
+			}))
+		}
+	}
+	return shortfalls
+}
+
+// checkNamespaceQuota returns a warning for the user when the launch would
+// exceed a resource quota of the namespace, or empty. Quotas that cannot be
+// read are logged and ignored: they must never block a launch on their own.
+func checkNamespaceQuota(ctx context.Context, client *KubernetesClients, namespace string, req *LaunchRequest) string {
+	quotas, err := namespaceQuotas(ctx, client.KubeClient, namespace)
+	if err != nil {
+		log.Printf("Error reading the resource quotas of namespace %s: %v", namespace, err)
+		return ""
+	}
+	shortfalls := quotaShortfalls(quotas, launchDemand(req.Type, req.Resources()))
+	if len(shortfalls) == 0 {
+		return ""
+	}
+	return messages.Render(msgs().LaunchQuotaWarning, messages.Data{
+		"Namespace":  namespace,
+		"Shortfalls": strings.Join(shortfalls, "\n"),
+	})
+}
+
+// HandleCapacity is the entry point for the "capacity" Slack command.
+// `capacity [cluster_type]` reports the usage and limits of the resource
+// quotas of the namespace a launch in the channel would use, so that users
+// can tell whether it fits.
+func HandleCapacity(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	var clusterType string
+	switch len(args) {
+	case 0:
+	case 1:
+		clusterType = strings.ToLower(args[0])
+		if !isSupportedClusterType(clusterType) {
+			return invalid(messages.Render(cfg.Messages.UnsupportedType, messages.Data{"Type": args[0]}))
+		}
+	default:
+		return invalid(cfg.Messages.CapacityUsage)
+	}
+	namespace := cfg.DefaultsFor(event.Channel, clusterType).Namespace
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+	quotas, err := namespaceQuotas(ctx, client.KubeClient, namespace)
+	if err != nil {
+		return failure(err, messages.Render(cfg.Messages.CapacityFailed, messages.Data{"Namespace": namespace}),
+			"reading the resource quotas of namespace %s", namespace)
+	}
+	if len(quotas) == 0 {
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.CapacityNone, messages.Data{"Namespace": namespace}))
+		return nil
+	}
+
+	var entries strings.Builder
+	for _, quota := range quotas {
+		entries.WriteString(messages.Render(cfg.Messages.CapacityQuota, messages.Data{"Name": quota.Name}))
+		for _, name := range slices.Sorted(maps.Keys(quota.Status.Hard)) {
+			hard, used := quota.Status.Hard[name], quota.Status.Used[name]
+			data := messages.Data{
+				"Resource": string(name),
+				"Used":     used.String(),
+				"Hard":     hard.String(),
+				"Full":     used.Cmp(hard) >= 0,
+			}
+			if !hard.IsZero() {
+				data["Percent"] = fmt.Sprintf("%.0f%%", 100*used.AsApproximateFloat64()/hard.AsApproximateFloat64())
+			}
+			entries.WriteString(messages.Render(cfg.Messages.CapacityEntry, data))
+		}
+	}
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.Capacity, messages.Data{
+		"Namespace": namespace,
+		"Entries":   entries.String(),
+	}))
+	return nil
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// resourceList parses the quantities of the resources.
+func resourceList(quantities map[string]string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for name, quantity := range quantities {
+		list[corev1.ResourceName(name)] = resource.MustParse(quantity)
+	}
+	return list
+}
+
+// resourceQuota is a ResourceQuota of the namespace whose status reports the
+// hard limits and usage.
+func resourceQuota(namespace, name string, hard, used map[string]string) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status:     corev1.ResourceQuotaStatus{Hard: resourceList(hard), Used: resourceList(used)},
+	}
+}
+
+// useQuotas adds the resource quotas to the fake cluster.
+func useQuotas(t *testing.T, kube *slacktest.Kube, quotas ...*corev1.ResourceQuota) {
+	t.Helper()
+	for _, quota := range quotas {
+		if _, err := kube.KubeClient.CoreV1().ResourceQuotas(quota.Namespace).Create(context.Background(), quota, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("creating quota %s: %v", quota.Name, err)
+		}
+	}
+}
+
+func TestLaunchDemand(t *testing.T) {
+	demand := launchDemand("k8s", spoticusConfig.SizeSpec{CPUs: 8, MemoryGB: 32})
+
+	count := corev1.ResourceName("count/kinds." + maptApi.GroupVersion.Group)
+	if clusterCountResource("k8s") != count {
+		t.Errorf("clusterCountResource(k8s) = %s, want %s", clusterCountResource("k8s"), count)
+	}
+	for name, want := range map[corev1.ResourceName]string{
+		count:                         "1",
+		corev1.ResourceRequestsCPU:    "8",
+		corev1.ResourceLimitsCPU:      "8",
+		corev1.ResourceMemory:         "32Gi",
+		corev1.ResourceRequestsMemory: "32Gi",
+	} {
+		got := demand[name]
+		if got.Cmp(resource.MustParse(want)) != 0 {
+			t.Errorf("demand of %s = %s, want %s", name, got.String(), want)
+		}
+	}
+
+	if demand := launchDemand("openshift", spoticusConfig.SizeSpec{}); len(demand) != 1 {
+		t.Errorf("launchDemand() without resources = %v, want only the object count", demand)
+	}
+}
+
+func TestQuotaShortfalls(t *testing.T) {
+	useConfig(t, testConfig())
+	quotas := []corev1.ResourceQuota{
+		*resourceQuota("default", "compute", map[string]string{"requests.cpu": "16", "requests.memory": "64Gi"}, map[string]string{"requests.cpu": "4", "requests.memory": "48Gi"}),
+		*resourceQuota("default", "objects", map[string]string{"pods": "10", string(clusterCountResource("k8s")): "2"}, map[string]string{string(clusterCountResource("k8s")): "1"}),
+	}
+
+	shortfalls := quotaShortfalls(quotas, launchDemand("k8s", spoticusConfig.SizeSpec{CPUs: 8, MemoryGB: 32}))
+	want := "• `requests.memory` of quota *compute*: 32Gi more would exceed the 48Gi of 64Gi used"
+	if len(shortfalls) != 1 || shortfalls[0] != want {
+		t.Errorf("quotaShortfalls() = %q, want only %q", shortfalls, want)
+	}
+
+	if shortfalls := quotaShortfalls(quotas, launchDemand("k8s", spoticusConfig.SizeSpec{CPUs: 12, MemoryGB: 16})); len(shortfalls) != 0 {
+		t.Errorf("quotaShortfalls() at the limit = %q, want none", shortfalls)
+	}
+}
+
+func TestHandleCapacityReportsQuotas(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKube()
+	useQuotas(t, kube,
+		resourceQuota("default", "objects", map[string]string{"pods": "10"}, map[string]string{"pods": "10"}),
+		resourceQuota("default", "compute", map[string]string{"requests.cpu": "16"}, map[string]string{"requests.cpu": "4"}),
+		resourceQuota("elsewhere", "hidden", map[string]string{"pods": "1"}, nil),
+	)
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleCapacity(context.Background(), api, message("U1", "C1", "capacity"), nil); err != nil {
+		t.Fatalf("HandleCapacity: %v", err)
+	}
+	text := server.WaitForMessage("Resource quotas of namespace `default`", time.Second).Text()
+	compute := strings.Index(text, "*compute*\n• `requests.cpu`: 4 of 16 used (25%)")
+	objects := strings.Index(text, "*objects*\n• `pods`: 10 of 10 used (100%) ⚠️ full")
+	if compute < 0 || objects < compute {
+		t.Errorf("capacity %q, want the quotas sorted with their usage", text)
+	}
+	if strings.Contains(text, "hidden") {
+		t.Errorf("capacity %q shows a quota of another namespace", text)
+	}
+}
+
+func TestHandleCapacityUsesTypeNamespace(t *testing.T) {
+	cfg := testConfig()
+	cfg.TypeNamespaces = map[string]string{"openshift": "ocp"}
+	useConfig(t, cfg)
+	kube := slacktest.NewKube()
+	useQuotas(t, kube, resourceQuota("ocp", "ocp-compute", map[string]string{"requests.cpu": "32"}, map[string]string{"requests.cpu": "8"}))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleCapacity(context.Background(), api, message("U1", "C1", "capacity OpenShift"), []string{"OpenShift"}); err != nil {
+		t.Fatalf("HandleCapacity: %v", err)
+	}
+	server.WaitForMessage("*ocp-compute*", time.Second)
+
+	if err := HandleCapacity(context.Background(), api, message("U1", "C1", "capacity k8s"), []string{"k8s"}); err != nil {
+		t.Fatalf("HandleCapacity: %v", err)
+	}
+	server.WaitForMessage("Namespace `default` has no resource quota", time.Second)
+}
+
+func TestHandleCapacityRejectsArguments(t *testing.T) {
+	useConfig(t, testConfig())
+	api, _ := newAPI(t)
+
+	for args, want := range map[string]string{
+		"gke":           "gke",
+		"k8s openshift": "Usage: `capacity [cluster_type]`",
+	} {
+		err := HandleCapacity(context.Background(), api, message("U1", "C1", "capacity "+args), strings.Fields(args))
+		var cmdErr *CommandError
+		if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, want) {
+			t.Errorf("HandleCapacity(%q) = %v, want a validation error containing %q", args, err, want)
+		}
+	}
+}
+
+func TestLaunchWarnsAboutNamespaceQuota(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useQuotas(t, kube, resourceQuota("default", "compute", map[string]string{"requests.cpu": "10"}, map[string]string{"requests.cpu": "6"}))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U29", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	warning := server.WaitForMessage("may not fit the resource quotas of namespace `default`", 5*time.Second).Text()
+	if !strings.Contains(warning, "`requests.cpu` of quota *compute*: 8 more would exceed the 6 of 10 used") {
+		t.Errorf("warning %q, want the exceeded quota", warning)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want the launch to go ahead despite the warning", len(kinds))
+	}
+}
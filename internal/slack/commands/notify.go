@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// notifyTimeout bounds the delivery of a notification to a notifier other
+// than Slack, e.g. a slow webhook.
+const notifyTimeout = 10 * time.Second
+
+// Cluster lifecycle events delivered to the notifiers.
+const (
+	EventLaunched = "launched"
+	EventReady    = "ready"
+	EventFailed   = "failed"
+	EventDeleted  = "deleted"
+)
+
+// Notification is a cluster lifecycle event, as delivered to the notifiers.
+// It is also the JSON payload posted to the notification webhooks.
+type Notification struct {
+	Event     string    `json:"event"`
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Type      string    `json:"type"`
+	Owner     string    `json:"owner,omitempty"`
+	Time      time.Time `json:"time"`
+
+	// User is the Slack user ID of whoever caused the event, when it was
+	// not the bot itself.
+	User string `json:"user,omitempty"`
+
+	// Permalink links to the Slack thread the cluster was launched from.
+	Permalink string `json:"permalink,omitempty"`
+
+	// Text is the Slack message describing the event, or empty when the
+	// event has none of its own (e.g. it is the reply to a command).
+	Text string `json:"text,omitempty"`
+
+	// Channel and ThreadTS are where the Slack message is posted.
+	Channel  string `json:"channel,omitempty"`
+	ThreadTS string `json:"-"`
+}
+
+// Notifier delivers cluster lifecycle events somewhere, e.g. to Slack or to
+// a ChatOps pipeline.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// SlackNotifier posts the text of the notifications in their Slack channel
+// or thread. Notifications without a text or channel are skipped.
+type SlackNotifier struct {
+	API *slack.Client
+}
+
+// Notify posts the notification's text.
+func (n SlackNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Text == "" || notification.Channel == "" {
+		return nil
+	}
+	respond.Thread(n.API, notification.Channel, notification.ThreadTS, notification.Text)
+	return nil
+}
+
+// WebhookNotifier posts the notifications as JSON to an HTTP endpoint.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// Notify posts the notification, failing unless the endpoint answers 2xx.
+func (n WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+var (
+	extraNotifiersMu sync.RWMutex
+	extraNotifiers   []Notifier
+)
+
+// SetNotifiers sets notifiers to deliver the lifecycle events to, in addition
+// to Slack and the configured notificationWebhooks. Calling it with none
+// removes them.
+func SetNotifiers(notifiers ...Notifier) {
+	extraNotifiersMu.Lock()
+	defer extraNotifiersMu.Unlock()
+	extraNotifiers = notifiers
+}
+
+// currentNotifiers returns the notifiers other than Slack: one per configured
+// webhook, then those set with SetNotifiers.
+func currentNotifiers() []Notifier {
+	var notifiers []Notifier
+	for _, url := range spoticusConfig.Get().NotificationWebhooks {
+		notifiers = append(notifiers, WebhookNotifier{URL: url})
+	}
+	extraNotifiersMu.RLock()
+	defer extraNotifiersMu.RUnlock()
+	return append(notifiers, extraNotifiers...)
+}
+
+// clusterNotification describes an event of the cluster caused by user.
+func clusterNotification(event string, cluster ClusterInfo, user string) Notification {
+	metadata := cluster.Metadata()
+	return Notification{
+		Event:     event,
+		Cluster:   cluster.Name,
+		Namespace: cluster.Namespace,
+		Type:      cluster.Type,
+		Owner:     metadata.Owner,
+		Time:      time.Now(),
+		User:      user,
+		Permalink: metadata.Permalink,
+	}
+}
+
+// notify delivers the notification to Slack right away, then to the other
+// notifiers in the background so that a slow one holds nothing up. Delivery
+// failures are logged. Repeats within the notificationWindow are dropped.
+func notify(api *slack.Client, notification Notification) {
+	if !shouldNotify(notification.Cluster, notification.Event+"\x00"+notification.Text) {
+		return
+	}
+	if err := (SlackNotifier{API: api}).Notify(context.Background(), notification); err != nil {
+		log.Printf("Error notifying Slack of %s cluster %s: %v", notification.Event, notification.Cluster, err)
+	}
+	for _, notifier := range currentNotifiers() {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+			if err := notifier.Notify(ctx, notification); err != nil {
+				log.Printf("Error delivering the %s notification of cluster %s: %v", notification.Event, notification.Cluster, err)
+			}
+		}()
+	}
+}
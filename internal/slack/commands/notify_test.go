@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// recordingNotifier is a Notifier that keeps what it is delivered.
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+// waitFor waits until the notifier got the event about the cluster, and
+// returns it. The test fails if it did not within the timeout.
+func (n *recordingNotifier) waitFor(t *testing.T, event, cluster string, timeout time.Duration) Notification {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		n.mu.Lock()
+		i := slices.IndexFunc(n.notifications, func(got Notification) bool {
+			return got.Event == event && got.Cluster == cluster
+		})
+		var found Notification
+		if i >= 0 {
+			found = n.notifications[i]
+		}
+		got := slices.Clone(n.notifications)
+		n.mu.Unlock()
+		if i >= 0 {
+			return found
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no %s notification of %s within %s; got %+v", event, cluster, timeout, got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// useNotifier delivers the lifecycle events to a recordingNotifier for the
+// duration of the test.
+func useNotifier(t *testing.T) *recordingNotifier {
+	t.Helper()
+	notifier := &recordingNotifier{}
+	SetNotifiers(notifier)
+	t.Cleanup(func() { SetNotifiers() })
+	return notifier
+}
+
+func TestWebhookNotifierPostsJSON(t *testing.T) {
+	var (
+		contentType string
+		body        []byte
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	notification := Notification{
+		Event: EventReady, Cluster: "spoticus-k8s-hooked", Namespace: "default", Type: "k8s", Owner: "U1",
+		Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Text: "ready", Channel: "C1", ThreadTS: "1700000000.000001",
+	}
+	if err := (WebhookNotifier{URL: webhook.URL}).Notify(context.Background(), notification); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type %q, want application/json", contentType)
+	}
+	want := `{"event":"ready","cluster":"spoticus-k8s-hooked","namespace":"default","type":"k8s","owner":"U1",` +
+		`"time":"2026-01-02T03:04:05Z","text":"ready","channel":"C1"}`
+	if string(body) != want {
+		t.Errorf("body %s, want %s", body, want)
+	}
+
+	var decoded Notification
+	if err := json.Unmarshal(body, &decoded); err != nil || decoded.ThreadTS != "" {
+		t.Errorf("decoded %+v (%v), want the thread timestamp left out", decoded, err)
+	}
+}
+
+func TestWebhookNotifierFailsOnErrorStatus(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer webhook.Close()
+
+	err := (WebhookNotifier{URL: webhook.URL}).Notify(context.Background(), Notification{Event: EventDeleted})
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Notify() = %v, want the status reported", err)
+	}
+}
+
+func TestSlackNotifierPostsText(t *testing.T) {
+	api, server := newAPI(t)
+	notifier := SlackNotifier{API: api}
+
+	if err := notifier.Notify(context.Background(), Notification{Event: EventLaunched, Cluster: "spoticus-k8s-quiet", Channel: "C1"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if err := notifier.Notify(context.Background(), Notification{Event: EventReady, Text: "no channel"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got := len(server.Messages()); got != 0 {
+		t.Errorf("posted %d messages, want notifications without a text or channel skipped", got)
+	}
+
+	if err := notifier.Notify(context.Background(), Notification{Event: EventReady, Text: "*spoticus-k8s-loud* is ready", Channel: "C1", ThreadTS: "1700000000.000001"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	posted := server.WaitForMessage("*spoticus-k8s-loud* is ready", time.Second)
+	if posted.Channel() != "C1" || posted.Values.Get("thread_ts") != "1700000000.000001" {
+		t.Errorf("posted in %s thread %q, want the notification's thread", posted.Channel(), posted.Values.Get("thread_ts"))
+	}
+}
+
+func TestCurrentNotifiersIncludeWebhooks(t *testing.T) {
+	cfg := testConfig()
+	cfg.NotificationWebhooks = []string{"https://events.example.com/a", "https://events.example.com/b"}
+	useConfig(t, cfg)
+	extra := useNotifier(t)
+
+	notifiers := currentNotifiers()
+	if len(notifiers) != 3 {
+		t.Fatalf("currentNotifiers() = %v, want the two webhooks and the extra notifier", notifiers)
+	}
+	for i, url := range cfg.NotificationWebhooks {
+		if webhook, ok := notifiers[i].(WebhookNotifier); !ok || webhook.URL != url {
+			t.Errorf("notifier %d = %v, want the webhook %s", i, notifiers[i], url)
+		}
+	}
+	if notifiers[2] != Notifier(extra) {
+		t.Errorf("notifier 2 = %v, want the extra notifier", notifiers[2])
+	}
+}
+
+func TestLaunchNotifiesLifecycle(t *testing.T) {
+	useConfig(t, testConfig())
+	notifier := useNotifier(t)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U28", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(kinds))
+	}
+	name := kinds[0].Name
+
+	launched := notifier.waitFor(t, EventLaunched, name, time.Second)
+	if launched.User != "U28" || launched.Owner != "U28" || launched.Type != "k8s" || launched.Namespace != "default" {
+		t.Errorf("launched notification %+v, want the requester, type and namespace", launched)
+	}
+	ready := notifier.waitFor(t, EventReady, name, time.Second)
+	if ready.Channel != "C1" || !strings.Contains(ready.Text, "is ready") {
+		t.Errorf("ready notification %+v, want the Slack message and where it is posted", ready)
+	}
+}
+
+func TestDoneNotifiesDeleted(t *testing.T) {
+	useConfig(t, testConfig())
+	notifier := useNotifier(t)
+	name := "spoticus-k8s-notifydone"
+	useKube(t, slacktest.NewKube(existingCluster(name, "UOWNER", 0)))
+	api, _ := newAPI(t)
+
+	if err := HandleDone(context.Background(), api, message("UOWNER", "C1", "done "+name), []string{name}); err != nil {
+		t.Fatalf("HandleDone: %v", err)
+	}
+	deleted := notifier.waitFor(t, EventDeleted, name, time.Second)
+	if deleted.User != "UOWNER" || deleted.Owner != "UOWNER" {
+		t.Errorf("deleted notification %+v, want who deleted and owned the cluster", deleted)
+	}
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// permissionCheck is a verb the bot needs on a resource, in a namespace.
+type permissionCheck struct {
+	Namespace   string
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+}
+
+// String names the resource as kubectl does, e.g. "kinds.mapt.redhat.com" or
+// "pods/log".
+func (c permissionCheck) String() string {
+	name := c.Resource
+	if c.Subresource != "" {
+		name += "/" + c.Subresource
+	}
+	if c.Group != "" {
+		name += "." + c.Group
+	}
+	return name
+}
+
+// permissionResult is the outcome of a permissionCheck.
+type permissionResult struct {
+	permissionCheck
+	Allowed bool
+
+	// Reason is why the API server allowed or denied the request, if it
+	// said so, or the error when it could not be checked.
+	Reason string
+}
+
+// requiredPermissions lists what the bot does with the Kubernetes API: the
+// MAPT resources, presets and state in the namespace, the events and resource
+// quotas it reports, and the MAPT operator's logs read by `diagnostics`.
+func requiredPermissions(operator spoticusConfig.MaptOperator, namespace string) []permissionCheck {
+	var checks []permissionCheck
+	add := func(namespace, group, resource, subresource string, verbs ...string) {
+		for _, verb := range verbs {
+			checks = append(checks, permissionCheck{namespace, verb, group, resource, subresource})
+		}
+	}
+	for _, clusterType := range slices.Sorted(maps.Keys(supportedClusterTypes)) {
+		add(namespace, maptApi.GroupVersion.Group, strings.ToLower(clusterKinds[clusterType])+"s", "",
+			"get", "list", "create", "update", "patch", "delete")
+	}
+	add(namespace, "", "configmaps", "", "get", "create", "update")
+	add(namespace, "", "events", "", "list")
+	add(namespace, "", "resourcequotas", "", "list")
+	if operator.Namespace != "" && operator.Deployment != "" {
+		add(operator.Namespace, "apps", "deployments", "", "get")
+		add(operator.Namespace, "", "pods", "", "list")
+		add(operator.Namespace, "", "pods", "log", "get")
+	}
+	return checks
+}
+
+// checkPermissions asks the API server, with a SelfSubjectAccessReview each,
+// whether the bot may perform the checks. An error is only returned when none
+// of them could be checked; the others carry their error as the reason.
+func checkPermissions(ctx context.Context, kube kubernetes.Interface, checks []permissionCheck) ([]permissionResult, error) {
+	results := make([]permissionResult, 0, len(checks))
+	var errs []error
+	for _, check := range checks {
+		review, err := kube.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   check.Namespace,
+					Verb:        check.Verb,
+					Group:       check.Group,
+					Resource:    check.Resource,
+					Subresource: check.Subresource,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("checking %s %s in %s: %w", check.Verb, check, check.Namespace, err))
+			results = append(results, permissionResult{permissionCheck: check, Reason: err.Error()})
+			continue
+		}
+		reason := review.Status.Reason
+		if review.Status.EvaluationError != "" {
+			reason = strings.TrimSpace(reason + " " + review.Status.EvaluationError)
+		}
+		results = append(results, permissionResult{permissionCheck: check, Allowed: review.Status.Allowed, Reason: reason})
+	}
+	if len(errs) == len(checks) && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
+
+// HandlePermissions is the entry point for the admin "permissions" Slack
+// command. `permissions [namespace]` reports, as a checklist, which of the
+// Kubernetes permissions the bot needs it holds in the namespace (by default
+// the one launches in the channel use) and in the MAPT operator's namespace,
+// so that RBAC can be verified before users run into errors.
+func HandlePermissions(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	var namespace string
+	switch len(args) {
+	case 0:
+		namespace = cfg.DefaultsFor(event.Channel, "").Namespace
+	case 1:
+		namespace = args[0]
+		if len(validation.IsDNS1123Label(namespace)) > 0 {
+			return invalid(cfg.Messages.PermissionsUsage)
+		}
+	default:
+		return invalid(cfg.Messages.PermissionsUsage)
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+	results, err := checkPermissions(ctx, client.KubeClient, requiredPermissions(cfg.MaptOperator, namespace))
+	if err != nil {
+		return failure(err, messages.Render(cfg.Messages.PermissionsFailed, messages.Data{"Namespace": namespace}),
+			"reviewing the permissions of the bot in namespace %s", namespace)
+	}
+
+	var entries strings.Builder
+	missing := 0
+	for _, result := range results {
+		if !result.Allowed {
+			missing++
+		}
+		entries.WriteString(messages.Render(cfg.Messages.PermissionsEntry, messages.Data{
+			"Allowed":   result.Allowed,
+			"Verb":      result.Verb,
+			"Resource":  result.String(),
+			"Namespace": result.Namespace,
+			"Reason":    result.Reason,
+		}))
+	}
+	log.Printf("Reviewed the permissions of the bot in namespace %s for %s: %d of %d missing", namespace, event.User, missing, len(results))
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.Permissions, messages.Data{
+		"Namespace": namespace,
+		"Entries":   entries.String(),
+		"Missing":   missing,
+		"Total":     len(results),
+	}))
+	return nil
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// reviewAccess makes the fake access reviews allow everything but the
+// denied "verb resource" pairs, which are denied with reason.
+func reviewAccess(kube *slacktest.Kube, reason string, denied ...string) {
+	kube.KubeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = true
+		for _, d := range denied {
+			if d == attrs.Verb+" "+attrs.Resource {
+				review.Status = authorizationv1.SubjectAccessReviewStatus{Denied: true, Reason: reason}
+			}
+		}
+		return true, review, nil
+	})
+}
+
+func TestCheckPermissions(t *testing.T) {
+	kube := slacktest.NewKube()
+	reviewAccess(kube, "RBAC: no role binding", "delete kinds")
+	checks := []permissionCheck{
+		{Namespace: "team-a", Verb: "get", Group: "mapt.redhat.com", Resource: "kinds"},
+		{Namespace: "team-a", Verb: "delete", Group: "mapt.redhat.com", Resource: "kinds"},
+	}
+
+	results, err := checkPermissions(context.Background(), kube.KubeClient, checks)
+	if err != nil {
+		t.Fatalf("checkPermissions: %v", err)
+	}
+	if len(results) != 2 || !results[0].Allowed || results[1].Allowed {
+		t.Fatalf("results %+v, want get allowed and delete denied", results)
+	}
+	if results[1].Reason != "RBAC: no role binding" {
+		t.Errorf("denied reason %q, want the review's", results[1].Reason)
+	}
+}
+
+func TestCheckPermissionsFailsWhenNothingIsReviewed(t *testing.T) {
+	kube := slacktest.NewKube()
+	kube.KubeClient.PrependReactor("create", "selfsubjectaccessreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	_, err := checkPermissions(context.Background(), kube.KubeClient, []permissionCheck{{Namespace: "team-a", Verb: "get", Resource: "configmaps"}})
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("checkPermissions() error = %v, want the review error", err)
+	}
+}
+
+func TestPermissionsRendersChecklist(t *testing.T) {
+	cfg := testConfig()
+	useConfig(t, cfg)
+	kube := slacktest.NewKube()
+	reviewAccess(kube, "RBAC: no role binding", "delete kinds", "list resourcequotas")
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandlePermissions(context.Background(), api, message("UADMIN", "C1", "permissions team-a"), []string{"team-a"}); err != nil {
+		t.Fatalf("HandlePermissions: %v", err)
+	}
+
+	total := len(requiredPermissions(cfg.MaptOperator, "team-a"))
+	text := server.WaitForMessage("Kubernetes permissions of the bot", time.Second).Text()
+	for _, want := range []string{
+		"(namespace `team-a`): " + fmt.Sprintf("2 of %d missing", total),
+		"✅ `get` kinds.mapt.redhat.com in `team-a`\n",
+		"❌ `delete` kinds.mapt.redhat.com in `team-a` — RBAC: no role binding\n",
+		"❌ `list` resourcequotas in `team-a` — RBAC: no role binding\n",
+		"✅ `create` configmaps in `team-a`\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("checklist does not contain %q:\n%s", want, text)
+		}
+	}
+	if got := strings.Count(text, "✅") + strings.Count(text, "❌"); got != total {
+		t.Errorf("checklist has %d entries, want %d", got, total)
+	}
+}
+
+func TestPermissionsAllGranted(t *testing.T) {
+	cfg := testConfig()
+	useConfig(t, cfg)
+	kube := slacktest.NewKube()
+	reviewAccess(kube, "")
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandlePermissions(context.Background(), api, message("UADMIN", "C1", "permissions"), nil); err != nil {
+		t.Fatalf("HandlePermissions: %v", err)
+	}
+	total := len(requiredPermissions(cfg.MaptOperator, "default"))
+	server.WaitForMessage(fmt.Sprintf("all %d granted", total), time.Second)
+}
+
+func TestPermissionsRejectsInvalidNamespace(t *testing.T) {
+	useConfig(t, testConfig())
+	api, _ := newAPI(t)
+
+	err := HandlePermissions(context.Background(), api, message("UADMIN", "C1", "permissions Not_A_Namespace"), []string{"Not_A_Namespace"})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Usage") {
+		t.Errorf("HandlePermissions() = %v, want the usage", err)
+	}
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"log"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// annotationNoReap marks a cluster that `cleanup` must never select.
+const annotationNoReap = "spoticus.io/no-reap"
+
+// Pinned reports whether the cluster is protected from cleanup.
+func (c ClusterInfo) Pinned() bool {
+	return c.Annotations[annotationNoReap] == "true"
+}
+
+// HandlePin is the entry point for the "pin" Slack command.
+// It protects a cluster from cleanup.
+func HandlePin(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	return setPinned(ctx, api, event, args, true)
+}
+
+// HandleUnpin is the entry point for the "unpin" Slack command.
+// It lets cleanup select a pinned cluster again.
+func HandleUnpin(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	return setPinned(ctx, api, event, args, false)
+}
+
+// setPinned pins or unpins the cluster named in args. Only the cluster's
+// owner and admins may change it.
+func setPinned(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string, pinned bool) error {
+	cfg := spoticusConfig.Get()
+	if len(args) < 1 {
+		usage := cfg.Messages.UnpinUsage
+		if pinned {
+			usage = cfg.Messages.PinUsage
+		}
+		return invalid(usage)
+	}
+	name := args[0]
+	tracing.SetCluster(ctx, name)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	cluster, err := findCluster(ctx, client, name)
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+	owner := cluster.Metadata().Owner
+	if owner != event.User && !cfg.IsAdmin(event.User) {
+		return denied(messages.Render(cfg.Messages.PinDenied, messages.Data{
+			"Name":  name,
+			"Owner": owner,
+		}), "denied pinning of cluster %s (owner %s) to user %s", name, owner, event.User)
+	}
+
+	if err := patchNoReap(ctx, client, cluster, pinned); err != nil {
+		return failure(err, cfg.Messages.PinFailed, "updating %s on MAPT cluster %s/%s", annotationNoReap, cluster.Namespace, cluster.Name)
+	}
+
+	log.Printf("Cluster %s/%s pinned=%t by %s", cluster.Namespace, cluster.Name, pinned, event.User)
+	confirmation := cfg.Messages.Unpinned
+	if pinned {
+		confirmation = cfg.Messages.Pinned
+	}
+	respond.Text(api, event.Channel, messages.Render(confirmation, messages.Data{"Name": name}))
+	return nil
+}
+
+// patchNoReap sets or removes the no-reap annotation of the cluster.
+func patchNoReap(ctx context.Context, client *KubernetesClients, cluster ClusterInfo, pinned bool) error {
+	value := ""
+	if pinned {
+		value = "true"
+	}
+	return patchAnnotation(ctx, client, cluster, annotationNoReap, value)
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestPinnedClusterSkippedByCleanup(t *testing.T) {
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	cfg.Cleanup = spoticusConfig.CleanupCriteria{Orphaned: true}
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-keep", "", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandlePin(context.Background(), api, message("UADMIN", "C1", "pin spoticus-k8s-keep"), []string{"spoticus-k8s-keep"}); err != nil {
+		t.Fatalf("HandlePin: %v", err)
+	}
+	server.WaitForMessage("Pinned *spoticus-k8s-keep*", time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 || kinds[0].Annotations[annotationNoReap] != "true" {
+		t.Fatalf("clusters %v, want the cluster annotated %s", kinds, annotationNoReap)
+	}
+
+	if err := HandleCleanup(context.Background(), api, message("UADMIN", "C1", "cleanup"), nil); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	server.WaitForMessage("No clusters match the cleanup criteria", time.Second)
+
+	if err := HandleUnpin(context.Background(), api, message("UADMIN", "C1", "unpin spoticus-k8s-keep"), []string{"spoticus-k8s-keep"}); err != nil {
+		t.Fatalf("HandleUnpin: %v", err)
+	}
+	server.WaitForMessage("Unpinned *spoticus-k8s-keep*", time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(kinds))
+	} else if _, ok := kinds[0].Annotations[annotationNoReap]; ok {
+		t.Errorf("annotations %v, want %s removed", kinds[0].Annotations, annotationNoReap)
+	}
+
+	if err := HandleCleanup(context.Background(), api, message("UADMIN", "C1", "cleanup"), nil); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	server.WaitForMessage("1 cluster would be deleted", time.Second)
+}
+
+func TestPinRestrictedToOwnerAndAdmins(t *testing.T) {
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-mine", "UOWNER", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	err := HandlePin(context.Background(), api, message("UOTHER", "C1", "pin spoticus-k8s-mine"), []string{"spoticus-k8s-mine"})
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryAuth || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "<@UOWNER>") {
+		t.Errorf("HandlePin() by another user = %v, want access denied naming the owner", err)
+	}
+	if kinds := launchedKinds(t, kube); kinds[0].Annotations[annotationNoReap] != "" {
+		t.Errorf("cluster pinned by a user who is neither its owner nor an admin")
+	}
+
+	for _, user := range []string{"UOWNER", "UADMIN"} {
+		if err := HandlePin(context.Background(), api, message(user, "C1", "pin spoticus-k8s-mine"), []string{"spoticus-k8s-mine"}); err != nil {
+			t.Errorf("HandlePin() by %s: %v", user, err)
+		}
+	}
+	if got := len(server.Messages()); got != 2 {
+		t.Errorf("posted %d confirmations, want one per allowed pin", got)
+	}
+}
+
+func TestPinnedShownInListAndStatus(t *testing.T) {
+	useConfig(t, testConfig())
+	pinned := ClusterInfo{Name: "spoticus-k8s-pinned", Type: "k8s", Created: time.Now(), Annotations: map[string]string{annotationNoReap: "true"}}
+	unpinned := ClusterInfo{Name: "spoticus-k8s-free", Type: "k8s", Created: time.Now()}
+
+	list, _ := formatClusterList(clusterInventory{Clusters: []ClusterInfo{pinned, unpinned}}, false, 0, 10)
+	pinnedLine, _, _ := strings.Cut(list[strings.Index(list, "*spoticus-k8s-pinned*"):], "\n")
+	freeLine, _, _ := strings.Cut(list[strings.Index(list, "*spoticus-k8s-free*"):], "\n")
+	if !strings.HasSuffix(pinnedLine, "📌") || strings.Contains(freeLine, "📌") {
+		t.Errorf("list %q, want only the pinned cluster marked", list)
+	}
+
+	if text := formatClusterStatus(testConfig(), pinned); !strings.Contains(text, "Pinned: never selected by `cleanup`") {
+		t.Errorf("status %q does not show the cluster pinned", text)
+	}
+	if text := formatClusterStatus(testConfig(), unpinned); strings.Contains(text, "Pinned") {
+		t.Errorf("status %q shows an unpinned cluster pinned", text)
+	}
+}
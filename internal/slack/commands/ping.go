@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"context"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/kube"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// HandlePing replies to the "ping" Slack command with the Kubernetes context in use.
+func HandlePing(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.Pong, messages.Data{
+		"Context": kube.ActiveContext(cfg.KubeContext),
+	}))
+	return nil
+}
@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPingReportsKubeContext(t *testing.T) {
+	cfg := testConfig()
+	cfg.KubeContext = "staging"
+	useConfig(t, cfg)
+	api, server := newAPI(t)
+
+	if err := HandlePing(context.Background(), api, message("U1", "C1", "ping"), nil); err != nil {
+		t.Fatalf("HandlePing: %v", err)
+	}
+	server.WaitForMessage("staging", time.Second)
+}
@@ -0,0 +1,259 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// presetsConfigMapName is the ConfigMap persisting the users' launch presets.
+// Each entry is keyed by "<user ID>.<preset name>" and holds the launch
+// arguments as a JSON array.
+const presetsConfigMapName = "spoticus-presets"
+
+// presetFlag is the launch flag expanding a preset.
+const presetFlag = "--preset="
+
+// presetNamePattern is what preset names must match, so that they are valid
+// ConfigMap keys and easy to type.
+var presetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// HandlePreset is the entry point for the "preset" Slack command.
+//
+// `preset save <name> <launch arguments>` stores launch arguments under a
+// name for the requesting user, to be reused with `launch --preset=<name>`;
+// `preset list` shows the user's presets and `preset delete <name>` removes
+// one. The arguments are validated like a launch when saved.
+func HandlePreset(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	templates := msgs()
+	if len(args) == 0 {
+		return invalid(templates.PresetUsage)
+	}
+
+	action := strings.ToLower(args[0])
+	switch {
+	case action == "list" && len(args) == 1:
+	case action == "save" && len(args) >= 3:
+	case action == "delete" && len(args) == 2:
+	default:
+		return invalid(templates.PresetUsage)
+	}
+
+	var name string
+	if action != "list" {
+		name = strings.ToLower(args[1])
+		if !presetNamePattern.MatchString(name) {
+			return invalid(messages.Render(templates.PresetInvalidName, messages.Data{"Name": args[1]}))
+		}
+	}
+
+	var launchArgs []string
+	if action == "save" {
+		var err error
+		launchArgs, err = normalizeLaunchArgs(args[2:])
+		if err != nil {
+			return invalid(err.Error())
+		}
+		if err := validatePresetArgs(launchArgs, event.Channel); err != nil {
+			return invalid(err.Error())
+		}
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	switch action {
+	case "list":
+		presets, err := loadPresets(ctx, client, event.User)
+		if err != nil {
+			return failure(err, templates.PresetFailed, "reading launch presets")
+		}
+		respond.Text(api, event.Channel, formatPresets(presets))
+	case "save":
+		if err := storePreset(ctx, client, event.User, name, launchArgs); err != nil {
+			return failure(err, templates.PresetFailed, "saving launch preset %s of %s", name, event.User)
+		}
+		log.Printf("User %s saved launch preset %s: %v", event.User, name, launchArgs)
+		respond.Text(api, event.Channel, messages.Render(templates.PresetSaved, messages.Data{
+			"Name": name,
+			"Args": strings.Join(launchArgs, " "),
+		}))
+	case "delete":
+		err := storePreset(ctx, client, event.User, name, nil)
+		switch {
+		case errors.Is(err, errPresetNotFound):
+			return invalid(messages.Render(templates.PresetNotFound, messages.Data{"Name": name}))
+		case err != nil:
+			return failure(err, templates.PresetFailed, "deleting launch preset %s of %s", name, event.User)
+		default:
+			log.Printf("User %s deleted launch preset %s", event.User, name)
+			respond.Text(api, event.Channel, messages.Render(templates.PresetDeleted, messages.Data{"Name": name}))
+		}
+	}
+	return nil
+}
+
+// validatePresetArgs checks that args make a valid launch on their own.
+// Presets may not schedule the launch or expand other presets.
+func validatePresetArgs(args []string, channel string) error {
+	for _, arg := range args {
+		if strings.HasPrefix(strings.ToLower(arg), presetFlag) {
+			return errors.New(messages.Render(msgs().UnrecognizedArgument, messages.Data{"Arg": arg}))
+		}
+	}
+	_, err := parseLaunchArgs(args, channel)
+	return err
+}
+
+// errPresetNotFound is returned when deleting a preset that does not exist.
+var errPresetNotFound = errors.New("preset not found")
+
+// presetKey is the ConfigMap key of a user's preset.
+func presetKey(user, name string) string {
+	return user + "." + name
+}
+
+// loadPresets returns the presets of user, keyed by name.
+func loadPresets(ctx context.Context, client *KubernetesClients, user string) (map[string][]string, error) {
+	cm := &corev1.ConfigMap{}
+	key := crclient.ObjectKey{Namespace: spoticusConfig.Get().Namespace, Name: presetsConfigMapName}
+	if err := client.CrClient.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	presets := map[string][]string{}
+	for k, value := range cm.Data {
+		name, ok := strings.CutPrefix(k, user+".")
+		if !ok {
+			continue
+		}
+		var args []string
+		if err := json.Unmarshal([]byte(value), &args); err != nil {
+			log.Printf("Ignoring malformed launch preset %s: %v", k, err)
+			continue
+		}
+		presets[name] = args
+	}
+	return presets, nil
+}
+
+// storePreset saves args as the user's preset name, or deletes the preset
+// when args is nil.
+func storePreset(ctx context.Context, client *KubernetesClients, user, name string, args []string) error {
+	var value string
+	if args != nil {
+		data, err := json.Marshal(args)
+		if err != nil {
+			return err
+		}
+		value = string(data)
+	}
+
+	cm := &corev1.ConfigMap{}
+	key := crclient.ObjectKey{Namespace: spoticusConfig.Get().Namespace, Name: presetsConfigMapName}
+	err := client.CrClient.Get(ctx, key, cm)
+	switch {
+	case apierrors.IsNotFound(err) && args == nil:
+		return errPresetNotFound
+	case apierrors.IsNotFound(err):
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Data:       map[string]string{presetKey(user, name): value},
+		}
+		return client.CrClient.Create(ctx, cm)
+	case err != nil:
+		return err
+	}
+
+	if args == nil {
+		if _, ok := cm.Data[presetKey(user, name)]; !ok {
+			return errPresetNotFound
+		}
+		delete(cm.Data, presetKey(user, name))
+	} else {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[presetKey(user, name)] = value
+	}
+	return client.CrClient.Update(ctx, cm)
+}
+
+// formatPresets renders a user's presets, sorted by name.
+func formatPresets(presets map[string][]string) string {
+	templates := msgs()
+	if len(presets) == 0 {
+		return templates.PresetNone
+	}
+	var entries strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(presets)) {
+		entries.WriteString(messages.Render(templates.PresetEntry, messages.Data{
+			"Name": name,
+			"Args": strings.Join(presets[name], " "),
+		}))
+	}
+	return messages.Render(templates.PresetList, messages.Data{
+		"Count":   len(presets),
+		"Entries": entries.String(),
+	})
+}
+
+// expandPreset replaces a `--preset=<name>` flag in the launch arguments with
+// the arguments saved in the user's preset. The preset's arguments come
+// first, so that the others add to them. The returned error is suitable to be
+// shown to the user as-is.
+func expandPreset(ctx context.Context, user string, args []string) ([]string, error) {
+	index := slices.IndexFunc(args, func(arg string) bool {
+		return strings.HasPrefix(strings.ToLower(arg), presetFlag)
+	})
+	if index < 0 {
+		return args, nil
+	}
+	name := strings.ToLower(args[index][len(presetFlag):])
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		log.Printf("Error getting kubernetes client: %v", err)
+		return nil, errors.New(backendError(err, msgs().ConnectFailed))
+	}
+	presets, err := loadPresets(ctx, client, user)
+	if err != nil {
+		log.Printf("Error reading launch presets: %v", err)
+		return nil, errors.New(backendError(err, msgs().PresetFailed))
+	}
+	preset, ok := presets[name]
+	if !ok {
+		return nil, errors.New(messages.Render(msgs().PresetNotFound, messages.Data{"Name": name}))
+	}
+
+	expanded := slices.Clone(preset)
+	expanded = append(expanded, args[:index]...)
+	expanded = append(expanded, args[index+1:]...)
+	if slices.ContainsFunc(expanded, func(arg string) bool {
+		return strings.HasPrefix(strings.ToLower(arg), presetFlag)
+	}) {
+		return nil, errors.New(msgs().PresetMultiple)
+	}
+	return expanded, nil
+}
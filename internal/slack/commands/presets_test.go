@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestPresetSaveListDelete(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube())
+	api, server := newAPI(t)
+	run := func(user string, args ...string) error {
+		return HandlePreset(context.Background(), api, message(user, "C1", "preset "+strings.Join(args, " ")), args)
+	}
+
+	if err := run("U1", "list"); err != nil {
+		t.Fatalf("preset list: %v", err)
+	}
+	server.WaitForMessage("You have no presets", time.Second)
+
+	for _, args := range [][]string{{"save", "dev", "k8s", "large"}, {"save", "CI", "k8s", "medium", "--ref=CI-1"}} {
+		if err := run("U1", args...); err != nil {
+			t.Fatalf("preset %v: %v", args, err)
+		}
+	}
+	server.WaitForMessage("Saved preset *dev*: `k8s large`", time.Second)
+	server.WaitForMessage("Saved preset *ci*: `k8s medium --ref=CI-1`", time.Second)
+	if err := run("U2", "save", "dev", "k8s", "xlarge"); err != nil {
+		t.Fatalf("preset save of another user: %v", err)
+	}
+
+	if err := run("U1", "list"); err != nil {
+		t.Fatalf("preset list: %v", err)
+	}
+	text := server.WaitForMessage("Your presets", time.Second).Text()
+	if want := "💾 *Your presets* (2)\n\n• *ci* — `k8s medium --ref=CI-1`\n• *dev* — `k8s large`\n"; text != want {
+		t.Errorf("preset list = %q, want %q", text, want)
+	}
+
+	if err := run("U1", "delete", "dev"); err != nil {
+		t.Fatalf("preset delete: %v", err)
+	}
+	server.WaitForMessage("Deleted preset *dev*", time.Second)
+	err := run("U1", "delete", "dev")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "no preset named *dev*") {
+		t.Errorf("deleting a deleted preset = %v, want not found", err)
+	}
+
+	client, _ := GetKubernetesClient()
+	presets, err := loadPresets(context.Background(), client, "U2")
+	if err != nil || !slices.Equal(presets["dev"], []string{"k8s", "xlarge"}) {
+		t.Errorf("presets of U2 = %v, %v, want its own dev preset kept", presets, err)
+	}
+}
+
+func TestPresetSaveValidates(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube())
+	api, _ := newAPI(t)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "invalid name", args: []string{"save", "my_preset", "k8s", "large"}, wantErr: "Invalid preset name *my_preset*"},
+		{name: "invalid launch", args: []string{"save", "dev", "k8s", "huge"}, wantErr: "huge"},
+		{name: "nested preset", args: []string{"save", "dev", "--preset=other"}, wantErr: "Unrecognized argument: `--preset=other`"},
+		{name: "missing arguments", args: []string{"save", "dev"}, wantErr: "Usage: `preset save"},
+		{name: "unknown action", args: []string{"rename", "dev"}, wantErr: "Usage: `preset save"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := HandlePreset(context.Background(), api, message("U1", "C1", "preset "+strings.Join(tt.args, " ")), tt.args)
+			var cmdErr *CommandError
+			if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, tt.wantErr) {
+				t.Errorf("HandlePreset() = %v, want a validation error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpandPreset(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube())
+	client, _ := GetKubernetesClient()
+	if err := storePreset(context.Background(), client, "U1", "dev", []string{"k8s", "large"}); err != nil {
+		t.Fatalf("storePreset: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "no preset", args: []string{"k8s", "medium"}, want: []string{"k8s", "medium"}},
+		{name: "preset first", args: []string{"--ttl=2h", "--PRESET=Dev"}, want: []string{"k8s", "large", "--ttl=2h"}},
+		{name: "unknown preset", args: []string{"--preset=prod"}, wantErr: "no preset named *prod*"},
+		{name: "two presets", args: []string{"--preset=dev", "--preset=dev"}, wantErr: "Only one `--preset`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPreset(context.Background(), "U1", tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expandPreset() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !slices.Equal(got, tt.want) {
+				t.Errorf("expandPreset() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLaunchWithPreset(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+	if err := HandlePreset(context.Background(), api, message("U20", "C1", "preset save dev k8s large"), []string{"save", "dev", "k8s", "large"}); err != nil {
+		t.Fatalf("preset save: %v", err)
+	}
+
+	if err := HandleLaunch(context.Background(), api, message("U20", "C1", "launch --preset=dev"), []string{"--preset=dev"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 || ParseLaunchMetadata(kinds[0].Annotations).Size != "large" {
+		t.Errorf("clusters %v, want one large cluster from the preset", kinds)
+	}
+}
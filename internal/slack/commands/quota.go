@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+var (
+	quotaOverridesMu sync.RWMutex
+	// quotaOverrides holds per-user limits set at runtime with `quota @user n`.
+	// They take precedence over the configured limits until the bot restarts.
+	quotaOverrides = map[string]int{}
+)
+
+// userMentionPattern matches a Slack user mention such as <@U123> or <@U123|name>.
+var userMentionPattern = regexp.MustCompile(`^<@([A-Z0-9]+)(?:\|[^>]*)?>$`)
+
+// quotaLimit returns the maximum number of clusters the user may own at once.
+// Zero means unlimited.
+func quotaLimit(user string) int {
+	quotaOverridesMu.RLock()
+	limit, ok := quotaOverrides[user]
+	quotaOverridesMu.RUnlock()
+	if ok {
+		return limit
+	}
+
+	cfg := spoticusConfig.Get().Quota
+	if limit, ok := cfg.Users[user]; ok {
+		return limit
+	}
+	return cfg.Default
+}
+
+// setQuotaOverride sets a runtime per-user limit.
+func setQuotaOverride(user string, limit int) {
+	quotaOverridesMu.Lock()
+	defer quotaOverridesMu.Unlock()
+	quotaOverrides[user] = limit
+}
+
+// quotaUsage counts the clusters owned by the user.
+func quotaUsage(inventory clusterInventory, user string) int {
+	usage := 0
+	for _, cluster := range inventory.Clusters {
+		if cluster.Metadata().Owner == user {
+			usage++
+		}
+	}
+	return usage
+}
+
+// formatLimit renders a quota limit, treating zero as unlimited.
+func formatLimit(limit int) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+	return strconv.Itoa(limit)
+}
+
+// HandleQuota is the entry point for the "quota" Slack command.
+//
+//	quota              — show your own limit and usage
+//	quota @user        — show another user's limit and usage (admin only)
+//	quota @user <n>    — set a per-user limit, 0 for unlimited (admin only)
+func HandleQuota(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	user := event.User
+
+	if len(args) > 0 {
+		match := userMentionPattern.FindStringSubmatch(args[0])
+		if match == nil {
+			return invalid(cfg.Messages.QuotaUsage)
+		}
+		user = match[1]
+	}
+
+	if (user != event.User || len(args) > 1) && !cfg.IsAdmin(event.User) {
+		return denied(cfg.Messages.QuotaDenied, "denied the quota of another user to user %s", event.User)
+	}
+
+	if len(args) > 1 {
+		limit, err := strconv.Atoi(args[1])
+		if err != nil || limit < 0 {
+			return invalid(cfg.Messages.QuotaUsage)
+		}
+		setQuotaOverride(user, limit)
+		log.Printf("Quota for %s set to %d by %s", user, limit, event.User)
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.QuotaUpdated, messages.Data{
+			"User":  user,
+			"Limit": formatLimit(limit),
+		}))
+		return nil
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	inventory, err := listClusters(ctx, client)
+	if err != nil {
+		return failure(err, cfg.Messages.ListFailed, "listing MAPT clusters for quota")
+	}
+
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.QuotaStatus, messages.Data{
+		"User":  user,
+		"Usage": quotaUsage(inventory, user),
+		"Limit": formatLimit(quotaLimit(user)),
+	}))
+	return nil
+}
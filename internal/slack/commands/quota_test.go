@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// useQuotaOverrides starts the test without runtime quota overrides, and
+// restores them when it ends.
+func useQuotaOverrides(t *testing.T) {
+	t.Helper()
+	quotaOverridesMu.Lock()
+	previous := quotaOverrides
+	quotaOverrides = map[string]int{}
+	quotaOverridesMu.Unlock()
+	t.Cleanup(func() {
+		quotaOverridesMu.Lock()
+		quotaOverrides = previous
+		quotaOverridesMu.Unlock()
+	})
+}
+
+func TestQuotaLimit(t *testing.T) {
+	useQuotaOverrides(t)
+	cfg := testConfig()
+	cfg.Quota.Default = 2
+	cfg.Quota.Users = map[string]int{"UBIG": 5}
+	useConfig(t, cfg)
+
+	if got := quotaLimit("USOME"); got != 2 {
+		t.Errorf("default limit = %d, want 2", got)
+	}
+	if got := quotaLimit("UBIG"); got != 5 {
+		t.Errorf("configured limit = %d, want 5", got)
+	}
+	setQuotaOverride("UBIG", 0)
+	if got := quotaLimit("UBIG"); got != 0 {
+		t.Errorf("overridden limit = %d, want 0 (unlimited)", got)
+	}
+}
+
+func TestQuotaShowsOwnUsage(t *testing.T) {
+	useQuotaOverrides(t)
+	cfg := testConfig()
+	cfg.Quota.Default = 3
+	useConfig(t, cfg)
+	useKube(t, slacktest.NewKube(
+		existingCluster("spoticus-k8s-a", "U1", 0),
+		existingCluster("spoticus-k8s-b", "U1", 0),
+		existingCluster("spoticus-k8s-c", "U2", 0),
+	))
+	api, server := newAPI(t)
+
+	if err := HandleQuota(context.Background(), api, message("U1", "C1", "quota"), nil); err != nil {
+		t.Fatalf("HandleQuota: %v", err)
+	}
+	server.WaitForMessage("Quota for <@U1>: 2 of 3 clusters in use.", time.Second)
+}
+
+func TestQuotaAdminViewsAndSetsOtherUsers(t *testing.T) {
+	useQuotaOverrides(t)
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-a", "U1", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleQuota(context.Background(), api, message("UADMIN", "C1", "quota <@U1> 4"), []string{"<@U1|alice>", "4"}); err != nil {
+		t.Fatalf("setting the quota: %v", err)
+	}
+	server.WaitForMessage("Quota for <@U1> set to 4 clusters", time.Second)
+	if got := quotaLimit("U1"); got != 4 {
+		t.Errorf("limit after set = %d, want 4", got)
+	}
+
+	if err := HandleQuota(context.Background(), api, message("UADMIN", "C1", "quota <@U1>"), []string{"<@U1>"}); err != nil {
+		t.Fatalf("viewing the quota: %v", err)
+	}
+	server.WaitForMessage("Quota for <@U1>: 1 of 4 clusters in use.", time.Second)
+}
+
+func TestQuotaPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "view another user", args: []string{"<@U2>"}, wantErr: "Only bot administrators"},
+		{name: "set another user", args: []string{"<@U2>", "9"}, wantErr: "Only bot administrators"},
+		{name: "set own quota", args: []string{"<@U1>", "9"}, wantErr: "Only bot administrators"},
+		{name: "not a mention", args: []string{"U2"}, wantErr: "Usage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useQuotaOverrides(t)
+			useConfig(t, testConfig())
+			api, _ := newAPI(t)
+
+			err := HandleQuota(context.Background(), api, message("U1", "C1", "quota "+strings.Join(tt.args, " ")), tt.args)
+			var cmdErr *CommandError
+			if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, tt.wantErr) {
+				t.Errorf("HandleQuota() = %v, want %q", err, tt.wantErr)
+			}
+			quotaOverridesMu.RLock()
+			defer quotaOverridesMu.RUnlock()
+			if len(quotaOverrides) != 0 {
+				t.Errorf("quota overrides %v, want none set", quotaOverrides)
+			}
+		})
+	}
+}
+
+func TestQuotaRejectsInvalidLimit(t *testing.T) {
+	useQuotaOverrides(t)
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	api, _ := newAPI(t)
+
+	for _, limit := range []string{"-1", "many"} {
+		err := HandleQuota(context.Background(), api, message("UADMIN", "C1", "quota <@U1> "+limit), []string{"<@U1>", limit})
+		if CategoryOf(err) != CategoryValidation {
+			t.Errorf("quota <@U1> %s = %v, want the usage", limit, err)
+		}
+	}
+}
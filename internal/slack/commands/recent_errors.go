@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+const (
+	// recentErrorsSize bounds how many errors are kept for the `errors` command.
+	recentErrorsSize = 50
+
+	// defaultErrorEntries is how many errors `errors` prints when no count is given.
+	defaultErrorEntries = 10
+)
+
+// RecentError is a failure reported to a user, kept for the `errors` command.
+type RecentError struct {
+	Time time.Time
+
+	// Message is what the user was shown.
+	Message string
+
+	// Error is the underlying error, with credentials redacted.
+	Error string
+}
+
+var (
+	recentErrorsMu sync.Mutex
+	// recentErrors holds the latest errors, oldest first.
+	recentErrors []RecentError
+)
+
+// RecordError keeps err, and the message the user was shown for it, in the
+// bounded list of recent errors. The oldest error is dropped once the list is
+// full. Nil errors are ignored.
+func RecordError(message string, err error) {
+	if err == nil {
+		return
+	}
+	entry := RecentError{
+		Time:    time.Now(),
+		Message: spoticusConfig.Redact(message),
+		Error:   spoticusConfig.Redact(err.Error()),
+	}
+
+	recentErrorsMu.Lock()
+	defer recentErrorsMu.Unlock()
+	recentErrors = append(recentErrors, entry)
+	if len(recentErrors) > recentErrorsSize {
+		recentErrors = recentErrors[len(recentErrors)-recentErrorsSize:]
+	}
+}
+
+// lastErrors returns up to n of the most recent errors, newest first.
+func lastErrors(n int) []RecentError {
+	recentErrorsMu.Lock()
+	defer recentErrorsMu.Unlock()
+
+	n = min(n, len(recentErrors))
+	result := make([]RecentError, 0, n)
+	for i := len(recentErrors) - 1; i >= len(recentErrors)-n; i-- {
+		result = append(result, recentErrors[i])
+	}
+	return result
+}
+
+// HandleErrors is the entry point for the admin "errors" Slack command.
+// It prints the last N errors reported to users, newest first.
+func HandleErrors(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	n := defaultErrorEntries
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed <= 0 {
+			return invalid(messages.Render(msgs().ErrorsInvalidCount, messages.Data{"Count": args[0]}))
+		}
+		n = parsed
+	}
+
+	respond.Text(api, event.Channel, formatRecentErrors(lastErrors(n)))
+	return nil
+}
+
+// formatRecentErrors renders recent errors as a Slack-friendly list.
+func formatRecentErrors(entries []RecentError) string {
+	templates := msgs()
+	if len(entries) == 0 {
+		return templates.ErrorsEmpty
+	}
+
+	var b strings.Builder
+	b.WriteString(messages.Render(templates.ErrorsHeader, messages.Data{"Count": len(entries)}))
+	for _, e := range entries {
+		b.WriteString(messages.Render(templates.ErrorsEntry, messages.Data{
+			"Time":    e.Time.Format("2006-01-02 15:04:05"),
+			"Message": firstLine(e.Message),
+			"Error":   e.Error,
+		}))
+	}
+	return b.String()
+}
+
+// firstLine returns s up to its first line break.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useRecentErrors starts the test with no recorded errors, and restores the
+// recorded ones when it ends.
+func useRecentErrors(t *testing.T) {
+	t.Helper()
+	recentErrorsMu.Lock()
+	previous := recentErrors
+	recentErrors = nil
+	recentErrorsMu.Unlock()
+	t.Cleanup(func() {
+		recentErrorsMu.Lock()
+		recentErrors = previous
+		recentErrorsMu.Unlock()
+	})
+}
+
+func TestRecordErrorKeepsTheLatest(t *testing.T) {
+	useRecentErrors(t)
+
+	RecordError("ignored", nil)
+	for i := range recentErrorsSize + 5 {
+		RecordError(fmt.Sprintf("failure %d", i), fmt.Errorf("cause %d", i))
+	}
+
+	all := lastErrors(recentErrorsSize * 2)
+	if len(all) != recentErrorsSize {
+		t.Fatalf("got %d errors, want the buffer bounded to %d", len(all), recentErrorsSize)
+	}
+	if want := fmt.Sprintf("failure %d", recentErrorsSize+4); all[0].Message != want {
+		t.Errorf("newest error is %q, want %q", all[0].Message, want)
+	}
+	if last := all[len(all)-1].Message; last != "failure 5" {
+		t.Errorf("oldest error kept is %q, want the first 5 dropped", last)
+	}
+
+	two := lastErrors(2)
+	if len(two) != 2 || two[0].Error != fmt.Sprintf("cause %d", recentErrorsSize+4) || two[1].Error != fmt.Sprintf("cause %d", recentErrorsSize+3) {
+		t.Errorf("lastErrors(2) = %+v, want the two newest, newest first", two)
+	}
+}
+
+func TestRecordErrorRedactsCredentials(t *testing.T) {
+	useRecentErrors(t)
+
+	RecordError("posting to https://hooks.slack.com/services/T0/B0/secret failed",
+		errors.New("auth with xoxb-1234-abcd and Bearer eyJhbGciOi rejected"))
+
+	entry := lastErrors(1)[0]
+	for _, secret := range []string{"xoxb-1234-abcd", "eyJhbGciOi", "hooks.slack.com/services"} {
+		if strings.Contains(entry.Message, secret) || strings.Contains(entry.Error, secret) {
+			t.Errorf("recorded error %+v leaks %q", entry, secret)
+		}
+	}
+	if !strings.Contains(entry.Error, "[REDACTED]") || !strings.Contains(entry.Error, "rejected") {
+		t.Errorf("recorded error %q, want the credentials replaced and the rest kept", entry.Error)
+	}
+}
+
+func TestBackendErrorRecordsFailure(t *testing.T) {
+	useRecentErrors(t)
+
+	message := backendError(fmt.Errorf("listing: %w", ErrBackendUnavailable), "fallback")
+
+	entries := lastErrors(1)
+	if len(entries) != 1 || entries[0].Message != message || !strings.Contains(entries[0].Error, "listing") {
+		t.Errorf("recorded %+v, want the failure shown as %q", entries, message)
+	}
+}
+
+func TestHandleErrors(t *testing.T) {
+	useRecentErrors(t)
+	api, server := newAPI(t)
+
+	if err := HandleErrors(context.Background(), api, message("UADMIN", "C1", "errors"), nil); err != nil {
+		t.Fatalf("HandleErrors: %v", err)
+	}
+	server.WaitForMessage("No errors recorded", time.Second)
+
+	RecordError("❌ Failed to list clusters.\nTry again later.", errors.New("connection refused"))
+	RecordError("❌ Failed to launch.", errors.New("quota exceeded"))
+	if err := HandleErrors(context.Background(), api, message("UADMIN", "C1", "errors 1"), []string{"1"}); err != nil {
+		t.Fatalf("HandleErrors(1): %v", err)
+	}
+	text := server.WaitForMessage("(last 1)", time.Second).Text()
+	if !strings.Contains(text, "quota exceeded") || strings.Contains(text, "connection refused") {
+		t.Errorf("errors 1 printed %q, want only the newest error", text)
+	}
+
+	if err := HandleErrors(context.Background(), api, message("UADMIN", "C1", "errors"), nil); err != nil {
+		t.Fatalf("HandleErrors: %v", err)
+	}
+	text = server.WaitForMessage("(last 2)", time.Second).Text()
+	if strings.Contains(text, "Try again later") {
+		t.Errorf("errors printed %q, want only the first line of each message", text)
+	}
+
+	for _, count := range []string{"0", "-3", "many"} {
+		err := HandleErrors(context.Background(), api, message("UADMIN", "C1", "errors "+count), []string{count})
+		var cmdErr *CommandError
+		if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Invalid count") {
+			t.Errorf("HandleErrors(%q) = %v, want an invalid count", count, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+const (
+	// launchOutcomesSize bounds how many launch outcomes are kept for `reliability`.
+	launchOutcomesSize = 500
+
+	// defaultReliabilityWindow is the window `reliability` reports on when none is given.
+	defaultReliabilityWindow = 24 * time.Hour
+
+	// topFailureReasons is how many failure reasons `reliability` lists.
+	topFailureReasons = 3
+)
+
+// Failure reasons recorded for launches that did not become ready.
+const (
+	reasonProvisionFailed = "MAPT reported the cluster Failed"
+	reasonWatchTimeout    = "not ready within the provisioning timeout"
+)
+
+// launchOutcome is the result of a launch, kept for the `reliability` command.
+type launchOutcome struct {
+	// Time is when the outcome was known.
+	Time time.Time
+
+	// Ready is set for launches that became ready, after TimeToReady.
+	Ready       bool
+	TimeToReady time.Duration
+
+	// Reason explains why a launch that did not become ready failed.
+	Reason string
+}
+
+var (
+	launchOutcomesMu sync.Mutex
+	// launchOutcomes holds the latest outcomes, oldest first.
+	launchOutcomes []launchOutcome
+)
+
+// recordLaunchOutcome keeps the outcome in the bounded list of launch
+// outcomes, dropping the oldest once the list is full.
+func recordLaunchOutcome(outcome launchOutcome) {
+	launchOutcomesMu.Lock()
+	defer launchOutcomesMu.Unlock()
+	launchOutcomes = append(launchOutcomes, outcome)
+	if len(launchOutcomes) > launchOutcomesSize {
+		launchOutcomes = launchOutcomes[len(launchOutcomes)-launchOutcomesSize:]
+	}
+}
+
+// createFailureReason describes why the MAPT resource of a launch could not
+// be created, by the API server's reason when it gave one.
+func createFailureReason(err error) string {
+	if reason := apierrors.ReasonForError(err); reason != "" {
+		return "create rejected: " + string(reason)
+	}
+	return "create " + classifyError(err).String()
+}
+
+// reliabilityStats summarizes the launch outcomes of a window.
+type reliabilityStats struct {
+	Succeeded int
+	Failed    int
+
+	// AverageReady is the mean time to ready of the successful launches.
+	AverageReady time.Duration
+
+	// Reasons are the failure reasons, most frequent first, with their counts.
+	Reasons []reasonCount
+}
+
+// reasonCount is how many launches failed for a reason.
+type reasonCount struct {
+	Reason string
+	Count  int
+}
+
+// computeReliability summarizes the outcomes known since `since`.
+func computeReliability(outcomes []launchOutcome, since time.Time) reliabilityStats {
+	var stats reliabilityStats
+	var totalReady time.Duration
+	reasons := map[string]int{}
+	for _, outcome := range outcomes {
+		if outcome.Time.Before(since) {
+			continue
+		}
+		if outcome.Ready {
+			stats.Succeeded++
+			totalReady += outcome.TimeToReady
+			continue
+		}
+		stats.Failed++
+		reasons[outcome.Reason]++
+	}
+	if stats.Succeeded > 0 {
+		stats.AverageReady = totalReady / time.Duration(stats.Succeeded)
+	}
+	for reason, count := range reasons {
+		stats.Reasons = append(stats.Reasons, reasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(stats.Reasons, func(i, j int) bool {
+		a, b := stats.Reasons[i], stats.Reasons[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Reason < b.Reason
+	})
+	return stats
+}
+
+// parseWindow parses a `reliability` window: a Go duration such as "12h", or
+// a number of days such as "7d".
+func parseWindow(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q", value)
+	}
+	return d, nil
+}
+
+// HandleReliability is the entry point for the admin "reliability" Slack command.
+//
+// It reports how many launches became ready or failed over a rolling window
+// (24 hours unless given, e.g. `reliability 7d`), the average time to ready
+// and the most common failure reasons. Outcomes are kept in memory since the
+// bot started.
+func HandleReliability(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	window, label := defaultReliabilityWindow, "24h"
+	if len(args) > 0 {
+		parsed, err := parseWindow(args[0])
+		if len(args) > 1 || err != nil {
+			return invalid(msgs().ReliabilityUsage)
+		}
+		window, label = parsed, args[0]
+	}
+
+	launchOutcomesMu.Lock()
+	stats := computeReliability(launchOutcomes, time.Now().Add(-window))
+	launchOutcomesMu.Unlock()
+
+	respond.Text(api, event.Channel, formatReliability(stats, label))
+	return nil
+}
+
+// formatReliability renders the reliability summary of the window labelled
+// window.
+func formatReliability(stats reliabilityStats, window string) string {
+	total := stats.Succeeded + stats.Failed
+	data := messages.Data{
+		"Window":    window,
+		"Total":     total,
+		"Succeeded": stats.Succeeded,
+		"Failed":    stats.Failed,
+	}
+	if total > 0 {
+		data["SuccessRate"] = fmt.Sprintf("%.0f%%", 100*float64(stats.Succeeded)/float64(total))
+	}
+	if stats.Succeeded > 0 {
+		data["AverageReady"] = messages.FormatDuration(stats.AverageReady)
+	}
+	var reasons strings.Builder
+	for _, reason := range stats.Reasons[:min(len(stats.Reasons), topFailureReasons)] {
+		reasons.WriteString(messages.Render(msgs().ReliabilityReason, messages.Data{
+			"Reason": reason.Reason,
+			"Count":  reason.Count,
+		}))
+	}
+	data["Reasons"] = reasons.String()
+	return messages.Render(msgs().Reliability, data)
+}
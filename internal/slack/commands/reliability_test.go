@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// useLaunchOutcomes replaces the recorded launch outcomes with outcomes
+// until the test ends.
+func useLaunchOutcomes(t *testing.T, outcomes ...launchOutcome) {
+	t.Helper()
+	launchOutcomesMu.Lock()
+	previous := launchOutcomes
+	launchOutcomes = outcomes
+	launchOutcomesMu.Unlock()
+	t.Cleanup(func() {
+		launchOutcomesMu.Lock()
+		launchOutcomes = previous
+		launchOutcomesMu.Unlock()
+	})
+}
+
+// sampleOutcomes are three ready launches and four failures in the last day,
+// and two older outcomes.
+func sampleOutcomes(now time.Time) []launchOutcome {
+	return []launchOutcome{
+		{Time: now.Add(-48 * time.Hour), Ready: true, TimeToReady: time.Hour},
+		{Time: now.Add(-30 * time.Hour), Reason: "create rejected: Forbidden"},
+		{Time: now.Add(-20 * time.Hour), Ready: true, TimeToReady: 10 * time.Minute},
+		{Time: now.Add(-10 * time.Hour), Reason: reasonWatchTimeout},
+		{Time: now.Add(-5 * time.Hour), Reason: reasonProvisionFailed},
+		{Time: now.Add(-4 * time.Hour), Ready: true, TimeToReady: 20 * time.Minute},
+		{Time: now.Add(-3 * time.Hour), Reason: reasonProvisionFailed},
+		{Time: now.Add(-2 * time.Hour), Ready: true, TimeToReady: 30 * time.Minute},
+		{Time: now.Add(-time.Hour), Reason: "create rejected: AlreadyExists"},
+	}
+}
+
+func TestComputeReliability(t *testing.T) {
+	now := time.Now()
+	stats := computeReliability(sampleOutcomes(now), now.Add(-24*time.Hour))
+
+	if stats.Succeeded != 3 || stats.Failed != 4 {
+		t.Errorf("succeeded %d, failed %d, want 3 and 4 within the window", stats.Succeeded, stats.Failed)
+	}
+	if stats.AverageReady != 20*time.Minute {
+		t.Errorf("average time to ready = %s, want 20m", stats.AverageReady)
+	}
+	want := []reasonCount{
+		{Reason: reasonProvisionFailed, Count: 2},
+		{Reason: "create rejected: AlreadyExists", Count: 1},
+		{Reason: reasonWatchTimeout, Count: 1},
+	}
+	if len(stats.Reasons) != len(want) {
+		t.Fatalf("reasons = %+v, want %+v", stats.Reasons, want)
+	}
+	for i := range want {
+		if stats.Reasons[i] != want[i] {
+			t.Errorf("reason %d = %+v, want %+v", i, stats.Reasons[i], want[i])
+		}
+	}
+
+	if empty := computeReliability(sampleOutcomes(now), now); empty.Succeeded != 0 || empty.Failed != 0 || empty.AverageReady != 0 {
+		t.Errorf("stats of an empty window = %+v, want zero", empty)
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "7d", want: 7 * 24 * time.Hour},
+		{value: "12h", want: 12 * time.Hour},
+		{value: "90m", want: 90 * time.Minute},
+		{value: "0d", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "week", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseWindow(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseWindow(%q) = %s, %v, want %s, error %t", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRecordLaunchOutcomeIsBounded(t *testing.T) {
+	useLaunchOutcomes(t)
+	start := time.Now()
+	for i := range launchOutcomesSize + 10 {
+		recordLaunchOutcome(launchOutcome{Time: start.Add(time.Duration(i) * time.Second)})
+	}
+	if len(launchOutcomes) != launchOutcomesSize {
+		t.Fatalf("kept %d outcomes, want %d", len(launchOutcomes), launchOutcomesSize)
+	}
+	if got := launchOutcomes[0].Time; !got.Equal(start.Add(10 * time.Second)) {
+		t.Errorf("oldest outcome at %v, want the first ten dropped", got)
+	}
+}
+
+func TestCreateFailureReason(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Group: "mapt.redhat.com", Resource: "kinds"}, "c1", errors.New("denied"))
+	if got := createFailureReason(forbidden); got != "create rejected: Forbidden" {
+		t.Errorf("createFailureReason() = %q, want the API server reason", got)
+	}
+	if got := createFailureReason(errors.New("connection refused")); !strings.HasPrefix(got, "create ") {
+		t.Errorf("createFailureReason() = %q, want the error class", got)
+	}
+}
+
+func TestHandleReliability(t *testing.T) {
+	useConfig(t, testConfig())
+	useLaunchOutcomes(t, sampleOutcomes(time.Now())...)
+	api, server := newAPI(t)
+
+	if err := HandleReliability(context.Background(), api, message("UADMIN", "C1", "reliability"), nil); err != nil {
+		t.Fatalf("HandleReliability: %v", err)
+	}
+	text := server.WaitForMessage("Launch Reliability", time.Second).Text()
+	for _, want := range []string{"(last 24h)", "Launches: *7* — ✅ 3 ready, ❌ 4 failed (43% success)", "Average time to ready: *20m*", "◦ MAPT reported the cluster Failed — 2"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("reliability %q does not contain %q", text, want)
+		}
+	}
+
+	if err := HandleReliability(context.Background(), api, message("UADMIN", "C1", "reliability 3d"), []string{"3d"}); err != nil {
+		t.Fatalf("HandleReliability: %v", err)
+	}
+	if text := server.WaitForMessage("(last 3d)", time.Second).Text(); !strings.Contains(text, "Launches: *9*") {
+		t.Errorf("reliability %q, want every sample outcome within 3 days", text)
+	}
+
+	if err := HandleReliability(context.Background(), api, message("UADMIN", "C1", "reliability soon"), []string{"soon"}); CategoryOf(err) != CategoryValidation {
+		t.Errorf("HandleReliability() with an invalid window = %v, want the usage", err)
+	}
+}
+
+func TestLaunchRecordsOutcome(t *testing.T) {
+	useConfig(t, testConfig())
+	useLaunchOutcomes(t)
+	useKube(t, slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady)))
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U22", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	launchOutcomesMu.Lock()
+	defer launchOutcomesMu.Unlock()
+	if !slices.ContainsFunc(launchOutcomes, func(outcome launchOutcome) bool { return outcome.Ready && outcome.TimeToReady > 0 }) {
+		t.Errorf("outcomes %+v, want the ready launch recorded", launchOutcomes)
+	}
+}
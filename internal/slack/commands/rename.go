@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// annotationDisplayName holds the friendly name a cluster was given with
+// `rename`, shown next to its resource name.
+const annotationDisplayName = "spoticus.io/display-name"
+
+// maxDisplayNameLength bounds display names, in characters, so that they
+// stay readable in lists.
+const maxDisplayNameLength = 48
+
+// DisplayName returns the friendly name the cluster was given, or empty.
+func (c ClusterInfo) DisplayName() string {
+	return c.Annotations[annotationDisplayName]
+}
+
+// validDisplayName reports whether name may be used as a display name: not
+// too long, and without control characters or the characters Slack would
+// interpret as mentions and links.
+func validDisplayName(name string) bool {
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		return false
+	}
+	return !strings.ContainsFunc(name, func(r rune) bool {
+		return unicode.IsControl(r) || strings.ContainsRune("<>&*_`~", r)
+	})
+}
+
+// HandleRename is the entry point for the "rename" Slack command.
+//
+// It gives a cluster a friendly display name, shown alongside its resource
+// name by `list` and `status`; the resource itself keeps its name. Only the
+// cluster's owner and admins may rename it.
+func HandleRename(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) < 2 {
+		return invalid(cfg.Messages.RenameUsage)
+	}
+	name := args[0]
+	displayName := strings.Join(args[1:], " ")
+	tracing.SetCluster(ctx, name)
+
+	if !validDisplayName(displayName) {
+		return invalid(messages.Render(cfg.Messages.RenameInvalid, messages.Data{
+			"DisplayName": displayName,
+			"Max":         maxDisplayNameLength,
+		}))
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	cluster, err := findCluster(ctx, client, name)
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+	owner := cluster.Metadata().Owner
+	if owner != event.User && !cfg.IsAdmin(event.User) {
+		return denied(messages.Render(cfg.Messages.RenameDenied, messages.Data{
+			"Name":  name,
+			"Owner": owner,
+		}), "denied renaming of cluster %s (owner %s) to user %s", name, owner, event.User)
+	}
+
+	if err := patchAnnotation(ctx, client, cluster, annotationDisplayName, displayName); err != nil {
+		return failure(err, cfg.Messages.RenameFailed, "updating %s on MAPT cluster %s/%s", annotationDisplayName, cluster.Namespace, cluster.Name)
+	}
+
+	log.Printf("Cluster %s/%s renamed to %q by %s", cluster.Namespace, cluster.Name, displayName, event.User)
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.Renamed, messages.Data{
+		"Name":        name,
+		"DisplayName": displayName,
+	}))
+	return nil
+}
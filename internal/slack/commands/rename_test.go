@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestValidDisplayName(t *testing.T) {
+	tests := []struct {
+		name        string
+		displayName string
+		want        bool
+	}{
+		{name: "words", displayName: "Payments demo", want: true},
+		{name: "at the limit in characters", displayName: strings.Repeat("é", maxDisplayNameLength), want: true},
+		{name: "too long", displayName: strings.Repeat("a", maxDisplayNameLength+1)},
+		{name: "mention", displayName: "<@U1>"},
+		{name: "link", displayName: "<https://example.com|here>"},
+		{name: "formatting", displayName: "*bold*"},
+		{name: "control character", displayName: "line\nbreak"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validDisplayName(tt.displayName); got != tt.want {
+				t.Errorf("validDisplayName(%q) = %v, want %v", tt.displayName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameShownInListAndStatus(t *testing.T) {
+	useConfig(t, testConfig())
+	name := "spoticus-k8s-renamed"
+	kube := slacktest.NewKube(existingCluster(name, "UOWNER", 0))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	args := []string{name, "Payments", "demo"}
+	if err := HandleRename(context.Background(), api, message("UOWNER", "C1", "rename "+strings.Join(args, " ")), args); err != nil {
+		t.Fatalf("HandleRename: %v", err)
+	}
+	server.WaitForMessage("is now shown as “Payments demo”", time.Second)
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 || kinds[0].Name != name || kinds[0].Annotations[annotationDisplayName] != "Payments demo" {
+		t.Fatalf("clusters %v, want %s kept and annotated %s", kinds, name, annotationDisplayName)
+	}
+	if kinds[0].Annotations[annotationOwner] != "UOWNER" {
+		t.Errorf("annotations %v, want the other annotations kept", kinds[0].Annotations)
+	}
+
+	if err := HandleList(context.Background(), api, message("UOWNER", "C1", "list"), nil); err != nil {
+		t.Fatalf("HandleList: %v", err)
+	}
+	if text := server.WaitForMessage("Cluster List", time.Second).Text(); !strings.Contains(text, "*"+name+"* “Payments demo”") {
+		t.Errorf("list %q does not show the display name next to the resource name", text)
+	}
+	if err := HandleStatus(context.Background(), api, message("UOWNER", "C1", "status "+name), []string{name}); err != nil {
+		t.Fatalf("HandleStatus: %v", err)
+	}
+	if text := server.WaitForMessage("("+clusterTypeNames["k8s"]+")", time.Second).Text(); !strings.Contains(text, "*"+name+"* “Payments demo”") {
+		t.Errorf("status %q does not show the display name next to the resource name", text)
+	}
+}
+
+func TestRenameRestrictedToOwnerAndAdmins(t *testing.T) {
+	cfg := testConfig()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	name := "spoticus-k8s-named"
+	kube := slacktest.NewKube(existingCluster(name, "UOWNER", 0))
+	useKube(t, kube)
+	api, _ := newAPI(t)
+
+	err := HandleRename(context.Background(), api, message("UOTHER", "C1", "rename "+name+" mine"), []string{name, "mine"})
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryAuth || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "<@UOWNER>") {
+		t.Errorf("HandleRename() by another user = %v, want access denied naming the owner", err)
+	}
+	if kinds := launchedKinds(t, kube); kinds[0].Annotations[annotationDisplayName] != "" {
+		t.Error("cluster renamed by a user who is neither its owner nor an admin")
+	}
+
+	if err := HandleRename(context.Background(), api, message("UADMIN", "C1", "rename "+name+" shared"), []string{name, "shared"}); err != nil {
+		t.Fatalf("HandleRename() by an admin: %v", err)
+	}
+	if kinds := launchedKinds(t, kube); kinds[0].Annotations[annotationDisplayName] != "shared" {
+		t.Errorf("annotations %v, want the admin's display name", kinds[0].Annotations)
+	}
+}
+
+func TestRenameRejectsInvalidArguments(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-invalid", "U1", 0)))
+	api, server := newAPI(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "no display name", args: []string{"spoticus-k8s-invalid"}, want: "Usage: `rename <cluster_name> <display name>`"},
+		{name: "too long", args: []string{"spoticus-k8s-invalid", strings.Repeat("a", maxDisplayNameLength+1)}, want: "at most 48 characters"},
+		{name: "mention", args: []string{"spoticus-k8s-invalid", "<!here>"}, want: "Invalid display name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := HandleRename(context.Background(), api, message("U1", "C1", "rename "+strings.Join(tt.args, " ")), tt.args)
+			var cmdErr *CommandError
+			if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, tt.want) {
+				t.Errorf("HandleRename() = %v, want a validation error containing %q", err, tt.want)
+			}
+		})
+	}
+	if got := len(server.Messages()); got != 0 {
+		t.Errorf("posted %d messages, want none", got)
+	}
+}
@@ -0,0 +1,365 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// maintenanceRetryInterval is how long a scheduled launch due during maintenance is postponed.
+const maintenanceRetryInterval = 5 * time.Minute
+
+// labelScheduledLaunch marks the ConfigMaps persisting scheduled launches.
+const labelScheduledLaunch = "spoticus.io/scheduled-launch"
+
+// Keys of the scheduled launch ConfigMap data.
+const (
+	scheduleKeyUser    = "user"
+	scheduleKeyChannel = "channel"
+	scheduleKeyTeam    = "team"
+	scheduleKeyCommand = "command"
+	scheduleKeyArgs    = "args"
+	scheduleKeyRunAt   = "runAt"
+)
+
+// scheduledLaunch is a launch deferred with `--at` or `--in`.
+//
+// Each one is persisted as a ConfigMap in the configured namespace so that
+// it survives restarts; the ConfigMap is deleted when the launch starts or
+// is cancelled.
+type scheduledLaunch struct {
+	ID      string
+	User    string
+	Channel string
+	Team    string
+	Command string
+
+	// Args are the launch arguments, without the scheduling flags.
+	Args  []string
+	RunAt time.Time
+}
+
+var (
+	scheduleTimersMu sync.Mutex
+	// scheduleTimers holds the pending timer of each scheduled launch by ID.
+	scheduleTimers = map[string]*time.Timer{}
+)
+
+// scheduleConfigMapName returns the name of the ConfigMap persisting a scheduled launch.
+func scheduleConfigMapName(id string) string {
+	return "spoticus-schedule-" + id
+}
+
+// configMap encodes the scheduled launch as a ConfigMap.
+func (s scheduledLaunch) configMap(namespace string) *corev1.ConfigMap {
+	args, _ := json.Marshal(s.Args)
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      scheduleConfigMapName(s.ID),
+			Namespace: namespace,
+			Labels:    map[string]string{labelScheduledLaunch: s.ID},
+		},
+		Data: map[string]string{
+			scheduleKeyUser:    s.User,
+			scheduleKeyChannel: s.Channel,
+			scheduleKeyTeam:    s.Team,
+			scheduleKeyCommand: s.Command,
+			scheduleKeyArgs:    string(args),
+			scheduleKeyRunAt:   s.RunAt.UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+// scheduledLaunchFromConfigMap decodes a scheduled launch persisted by configMap.
+func scheduledLaunchFromConfigMap(cm *corev1.ConfigMap) (scheduledLaunch, error) {
+	s := scheduledLaunch{
+		ID:      cm.Labels[labelScheduledLaunch],
+		User:    cm.Data[scheduleKeyUser],
+		Channel: cm.Data[scheduleKeyChannel],
+		Team:    cm.Data[scheduleKeyTeam],
+		Command: cm.Data[scheduleKeyCommand],
+	}
+	if err := json.Unmarshal([]byte(cm.Data[scheduleKeyArgs]), &s.Args); err != nil {
+		return s, fmt.Errorf("decoding args of scheduled launch %s: %w", cm.Name, err)
+	}
+	runAt, err := time.Parse(time.RFC3339, cm.Data[scheduleKeyRunAt])
+	if err != nil {
+		return s, fmt.Errorf("decoding run time of scheduled launch %s: %w", cm.Name, err)
+	}
+	s.RunAt = runAt
+	return s, nil
+}
+
+// listScheduledLaunches returns the persisted scheduled launches, soonest
+// first. When ctx is scoped to a workspace, only its launches are returned.
+func listScheduledLaunches(ctx context.Context, client *KubernetesClients) ([]scheduledLaunch, error) {
+	var list corev1.ConfigMapList
+	if err := client.CrClient.List(ctx, &list,
+		crclient.InNamespace(spoticusConfig.Get().Namespace),
+		crclient.HasLabels{labelScheduledLaunch},
+	); err != nil {
+		return nil, err
+	}
+
+	tenant, scoped := tenantFrom(ctx)
+	launches := make([]scheduledLaunch, 0, len(list.Items))
+	for i := range list.Items {
+		launch, err := scheduledLaunchFromConfigMap(&list.Items[i])
+		if err != nil {
+			log.Printf("Skipping scheduled launch: %v", err)
+			continue
+		}
+		if scoped && launch.Team != tenant {
+			continue
+		}
+		launches = append(launches, launch)
+	}
+	sort.Slice(launches, func(i, j int) bool { return launches[i].RunAt.Before(launches[j].RunAt) })
+	return launches, nil
+}
+
+// RestoreScheduledLaunches re-arms the timers of the scheduled launches
+// persisted in the cluster. It is called when the bot starts processing
+// events; launches whose time passed while the bot was down start right away.
+func RestoreScheduledLaunches(api *slack.Client) {
+	client, err := GetKubernetesClient()
+	if err != nil {
+		log.Printf("Error getting kubernetes client to restore scheduled launches: %v", err)
+		return
+	}
+	launches, err := listScheduledLaunches(context.TODO(), client)
+	if err != nil {
+		log.Printf("Error listing scheduled launches: %v", err)
+		return
+	}
+	for _, launch := range launches {
+		armScheduledLaunch(api, launch)
+	}
+	if len(launches) > 0 {
+		log.Printf("Restored %d scheduled launch(es)", len(launches))
+	}
+}
+
+// armScheduledLaunch starts the timer running the launch at its scheduled time.
+func armScheduledLaunch(api *slack.Client, launch scheduledLaunch) {
+	scheduleTimersMu.Lock()
+	defer scheduleTimersMu.Unlock()
+	if timer, ok := scheduleTimers[launch.ID]; ok {
+		timer.Stop()
+	}
+	scheduleTimers[launch.ID] = time.AfterFunc(time.Until(launch.RunAt), func() {
+		runScheduledLaunch(api, launch)
+	})
+}
+
+// disarmScheduledLaunch stops the timer of a scheduled launch, if any.
+func disarmScheduledLaunch(id string) {
+	scheduleTimersMu.Lock()
+	defer scheduleTimersMu.Unlock()
+	if timer, ok := scheduleTimers[id]; ok {
+		timer.Stop()
+		delete(scheduleTimers, id)
+	}
+}
+
+// runScheduledLaunch starts a scheduled launch as if its owner had just run it.
+//
+// During maintenance the launch is postponed until maintenance ends.
+// Otherwise the persisted ConfigMap is deleted first: a launch that was cancelled in
+// the meantime is skipped, and a launch never runs twice, even across replicas.
+func runScheduledLaunch(api *slack.Client, launch scheduledLaunch) {
+	disarmScheduledLaunch(launch.ID)
+
+	ctx, span := tracing.Start(context.Background(), "scheduled launch",
+		tracing.AttrCommand.String("launch"),
+		tracing.AttrUser.String(launch.User),
+		tracing.AttrChannel.String(launch.Channel))
+	defer span.End()
+
+	// Hold off during maintenance rather than dropping the launch.
+	if MaintenanceEnabled() {
+		log.Printf("Maintenance mode is on, postponing scheduled launch %s", launch.ID)
+		launch.RunAt = time.Now().Add(maintenanceRetryInterval)
+		armScheduledLaunch(api, launch)
+		return
+	}
+
+	client, err := GetKubernetesClient()
+	if err == nil {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      scheduleConfigMapName(launch.ID),
+			Namespace: spoticusConfig.Get().Namespace,
+		}}
+		err = client.CrClient.Delete(ctx, cm)
+	}
+	switch {
+	case apierrors.IsNotFound(err):
+		log.Printf("Scheduled launch %s was cancelled, skipping", launch.ID)
+		return
+	case err != nil:
+		log.Printf("Error starting scheduled launch %s: %v", launch.ID, err)
+		respond.Text(api, launch.Channel, messages.Render(backendError(err, msgs().ScheduleRunFailed), messages.Data{
+			"ID":   launch.ID,
+			"User": launch.User,
+		}))
+		return
+	}
+
+	log.Printf("Starting scheduled launch %s for user %s", launch.ID, launch.User)
+	respond.Text(api, launch.Channel, messages.Render(msgs().ScheduleStarting, messages.Data{
+		"ID":      launch.ID,
+		"User":    launch.User,
+		"Command": launch.Command,
+	}))
+	event := &slackevents.MessageEvent{
+		User:    launch.User,
+		Channel: launch.Channel,
+		Text:    launch.Command,
+	}
+	ctx, ok := WithTeam(ctx, launch.Team)
+	if !ok {
+		log.Printf("Skipping scheduled launch %s: no workspace recorded for tenant isolation", launch.ID)
+		return
+	}
+	// Expensive launches were confirmed before they were scheduled.
+	if err := launchCluster(ctx, api, event, launch.Args, true); err != nil {
+		ReportError(api, launch.Channel, "launch", launch.User, err)
+	}
+}
+
+// scheduleLaunch persists a validated launch to run at runAt and confirms it to the user.
+func scheduleLaunch(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string, runAt time.Time) error {
+	cfg := spoticusConfig.Get()
+	launch := scheduledLaunch{
+		ID:      utilrand.String(5),
+		User:    event.User,
+		Channel: event.Channel,
+		Team:    teamFrom(ctx),
+		Command: event.Text,
+		Args:    args,
+		RunAt:   runAt,
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+	if err := client.CrClient.Create(ctx, launch.configMap(cfg.Namespace)); err != nil {
+		return failure(err, cfg.Messages.ScheduleFailed, "persisting scheduled launch %s", launch.ID)
+	}
+	armScheduledLaunch(api, launch)
+
+	log.Printf("Scheduled launch %s for user %s at %s", launch.ID, launch.User, runAt.Format(time.RFC3339))
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.ScheduleConfirm, messages.Data{
+		"ID":    launch.ID,
+		"RunAt": runAt.Format(timestampLayout),
+		"In":    humanizeAge(runAt),
+	}))
+	return nil
+}
+
+// HandleSchedule is the entry point for the "schedule" Slack command.
+//
+// `schedule` lists the pending scheduled launches and `schedule cancel <id>`
+// cancels one. Only the user who scheduled a launch, or an admin, may cancel it.
+func HandleSchedule(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	switch {
+	case len(args) == 0:
+		return listSchedule(ctx, api, event)
+	case len(args) == 2 && strings.ToLower(args[0]) == "cancel":
+		return cancelSchedule(ctx, api, event, args[1])
+	default:
+		return invalid(cfg.Messages.ScheduleUsage)
+	}
+}
+
+// listSchedule shows the pending scheduled launches.
+func listSchedule(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent) error {
+	cfg := spoticusConfig.Get()
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+	launches, err := listScheduledLaunches(ctx, client)
+	if err != nil {
+		return failure(err, cfg.Messages.ScheduleListFailed, "listing scheduled launches")
+	}
+	if len(launches) == 0 {
+		respond.Text(api, event.Channel, cfg.Messages.ScheduleNone)
+		return nil
+	}
+
+	var msg strings.Builder
+	msg.WriteString(messages.Render(cfg.Messages.ScheduleHeader, messages.Data{"Count": len(launches)}))
+	for _, launch := range launches {
+		msg.WriteString(messages.Render(cfg.Messages.ScheduleEntry, messages.Data{
+			"ID":      launch.ID,
+			"RunAt":   launch.RunAt.Local().Format(timestampLayout),
+			"In":      humanizeAge(launch.RunAt),
+			"User":    launch.User,
+			"Command": launch.Command,
+		}))
+	}
+	respond.Text(api, event.Channel, msg.String())
+	return nil
+}
+
+// cancelSchedule cancels the scheduled launch with the given ID.
+func cancelSchedule(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, id string) error {
+	cfg := spoticusConfig.Get()
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	key := crclient.ObjectKey{Namespace: cfg.Namespace, Name: scheduleConfigMapName(id)}
+	err = client.CrClient.Get(ctx, key, cm)
+	if tenant, ok := tenantFrom(ctx); ok && err == nil && cm.Data[scheduleKeyTeam] != tenant {
+		// Another workspace's launch is treated as if it did not exist.
+		err = apierrors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+	}
+	if err != nil {
+		message := messages.Render(cfg.Messages.ScheduleNotFound, messages.Data{"ID": id})
+		if apierrors.IsNotFound(err) {
+			return invalid(message)
+		}
+		return failure(err, message, "looking up scheduled launch %s", id)
+	}
+
+	owner := cm.Data[scheduleKeyUser]
+	if owner != event.User && !cfg.IsAdmin(event.User) {
+		return denied(messages.Render(cfg.Messages.ScheduleCancelDenied, messages.Data{
+			"ID":   id,
+			"User": owner,
+		}), "denied cancelling scheduled launch %s (user %s) to user %s", id, owner, event.User)
+	}
+
+	if err := client.CrClient.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+		return failure(err, cfg.Messages.ScheduleFailed, "cancelling scheduled launch %s", id)
+	}
+	disarmScheduledLaunch(id)
+
+	log.Printf("Scheduled launch %s cancelled by %s", id, event.User)
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.ScheduleCancelled, messages.Data{"ID": id}))
+	return nil
+}
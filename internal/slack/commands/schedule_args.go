@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+)
+
+// maxScheduleAhead bounds how far in the future a launch may be scheduled.
+const maxScheduleAhead = 30 * 24 * time.Hour
+
+// scheduleLayouts are the absolute time formats accepted by `--at`, in the bot's local time zone.
+var scheduleLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	time.RFC3339,
+}
+
+// joinQuoted rejoins the arguments of a quoted value that was split on
+// whitespace, e.g. `--at="2025-06-01` `09:00"`, and strips the quotes.
+// Slack's curly quotes are handled too.
+func joinQuoted(args []string) []string {
+	var joined []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		key, value, isFlag := strings.Cut(arg, "=")
+		if !isFlag || !strings.HasPrefix(arg, "--") || !(strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "“")) {
+			joined = append(joined, arg)
+			continue
+		}
+		for !hasClosingQuote(value) && i+1 < len(args) {
+			i++
+			value += " " + args[i]
+		}
+		joined = append(joined, key+"="+strings.Trim(value, `"“”`))
+	}
+	return joined
+}
+
+// hasClosingQuote reports whether a value starting with a quote also ends with one.
+func hasClosingQuote(value string) bool {
+	trimmed := strings.TrimLeft(value, `"“`)
+	return len(trimmed) < len(value) && (strings.HasSuffix(trimmed, `"`) || strings.HasSuffix(trimmed, "”"))
+}
+
+// extractSchedule removes the `--at` and `--in` flags from the launch
+// arguments and returns when the launch should run. A zero time means now.
+// The remaining arguments are returned even with an error, which is suitable
+// to be shown to the user as-is.
+func extractSchedule(args []string, now time.Time) ([]string, time.Time, error) {
+	templates := spoticusConfig.Get().Messages
+	var rest []string
+	var at, in string
+	var hasAt, hasIn bool
+	for _, arg := range joinQuoted(args) {
+		key, value, _ := strings.Cut(arg, "=")
+		switch strings.ToLower(key) {
+		case "--at":
+			at, hasAt = value, true
+		case "--in":
+			in, hasIn = value, true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	var runAt time.Time
+	switch {
+	case hasAt && hasIn:
+		return rest, time.Time{}, errors.New(templates.ScheduleBoth)
+	case hasIn:
+		d, err := time.ParseDuration(in)
+		if err != nil {
+			return rest, time.Time{}, errors.New(messages.Render(templates.ScheduleInvalidTime, messages.Data{"Value": in}))
+		}
+		runAt = now.Add(d)
+	case hasAt:
+		t, err := parseScheduleTime(at)
+		if err != nil {
+			return rest, time.Time{}, errors.New(messages.Render(templates.ScheduleInvalidTime, messages.Data{"Value": at}))
+		}
+		runAt = t
+	default:
+		return rest, time.Time{}, nil
+	}
+
+	if !runAt.After(now) || runAt.Sub(now) > maxScheduleAhead {
+		return rest, time.Time{}, errors.New(messages.Render(templates.ScheduleOutOfRange, messages.Data{
+			"Max": fmt.Sprintf("%d days", int(maxScheduleAhead/(24*time.Hour))),
+		}))
+	}
+	return rest, runAt, nil
+}
+
+// parseScheduleTime parses an `--at` value in one of the scheduleLayouts.
+func parseScheduleTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range scheduleLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
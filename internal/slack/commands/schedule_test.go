@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// useScheduleTimers starts the test without armed scheduled launches, and
+// stops the ones it armed when it ends.
+func useScheduleTimers(t *testing.T) {
+	t.Helper()
+	scheduleTimersMu.Lock()
+	previous := scheduleTimers
+	scheduleTimers = map[string]*time.Timer{}
+	scheduleTimersMu.Unlock()
+	t.Cleanup(func() {
+		scheduleTimersMu.Lock()
+		defer scheduleTimersMu.Unlock()
+		for _, timer := range scheduleTimers {
+			timer.Stop()
+		}
+		scheduleTimers = previous
+	})
+}
+
+// armed returns the IDs of the armed scheduled launches.
+func armed() []string {
+	scheduleTimersMu.Lock()
+	defer scheduleTimersMu.Unlock()
+	ids := make([]string, 0, len(scheduleTimers))
+	for id := range scheduleTimers {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// scheduledConfigMaps lists the ConfigMaps persisting scheduled launches.
+func scheduledConfigMaps(t *testing.T, kube *slacktest.Kube) []corev1.ConfigMap {
+	t.Helper()
+	var list corev1.ConfigMapList
+	if err := kube.CrClient.List(context.Background(), &list, crclient.HasLabels{labelScheduledLaunch}); err != nil {
+		t.Fatalf("listing the scheduled launches: %v", err)
+	}
+	return list.Items
+}
+
+// scheduledFor is a scheduled launch of a medium Kubernetes cluster by the user.
+func scheduledFor(id, user string, runAt time.Time) scheduledLaunch {
+	return scheduledLaunch{
+		ID:      id,
+		User:    user,
+		Channel: "C1",
+		Command: "launch k8s medium --in=1h",
+		Args:    []string{"k8s", "medium"},
+		RunAt:   runAt,
+	}
+}
+
+func TestExtractSchedule(t *testing.T) {
+	useConfig(t, testConfig())
+	now := time.Date(2025, 6, 1, 8, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantRest  []string
+		wantRunAt time.Time
+		wantErr   string
+	}{
+		{name: "no schedule", args: []string{"k8s", "medium"}, wantRest: []string{"k8s", "medium"}},
+		{name: "in", args: []string{"k8s", "--in=2h", "medium"}, wantRest: []string{"k8s", "medium"}, wantRunAt: now.Add(2 * time.Hour)},
+		{name: "at split on the space", args: []string{"k8s", `--at="2025-06-01`, `09:30"`}, wantRest: []string{"k8s"}, wantRunAt: now.Add(90 * time.Minute)},
+		{name: "at with curly quotes", args: []string{"k8s", "--at=“2025-06-01", "09:30”"}, wantRest: []string{"k8s"}, wantRunAt: now.Add(90 * time.Minute)},
+		{name: "at with a T", args: []string{"k8s", "--at=2025-06-01T09:30"}, wantRest: []string{"k8s"}, wantRunAt: now.Add(90 * time.Minute)},
+		{name: "at in RFC 3339", args: []string{"k8s", "--at=" + now.Add(time.Hour).Format(time.RFC3339)}, wantRest: []string{"k8s"}, wantRunAt: now.Add(time.Hour)},
+		{name: "both", args: []string{"k8s", "--in=2h", "--at=2025-06-01T09:30"}, wantErr: "mutually exclusive"},
+		{name: "invalid duration", args: []string{"k8s", "--in=soon"}, wantErr: "Invalid schedule *soon*"},
+		{name: "invalid time", args: []string{"k8s", "--at=tomorrow"}, wantErr: "Invalid schedule *tomorrow*"},
+		{name: "in the past", args: []string{"k8s", "--at=2025-06-01 07:00"}, wantErr: "in the future"},
+		{name: "too far ahead", args: []string{"k8s", "--in=800h"}, wantErr: "within 30 days"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest, runAt, err := extractSchedule(tt.args, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("extractSchedule(%q) error = %v, want it to contain %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractSchedule(%q): %v", tt.args, err)
+			}
+			if !slices.Equal(rest, tt.wantRest) {
+				t.Errorf("rest = %q, want %q", rest, tt.wantRest)
+			}
+			if !runAt.Equal(tt.wantRunAt) {
+				t.Errorf("run at %v, want %v", runAt, tt.wantRunAt)
+			}
+		})
+	}
+}
+
+func TestScheduledLaunchConfigMapRoundTrip(t *testing.T) {
+	launch := scheduledFor("abcde", "U1", time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC))
+	launch.Team = "T1"
+
+	got, err := scheduledLaunchFromConfigMap(launch.configMap("default"))
+	if err != nil {
+		t.Fatalf("scheduledLaunchFromConfigMap: %v", err)
+	}
+	if got.ID != launch.ID || got.User != launch.User || got.Team != launch.Team || got.Command != launch.Command ||
+		!slices.Equal(got.Args, launch.Args) || !got.RunAt.Equal(launch.RunAt) {
+		t.Errorf("decoded %+v, want %+v", got, launch)
+	}
+}
+
+func TestLaunchWithScheduleIsQueued(t *testing.T) {
+	useConfig(t, testConfig())
+	useScheduleTimers(t)
+	kube := slacktest.NewKube()
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	args := []string{"k8s", "medium", "--in=1h"}
+	if err := HandleLaunch(context.Background(), api, message("U1", "C1", "launch k8s medium --in=1h"), args); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+
+	server.WaitForMessage("Launch scheduled for", time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Errorf("got %d clusters, want the launch deferred", len(kinds))
+	}
+	configMaps := scheduledConfigMaps(t, kube)
+	if len(configMaps) != 1 {
+		t.Fatalf("got %d scheduled launches persisted, want 1", len(configMaps))
+	}
+	launch, err := scheduledLaunchFromConfigMap(&configMaps[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(launch.Args, []string{"k8s", "medium"}) {
+		t.Errorf("persisted args %q, want them without the schedule", launch.Args)
+	}
+	if ids := armed(); !slices.Equal(ids, []string{launch.ID}) {
+		t.Errorf("armed %q, want the scheduled launch %s", ids, launch.ID)
+	}
+}
+
+func TestRunScheduledLaunchStartsLaunch(t *testing.T) {
+	useConfig(t, testConfig())
+	useScheduleTimers(t)
+	launch := scheduledFor("abcde", "U9", time.Now())
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady), launch.configMap("default"))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	runScheduledLaunch(api, launch)
+
+	server.WaitForMessage("Starting the launch <@U9> scheduled with id *abcde*", time.Second)
+	server.WaitForMessage("is ready", 5*time.Second)
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want the scheduled one", len(kinds))
+	}
+	if configMaps := scheduledConfigMaps(t, kube); len(configMaps) != 0 {
+		t.Errorf("scheduled launch still persisted after it started")
+	}
+}
+
+func TestRunScheduledLaunchSkipsCancelled(t *testing.T) {
+	useConfig(t, testConfig())
+	useScheduleTimers(t)
+	kube := slacktest.NewKube()
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	runScheduledLaunch(api, scheduledFor("abcde", "U9", time.Now()))
+
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Errorf("got %d clusters, want the cancelled launch skipped", len(kinds))
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("posted %d messages, want none", len(messages))
+	}
+}
+
+func TestRestoreScheduledLaunches(t *testing.T) {
+	useConfig(t, testConfig())
+	useScheduleTimers(t)
+	useKube(t, slacktest.NewKube(
+		scheduledFor("later", "U1", time.Now().Add(time.Hour)).configMap("default"),
+		scheduledFor("after", "U2", time.Now().Add(2*time.Hour)).configMap("default"),
+	))
+	api, _ := newAPI(t)
+
+	RestoreScheduledLaunches(api)
+
+	ids := armed()
+	slices.Sort(ids)
+	if want := []string{"after", "later"}; !slices.Equal(ids, want) {
+		t.Errorf("armed %q, want %q", ids, want)
+	}
+}
+
+func TestScheduleListsLaunches(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(
+		scheduledFor("after", "U2", time.Now().Add(2*time.Hour)).configMap("default"),
+		scheduledFor("first", "U1", time.Now().Add(time.Hour)).configMap("default"),
+	))
+	api, server := newAPI(t)
+
+	if err := HandleSchedule(context.Background(), api, message("U1", "C1", "schedule"), nil); err != nil {
+		t.Fatalf("HandleSchedule: %v", err)
+	}
+	text := server.WaitForMessage("Scheduled Launches* (2)", time.Second).Text()
+	if first, after := strings.Index(text, "*first*"), strings.Index(text, "*after*"); first < 0 || after < first {
+		t.Errorf("schedule %q does not list the soonest launch first", text)
+	}
+}
+
+func TestScheduleCancel(t *testing.T) {
+	tests := []struct {
+		name         string
+		user         string
+		id           string
+		wantCategory ErrorCategory
+		wantCancel   bool
+	}{
+		{name: "by the owner", user: "U1", id: "abcde", wantCancel: true},
+		{name: "by an admin", user: "UADMIN", id: "abcde", wantCancel: true},
+		{name: "by someone else", user: "U2", id: "abcde", wantCategory: CategoryAuth},
+		{name: "unknown id", user: "U1", id: "zzzzz", wantCategory: CategoryValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Admins = []string{"UADMIN"}
+			useConfig(t, cfg)
+			useScheduleTimers(t)
+			launch := scheduledFor("abcde", "U1", time.Now().Add(time.Hour))
+			kube := slacktest.NewKube(launch.configMap("default"))
+			useKube(t, kube)
+			api, server := newAPI(t)
+			armScheduledLaunch(api, launch)
+
+			err := HandleSchedule(context.Background(), api, message(tt.user, "C1", "schedule cancel "+tt.id), []string{"cancel", tt.id})
+			if tt.wantCancel {
+				if err != nil {
+					t.Fatalf("HandleSchedule: %v", err)
+				}
+				server.WaitForMessage("Scheduled launch *abcde* cancelled", time.Second)
+			} else {
+				var commandErr *CommandError
+				if !errors.As(err, &commandErr) || commandErr.Category != tt.wantCategory {
+					t.Fatalf("HandleSchedule() = %v, want a %v error", err, tt.wantCategory)
+				}
+			}
+			if cancelled := len(scheduledConfigMaps(t, kube)) == 0 && len(armed()) == 0; cancelled != tt.wantCancel {
+				t.Errorf("launch cancelled = %v, want %v", cancelled, tt.wantCancel)
+			}
+		})
+	}
+}
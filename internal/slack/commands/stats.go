@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// unknownLabel groups clusters whose size was not recorded.
+const unknownLabel = "unknown"
+
+// clusterStats aggregates an inventory for the "stats" command.
+type clusterStats struct {
+	Total      int
+	ByType     map[string]int
+	BySize     map[string]int
+	ByPhase    map[string]int
+	Nodes      int
+	HourlyCost float64
+
+	// AccumulatedCost is what the clusters have cost since they were launched.
+	AccumulatedCost float64
+}
+
+// computeStats aggregates the clusters by type, size and status, and sums their
+// node count, estimated hourly cost and cost accumulated until now. Node
+// counts come from the configured size tiers; clusters of an unknown size
+// count as a single node.
+func computeStats(clusters []ClusterInfo, sizes map[string]spoticusConfig.SizeSpec, now time.Time) clusterStats {
+	stats := clusterStats{
+		Total:   len(clusters),
+		ByType:  map[string]int{},
+		BySize:  map[string]int{},
+		ByPhase: map[string]int{},
+	}
+	for _, cluster := range clusters {
+		metadata := cluster.Metadata()
+
+		stats.ByType[clusterTypeNames[cluster.Type]]++
+		stats.ByPhase[cluster.Phase]++
+
+		size := metadata.Size
+		if size == "" {
+			size = unknownLabel
+		}
+		stats.BySize[size]++
+
+		nodes := 1
+		if spec, ok := sizes[metadata.Size]; ok {
+			nodes = spec.Nodes
+		}
+		stats.Nodes += nodes
+		stats.HourlyCost += metadata.HourlyCost
+		stats.AccumulatedCost += accumulatedCost(metadata, cluster.Created, now)
+	}
+	return stats
+}
+
+// formatCounts renders counts as "a 2, b 1", sorted by label for stable output.
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	labels := make([]string, 0, len(counts))
+	for label := range counts {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = fmt.Sprintf("%s %d", label, counts[label])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// HandleStats is the entry point for the "stats" Slack command.
+// It summarizes the whole cluster inventory from a single list fetch.
+func HandleStats(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	inventory, err := listClusters(ctx, client)
+	if len(inventory.Failed) == len(clusterTypeNames) {
+		return failure(err, cfg.Messages.ListFailed, "listing MAPT clusters for stats")
+	}
+	if err != nil {
+		log.Printf("Error listing MAPT clusters for stats: %v", err)
+	}
+
+	stats := computeStats(inventory.Clusters, cfg.Sizes, time.Now())
+	message := messages.Render(cfg.Messages.Stats, messages.Data{
+		"Total":       stats.Total,
+		"ByType":      formatCounts(stats.ByType),
+		"BySize":      formatCounts(stats.BySize),
+		"ByStatus":    formatCounts(stats.ByPhase),
+		"Nodes":       stats.Nodes,
+		"HourlyCost":  formatHourlyCost(stats.HourlyCost),
+		"Accumulated": formatCost(stats.AccumulatedCost),
+	})
+	for _, failed := range inventory.Failed {
+		message += "\n\n" + messages.Render(cfg.Messages.ListTypeFailed, messages.Data{"Type": failed})
+	}
+	respond.Text(api, event.Channel, message)
+	return nil
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// statsCluster returns a cluster of the type, size and phase, launched hours
+// ago at the hourly cost.
+func statsCluster(clusterType, size, phase string, hourlyCost float64, launchedAt time.Time) ClusterInfo {
+	meta := &metav1.ObjectMeta{}
+	SetLaunchMetadata(meta, LaunchMetadata{Owner: "U1", Size: size, HourlyCost: hourlyCost, LaunchedAt: launchedAt})
+	return ClusterInfo{Name: "spoticus-" + clusterType, Type: clusterType, Phase: phase, Annotations: meta.GetAnnotations()}
+}
+
+func TestComputeStatsMixedInventory(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	sizes := map[string]spoticusConfig.SizeSpec{
+		"medium": {Nodes: 1},
+		"large":  {Nodes: 3},
+	}
+	clusters := []ClusterInfo{
+		statsCluster("k8s", "medium", "Ready", 0.15, now.Add(-2*time.Hour)),
+		statsCluster("k8s", "large", "Provisioning", 0.30, now.Add(-time.Hour)),
+		statsCluster("openshift", "large", "Ready", 1.00, now.Add(-30*time.Minute)),
+		statsCluster("openshift", "", "Failed", 0, time.Time{}),
+	}
+
+	stats := computeStats(clusters, sizes, now)
+
+	if stats.Total != 4 {
+		t.Errorf("Total = %d, want 4", stats.Total)
+	}
+	if got := formatCounts(stats.ByType); got != "Kubernetes 2, OpenShift 2" {
+		t.Errorf("ByType = %q, want Kubernetes 2, OpenShift 2", got)
+	}
+	if got := formatCounts(stats.BySize); got != "large 2, medium 1, unknown 1" {
+		t.Errorf("BySize = %q, want large 2, medium 1, unknown 1", got)
+	}
+	if got := formatCounts(stats.ByPhase); got != "Failed 1, Provisioning 1, Ready 2" {
+		t.Errorf("ByPhase = %q, want Failed 1, Provisioning 1, Ready 2", got)
+	}
+	// The unknown size counts as a single node.
+	if stats.Nodes != 1+3+3+1 {
+		t.Errorf("Nodes = %d, want 8", stats.Nodes)
+	}
+	if math.Abs(stats.HourlyCost-1.45) > 1e-9 {
+		t.Errorf("HourlyCost = %v, want 1.45", stats.HourlyCost)
+	}
+	if want := 0.15*2 + 0.30*1 + 1.00*0.5; math.Abs(stats.AccumulatedCost-want) > 1e-9 {
+		t.Errorf("AccumulatedCost = %v, want %v", stats.AccumulatedCost, want)
+	}
+}
+
+func TestComputeStatsEmptyInventory(t *testing.T) {
+	stats := computeStats(nil, spoticusConfig.Default().Sizes, time.Now())
+
+	if stats.Total != 0 || stats.Nodes != 0 || stats.HourlyCost != 0 {
+		t.Errorf("stats = %+v, want all zero", stats)
+	}
+	if got := formatCounts(stats.ByType); got != "none" {
+		t.Errorf("ByType = %q, want none", got)
+	}
+}
+
+func TestHandleStats(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(
+		existingCluster("spoticus-k8s-a", "U1", 0.15),
+		existingCluster("spoticus-k8s-b", "U2", 0.15),
+		existingClusterOf("openshift", "spoticus-openshift-a", "U1", 1.00),
+	))
+	api, server := newAPI(t)
+
+	if err := HandleStats(context.Background(), api, message("U1", "C1", "stats"), nil); err != nil {
+		t.Fatalf("HandleStats: %v", err)
+	}
+	text := server.WaitForMessage("Cluster Stats", time.Second).Text()
+	for _, want := range []string{"(3 clusters)", "By type: Kubernetes 2, OpenShift 1", "Total nodes: 3", "$1.30/h"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("stats %q do not contain %q", text, want)
+		}
+	}
+}
+
+func TestHandleStatsNotesFailedType(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKubeWithInterceptor(failList("openshift"),
+		existingCluster("spoticus-k8s-a", "U1", 0.15),
+	))
+	api, server := newAPI(t)
+
+	if err := HandleStats(context.Background(), api, message("U1", "C1", "stats"), nil); err != nil {
+		t.Fatalf("HandleStats: %v", err)
+	}
+	text := server.WaitForMessage("Cluster Stats", time.Second).Text()
+	if !strings.Contains(text, "(1 cluster)") {
+		t.Errorf("stats %q do not count the one Kubernetes cluster", text)
+	}
+	if !strings.Contains(text, "Could not retrieve OpenShift clusters") {
+		t.Errorf("stats %q do not warn about the OpenShift clusters", text)
+	}
+}
+
+func TestHandleStatsFailsWhenEveryTypeFails(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKubeWithInterceptor(failList("k8s", "openshift")))
+	api, _ := newAPI(t)
+
+	err := HandleStats(context.Background(), api, message("U1", "C1", "stats"), nil)
+	if CategoryOf(err) != CategoryBackend {
+		t.Errorf("HandleStats() = %v, want a backend failure", err)
+	}
+}
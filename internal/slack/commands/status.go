@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// HandleStatus is the entry point for the "status" and "describe" Slack commands.
+// It shows the provisioning status and launch metadata of a single cluster.
+func HandleStatus(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	if len(args) < 1 {
+		return invalid(cfg.Messages.StatusUsage)
+	}
+	name := args[0]
+	tracing.SetCluster(ctx, name)
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	cluster, err := findCluster(ctx, client, name)
+	if err != nil {
+		return clusterLookupError(err, name)
+	}
+	if !canOperate(cfg, event.User, cluster) {
+		return clusterAccessDenied(event.User, "status", cluster)
+	}
+
+	respond.Text(api, event.Channel, formatClusterStatus(cfg, cluster))
+	return nil
+}
+
+// formatClusterStatus renders the detailed view of a cluster.
+func formatClusterStatus(cfg *spoticusConfig.Config, cluster ClusterInfo) string {
+	metadata := cluster.Metadata()
+	data := messages.Data{
+		"Name":        cluster.Name,
+		"DisplayName": cluster.DisplayName(),
+		"Type":        clusterTypeNames[cluster.Type],
+		"Namespace":   cluster.Namespace,
+		"Phase":       cluster.Phase,
+		"Version":     metadata.Version,
+		"Size":        metadata.Size,
+		"Ref":         metadata.Ref,
+		"Owner":       metadata.Owner,
+		"Age":         humanizeAge(cluster.Created),
+		"Created":     cluster.Created.Format(timestampLayout),
+		"APIServer":   cluster.APIServer,
+		"Console":     cluster.Console,
+		"Pinned":      cluster.Pinned(),
+		"Permalink":   metadata.Permalink,
+	}
+	if cluster.APIServer == "" && cluster.Console == "" && cluster.Phase != phaseReady && cluster.Phase != phaseFailed {
+		data["EndpointsPending"] = true
+	}
+	if spec, ok := cfg.Sizes[metadata.Size]; ok {
+		data["CPU"] = spec.CPU()
+		data["RAM"] = spec.RAM()
+	}
+	var limits []string
+	if metadata.CPULimit > 0 {
+		limits = append(limits, spoticusConfig.SizeSpec{CPUs: metadata.CPULimit}.CPU())
+	}
+	if metadata.MemoryLimit > 0 {
+		limits = append(limits, spoticusConfig.SizeSpec{MemoryGB: metadata.MemoryLimit}.RAM())
+	}
+	data["Limits"] = strings.Join(limits, ", ")
+	if metadata.HourlyCost > 0 {
+		data["HourlyCost"] = formatHourlyCost(metadata.HourlyCost)
+		data["Accumulated"] = formatCost(cluster.AccumulatedCost(time.Now()))
+	}
+	if metadata.TTL > 0 {
+		data["TTL"] = messages.FormatDuration(metadata.TTL)
+		data["Expires"] = humanizeAge(cluster.Created.Add(metadata.TTL))
+	}
+	if at := cluster.DeleteAt(); !at.IsZero() {
+		data["DeleteAt"] = humanizeAge(at)
+	}
+	return messages.Render(cfg.Messages.StatusDetails, data)
+}
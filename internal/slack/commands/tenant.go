@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+)
+
+// labelTenant is the label carrying the Slack workspace (team ID) a cluster
+// was launched from when tenant isolation is enabled.
+const labelTenant = "spoticus.io/tenant"
+
+// tenantKey is the context key of the workspace a command is scoped to.
+type tenantKey struct{}
+
+// teamKey is the context key of the workspace a command was received from.
+type teamKey struct{}
+
+// WithTeam records that the command in ctx was received from the Slack
+// workspace with the given team ID and, when tenant isolation is enabled,
+// scopes ctx to it. Clusters are then listed, looked up and launched within
+// that workspace only, so one workspace can neither see nor delete another's
+// clusters.
+//
+// team must be the workspace the event was delivered to: the team ID of the
+// Events API envelope, or of the interaction. The teams of the message
+// itself name the sender's workspace, which differs in Slack Connect
+// channels.
+//
+// It returns false when isolation is enabled but team is empty, in which
+// case the command must not run.
+func WithTeam(ctx context.Context, team string) (context.Context, bool) {
+	if team != "" {
+		ctx = context.WithValue(ctx, teamKey{}, team)
+	}
+	if !spoticusConfig.Get().TenantIsolation {
+		return ctx, true
+	}
+	if team == "" {
+		return ctx, false
+	}
+	return context.WithValue(ctx, tenantKey{}, team), true
+}
+
+// teamFrom returns the workspace the command in ctx was received from, or ""
+// if unknown.
+func teamFrom(ctx context.Context) string {
+	team, _ := ctx.Value(teamKey{}).(string)
+	return team
+}
+
+// tenantFrom returns the workspace ctx is scoped to, if any.
+func tenantFrom(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// tenantListOptions returns the list options restricting a list to the
+// workspace ctx is scoped to, or nothing when it is not scoped.
+func tenantListOptions(ctx context.Context) []crclient.ListOption {
+	if tenant, ok := tenantFrom(ctx); ok {
+		return []crclient.ListOption{crclient.MatchingLabels{labelTenant: tenant}}
+	}
+	return nil
+}
+
+// tenantScoped returns key prefixed with the workspace ctx is scoped to, if
+// any, so that state kept per user in one workspace is not reachable from
+// another.
+func tenantScoped(ctx context.Context, key string) string {
+	if tenant, ok := tenantFrom(ctx); ok {
+		return tenant + "/" + key
+	}
+	return key
+}
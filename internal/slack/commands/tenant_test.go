@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestWithTeam(t *testing.T) {
+	tests := []struct {
+		name       string
+		isolation  bool
+		team       string
+		wantOK     bool
+		wantTenant string
+		wantTeam   string
+	}{
+		{name: "isolation off", team: "T1", wantOK: true, wantTeam: "T1"},
+		{name: "isolation off without team", wantOK: true},
+		{name: "isolation on", isolation: true, team: "T1", wantOK: true, wantTenant: "T1", wantTeam: "T1"},
+		{name: "isolation on without team", isolation: true, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.TenantIsolation = tt.isolation
+			useConfig(t, cfg)
+
+			ctx, ok := WithTeam(context.Background(), tt.team)
+			if ok != tt.wantOK {
+				t.Fatalf("WithTeam() ok = %t, want %t", ok, tt.wantOK)
+			}
+			tenant, scoped := tenantFrom(ctx)
+			if tenant != tt.wantTenant || scoped != (tt.wantTenant != "") {
+				t.Errorf("tenant = %q (scoped %t), want %q", tenant, scoped, tt.wantTenant)
+			}
+			if got := teamFrom(ctx); got != tt.wantTeam {
+				t.Errorf("team = %q, want %q", got, tt.wantTeam)
+			}
+		})
+	}
+}
+
+// tenantCluster returns a cluster owned by the owner, launched from the
+// workspace.
+func tenantCluster(name, owner, tenant string) *unstructured.Unstructured {
+	cluster := existingCluster(name, owner, 0)
+	cluster.SetLabels(map[string]string{labelTenant: tenant})
+	return cluster
+}
+
+func TestTenantIsolationScopesClusters(t *testing.T) {
+	cfg := testConfig()
+	cfg.TenantIsolation = true
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(
+		tenantCluster("spoticus-k8s-teama", "U1", "TA"),
+		tenantCluster("spoticus-k8s-teamb", "U2", "TB"),
+	)
+	useKube(t, kube)
+
+	ctxA, _ := WithTeam(context.Background(), "TA")
+	inventory, err := listClusters(ctxA, fakeClients(kube))
+	if err != nil {
+		t.Fatalf("listClusters: %v", err)
+	}
+	if len(inventory.Clusters) != 1 || inventory.Clusters[0].Name != "spoticus-k8s-teama" {
+		t.Errorf("team A lists %v, want only its own cluster", inventory.Clusters)
+	}
+
+	if _, err := findCluster(ctxA, fakeClients(kube), "spoticus-k8s-teamb"); !errors.Is(err, errClusterNotFound) {
+		t.Errorf("team A finding team B's cluster: error = %v, want not found", err)
+	}
+}
+
+func TestTenantIsolationRejectsCrossTenantDelete(t *testing.T) {
+	cfg := testConfig()
+	cfg.TenantIsolation = true
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(tenantCluster("spoticus-k8s-teamb", "U2", "TB"))
+	useKube(t, kube)
+	api, _ := newAPI(t)
+
+	// Even an admin of workspace A cannot reach workspace B's cluster.
+	ctxA, _ := WithTeam(context.Background(), "TA")
+	err := HandleDone(ctxA, api, message("UADMIN", "C1", "done spoticus-k8s-teamb --now"), []string{"spoticus-k8s-teamb", "--now"})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "not found") {
+		t.Fatalf("HandleDone() from team A = %v, want not found", err)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("team B's cluster was deleted from team A")
+	}
+}
+
+func TestLaunchLabelsEnvelopeTeam(t *testing.T) {
+	cfg := testConfig()
+	cfg.TenantIsolation = true
+	useConfig(t, cfg)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	// In a Slack Connect channel the sender's team is another organization's.
+	event := message("U1", "C1", "launch k8s medium")
+	event.UserTeam, event.SourceTeam = "TOTHER", "TOTHER"
+	ctx, _ := WithTeam(context.Background(), "TA")
+	if err := HandleLaunch(ctx, api, event, []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	kinds := launchedKinds(t, kube)
+	if len(kinds) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(kinds))
+	}
+	if got := kinds[0].Labels[labelTenant]; got != "TA" {
+		t.Errorf("cluster labelled with tenant %q, want the envelope's TA", got)
+	}
+	if got := kinds[0].Annotations[annotationTeam]; got != "TA" {
+		t.Errorf("cluster annotated with team %q, want the envelope's TA", got)
+	}
+}
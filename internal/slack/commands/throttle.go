@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+)
+
+// notificationThrottle coalesces the notifications posted about clusters: a
+// notification identical to one posted about the same cluster within the
+// window is suppressed, so that a flapping cluster only reports each state
+// once until the window has passed.
+type notificationThrottle struct {
+	mu sync.Mutex
+	// posted records when each notification was last posted, by cluster
+	// then by notification.
+	posted map[string]map[string]time.Time
+}
+
+// notifications throttles the lifecycle notifications and followers' updates.
+var notifications = &notificationThrottle{posted: map[string]map[string]time.Time{}}
+
+// allow reports whether the notification may be posted about the cluster at
+// now, and records it if so. A window of zero or less allows everything.
+func (t *notificationThrottle) allow(cluster, notification string, now time.Time, window time.Duration) bool {
+	if window <= 0 {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Forget what fell out of the window, for every cluster, so that
+	// clusters that are gone do not linger.
+	for name, posted := range t.posted {
+		for text, at := range posted {
+			if now.Sub(at) >= window {
+				delete(posted, text)
+			}
+		}
+		if len(posted) == 0 {
+			delete(t.posted, name)
+		}
+	}
+
+	if _, ok := t.posted[cluster][notification]; ok {
+		return false
+	}
+	if t.posted[cluster] == nil {
+		t.posted[cluster] = map[string]time.Time{}
+	}
+	t.posted[cluster][notification] = now
+	return true
+}
+
+// shouldNotify reports whether the notification about the cluster may be
+// posted now, given the configured notificationWindow. Suppressed ones are
+// logged.
+func shouldNotify(cluster, notification string) bool {
+	window := time.Duration(spoticusConfig.Get().NotificationWindow)
+	if notifications.allow(cluster, notification, time.Now(), window) {
+		return true
+	}
+	log.Printf("Suppressed a repeated notification about cluster %s within %s", cluster, window)
+	return false
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+// useNotificationThrottle starts the test with no notification posted.
+func useNotificationThrottle(t *testing.T) {
+	t.Helper()
+	previous := notifications
+	notifications = &notificationThrottle{posted: map[string]map[string]time.Time{}}
+	t.Cleanup(func() { notifications = previous })
+}
+
+func TestNotificationThrottleAllow(t *testing.T) {
+	throttle := &notificationThrottle{posted: map[string]map[string]time.Time{}}
+	window := 10 * time.Minute
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name          string
+		cluster, text string
+		at            time.Duration
+		want          bool
+	}{
+		{name: "first", cluster: "a", text: "failed", want: true},
+		{name: "repeat within the window", cluster: "a", text: "failed", at: time.Minute},
+		{name: "transition", cluster: "a", text: "ready", at: 2 * time.Minute, want: true},
+		{name: "flapping back", cluster: "a", text: "failed", at: 3 * time.Minute},
+		{name: "other cluster", cluster: "b", text: "failed", at: 4 * time.Minute, want: true},
+		{name: "after the window", cluster: "a", text: "failed", at: 10 * time.Minute, want: true},
+	}
+	for _, step := range steps {
+		if got := throttle.allow(step.cluster, step.text, now.Add(step.at), window); got != step.want {
+			t.Errorf("%s: allow(%s, %s) = %v, want %v", step.name, step.cluster, step.text, got, step.want)
+		}
+	}
+
+	throttle.allow("c", "ready", now.Add(time.Hour), window)
+	if _, ok := throttle.posted["b"]; ok {
+		t.Errorf("posted %v, want the clusters outside the window forgotten", throttle.posted)
+	}
+}
+
+func TestNotificationThrottleDisabled(t *testing.T) {
+	throttle := &notificationThrottle{posted: map[string]map[string]time.Time{}}
+	now := time.Now()
+	for range 3 {
+		if !throttle.allow("a", "failed", now, 0) {
+			t.Fatal("allow() with a zero window suppressed a notification")
+		}
+	}
+	if len(throttle.posted) != 0 {
+		t.Errorf("posted %v, want nothing recorded with a zero window", throttle.posted)
+	}
+}
+
+func TestNotifyCoalescesRepeats(t *testing.T) {
+	useConfig(t, testConfig())
+	useNotificationThrottle(t)
+	notifier := useNotifier(t)
+	api, server := newAPI(t)
+
+	failed := Notification{Event: EventFailed, Cluster: "spoticus-k8s-flapping", Channel: "C1", Text: "*spoticus-k8s-flapping* failed"}
+	ready := Notification{Event: EventReady, Cluster: "spoticus-k8s-flapping", Channel: "C1", Text: "*spoticus-k8s-flapping* is ready"}
+	for _, notification := range []Notification{failed, failed, ready, failed, ready} {
+		notify(api, notification)
+	}
+	notifier.waitFor(t, EventReady, "spoticus-k8s-flapping", time.Second)
+
+	if got := len(server.Messages()); got != 2 {
+		t.Errorf("posted %d messages, want the failure and the recovery once each", got)
+	}
+	time.Sleep(50 * time.Millisecond)
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	// Launches of other tests may still notify in the background.
+	delivered := 0
+	for _, notification := range notifier.notifications {
+		if notification.Cluster == failed.Cluster {
+			delivered++
+		}
+	}
+	if delivered != 2 {
+		t.Errorf("delivered %d notifications, want 2", delivered)
+	}
+}
+
+func TestNotifyFollowersCoalescesFlapping(t *testing.T) {
+	useConfig(t, testConfig())
+	useNotificationThrottle(t)
+	useFollows(t)
+	api, server := newAPI(t)
+	name := "spoticus-k8s-flapper"
+	follows[name] = &followedCluster{targets: []followTarget{{User: "U1", Channel: "C1", ThreadTS: "1.1"}}, phase: phaseReady}
+
+	now := time.Now()
+	for i, phase := range []string{phaseFailed, phaseReady, phaseFailed, phaseReady} {
+		cluster := ClusterInfo{Name: name, Phase: phase, Created: now}
+		notifyFollowers(api, clusterInventory{Clusters: []ClusterInfo{cluster}}, now.Add(time.Duration(i)*time.Minute))
+	}
+
+	server.WaitForMessage("is now *Failed*", time.Second)
+	server.WaitForMessage("is now *Ready*", time.Second)
+	if got := len(server.Messages()); got != 2 {
+		t.Errorf("posted %d updates to the follower, want each state once within the window", got)
+	}
+}
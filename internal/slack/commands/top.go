@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// maxTopResults is the largest count `top` accepts, keeping the reply short.
+const maxTopResults = 50
+
+// rankByCost returns the running clusters sorted by estimated hourly cost,
+// most expensive first. Failed clusters and clusters without a recorded cost
+// are left out; equal costs are ordered by name for a stable ranking.
+func rankByCost(clusters []ClusterInfo) []ClusterInfo {
+	var running []ClusterInfo
+	for _, cluster := range clusters {
+		if cluster.Phase != phaseFailed && cluster.Metadata().HourlyCost > 0 {
+			running = append(running, cluster)
+		}
+	}
+	slices.SortFunc(running, func(a, b ClusterInfo) int {
+		if c := cmp.Compare(b.Metadata().HourlyCost, a.Metadata().HourlyCost); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return running
+}
+
+// HandleTop is the entry point for the "top" Slack command.
+// `top [count]` lists the most expensive running clusters, topResults of
+// them unless a count is given.
+func HandleTop(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	usage := messages.Render(cfg.Messages.TopUsage, messages.Data{"Max": maxTopResults})
+
+	count := cfg.TopResults
+	switch len(args) {
+	case 0:
+	case 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > maxTopResults {
+			return invalid(usage)
+		}
+		count = n
+	default:
+		return invalid(usage)
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return connectError(err)
+	}
+
+	inventory, err := listClusters(ctx, client)
+	if len(inventory.Failed) == len(clusterTypeNames) {
+		return failure(err, cfg.Messages.ListFailed, "listing MAPT clusters for top")
+	}
+	if err != nil {
+		log.Printf("Error listing MAPT clusters for top: %v", err)
+	}
+
+	ranked := rankByCost(inventory.Clusters)
+	if len(ranked) == 0 {
+		respond.Text(api, event.Channel, cfg.Messages.TopNone)
+		return nil
+	}
+
+	now := time.Now()
+	shown := ranked[:min(count, len(ranked))]
+	var entries strings.Builder
+	for i, cluster := range shown {
+		metadata := cluster.Metadata()
+		size := metadata.Size
+		if size == "" {
+			size = metadata.InstanceType
+		}
+		data := messages.Data{
+			"Rank":       i + 1,
+			"Name":       cluster.Name,
+			"Owner":      metadata.Owner,
+			"Size":       size,
+			"HourlyCost": formatHourlyCost(metadata.HourlyCost),
+		}
+		if accumulated := accumulatedCost(metadata, cluster.Created, now); accumulated > 0 {
+			data["Accumulated"] = formatCost(accumulated)
+		}
+		entries.WriteString(messages.Render(cfg.Messages.TopEntry, data))
+	}
+
+	message := messages.Render(cfg.Messages.Top, messages.Data{
+		"Count":   len(shown),
+		"Total":   len(ranked),
+		"Entries": entries.String(),
+	})
+	for _, failed := range inventory.Failed {
+		message += "\n\n" + messages.Render(cfg.Messages.ListTypeFailed, messages.Data{"Type": failed})
+	}
+	respond.Text(api, event.Channel, message)
+	return nil
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// costing is a cluster in phase whose launch recorded hourlyCost.
+func costing(name, phase string, hourlyCost float64) ClusterInfo {
+	return ClusterInfo{Name: name, Phase: phase, Annotations: LaunchMetadata{HourlyCost: hourlyCost}.Annotations()}
+}
+
+func TestRankByCost(t *testing.T) {
+	inventory := []ClusterInfo{
+		costing("spoticus-k8s-medium", phaseReady, 0.15),
+		costing("spoticus-openshift-xlarge", phaseProvisioning, 0.60),
+		costing("spoticus-k8s-failed", phaseFailed, 1.20),
+		costing("spoticus-k8s-unpriced", phaseReady, 0),
+		costing("spoticus-k8s-large-b", phaseReady, 0.30),
+		costing("spoticus-k8s-large-a", "", 0.30),
+	}
+
+	var got []string
+	for _, cluster := range rankByCost(inventory) {
+		got = append(got, cluster.Name)
+	}
+	want := []string{"spoticus-openshift-xlarge", "spoticus-k8s-large-a", "spoticus-k8s-large-b", "spoticus-k8s-medium"}
+	if !slices.Equal(got, want) {
+		t.Errorf("rankByCost() = %v, want %v", got, want)
+	}
+	if ranked := rankByCost(nil); len(ranked) != 0 {
+		t.Errorf("rankByCost(nil) = %v, want no cluster", ranked)
+	}
+}
+
+func TestHandleTopRanksMostExpensive(t *testing.T) {
+	cfg := testConfig()
+	cfg.TopResults = 2
+	useConfig(t, cfg)
+	clusters := []struct {
+		name, owner, size string
+		cost              float64
+	}{
+		{"spoticus-k8s-top-medium", "U1", "medium", 0.15},
+		{"spoticus-k8s-top-xlarge", "U2", "xlarge", 0.60},
+		{"spoticus-k8s-top-large", "U3", "large", 0.30},
+		{"spoticus-k8s-top-unpriced", "U4", "medium", 0},
+	}
+	kube := slacktest.NewKube()
+	for _, c := range clusters {
+		cluster := existingCluster(c.name, c.owner, c.cost)
+		SetLaunchMetadata(cluster, LaunchMetadata{Size: c.size})
+		if err := kube.CrClient.Create(context.Background(), cluster); err != nil {
+			t.Fatalf("creating %s: %v", c.name, err)
+		}
+	}
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleTop(context.Background(), api, message("U1", "C1", "top"), nil); err != nil {
+		t.Fatalf("HandleTop: %v", err)
+	}
+	text := server.WaitForMessage("Top 2 clusters by cost", time.Second).Text()
+	if !strings.Contains(text, "(of 3 running)") {
+		t.Errorf("top %q, want the 3 clusters with a cost counted", text)
+	}
+	first := strings.Index(text, "1. *spoticus-k8s-top-xlarge* — $0.60/h")
+	second := strings.Index(text, "2. *spoticus-k8s-top-large* — $0.30/h")
+	if first < 0 || second < first {
+		t.Errorf("top %q, want xlarge then large", text)
+	}
+	for _, want := range []string{"· xlarge · <@U2>", "· large · <@U3>"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("top %q does not contain %q", text, want)
+		}
+	}
+	if strings.Contains(text, "spoticus-k8s-top-medium") {
+		t.Errorf("top %q shows more than the configured 2 clusters", text)
+	}
+
+	if err := HandleTop(context.Background(), api, message("U1", "C1", "top 10"), []string{"10"}); err != nil {
+		t.Fatalf("HandleTop: %v", err)
+	}
+	text = server.WaitForMessage("Top 3 clusters by cost", time.Second).Text()
+	if !strings.Contains(text, "3. *spoticus-k8s-top-medium*") || strings.Contains(text, "unpriced") {
+		t.Errorf("top %q, want every cluster with a cost and only those", text)
+	}
+}
+
+func TestHandleTopWithoutCosts(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-free", "U1", 0)))
+	api, server := newAPI(t)
+
+	if err := HandleTop(context.Background(), api, message("U1", "C1", "top"), nil); err != nil {
+		t.Fatalf("HandleTop: %v", err)
+	}
+	server.WaitForMessage("No running cluster has a recorded cost", time.Second)
+}
+
+func TestHandleTopRejectsBadCounts(t *testing.T) {
+	useConfig(t, testConfig())
+	api, _ := newAPI(t)
+
+	for _, args := range [][]string{{"0"}, {"51"}, {"five"}, {"1", "2"}} {
+		err := HandleTop(context.Background(), api, message("U1", "C1", "top "+strings.Join(args, " ")), args)
+		var cmdErr *CommandError
+		if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "from 1 to 50") {
+			t.Errorf("HandleTop(%q) = %v, want the usage", args, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// upload uploads a file within the configured upload limits.
+func upload(ctx context.Context, api *slack.Client, params slack.UploadFileV2Parameters) error {
+	limits := spoticusConfig.Get().Uploads
+	return respond.Upload(ctx, api, params, time.Duration(limits.Timeout), limits.MaxBytes)
+}
+
+// uploadError is the failure of an upload, explaining why it failed: the
+// file was too large, the upload timed out, or fallback for other failures.
+// The format and args describe the upload in the logs.
+func uploadError(err error, fallback, format string, args ...any) error {
+	limits := spoticusConfig.Get().Uploads
+	cmdErr := &CommandError{
+		Category: CategoryBackend,
+		Message:  fallback,
+		Log:      fmt.Sprintf(format, args...) + ": " + err.Error(),
+		Err:      err,
+	}
+	switch {
+	case errors.Is(err, respond.ErrFileTooLarge):
+		cmdErr.Category = CategoryValidation
+		cmdErr.Message = messages.Render(msgs().UploadTooLarge, messages.Data{"Max": formatBytes(limits.MaxBytes)})
+	case errors.Is(err, context.DeadlineExceeded):
+		cmdErr.Category = CategoryTimeout
+		cmdErr.Message = messages.Render(msgs().UploadTimedOut, messages.Data{"Timeout": messages.FormatDuration(time.Duration(limits.Timeout))})
+	}
+	return cmdErr
+}
+
+// formatBytes renders a size in bytes with a binary unit, e.g. "10 MiB".
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MiB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KiB", n>>10)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
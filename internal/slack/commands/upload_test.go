@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 10 << 20, want: "10 MiB"},
+		{n: 512 << 10, want: "512 KiB"},
+		{n: 1500, want: "1500 bytes"},
+		{n: 1 << 20, want: "1 MiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestExportRejectsOversizedFile(t *testing.T) {
+	cfg := testConfig()
+	cfg.Uploads = spoticusConfig.Uploads{Timeout: spoticusConfig.Duration(time.Second), MaxBytes: 16}
+	useConfig(t, cfg)
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-big", "U1", 0.15)))
+	api, server := newAPI(t)
+
+	err := HandleExport(context.Background(), api, message("U1", "C1", "export"), nil)
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryValidation || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "larger than the 16 bytes upload limit") {
+		t.Errorf("HandleExport() = %v, want the file too large", err)
+	}
+	if calls := server.Calls("files.getUploadURLExternal"); len(calls) != 0 {
+		t.Errorf("started %d uploads, want none", len(calls))
+	}
+}
+
+func TestExportUploadTimesOut(t *testing.T) {
+	cfg := testConfig()
+	cfg.Uploads = spoticusConfig.Uploads{Timeout: spoticusConfig.Duration(20 * time.Millisecond), MaxBytes: 1 << 20}
+	useConfig(t, cfg)
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-slow", "U1", 0.15)))
+	api, server := newAPI(t)
+	server.Handle("files.getUploadURLExternal", func(url.Values) map[string]any {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+
+	err := HandleExport(context.Background(), api, message("U1", "C1", "export"), nil)
+	var cmdErr *CommandError
+	if CategoryOf(err) != CategoryTimeout || !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "took longer than") {
+		t.Errorf("HandleExport() = %v, want the upload timed out", err)
+	}
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/health"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+const (
+	// backendProbeTimeout bounds how long a probe waits for the Kubernetes API server.
+	backendProbeTimeout = 5 * time.Second
+
+	// backendProbeInterval is how often WatchBackend probes the backend.
+	backendProbeInterval = 30 * time.Second
+)
+
+// WatchBackend probes the Kubernetes backend every backendProbeInterval
+// until ctx ends, recording its readiness in the health tracker. Commands
+// that need the backend are refused while it is not ready.
+func WatchBackend(ctx context.Context) {
+	ticker := time.NewTicker(backendProbeInterval)
+	defer ticker.Stop()
+	for {
+		_, err := probeBackend(ctx, spoticusConfig.Get())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Kubernetes backend probe failed: %v", err)
+		}
+		health.Default().SetBackend(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// BackendReady reports whether the last probe found the backend ready.
+func BackendReady() bool {
+	return health.Default().BackendReady()
+}
+
+// probeBackend reads the configured namespace from the Kubernetes API server
+// and returns how long it took.
+func probeBackend(ctx context.Context, cfg *spoticusConfig.Config) (time.Duration, error) {
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, backendProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.KubeClient.CoreV1().Namespaces().Get(ctx, cfg.Namespace, metav1.GetOptions{})
+	return time.Since(start), err
+}
+
+// HandleUptime is the entry point for the "uptime" Slack command.
+//
+// It reports a quick health snapshot: how long the bot has been running,
+// the state of the Slack connection, the events processed so far and
+// whether the Kubernetes backend is reachable.
+func HandleUptime(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	snapshot := health.Default().Snapshot()
+
+	data := messages.Data{
+		"Started":    humanizeAge(snapshot.Started),
+		"Since":      snapshot.Started.Format(timestampLayout),
+		"State":      snapshot.State,
+		"Reconnects": snapshot.Reconnects,
+		"Events":     snapshot.Events,
+		"Backend":    backendStatus(ctx, cfg),
+	}
+	if !snapshot.ConnectedAt.IsZero() {
+		data["ConnectedAt"] = humanizeAge(snapshot.ConnectedAt)
+	}
+	if !snapshot.LastReconnect.IsZero() {
+		data["LastReconnect"] = humanizeAge(snapshot.LastReconnect)
+	}
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.Uptime, data))
+	return nil
+}
+
+// backendStatus probes the Kubernetes API server, records the result in the
+// health tracker and describes it.
+func backendStatus(ctx context.Context, cfg *spoticusConfig.Config) string {
+	latency, err := probeBackend(ctx, cfg)
+	health.Default().SetBackend(err)
+	if err == nil {
+		return messages.Render(cfg.Messages.UptimeBackendUp, messages.Data{
+			"Latency": latency.Round(time.Millisecond).String(),
+		})
+	}
+
+	log.Printf("Kubernetes backend probe failed: %v", err)
+	if backendBreaker.State() == breakerOpen {
+		return cfg.Messages.UptimeBackendOpen
+	}
+	return cfg.Messages.UptimeBackendDown
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/flacatus/spoticus/internal/health"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestUptimeReportsBackendReachable(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKube()
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	if _, err := kube.KubeClient.CoreV1().Namespaces().Create(context.Background(), namespace, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	useKube(t, kube)
+	api, server := newAPI(t)
+	health.Default().EventProcessed()
+	events := health.Default().Snapshot().Events
+
+	if err := HandleUptime(context.Background(), api, message("U1", "C1", "uptime"), nil); err != nil {
+		t.Fatalf("HandleUptime: %v", err)
+	}
+	text := server.WaitForMessage("health", time.Second).Text()
+	for _, want := range []string{fmt.Sprintf("Events processed: %d", events), "Kubernetes backend: reachable ("} {
+		if !strings.Contains(text, want) {
+			t.Errorf("uptime %q does not contain %q", text, want)
+		}
+	}
+}
+
+func TestUptimeReportsBackendUnreachable(t *testing.T) {
+	useConfig(t, testConfig())
+	useKube(t, slacktest.NewKube())
+	t.Cleanup(func() { health.Default().SetBackend(nil) })
+	api, server := newAPI(t)
+
+	if err := HandleUptime(context.Background(), api, message("U1", "C1", "uptime"), nil); err != nil {
+		t.Fatalf("HandleUptime: %v", err)
+	}
+	text := server.WaitForMessage("health", time.Second).Text()
+	if !strings.Contains(text, "Kubernetes backend: unreachable") {
+		t.Errorf("uptime %q does not report the backend unreachable", text)
+	}
+	if BackendReady() {
+		t.Errorf("BackendReady() = true after a failed probe")
+	}
+}
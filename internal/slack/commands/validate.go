@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// HandleValidate handles the "validate <launch args>" command. It runs the
+// checks of `launch` — arguments, permissions, quota and budget — and reports
+// what the launch would do, or every check it would fail, without creating
+// or scheduling anything.
+func HandleValidate(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	if len(args) == 0 {
+		return invalid(msgs().ValidateUsage)
+	}
+
+	command := "launch " + strings.Join(args, " ")
+	req, runAt, problems := validateLaunch(ctx, event, args)
+	if len(problems) > 0 {
+		respond.Text(api, event.Channel, messages.Render(msgs().ValidateFailed, messages.Data{
+			"Command":  command,
+			"Problems": "• " + strings.Join(problems, "\n• "),
+		}))
+		return nil
+	}
+
+	data := messages.Data{
+		"Command":      command,
+		"Type":         req.Type,
+		"Size":         req.Size,
+		"InstanceType": req.InstanceType,
+		"CPU":          req.Resources().CPU(),
+		"RAM":          req.Resources().RAM(),
+		"Version":      req.Version,
+		"Cost":         formatHourlyCost(req.Spec.HourlyCost),
+		"Defaults":     strings.Join(req.ChannelDefaults, ", "),
+		"Confirm":      requiresConfirmation(spoticusConfig.Get(), req),
+	}
+	if !runAt.IsZero() {
+		data["RunAt"] = runAt.Format(timestampLayout)
+	}
+	respond.Text(api, event.Channel, messages.Render(msgs().ValidateOK, data))
+	return nil
+}
+
+// validateLaunch runs the checks a launch with args would go through and
+// returns the parsed request, when it parses, and the problems found. The
+// permission, quota and budget checks run even when the arguments are
+// invalid, so that every problem is reported at once.
+func validateLaunch(ctx context.Context, event *slackevents.MessageEvent, args []string) (*LaunchRequest, time.Time, []string) {
+	cfg := spoticusConfig.Get()
+	var problems []string
+
+	args, err := normalizeLaunchArgs(args)
+	if err != nil {
+		return nil, time.Time{}, []string{err.Error()}
+	}
+	args, err = expandPreset(ctx, event.User, args)
+	if err != nil {
+		return nil, time.Time{}, []string{err.Error()}
+	}
+	args, runAt, err := extractSchedule(args, time.Now())
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	req, err := parseLaunchArgs(args, event.Channel)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if req != nil && req.OverrideBudget && !cfg.IsAdmin(event.User) {
+		problems = append(problems, cfg.Messages.BudgetDenied)
+	}
+
+	limit := quotaLimit(event.User)
+	if limit == 0 && cfg.Budget.HourlyCap <= 0 {
+		return req, runAt, problems
+	}
+	client, err := GetKubernetesClient()
+	if err != nil {
+		log.Printf("Error getting kubernetes client: %v", err)
+		return req, runAt, append(problems, backendError(err, cfg.Messages.ConnectFailed))
+	}
+	inventory, err := listClusters(ctx, client)
+	if err != nil {
+		log.Printf("Error listing MAPT clusters for quota and budget checks: %v", err)
+		return req, runAt, append(problems, backendError(err, cfg.Messages.ListFailed))
+	}
+	if usage := quotaUsage(inventory, event.User); limit > 0 && usage >= limit {
+		problems = append(problems, messages.Render(cfg.Messages.QuotaExceeded, messages.Data{
+			"Usage": usage,
+			"Limit": limit,
+		}))
+	}
+	if req != nil && !req.OverrideBudget {
+		if current := budgetUsage(inventory); exceedsBudget(current, req.Spec.HourlyCost, cfg.Budget.HourlyCap) {
+			problems = append(problems, messages.Render(cfg.Messages.BudgetExceeded, messages.Data{
+				"Current":   formatHourlyCost(current),
+				"Projected": formatHourlyCost(current + req.Spec.HourlyCost),
+				"Cap":       formatHourlyCost(cfg.Budget.HourlyCap),
+			}))
+		}
+	}
+	return req, runAt, problems
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestValidateReportsWhatWouldLaunch(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKube()
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleValidate(context.Background(), api, message("U1", "C1", "validate k8s xlarge --in=2h"), []string{"k8s", "xlarge", "--in=2h"}); err != nil {
+		t.Fatalf("HandleValidate: %v", err)
+	}
+
+	text := server.WaitForMessage("is valid", time.Second).Text()
+	for _, want := range []string{"`launch k8s xlarge --in=2h`", "*k8s* cluster of size *xlarge* (32 CPUs, 128 GB RAM", "at version ", "scheduled for", "confirmed with `launch confirm`", "Nothing was created."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("reply %q does not contain %q", text, want)
+		}
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 0 {
+		t.Errorf("validate created %d clusters, want none", len(kinds))
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := testConfig()
+	cfg.Quota.Default = 1
+	cfg.Budget.HourlyCap = 0.20
+	useConfig(t, cfg)
+	kube := slacktest.NewKube(existingCluster("spoticus-k8s-validate", "U1", 0.15))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	args := []string{"k8s", "medium", "--override-budget", "--in=90d"}
+	if err := HandleValidate(context.Background(), api, message("U1", "C1", "validate "+strings.Join(args, " ")), args); err != nil {
+		t.Fatalf("HandleValidate: %v", err)
+	}
+
+	text := server.WaitForMessage("would fail", time.Second).Text()
+	for _, want := range []string{
+		"Only bot administrators can use `--override-budget`",
+		"Quota exceeded",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("reply %q does not report %q", text, want)
+		}
+	}
+	if got := strings.Count(text, "\n• "); got != 3 {
+		t.Errorf("reply %q lists %d problems, want the schedule, the budget override and the quota", text, got)
+	}
+	if kinds := launchedKinds(t, kube); len(kinds) != 1 {
+		t.Errorf("got %d clusters, want only the existing one", len(kinds))
+	}
+}
+
+func TestValidateReportsBudget(t *testing.T) {
+	cfg := testConfig()
+	cfg.Budget.HourlyCap = 0.20
+	useConfig(t, cfg)
+	useKube(t, slacktest.NewKube(existingCluster("spoticus-k8s-validate-costly", "U2", 0.15)))
+	api, server := newAPI(t)
+
+	if err := HandleValidate(context.Background(), api, message("U1", "C1", "validate k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleValidate: %v", err)
+	}
+	server.WaitForMessage("Budget cap reached", time.Second)
+}
+
+func TestValidateUsage(t *testing.T) {
+	useConfig(t, testConfig())
+	api, _ := newAPI(t)
+
+	err := HandleValidate(context.Background(), api, message("U1", "C1", "validate"), nil)
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "Usage: `validate <launch arguments>`") {
+		t.Errorf("HandleValidate() = %v, want the usage", err)
+	}
+}
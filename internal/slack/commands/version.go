@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+const (
+	// maptVersionCacheTTL is how long the operator version is reused before
+	// the deployment is read again.
+	maptVersionCacheTTL = 5 * time.Minute
+
+	// maptManagerContainer is the container of the operator deployment
+	// running the controller, as scaffolded by kubebuilder.
+	maptManagerContainer = "manager"
+
+	// labelVersion is the recommended label carrying an application's version.
+	labelVersion = "app.kubernetes.io/version"
+)
+
+// maptOperatorInfo is what the `version` command reports about the operator.
+type maptOperatorInfo struct {
+	Version string
+	Image   string
+	Ready   string
+}
+
+var (
+	maptVersionMu sync.Mutex
+	// maptVersionCache holds the last operator info read, for the deployment
+	// it was read from.
+	maptVersionCache struct {
+		operator spoticusConfig.MaptOperator
+		info     maptOperatorInfo
+		fetched  time.Time
+	}
+)
+
+// HandleVersion is the entry point for the "version" Slack command.
+// It reports the version of the MAPT operator deployment and the MAPT API
+// the bot supports.
+func HandleVersion(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := spoticusConfig.Get()
+	operator := cfg.MaptOperator
+	data := messages.Data{
+		"Namespace":  operator.Namespace,
+		"Deployment": operator.Deployment,
+		"APIVersion": maptApi.GroupVersion.String(),
+		"Kinds":      formatList(slices.Sorted(maps.Values(clusterKinds))),
+	}
+
+	info, err := maptOperatorVersion(ctx, operator, time.Now())
+	switch {
+	case apierrors.IsNotFound(err):
+		respond.Text(api, event.Channel, messages.Render(cfg.Messages.MaptVersionNotFound, data))
+		return nil
+	case err != nil:
+		return failure(err, cfg.Messages.MaptVersionFailed, "reading MAPT operator deployment %s/%s", operator.Namespace, operator.Deployment)
+	}
+
+	data["Version"] = info.Version
+	data["Image"] = info.Image
+	data["Ready"] = info.Ready
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.MaptVersion, data))
+	return nil
+}
+
+// maptOperatorVersion returns the operator info, from the cache when it was
+// read less than maptVersionCacheTTL before now.
+func maptOperatorVersion(ctx context.Context, operator spoticusConfig.MaptOperator, now time.Time) (maptOperatorInfo, error) {
+	maptVersionMu.Lock()
+	defer maptVersionMu.Unlock()
+
+	cache := &maptVersionCache
+	if cache.operator == operator && now.Sub(cache.fetched) < maptVersionCacheTTL {
+		return cache.info, nil
+	}
+
+	client, err := GetKubernetesClient()
+	if err != nil {
+		return maptOperatorInfo{}, err
+	}
+	deployment, err := client.KubeClient.AppsV1().Deployments(operator.Namespace).Get(ctx, operator.Deployment, metav1.GetOptions{})
+	if err != nil {
+		return maptOperatorInfo{}, err
+	}
+
+	info := operatorInfo(deployment)
+	cache.operator, cache.info, cache.fetched = operator, info, now
+	return info, nil
+}
+
+// operatorInfo extracts the version of the operator from its deployment:
+// the app.kubernetes.io/version label when set, otherwise the tag of the
+// manager container's image.
+func operatorInfo(deployment *appsv1.Deployment) maptOperatorInfo {
+	info := maptOperatorInfo{Version: "unknown"}
+
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) > 0 {
+		info.Image = containers[0].Image
+	}
+	for _, container := range containers {
+		if container.Name == maptManagerContainer {
+			info.Image = container.Image
+		}
+	}
+	if version := deployment.Labels[labelVersion]; version != "" {
+		info.Version = version
+	} else if tag := imageTag(info.Image); tag != "" {
+		info.Version = tag
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	info.Ready = fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, replicas)
+	return info
+}
+
+// imageTag returns the tag of an image reference, or "" when it has none or
+// is pinned by digest.
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, ok := strings.Cut(name, ":"); ok {
+		return tag
+	}
+	return ""
+}
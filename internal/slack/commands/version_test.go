@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// useMaptVersionCache starts the test with nothing cached, and restores the
+// cache when it ends.
+func useMaptVersionCache(t *testing.T) {
+	t.Helper()
+	maptVersionMu.Lock()
+	previous := maptVersionCache
+	maptVersionCache.fetched = time.Time{}
+	maptVersionMu.Unlock()
+	t.Cleanup(func() {
+		maptVersionMu.Lock()
+		maptVersionCache = previous
+		maptVersionMu.Unlock()
+	})
+}
+
+// operatorDeployment is a MAPT operator deployment as kubebuilder scaffolds
+// it, running image with ready of its two replicas.
+func operatorDeployment(image string, ready int32) *appsv1.Deployment {
+	replicas := int32(2)
+	operator := spoticusConfig.Default().MaptOperator
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: operator.Deployment, Namespace: operator.Namespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "kube-rbac-proxy", Image: "gcr.io/kubebuilder/kube-rbac-proxy:v0.15.0"},
+				{Name: maptManagerContainer, Image: image},
+			}}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: ready},
+	}
+}
+
+func TestOperatorInfo(t *testing.T) {
+	labelled := operatorDeployment("quay.io/redhat-developer/mapt-operator:v0.9.0", 2)
+	labelled.Labels = map[string]string{labelVersion: "v1.0.0"}
+	noReplicas := operatorDeployment("quay.io/redhat-developer/mapt-operator:v0.9.0", 1)
+	noReplicas.Spec.Replicas = nil
+	unnamed := operatorDeployment("", 0)
+	unnamed.Spec.Template.Spec.Containers = []corev1.Container{{Name: "operator", Image: "mapt-operator:v0.8.1"}}
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		want       maptOperatorInfo
+	}{
+		{name: "manager image tag", deployment: operatorDeployment("quay.io/redhat-developer/mapt-operator:v0.9.0", 1), want: maptOperatorInfo{Version: "v0.9.0", Image: "quay.io/redhat-developer/mapt-operator:v0.9.0", Ready: "1/2"}},
+		{name: "version label", deployment: labelled, want: maptOperatorInfo{Version: "v1.0.0", Image: "quay.io/redhat-developer/mapt-operator:v0.9.0", Ready: "2/2"}},
+		{name: "pinned by digest", deployment: operatorDeployment("quay.io/mapt-operator@sha256:abc", 2), want: maptOperatorInfo{Version: "unknown", Image: "quay.io/mapt-operator@sha256:abc", Ready: "2/2"}},
+		{name: "registry port without tag", deployment: operatorDeployment("localhost:5000/mapt-operator", 0), want: maptOperatorInfo{Version: "unknown", Image: "localhost:5000/mapt-operator", Ready: "0/2"}},
+		{name: "default replicas", deployment: noReplicas, want: maptOperatorInfo{Version: "v0.9.0", Image: "quay.io/redhat-developer/mapt-operator:v0.9.0", Ready: "1/1"}},
+		{name: "first container without a manager", deployment: unnamed, want: maptOperatorInfo{Version: "v0.8.1", Image: "mapt-operator:v0.8.1", Ready: "0/2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := operatorInfo(tt.deployment); got != tt.want {
+				t.Errorf("operatorInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleVersionReportsOperator(t *testing.T) {
+	useConfig(t, testConfig())
+	useMaptVersionCache(t)
+	kube := slacktest.NewKube()
+	if _, err := kube.KubeClient.AppsV1().Deployments("mapt-operator-system").Create(context.Background(), operatorDeployment("quay.io/redhat-developer/mapt-operator:v0.9.0", 2), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("creating the operator deployment: %v", err)
+	}
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleVersion(context.Background(), api, message("U1", "C1", "version"), nil); err != nil {
+		t.Fatalf("HandleVersion: %v", err)
+	}
+
+	text := server.WaitForMessage("MAPT operator", time.Second).Text()
+	for _, want := range []string{"v0.9.0", "`mapt-operator-system/mapt-operator-controller-manager` (2/2 ready)", "quay.io/redhat-developer/mapt-operator:v0.9.0", "`mapt.redhat.com/v1alpha1`"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("version %q does not contain %q", text, want)
+		}
+	}
+}
+
+func TestMaptOperatorVersionIsCached(t *testing.T) {
+	useConfig(t, testConfig())
+	useMaptVersionCache(t)
+	kube := slacktest.NewKube()
+	deployments := kube.KubeClient.AppsV1().Deployments("mapt-operator-system")
+	if _, err := deployments.Create(context.Background(), operatorDeployment("mapt-operator:v0.9.0", 1), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("creating the operator deployment: %v", err)
+	}
+	useKube(t, kube)
+	operator := spoticusConfig.Default().MaptOperator
+	now := time.Now()
+
+	if _, err := maptOperatorVersion(context.Background(), operator, now); err != nil {
+		t.Fatalf("maptOperatorVersion: %v", err)
+	}
+	if err := deployments.Delete(context.Background(), operator.Deployment, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("deleting the operator deployment: %v", err)
+	}
+
+	info, err := maptOperatorVersion(context.Background(), operator, now.Add(maptVersionCacheTTL/2))
+	if err != nil || info.Version != "v0.9.0" {
+		t.Errorf("maptOperatorVersion() within the cache TTL = %+v, %v, want the cached v0.9.0", info, err)
+	}
+	if _, err := maptOperatorVersion(context.Background(), operator, now.Add(maptVersionCacheTTL)); !apierrors.IsNotFound(err) {
+		t.Errorf("maptOperatorVersion() after the cache TTL error = %v, want the deployment read again and not found", err)
+	}
+}
+
+func TestHandleVersionOperatorNotFound(t *testing.T) {
+	useConfig(t, testConfig())
+	useMaptVersionCache(t)
+	useKube(t, slacktest.NewKube())
+	api, server := newAPI(t)
+
+	if err := HandleVersion(context.Background(), api, message("U1", "C1", "version"), nil); err != nil {
+		t.Fatalf("HandleVersion: %v", err)
+	}
+
+	text := server.WaitForMessage("was not found", time.Second).Text()
+	if !strings.Contains(text, "`mapt-operator-system/mapt-operator-controller-manager`") || !strings.Contains(text, "mapt.redhat.com/v1alpha1") {
+		t.Errorf("reply %q, want the deployment looked for and the supported API", text)
+	}
+}
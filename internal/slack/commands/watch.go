@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"github.com/slack-go/slack"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// launchWatchTimeout bounds how long a launch is watched before giving up,
+// for sizes without a provisioning timeout and explicit instance types.
+const launchWatchTimeout = 45 * time.Minute
+
+// provisionTimeout returns how long the launch is watched before the user is
+// told it is taking too long: the timeout of its size, or launchWatchTimeout.
+func provisionTimeout(req *LaunchRequest) time.Duration {
+	if timeout := time.Duration(req.Spec.ProvisionTimeout); timeout > 0 {
+		return timeout
+	}
+	return launchWatchTimeout
+}
+
+// pollDelay returns how long a watcher waits before its next poll after the
+// given number of consecutive failed polls: the base interval, doubled for
+// every failure up to the maximum backoff, then spread by the jitter. random
+// is a number in [0, 1).
+func pollDelay(polling spoticusConfig.Polling, failures int, random float64) time.Duration {
+	delay := time.Duration(polling.Interval)
+	ceiling := max(time.Duration(polling.MaxBackoff), delay)
+	for i := 0; i < failures && delay < ceiling; i++ {
+		delay *= 2
+	}
+	delay = min(delay, ceiling)
+
+	spread := (2*random - 1) * polling.Jitter
+	return time.Duration(float64(delay) * (1 + spread))
+}
+
+// nextPoll returns a channel receiving the time once the next poll is due,
+// according to the current polling configuration.
+func nextPoll(failures int) <-chan time.Time {
+	return time.After(pollDelay(spoticusConfig.Get().Polling, failures, rand.Float64()))
+}
+
+// waitForCluster polls the MAPT resource until it reports Ready or Failed,
+// returning that phase, or until it is gone, returning phaseDeleted. It
+// returns the context's error if ctx ends first. Transient errors reading the
+// resource are logged and retried with backoff.
+// onPoll, if set, is called with the phase observed at each poll that does
+// not end the wait.
+func waitForCluster(ctx context.Context, client *KubernetesClients, cluster *unstructured.Unstructured, onPoll func(phase string)) (string, error) {
+	key := crclient.ObjectKeyFromObject(cluster)
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-nextPoll(failures):
+		}
+
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(cluster.GroupVersionKind())
+		err := client.CrClient.Get(ctx, key, current)
+		if apierrors.IsNotFound(err) {
+			return phaseDeleted, nil
+		}
+		if err != nil {
+			log.Printf("Error checking status of MAPT cluster %s: %v", key.Name, err)
+			failures++
+			continue
+		}
+		failures = 0
+		phase := clusterPhase(current.Object)
+		if phase == phaseReady || phase == phaseFailed {
+			return phase, nil
+		}
+		if onPoll != nil {
+			onPoll(phase)
+		}
+	}
+}
+
+// launchProgress keeps a message in the launch thread up to date with the
+// phase and elapsed time of a cluster being provisioned. The message is
+// edited when the phase changes, and otherwise at most once per configured
+// progress interval so that long provisions do not run into Slack's rate
+// limits.
+type launchProgress struct {
+	api       *slack.Client
+	channel   string
+	timestamp string
+	name      string
+	started   time.Time
+
+	phase   string
+	updated time.Time
+}
+
+// startLaunchProgress posts the initial progress message in the thread.
+// Progress is not reported if the message cannot be posted.
+func startLaunchProgress(api *slack.Client, channel, threadTS, name string) *launchProgress {
+	p := &launchProgress{api: api, channel: channel, name: name, started: time.Now(), phase: phaseProvisioning}
+	_, ts, err := respond.Post(api, channel, slack.MsgOptionText(p.text(), false), slack.MsgOptionTS(threadTS))
+	if err != nil {
+		log.Printf("Error posting launch progress for %s: %v", name, err)
+		return p
+	}
+	p.timestamp = ts
+	p.updated = p.started
+	return p
+}
+
+// Observe records the phase seen at a poll, editing the message if the phase
+// changed or the progress interval has passed since the last edit.
+func (p *launchProgress) Observe(phase string) {
+	if phase == phaseUnknown {
+		phase = phaseProvisioning
+	}
+	interval := time.Duration(spoticusConfig.Get().ProgressInterval)
+	if phase == p.phase && time.Since(p.updated) < interval {
+		return
+	}
+	p.phase = phase
+	p.update()
+}
+
+// Finish edits the message a last time with the final phase.
+func (p *launchProgress) Finish(phase string) {
+	p.phase = phase
+	p.update()
+}
+
+func (p *launchProgress) update() {
+	if p.timestamp == "" {
+		return
+	}
+	p.updated = time.Now()
+	if err := respond.Update(p.api, p.channel, p.timestamp, p.text()); err != nil {
+		log.Printf("Error updating launch progress for %s: %v", p.name, err)
+	}
+}
+
+func (p *launchProgress) text() string {
+	return messages.Render(msgs().LaunchProgress, messages.Data{
+		"Name":    p.name,
+		"Phase":   p.phase,
+		"Elapsed": messages.FormatDuration(time.Since(p.started)),
+	})
+}
@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	spoticusConfig "github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestWaitForClusterReturnsFinalPhase(t *testing.T) {
+	useConfig(t, testConfig())
+	cluster := existingCluster("spoticus-k8s-ready", "U1", 0)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseReady), cluster)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	phase, err := waitForCluster(ctx, fakeClients(kube), cluster, nil)
+	if err != nil || phase != phaseReady {
+		t.Errorf("waitForCluster() = %q, %v, want %q", phase, err, phaseReady)
+	}
+}
+
+func TestWaitForClusterEndsWhenDeleted(t *testing.T) {
+	useConfig(t, testConfig())
+	cluster := existingCluster("spoticus-k8s-gone", "U1", 0)
+	kube := slacktest.NewKube()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	phase, err := waitForCluster(ctx, fakeClients(kube), cluster, nil)
+	if err != nil || phase != phaseDeleted {
+		t.Errorf("waitForCluster() = %q, %v, want %q", phase, err, phaseDeleted)
+	}
+}
+
+func TestWaitForClusterRetriesTransientErrors(t *testing.T) {
+	useConfig(t, testConfig())
+	cluster := existingCluster("spoticus-k8s-flaky", "U1", 0)
+	failures := 2
+	ready := slacktest.ReportPhase(phaseReady)
+	kube := slacktest.NewKubeWithInterceptor(interceptor.Funcs{
+		Get: func(ctx context.Context, client crclient.WithWatch, key crclient.ObjectKey, obj crclient.Object, opts ...crclient.GetOption) error {
+			if failures > 0 {
+				failures--
+				return apierrors.NewServiceUnavailable("etcd is down")
+			}
+			return ready.Get(ctx, client, key, obj, opts...)
+		},
+	}, cluster)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	phase, err := waitForCluster(ctx, fakeClients(kube), cluster, nil)
+	if err != nil || phase != phaseReady {
+		t.Errorf("waitForCluster() = %q, %v, want %q after the failed polls", phase, err, phaseReady)
+	}
+	if failures != 0 {
+		t.Errorf("%d failed polls left, want them all retried", failures)
+	}
+}
+
+func TestWaitForClusterStopsWithContext(t *testing.T) {
+	useConfig(t, testConfig())
+	cluster := existingCluster("spoticus-k8s-slow", "U1", 0)
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase(phaseProvisioning), cluster)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var polled []string
+	_, err := waitForCluster(ctx, fakeClients(kube), cluster, func(phase string) { polled = append(polled, phase) })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waitForCluster() error = %v, want the deadline", err)
+	}
+	if len(polled) == 0 || polled[0] != phaseProvisioning {
+		t.Errorf("observed phases %v, want %s reported", polled, phaseProvisioning)
+	}
+}
+
+func TestLaunchReportsClusterDeletedWhileProvisioning(t *testing.T) {
+	useConfig(t, testConfig())
+	var deleted atomic.Bool
+	provisioning := slacktest.ReportPhase(phaseProvisioning)
+	kube := slacktest.NewKubeWithInterceptor(interceptor.Funcs{
+		Get: func(ctx context.Context, client crclient.WithWatch, key crclient.ObjectKey, obj crclient.Object, opts ...crclient.GetOption) error {
+			if deleted.Load() {
+				return apierrors.NewNotFound(schema.GroupResource{Group: "mapt.redhat.com", Resource: "kinds"}, key.Name)
+			}
+			deleted.Store(true)
+			return provisioning.Get(ctx, client, key, obj, opts...)
+		},
+	})
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U3", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+
+	server.WaitForMessage("was deleted before it was ready", 5*time.Second)
+	for _, call := range server.Messages() {
+		if strings.Contains(call.Text(), "still not ready") {
+			t.Errorf("posted %q, want no watch timeout", call.Text())
+		}
+	}
+}
+
+// reportPhases makes the MAPT resources read unstructured report the phases
+// in turn, as the operator would move through them, then stay in the last one.
+func reportPhases(phases ...string) interceptor.Funcs {
+	var reads atomic.Int32
+	return interceptor.Funcs{
+		Get: func(ctx context.Context, client crclient.WithWatch, key crclient.ObjectKey, obj crclient.Object, opts ...crclient.GetOption) error {
+			if err := client.Get(ctx, key, obj, opts...); err != nil {
+				return err
+			}
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return nil
+			}
+			i := min(int(reads.Add(1))-1, len(phases)-1)
+			return unstructured.SetNestedField(u.Object, phases[i], "status", "phase")
+		},
+	}
+}
+
+// progressPhasePattern extracts the phase from a launch progress message.
+var progressPhasePattern = regexp.MustCompile(`\*: (\w+)…`)
+
+// progressUpdates returns the texts of the edits of the launch progress message.
+func progressUpdates(server *slacktest.Server) []string {
+	var texts []string
+	for _, call := range server.Calls("chat.update") {
+		texts = append(texts, call.Values.Get("text"))
+	}
+	return texts
+}
+
+func TestLaunchProgressFollowsPhases(t *testing.T) {
+	useConfig(t, testConfig())
+	kube := slacktest.NewKubeWithInterceptor(reportPhases("", "Provisioning", "Installing", "Installing", phaseReady))
+	useKube(t, kube)
+	api, server := newAPI(t)
+
+	if err := HandleLaunch(context.Background(), api, message("U11", "C1", "launch k8s medium"), []string{"k8s", "medium"}); err != nil {
+		t.Fatalf("HandleLaunch: %v", err)
+	}
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	var phases []string
+	for _, text := range progressUpdates(server) {
+		match := progressPhasePattern.FindStringSubmatch(text)
+		if match == nil {
+			t.Fatalf("progress update %q does not show a phase", text)
+		}
+		if len(phases) == 0 || phases[len(phases)-1] != match[1] {
+			phases = append(phases, match[1])
+		}
+	}
+	if want := []string{"Installing", phaseReady}; !slices.Equal(phases, want) {
+		t.Errorf("progress went through %q, want %q", phases, want)
+	}
+	posted := server.Calls("chat.postMessage")
+	if len(posted) < 2 || !strings.Contains(posted[1].Values.Get("text"), "Provisioning… ") || posted[1].Values.Get("thread_ts") == "" {
+		t.Errorf("initial progress message not posted in the launch thread: %v", posted)
+	}
+}
+
+func TestLaunchProgressThrottlesUpdates(t *testing.T) {
+	cfg := testConfig()
+	cfg.ProgressInterval = spoticusConfig.Duration(time.Hour)
+	useConfig(t, cfg)
+	api, server := newAPI(t)
+
+	progress := startLaunchProgress(api, "C1", "1700000000.000001", "spoticus-k8s-a")
+	progress.Observe(phaseUnknown)
+	progress.Observe(phaseProvisioning)
+	if updates := progressUpdates(server); len(updates) != 0 {
+		t.Errorf("edited the progress %d times without a phase change, want none within the interval", len(updates))
+	}
+
+	progress.updated = time.Now().Add(-2 * time.Hour)
+	progress.Observe(phaseProvisioning)
+	if updates := progressUpdates(server); len(updates) != 1 {
+		t.Errorf("edited the progress %d times once the interval passed, want once", len(updates))
+	}
+
+	progress.Finish(phaseFailed)
+	updates := progressUpdates(server)
+	if len(updates) != 2 || !strings.Contains(updates[1], "spoticus-k8s-a*: Failed") {
+		t.Errorf("progress updates %q, want the final phase last", updates)
+	}
+}
+
+func TestPollDelayBacksOff(t *testing.T) {
+	polling := spoticusConfig.Polling{Interval: spoticusConfig.Duration(30 * time.Second), Jitter: 0.2, MaxBackoff: spoticusConfig.Duration(5 * time.Minute)}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 30 * time.Second},
+		{failures: 1, want: time.Minute},
+		{failures: 3, want: 4 * time.Minute},
+		{failures: 4, want: 5 * time.Minute},
+		{failures: 100, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		// A random value of 0.5 is the middle of the jitter range.
+		if got := pollDelay(polling, tt.failures, 0.5); got != tt.want {
+			t.Errorf("pollDelay() after %d failures = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestPollDelayJitterStaysWithinBounds(t *testing.T) {
+	for _, polling := range []spoticusConfig.Polling{
+		{Interval: spoticusConfig.Duration(30 * time.Second), Jitter: 0.2, MaxBackoff: spoticusConfig.Duration(5 * time.Minute)},
+		{Interval: spoticusConfig.Duration(10 * time.Second), Jitter: 0.9, MaxBackoff: spoticusConfig.Duration(10 * time.Second)},
+		{Interval: spoticusConfig.Duration(time.Minute), MaxBackoff: spoticusConfig.Duration(time.Minute)},
+	} {
+		for failures := range 8 {
+			base := pollDelay(polling, failures, 0.5)
+			low := time.Duration(float64(base) * (1 - polling.Jitter))
+			high := time.Duration(float64(base) * (1 + polling.Jitter))
+			for _, random := range []float64{0, 0.1, 0.25, 0.5, 0.75, 0.999999} {
+				if got := pollDelay(polling, failures, random); got < low || got > high {
+					t.Errorf("pollDelay(%+v, %d, %g) = %s, want within [%s, %s]", polling, failures, random, got, low, high)
+				}
+			}
+		}
+	}
+
+	polling := spoticusConfig.Polling{Interval: spoticusConfig.Duration(30 * time.Second), Jitter: 0.2, MaxBackoff: spoticusConfig.Duration(5 * time.Minute)}
+	if got := pollDelay(polling, 0, 0); got != 24*time.Second {
+		t.Errorf("pollDelay() at the low end = %s, want 24s", got)
+	}
+	if got := pollDelay(polling, 10, 0); got != 4*time.Minute {
+		t.Errorf("pollDelay() at the low end of the maximum backoff = %s, want 4m", got)
+	}
+}
+
+func TestProvisionTimeoutPerSize(t *testing.T) {
+	cfg := spoticusConfig.Default()
+	cfg.Sizes["small"] = spoticusConfig.SizeSpec{CPUs: 4, MemoryGB: 16, Nodes: 1}
+	useConfig(t, cfg)
+
+	tests := []struct {
+		args []string
+		want time.Duration
+	}{
+		{args: []string{"k8s", "medium"}, want: 30 * time.Minute},
+		{args: []string{"k8s", "large"}, want: 45 * time.Minute},
+		{args: []string{"k8s", "xlarge"}, want: time.Hour},
+		{args: []string{"k8s", "small"}, want: launchWatchTimeout},
+		{args: []string{"k8s", "--instance-type=m6i.2xlarge"}, want: launchWatchTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			req, err := parseLaunchArgs(tt.args, "C1")
+			if err != nil {
+				t.Fatalf("parseLaunchArgs: %v", err)
+			}
+			if got := provisionTimeout(req); got != tt.want {
+				t.Errorf("provisionTimeout() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,27 +1,108 @@
 package events
 
 import (
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/flacatus/spoticus/internal/config"
 	"github.com/flacatus/spoticus/internal/slack/handlers"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
-	"github.com/slack-go/slack/socketmode"
 )
 
 type Bot struct {
-	api    *slack.Client
-	client *socketmode.Client
+	api *slack.Client
+
+	// welcomer introduces the bot in the channels it is added to.
+	welcomer *welcomer
+
+	// revoked is called when the app loses access to the workspace.
+	revoked func(reason string)
+
+	revokedMu sync.Mutex
+	// revokedTeams holds the workspaces the app lost access to under tenant
+	// isolation, whose events are dropped.
+	revokedTeams map[string]bool
 }
 
-func NewBot(api *slack.Client, client *socketmode.Client) (*Bot, error) {
+func NewBot(api *slack.Client) (*Bot, error) {
 	return &Bot{
-		api:    api,
-		client: client,
+		api:          api,
+		welcomer:     newWelcomer(api),
+		revokedTeams: map[string]bool{},
 	}, nil
 }
 
+// HandleInteraction handles a click on an interactive element of a message
+// the bot posted, such as the `list` page buttons.
+func (b *Bot) HandleInteraction(callback slack.InteractionCallback) {
+	if b.isRevoked(callback.Team.ID) {
+		log.Printf("Dropping %s interaction from workspace %s, which revoked the app", callback.Type, callback.Team.ID)
+		return
+	}
+	handlers.HandleInteraction(b.api, callback)
+}
+
+// OnRevoked registers fn to be called with the reason when the app is
+// uninstalled from the workspace or its bot tokens are revoked. Without
+// tenant isolation the bot serves a single workspace, so it should stop
+// processing events then. Under tenant isolation fn is not called: only the
+// events of that workspace are dropped from then on.
+func (b *Bot) OnRevoked(fn func(reason string)) {
+	b.revoked = fn
+}
+
 func (b *Bot) HandleEvent(event slackevents.EventsAPIEvent) {
+	if b.isRevoked(event.TeamID) {
+		log.Printf("Dropping %s event from workspace %s, which revoked the app", event.InnerEvent.Type, event.TeamID)
+		return
+	}
 	switch e := event.InnerEvent.Data.(type) {
 	case *slackevents.MessageEvent:
-		handlers.HandleMessageEvent(b.api, e)
+		if joinSubtypes[e.SubType] {
+			b.welcomer.memberJoined(e.Channel, e.User)
+			return
+		}
+		handlers.HandleMessageEvent(b.api, event.TeamID, e)
+	case *slackevents.MemberJoinedChannelEvent:
+		b.welcomer.memberJoined(e.Channel, e.User)
+	case *slackevents.AppUninstalledEvent:
+		b.revoke(event.TeamID, "the app was uninstalled")
+	case *slackevents.TokensRevokedEvent:
+		// User tokens may be revoked on their own; only the bot's matter.
+		if len(e.Tokens.Bot) == 0 {
+			log.Printf("Ignoring the revocation of %d user token(s) in workspace %s", len(e.Tokens.Oauth), event.TeamID)
+			return
+		}
+		b.revoke(event.TeamID, "the bot tokens were revoked ("+strings.Join(e.Tokens.Bot, ", ")+")")
+	}
+}
+
+// revoke reports that the app lost access to the workspace. Under tenant
+// isolation the other workspaces are still served, so only the workspace's
+// events are dropped from then on, until the bot restarts.
+func (b *Bot) revoke(team, reason string) {
+	log.Printf("⛔ Lost access to workspace %s: %s", team, reason)
+	if config.Get().TenantIsolation {
+		b.revokedMu.Lock()
+		b.revokedTeams[team] = true
+		b.revokedMu.Unlock()
+		log.Printf("Ignoring the events of workspace %s from now on", team)
+		return
+	}
+	if b.revoked != nil {
+		b.revoked(reason)
+	}
+}
+
+// isRevoked reports whether the app lost access to the workspace under
+// tenant isolation.
+func (b *Bot) isRevoked(team string) bool {
+	if team == "" {
+		return false
 	}
+	b.revokedMu.Lock()
+	defer b.revokedMu.Unlock()
+	return b.revokedTeams[team]
 }
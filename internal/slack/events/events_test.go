@@ -0,0 +1,117 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// newTestBot returns a bot talking to a fake Slack API, with isolation set
+// as given in the configuration, and the reasons it reported revocations with.
+func newTestBot(t *testing.T, isolation bool) (*Bot, *slacktest.Server, *[]string) {
+	t.Helper()
+	previous := config.Get()
+	cfg := config.Default()
+	cfg.TenantIsolation = isolation
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(previous) })
+
+	server := slacktest.NewServer(t)
+	bot, err := NewBot(server.Client())
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+	var reasons []string
+	bot.OnRevoked(func(reason string) { reasons = append(reasons, reason) })
+	return bot, server, &reasons
+}
+
+func uninstalled(team string) slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type:   slackevents.CallbackEvent,
+		TeamID: team,
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.AppUninstalled),
+			Data: &slackevents.AppUninstalledEvent{Type: string(slackevents.AppUninstalled)},
+		},
+	}
+}
+
+func tokensRevoked(team string, bot, oauth []string) slackevents.EventsAPIEvent {
+	e := &slackevents.TokensRevokedEvent{Type: string(slackevents.TokensRevoked)}
+	e.Tokens.Bot, e.Tokens.Oauth = bot, oauth
+	return slackevents.EventsAPIEvent{
+		Type:       slackevents.CallbackEvent,
+		TeamID:     team,
+		InnerEvent: slackevents.EventsAPIInnerEvent{Type: string(slackevents.TokensRevoked), Data: e},
+	}
+}
+
+func helpMessage(team, channel string) slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type:   slackevents.CallbackEvent,
+		TeamID: team,
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.Message),
+			Data: &slackevents.MessageEvent{Type: string(slackevents.Message), User: "U1", Channel: channel, Text: "help"},
+		},
+	}
+}
+
+func TestHandleEventRevocationStopsSingleTenantBot(t *testing.T) {
+	tests := []struct {
+		name  string
+		event slackevents.EventsAPIEvent
+		want  []string
+	}{
+		{name: "app uninstalled", event: uninstalled("T1"), want: []string{"the app was uninstalled"}},
+		{name: "bot tokens revoked", event: tokensRevoked("T1", []string{"UBOT"}, nil), want: []string{"the bot tokens were revoked (UBOT)"}},
+		{name: "user tokens revoked", event: tokensRevoked("T1", nil, []string{"U1"}), want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, _, reasons := newTestBot(t, false)
+
+			bot.HandleEvent(tt.event)
+
+			if len(*reasons) != len(tt.want) || (len(tt.want) > 0 && (*reasons)[0] != tt.want[0]) {
+				t.Errorf("revoked with %q, want %q", *reasons, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleEventRevocationUnderTenantIsolation(t *testing.T) {
+	for _, event := range []slackevents.EventsAPIEvent{uninstalled("TA"), tokensRevoked("TA", []string{"UBOT"}, nil)} {
+		t.Run(event.InnerEvent.Type, func(t *testing.T) {
+			bot, server, reasons := newTestBot(t, true)
+
+			bot.HandleEvent(event)
+			if len(*reasons) != 0 {
+				t.Fatalf("whole bot revoked with %q under tenant isolation", *reasons)
+			}
+
+			bot.HandleEvent(helpMessage("TA", "CA"))
+			bot.HandleInteraction(slack.InteractionCallback{
+				Type: slack.InteractionTypeBlockActions,
+				Team: slack.Team{ID: "TA"},
+			})
+			bot.HandleEvent(helpMessage("TB", "CB"))
+
+			server.WaitForMessage("", time.Second)
+			for _, call := range server.Messages() {
+				if call.Channel() == "CA" {
+					t.Errorf("replied to the revoked workspace: %q", call.Text())
+				}
+			}
+			if _, ok := server.Wait(func(call slacktest.Call) bool { return call.Channel() == "CB" }, time.Second); !ok {
+				t.Error("the other workspace is no longer served")
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// welcomeDedupWindow is how long after welcoming a channel further joins of
+// the bot to it are ignored. Slack reports the same join both as a
+// member_joined_channel event and a channel_join message.
+const welcomeDedupWindow = time.Minute
+
+// joinSubtypes are the message subtypes Slack posts when someone joins a
+// channel. They are never commands.
+var joinSubtypes = map[string]bool{
+	slack.MsgSubTypeChannelJoin: true,
+	slack.MsgSubTypeGroupJoin:   true,
+}
+
+// welcomer posts the configured welcome message when the bot itself is added
+// to a channel. Joins of other users are ignored.
+type welcomer struct {
+	api *slack.Client
+
+	mu sync.Mutex
+	// userID is the bot's own user ID, looked up on the first join.
+	userID   string
+	welcomed map[string]time.Time
+}
+
+func newWelcomer(api *slack.Client) *welcomer {
+	return &welcomer{api: api, welcomed: map[string]time.Time{}}
+}
+
+// memberJoined welcomes the channel if user is the bot, unless welcomes are
+// disabled or the channel was just welcomed.
+func (w *welcomer) memberJoined(channel, user string) {
+	if config.Get().DisableWelcome || !w.shouldWelcome(channel, user, time.Now()) {
+		return
+	}
+	log.Printf("Added to channel %s, posting the welcome message", channel)
+	respond.Text(w.api, channel, config.Get().Messages.Welcome)
+}
+
+// shouldWelcome reports whether user joining channel at now is the bot's own
+// first join in the dedup window, and records it if so.
+func (w *welcomer) shouldWelcome(channel, user string, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.userID == "" {
+		resp, err := w.api.AuthTest()
+		if err != nil {
+			log.Printf("Error looking up the bot user ID, not welcoming channel %s: %v", channel, err)
+			return false
+		}
+		w.userID = resp.UserID
+	}
+	if user != w.userID {
+		return false
+	}
+	if last, ok := w.welcomed[channel]; ok && now.Sub(last) < welcomeDedupWindow {
+		return false
+	}
+	w.welcomed[channel] = now
+	return true
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+)
+
+// memberJoined returns a member_joined_channel event for the user.
+func memberJoined(user, channel string) slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type:   slackevents.CallbackEvent,
+		TeamID: "T1",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.MemberJoinedChannel),
+			Data: &slackevents.MemberJoinedChannelEvent{Type: string(slackevents.MemberJoinedChannel), User: user, Channel: channel},
+		},
+	}
+}
+
+// channelJoin returns the channel_join message Slack posts when the user joins.
+func channelJoin(user, channel string) slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type:   slackevents.CallbackEvent,
+		TeamID: "T1",
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.Message),
+			Data: &slackevents.MessageEvent{Type: string(slackevents.Message), SubType: slack.MsgSubTypeChannelJoin, User: user, Channel: channel, Text: "<@" + user + "> has joined the channel"},
+		},
+	}
+}
+
+func TestWelcomeOnlyForBotJoin(t *testing.T) {
+	bot, server, _ := newTestBot(t, false)
+
+	// The fake Slack API reports the bot as UBOT.
+	bot.HandleEvent(memberJoined("U1", "C1"))
+	bot.HandleEvent(channelJoin("U2", "C1"))
+	bot.HandleEvent(memberJoined("UBOT", "C2"))
+	bot.HandleEvent(channelJoin("UBOT", "C2"))
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("posted %d messages, want the one welcome", len(messages))
+	}
+	if messages[0].Channel() != "C2" || messages[0].Text() != config.Get().Messages.Welcome {
+		t.Errorf("posted %q to %s, want the welcome message in C2", messages[0].Text(), messages[0].Channel())
+	}
+}
+
+func TestWelcomeDisabled(t *testing.T) {
+	bot, server, _ := newTestBot(t, false)
+	cfg := config.Default()
+	cfg.DisableWelcome = true
+	config.Set(cfg)
+
+	bot.HandleEvent(memberJoined("UBOT", "C1"))
+
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("posted %d messages with the welcome disabled, want none", len(messages))
+	}
+}
+
+func TestShouldWelcomeDedupWindow(t *testing.T) {
+	_, server, _ := newTestBot(t, false)
+	w := newWelcomer(server.Client())
+	now := time.Now()
+
+	if !w.shouldWelcome("C1", "UBOT", now) {
+		t.Fatal("first join of the bot not welcomed")
+	}
+	if w.shouldWelcome("C1", "UBOT", now.Add(welcomeDedupWindow/2)) {
+		t.Error("join within the dedup window welcomed again")
+	}
+	if !w.shouldWelcome("C2", "UBOT", now) {
+		t.Error("join of another channel not welcomed")
+	}
+	if !w.shouldWelcome("C1", "UBOT", now.Add(welcomeDedupWindow)) {
+		t.Error("join after the dedup window not welcomed")
+	}
+	if calls := server.Calls("auth.test"); len(calls) != 1 {
+		t.Errorf("looked the bot user up %d times, want once", len(calls))
+	}
+}
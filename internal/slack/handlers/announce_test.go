@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/config"
+)
+
+func TestAnnounceIsAdminOnly(t *testing.T) {
+	cfg := config.Default()
+	cfg.Admins = []string{"UADMIN"}
+	cfg.AnnounceChannels = []string{"CA"}
+	useConfig(t, cfg)
+	useHistory(t, 10)
+	api, server := newAPI(t)
+
+	HandleMessageEvent(api, "T1", message("UOTHER", "C1", "announce hello"))
+
+	server.WaitForMessage("restricted to bot administrators", time.Second)
+	for _, call := range server.Messages() {
+		if call.Channel() == "CA" {
+			t.Errorf("a non-admin's announcement was posted: %q", call.Text())
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// handleConfig shows the configuration currently in effect, with secrets
+// redacted, to help debug a deployment.
+func handleConfig(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := config.Get()
+	dump, err := config.Dump(cfg)
+	if err != nil {
+		return &commands.CommandError{
+			Category: commands.CategoryInternal,
+			Message:  cfg.Messages.ConfigFailed,
+			Log:      "rendering the configuration: " + err.Error(),
+			Err:      err,
+		}
+	}
+	respond.Text(api, event.Channel, messages.Render(cfg.Messages.ConfigDump, messages.Data{"Config": dump}))
+	return nil
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// cooldowns tracks, per user and command, until when the command may not be
+// run again.
+type cooldowns struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// commandCooldowns holds the cooldowns of the running bot.
+var commandCooldowns = &cooldowns{until: make(map[string]time.Time)}
+
+// take starts a cooldown of cmd for user at now. If the previous one has not
+// ended yet, it returns how long remains instead.
+func (c *cooldowns) take(user, cmd string, cooldown time.Duration, now time.Time) (time.Duration, bool) {
+	key := user + "/" + cmd
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if remaining := c.until[key].Sub(now); remaining > 0 {
+		return remaining, false
+	}
+
+	// Forget ended cooldowns so the map stays small.
+	for k, until := range c.until {
+		if !now.Before(until) {
+			delete(c.until, k)
+		}
+	}
+	c.until[key] = now.Add(cooldown)
+	return 0, true
+}
+
+// refund ends the cooldown of cmd for user started by take, for a command
+// that failed and so should not hold the user back from retrying.
+func (c *cooldowns) refund(user, cmd string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.until, user+"/"+cmd)
+}
+
+// confirmsPending reports whether args only confirm a request made earlier
+// (e.g. `launch confirm`), which is not subject to the command's cooldown.
+func confirmsPending(args []string) bool {
+	return len(args) == 1 && strings.EqualFold(args[0], "confirm")
+}
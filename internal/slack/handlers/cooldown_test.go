@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+)
+
+func TestCooldownTake(t *testing.T) {
+	start := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		user, cmd     string
+		at            time.Duration
+		wantOK        bool
+		wantRemaining time.Duration
+	}{
+		{name: "within the cooldown", user: "U1", cmd: "launch", at: 10 * time.Second, wantRemaining: 20 * time.Second},
+		{name: "just before it ends", user: "U1", cmd: "launch", at: 30*time.Second - time.Millisecond, wantRemaining: time.Millisecond},
+		{name: "once it ended", user: "U1", cmd: "launch", at: 30 * time.Second, wantOK: true},
+		{name: "another user", user: "U2", cmd: "launch", at: time.Second, wantOK: true},
+		{name: "another command", user: "U1", cmd: "done", at: time.Second, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cooldowns{until: make(map[string]time.Time)}
+			if _, ok := c.take("U1", "launch", 30*time.Second, start); !ok {
+				t.Fatal("first take refused")
+			}
+			remaining, ok := c.take(tt.user, tt.cmd, 30*time.Second, start.Add(tt.at))
+			if ok != tt.wantOK || remaining != tt.wantRemaining {
+				t.Errorf("take() = %s, %t, want %s, %t", remaining, ok, tt.wantRemaining, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCooldownRefund(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	c := &cooldowns{until: make(map[string]time.Time)}
+	c.take("U1", "launch", 30*time.Second, now)
+	c.take("U2", "launch", 30*time.Second, now)
+
+	c.refund("U1", "launch")
+
+	if _, ok := c.take("U1", "launch", 30*time.Second, now.Add(time.Second)); !ok {
+		t.Error("refunded cooldown still refuses the command")
+	}
+	if _, ok := c.take("U2", "launch", 30*time.Second, now.Add(time.Second)); ok {
+		t.Error("refund ended the cooldown of another user")
+	}
+}
+
+func TestHandleMessageEventCooldown(t *testing.T) {
+	useConfig(t, config.Default())
+	useHistory(t, 10)
+	api, server := newAPI(t)
+
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{name: "success starts the cooldown", wantCalls: 1},
+		{name: "failure refunds it", err: errors.New("kubernetes unreachable"), wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCooldowns(t)
+			calls := 0
+			useCommand(t, "probe", Command{Cooldown: time.Minute, Handler: func(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+				calls++
+				return tt.err
+			}})
+
+			HandleMessageEvent(api, "T1", message("U1", "C1", "probe"))
+			HandleMessageEvent(api, "T1", message("U1", "C1", "probe"))
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	refused := false
+	for _, call := range server.Messages() {
+		refused = refused || strings.Contains(call.Text(), "Please wait")
+	}
+	if !refused {
+		t.Error("the repeat of the successful command was not refused with the remaining time")
+	}
+}
+
+func TestHandleMessageEventConfirmSkipsCooldown(t *testing.T) {
+	useConfig(t, config.Default())
+	useHistory(t, 10)
+	useCooldowns(t)
+	api, _ := newAPI(t)
+	calls := 0
+	useCommand(t, "probe", Command{Cooldown: time.Minute, Handler: func(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+		calls++
+		return nil
+	}})
+
+	HandleMessageEvent(api, "T1", message("U1", "C1", "probe"))
+	HandleMessageEvent(api, "T1", message("U1", "C1", "probe confirm"))
+
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want the confirmation let through", calls)
+	}
+}
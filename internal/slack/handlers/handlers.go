@@ -1,64 +1,333 @@
 package handlers
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
 	"github.com/flacatus/spoticus/internal/slack/commands"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+	"github.com/flacatus/spoticus/internal/tracing"
 )
 
 // CommandHandler defines the function signature for command handlers.
-type CommandHandler func(api *slack.Client, event *slackevents.MessageEvent, args []string)
+// The context carries the command's trace span and should be passed to the
+// Kubernetes calls the handler makes. A failed command returns a
+// *commands.CommandError, which the dispatcher shows and logs.
+type CommandHandler func(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error
 
 // Command describes a command's usage and handler.
 type Command struct {
 	Description string
 	Usage       string
 	Handler     CommandHandler
+
+	// Details is the detailed documentation shown by `help <command>` and
+	// `<command> help`, in addition to the usage.
+	Details string
+
+	// Examples are complete invocations listed by `help <command>`, each
+	// shown as typed.
+	Examples []string
+
+	// AdminOnly restricts the command to the users listed in the bot configuration.
+	AdminOnly bool
+
+	// Mutating marks commands that create or delete clusters. They are refused
+	// while the bot is in maintenance mode.
+	Mutating bool
+
+	// Backend marks commands that need the Kubernetes backend. They are
+	// refused right away while the backend is reported not ready, instead
+	// of failing slowly.
+	Backend bool
+
+	// Cooldown is how long a user must wait before running the command
+	// again, e.g. to keep an accidental repeat from launching a second
+	// cluster. Zero disables it.
+	Cooldown time.Duration
 }
 
 // Registry of all available commands.
 var commandRegistry = map[string]Command{
 	"launch": {
 		Description: "Launch a cluster with specified type and size.",
+		Details:     commands.LaunchUsage,
 		Usage:       "`launch <cluster_type> <size>`\nExample: `launch kubernetes large`",
 		Handler:     commands.HandleLaunch,
+		Backend:     true,
+		Mutating:    true,
+		Cooldown:    30 * time.Second,
+		Examples: []string{
+			"launch k8s medium",
+			"launch openshift large --version=4.19.0",
+			"launch k8s large --ref=PROJ-123 --in=2h",
+			"launch k8s medium --set region=eu-west-1",
+			"launch type=openshift size=large version=4.19.0",
+			"launch --preset=dev",
+		},
+	},
+	"validate": {
+		Description: "Check a launch command without creating anything, reporting every problem found.",
+		Usage:       "`validate <launch arguments>`\nExample: `validate openshift large --version=4.19.0`",
+		Handler:     commands.HandleValidate,
+		Backend:     true,
+	},
+	"preset": {
+		Description: "Save, list or delete your own launch presets, used with `launch --preset=<name>`.",
+		Usage:       "`preset save <name> <launch arguments>`, `preset list` or `preset delete <name>`\nExample: `preset save dev k8s large`",
+		Handler:     commands.HandlePreset,
+		Backend:     true,
+	},
+	"schedule": {
+		Description: "List scheduled launches or cancel one.",
+		Usage:       "`schedule` or `schedule cancel <id>`\nSchedule with `launch ... --at=\"2025-06-01 09:00\"` or `launch ... --in=2h`",
+		Handler:     commands.HandleSchedule,
+		Backend:     true,
 	},
 	"list": {
-		Description: "List all mapt clusters.",
-		Usage:       "`list`",
+		Description: "List all mapt clusters, optionally grouped by owner or, for admins, across all namespaces.",
+		Usage:       "`list [--by-owner] [--all-namespaces]`",
+		Examples:    []string{"list", "list --by-owner", "list --all-namespaces"},
 		Handler:     commands.HandleList,
+		Backend:     true,
+	},
+	"failed": {
+		Description: "List the failed clusters with buttons to relaunch or delete each (admin only).",
+		Usage:       "`failed`",
+		Handler:     commands.HandleFailed,
+		AdminOnly:   true,
+		Backend:     true,
+		Mutating:    true,
+	},
+	"cleanup": {
+		Description: "Delete failed or orphaned clusters after confirmation (admin only).",
+		Usage:       "`cleanup` then `cleanup confirm <phrase>`, or `cleanup preview` for a dry run",
+		Handler:     commands.HandleCleanup,
+		Backend:     true,
+		AdminOnly:   true,
+		Mutating:    true,
+	},
+	"quota": {
+		Description: "Show your cluster quota; admins can view or set other users' limits.",
+		Usage:       "`quota [@user] [n]`\nExample: `quota @alice 5`",
+		Handler:     commands.HandleQuota,
+		Backend:     true,
+	},
+	"diff": {
+		Description: "Preview how resizing a cluster would change its resources and cost.",
+		Usage:       "`diff <cluster_name> <size>`\nExample: `diff spoticus-k8s-abc12 xlarge`",
+		Handler:     commands.HandleDiff,
+		Backend:     true,
+	},
+	"stats": {
+		Description: "Summarize the cluster inventory by type, size and status, with total cost.",
+		Usage:       "`stats`",
+		Handler:     commands.HandleStats,
+		Backend:     true,
+	},
+	"top": {
+		Description: "List the most expensive running clusters by estimated hourly cost.",
+		Usage:       "`top [count]`",
+		Handler:     commands.HandleTop,
+		Backend:     true,
+	},
+	"capacity": {
+		Description: "Show the resource quota usage and limits of the namespace launches use.",
+		Usage:       "`capacity [cluster_type]`",
+		Examples:    []string{"capacity", "capacity openshift"},
+		Handler:     commands.HandleCapacity,
+		Backend:     true,
+	},
+	"export": {
+		Description: "Upload the cluster inventory as a CSV file, optionally only your own clusters.",
+		Usage:       "`export [--mine]`",
+		Handler:     commands.HandleExport,
+		Backend:     true,
+	},
+	"diagnostics": {
+		Description: "Upload a zip of a cluster's status, resource, events and provisioning logs for support tickets (admin only).",
+		Usage:       "`diagnostics <cluster_name>`",
+		Examples:    []string{"diagnostics spoticus-k8s-x7k2p"},
+		Handler:     commands.HandleDiagnostics,
+		AdminOnly:   true,
+		Backend:     true,
+	},
+	"permissions": {
+		Description: "Check which Kubernetes permissions the bot holds in a namespace (admin only).",
+		Usage:       "`permissions [namespace]`",
+		Examples:    []string{"permissions", "permissions kind-clusters"},
+		Handler:     commands.HandlePermissions,
+		AdminOnly:   true,
+		Backend:     true,
+	},
+	"status": {
+		Description: "Show the status and launch details of a cluster.",
+		Usage:       "`status <cluster_name>`",
+		Handler:     commands.HandleStatus,
+		Backend:     true,
+	},
+	"describe": {
+		Description: "Alias of `status`.",
+		Usage:       "`describe <cluster_name>`",
+		Handler:     commands.HandleStatus,
+		Backend:     true,
+	},
+	"console": {
+		Description: "Link to the cloud provider console showing a cluster's instances.",
+		Usage:       "`console <cluster_name>`\nExample: `console my-cluster`",
+		Handler:     commands.HandleConsole,
+		Backend:     true,
+	},
+	"ping": {
+		Description: "Check that the bot is alive and show the Kubernetes context in use.",
+		Usage:       "`ping`",
+		Handler:     commands.HandlePing,
+	},
+	"version": {
+		Description: "Show the version of the MAPT operator and the MAPT API the bot supports.",
+		Usage:       "`version`",
+		Handler:     commands.HandleVersion,
+		Backend:     true,
+	},
+	"uptime": {
+		Description: "Show bot uptime, Slack connection state, events processed and backend reachability.",
+		Usage:       "`uptime`",
+		Handler:     commands.HandleUptime,
+	},
+	"done": {
+		Description: "Delete a cluster you are finished with.",
+		Usage:       "`done <cluster_name> [--now]` or `done --all`",
+		Examples:    []string{"done spoticus-k8s-x7k2p", "done spoticus-k8s-x7k2p --now", "done --all"},
+		Handler:     commands.HandleDone,
+		Backend:     true,
+		Mutating:    true,
+	},
+	"cancel": {
+		Description: "Abort a launch that is still provisioning and delete what it created.",
+		Usage:       "`cancel <cluster_name>`",
+		Examples:    []string{"cancel spoticus-k8s-x7k2p"},
+		Handler:     commands.HandleCancel,
+		Backend:     true,
+		Mutating:    true,
+	},
+	"cancel-delete": {
+		Description: "Keep a cluster marked for deletion with `done` during its grace period.",
+		Usage:       "`cancel-delete <cluster_name>`",
+		Handler:     commands.HandleCancelDelete,
+		Backend:     true,
+	},
+	"pin": {
+		Description: "Protect a cluster you own from `cleanup`.",
+		Usage:       "`pin <cluster_name>`",
+		Handler:     commands.HandlePin,
+		Backend:     true,
+	},
+	"unpin": {
+		Description: "Let `cleanup` select a pinned cluster again.",
+		Usage:       "`unpin <cluster_name>`",
+		Handler:     commands.HandleUnpin,
+		Backend:     true,
+	},
+	"rename": {
+		Description: "Give a cluster a friendly display name, shown by `list` and `status`.",
+		Usage:       "`rename <cluster_name> <display name>`\nExample: `rename k8s-ab12c payments demo`",
+		Handler:     commands.HandleRename,
+		Backend:     true,
+	},
+	"follow": {
+		Description: "Post a cluster's status changes, expiry warning and deletion in this thread.",
+		Usage:       "`follow <cluster_name>`",
+		Handler:     commands.HandleFollow,
+		Backend:     true,
+	},
+	"unfollow": {
+		Description: "Stop posting a followed cluster's updates.",
+		Usage:       "`unfollow <cluster_name>`",
+		Handler:     commands.HandleUnfollow,
+	},
+	"maintenance": {
+		Description: "Show or toggle maintenance mode, which refuses launches and deletions (admin only).",
+		Usage:       "`maintenance [on|off]`",
+		Handler:     commands.HandleMaintenance,
+		AdminOnly:   true,
+	},
+	"announce": {
+		Description: "Post an announcement to the configured announcement channels (admin only).",
+		Usage:       "`announce <message>`\nExample: `announce Clusters will be unavailable Friday 18:00-20:00 UTC`",
+		Handler:     commands.HandleAnnounce,
+		AdminOnly:   true,
+	},
+	"errors": {
+		Description: "Show the most recent errors reported to users, with their cause (admin only).",
+		Usage:       "`errors [n]`\nExample: `errors 20`",
+		Handler:     commands.HandleErrors,
+		AdminOnly:   true,
+	},
+	"reliability": {
+		Description: "Show launch success and failure rates over a rolling window (admin only).",
+		Usage:       "`reliability [window]`\nExample: `reliability 7d`",
+		Handler:     commands.HandleReliability,
+		AdminOnly:   true,
 	},
-	// Future command examples:
-	// "done": {
-	// 	Description: "Terminate the running cluster.",
-	// 	Usage:       "`done`",
-	// 	Handler:     commands.HandleDone,
-	// },
 }
 
 func init() {
 	// Register the built-in "help" command.
 	commandRegistry["help"] = Command{
-		Description: "Show available commands and usage.",
-		Usage:       "`help`",
+		Description: "Show available commands and usage, or the detailed usage of one command.",
+		Usage:       "`help [command]`\nExample: `help launch`",
 		Handler:     handleHelp,
 	}
+	commandRegistry["history"] = Command{
+		Description: "Show the most recent commands received by the bot (admin only).",
+		Usage:       "`history [n]`\nExample: `history 20`",
+		Handler:     handleHistory,
+		AdminOnly:   true,
+	}
+	commandRegistry["reload"] = Command{
+		Description: "Reload the bot configuration without restarting (admin only).",
+		Usage:       "`reload`",
+		Handler:     handleReload,
+		AdminOnly:   true,
+	}
+	commandRegistry["config"] = Command{
+		Description: "Show the effective bot configuration, with secrets redacted (admin only).",
+		Usage:       "`config`",
+		Handler:     handleConfig,
+		AdminOnly:   true,
+	}
+}
+
+// ignoredSubtypes lists the message subtypes that never trigger a command.
+var ignoredSubtypes = map[string]bool{
+	slack.MsgSubTypeMessageChanged: true,
+	slack.MsgSubTypeMessageDeleted: true,
 }
 
-// HandleMessageEvent routes incoming Slack messages to appropriate command handlers.
-func HandleMessageEvent(api *slack.Client, event *slackevents.MessageEvent) {
+// HandleMessageEvent routes incoming Slack messages to appropriate command
+// handlers. team is the workspace the event was delivered to, the team ID of
+// its Events API envelope.
+func HandleMessageEvent(api *slack.Client, team string, event *slackevents.MessageEvent) {
 	// Ignore messages from bots.
 	if event.BotID != "" {
 		return
 	}
 
-	text := strings.TrimSpace(event.Text)
-	fields := strings.Fields(text)
+	// Ignore edits and deletions so that changing an old command message
+	// (e.g. a previous `launch`) does not run the command again.
+	if ignoredSubtypes[event.SubType] {
+		log.Printf("Ignoring %s event from user %s in channel %s", event.SubType, event.User, event.Channel)
+		return
+	}
+
+	fields := strings.Fields(commandText(event.Text))
 	if len(fields) == 0 {
 		return
 	}
@@ -66,23 +335,115 @@ func HandleMessageEvent(api *slack.Client, event *slackevents.MessageEvent) {
 	cmd := strings.ToLower(fields[0])
 	args := fields[1:]
 
+	ctx, span := tracing.Start(context.Background(), "command "+cmd,
+		tracing.AttrCommand.String(cmd),
+		tracing.AttrUser.String(event.User),
+		tracing.AttrChannel.String(event.Channel))
+	// err is the error of the command's handler, recorded on the span.
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	ctx, ok := commands.WithTeam(ctx, team)
+	if !ok {
+		log.Printf("Dropping '%s' command from user %s without a workspace ID under tenant isolation", cmd, event.User)
+		return
+	}
+
 	command, ok := commandRegistry[cmd]
 	if !ok {
 		log.Printf("Unknown command '%s' from user %s in channel %s. Showing help.", cmd, event.User, event.Channel)
-		handleHelp(api, event, nil)
+		recordCommand(event, cmd, args, outcomeUnknown)
+		handleHelp(ctx, api, event, nil)
 		return
 	}
 
+	// `<command> help` documents the command without running it, so it is
+	// answered before the admin and maintenance checks.
+	if len(args) == 1 && strings.EqualFold(args[0], "help") {
+		log.Printf("Showing help of '%s' to user %s in channel %s", cmd, event.User, event.Channel)
+		recordCommand(event, cmd, args, outcomeExecuted)
+		handleHelp(ctx, api, event, []string{cmd})
+		return
+	}
+
+	if command.AdminOnly && !config.Get().IsAdmin(event.User) {
+		log.Printf("Denied admin command '%s' for user %s in channel %s", cmd, event.User, event.Channel)
+		recordCommand(event, cmd, args, outcomeDenied)
+		respond.Text(api, event.Channel, messages.Render(config.Get().Messages.AdminOnly, messages.Data{"Command": cmd}))
+		return
+	}
+
+	if command.Backend && !commands.BackendReady() {
+		log.Printf("Refused '%s' command from user %s while the backend is not ready", cmd, event.User)
+		recordCommand(event, cmd, args, outcomeNotReady)
+		respond.Text(api, event.Channel, messages.Render(config.Get().Messages.BackendNotReady, messages.Data{"Command": cmd}))
+		return
+	}
+
+	if command.Mutating && commands.MaintenanceEnabled() {
+		log.Printf("Refused '%s' command from user %s in maintenance mode", cmd, event.User)
+		recordCommand(event, cmd, args, outcomeMaintenance)
+		respond.Text(api, event.Channel, messages.Render(config.Get().Messages.MaintenanceActive, messages.Data{"Command": cmd}))
+		return
+	}
+
+	// The cooldown starts before the handler runs, so that a repeat sent
+	// while the command is still running is refused, and is refunded if the
+	// command fails.
+	cooling := command.Cooldown > 0 && !confirmsPending(args)
+	if cooling {
+		if remaining, ok := commandCooldowns.take(event.User, cmd, command.Cooldown, time.Now()); !ok {
+			log.Printf("Refused '%s' command from user %s during its cooldown", cmd, event.User)
+			recordCommand(event, cmd, args, outcomeCooldown)
+			respond.Text(api, event.Channel, messages.Render(config.Get().Messages.CommandCooldown, messages.Data{
+				"Command":   cmd,
+				"Remaining": messages.FormatDuration(remaining),
+			}))
+			return
+		}
+	}
+
 	log.Printf("Received '%s' command from user %s in channel %s", cmd, event.User, event.Channel)
-	command.Handler(api, event, args)
+	err = command.Handler(ctx, api, event, args)
+	if err != nil {
+		if cooling {
+			commandCooldowns.refund(event.User, cmd)
+		}
+		commands.ReportError(api, event.Channel, cmd, event.User, err)
+	}
+	recordCommand(event, cmd, args, commandOutcome(err))
 }
 
-// handleHelp sends a formatted message listing all available commands and their usage.
-func handleHelp(api *slack.Client, event *slackevents.MessageEvent, args []string) {
+// handleHelp sends a formatted message listing all available commands and
+// their usage, or the detailed usage of the command given as argument.
+func handleHelp(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	cfg := config.Get()
+	templates := cfg.Messages
+	if len(args) > 0 {
+		name := strings.ToLower(args[0])
+		cmd, ok := commandRegistry[name]
+		if !ok {
+			return &commands.CommandError{Message: messages.Render(templates.HelpUnknownCommand, messages.Data{"Name": name})}
+		}
+		respond.Text(api, event.Channel, messages.Render(templates.HelpCommand, messages.Data{
+			"Name":        name,
+			"Description": cmd.Description,
+			"Usage":       cmd.Usage,
+			"Details":     cfg.Branding.Text(cmd.Details),
+			"Examples":    cmd.Examples,
+		}))
+		return nil
+	}
+
 	var msg strings.Builder
-	msg.WriteString("📖 *Available commands:*\n")
+	msg.WriteString(templates.HelpHeader)
 	for name, cmd := range commandRegistry {
-		msg.WriteString(fmt.Sprintf("\n• *%s* — %s\n  _Usage:_ %s\n", name, cmd.Description, cmd.Usage))
+		msg.WriteString(messages.Render(templates.HelpEntry, messages.Data{
+			"Name":        name,
+			"Description": cmd.Description,
+			"Usage":       cmd.Usage,
+		}))
 	}
-	api.PostMessage(event.Channel, slack.MsgOptionText(msg.String(), false))
+	respond.Text(api, event.Channel, msg.String())
+	return nil
 }
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// useSpanRecorder records the spans ended until the test ends.
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+// endedSpan returns the ended span with the name, failing the test if there
+// is none.
+func endedSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("no %q span ended", name)
+	return nil
+}
+
+func TestHandleMessageEventTracesCommand(t *testing.T) {
+	useConfig(t, config.Default())
+	api, _ := newAPI(t)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{name: "succeeds", wantStatus: codes.Unset},
+		{name: "fails", err: errors.New("kubernetes unreachable"), wantStatus: codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useSpanRecorder(t)
+			useHistory(t, 10)
+			useCommand(t, "probe", Command{Handler: func(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+				tracing.SetCluster(ctx, "spoticus-k8s-abcde")
+				return tt.err
+			}})
+
+			HandleMessageEvent(api, "T1", message("U1", "C1", "probe"))
+
+			span := endedSpan(t, recorder, "command probe")
+			want := map[attribute.Key]string{
+				tracing.AttrCommand: "probe",
+				tracing.AttrUser:    "U1",
+				tracing.AttrChannel: "C1",
+				tracing.AttrCluster: "spoticus-k8s-abcde",
+			}
+			got := map[attribute.Key]string{}
+			for _, attr := range span.Attributes() {
+				got[attr.Key] = attr.Value.AsString()
+			}
+			for key, value := range want {
+				if got[key] != value {
+					t.Errorf("span attribute %s = %q, want %q", key, got[key], value)
+				}
+			}
+			if status := span.Status(); status.Code != tt.wantStatus {
+				t.Errorf("span status = %v, want %v", status.Code, tt.wantStatus)
+			}
+			if tt.err != nil {
+				events := span.Events()
+				if len(events) == 0 || events[0].Name != "exception" {
+					t.Errorf("span events = %v, want the handler error recorded", events)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleMessageEventIgnoresEditsAndDeletions(t *testing.T) {
+	useConfig(t, config.Default())
+	api, server := newAPI(t)
+
+	tests := []struct {
+		name    string
+		subtype string
+		wantRun bool
+	}{
+		{name: "new message", wantRun: true},
+		{name: "edited message", subtype: slack.MsgSubTypeMessageChanged},
+		{name: "deleted message", subtype: slack.MsgSubTypeMessageDeleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useHistory(t, 10)
+			ran := false
+			useCommand(t, "probe", Command{Handler: func(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+				ran = true
+				return nil
+			}})
+			event := message("U1", "C1", "probe")
+			event.SubType = tt.subtype
+
+			HandleMessageEvent(api, "T1", event)
+
+			if ran != tt.wantRun {
+				t.Errorf("command ran = %v, want %v", ran, tt.wantRun)
+			}
+		})
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("posted %d messages, want none", len(messages))
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// useConfig makes cfg the current configuration until the test ends.
+func useConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	previous := config.Get()
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(previous) })
+}
+
+// useHistory replaces the shared command history with an empty one of the
+// given size until the test ends.
+func useHistory(t *testing.T, size int) *History {
+	t.Helper()
+	previous := commandHistory()
+	history = NewHistory(size)
+	t.Cleanup(func() { history = previous })
+	return history
+}
+
+// useCommand registers the command under name until the test ends.
+func useCommand(t *testing.T, name string, command Command) {
+	t.Helper()
+	previous, existed := commandRegistry[name]
+	commandRegistry[name] = command
+	t.Cleanup(func() {
+		if existed {
+			commandRegistry[name] = previous
+		} else {
+			delete(commandRegistry, name)
+		}
+	})
+}
+
+// newAPI starts a fake Slack Web API and returns a client talking to it.
+func newAPI(t *testing.T) (*slack.Client, *slacktest.Server) {
+	t.Helper()
+	server := slacktest.NewServer(t)
+	return server.Client(), server
+}
+
+// message returns a message event from the user in the channel.
+func message(user, channel, text string) *slackevents.MessageEvent {
+	return &slackevents.MessageEvent{Type: "message", User: user, Channel: channel, Text: text}
+}
+
+// useCooldowns starts the test with no command cooling down, and restores
+// the cooldowns when it ends.
+func useCooldowns(t *testing.T) *cooldowns {
+	t.Helper()
+	previous := commandCooldowns
+	commandCooldowns = &cooldowns{until: make(map[string]time.Time)}
+	t.Cleanup(func() { commandCooldowns = previous })
+	return commandCooldowns
+}
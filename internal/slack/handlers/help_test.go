@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/config"
+)
+
+func TestHelpShowsDetailedUsage(t *testing.T) {
+	cfg := config.Default()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	useHistory(t, 10)
+
+	for _, text := range []string{"help launch", "launch help", "help LAUNCH"} {
+		t.Run(text, func(t *testing.T) {
+			api, server := newAPI(t)
+
+			HandleMessageEvent(api, "T1", message("U1", "C1", text))
+
+			reply := server.WaitForMessage("Launch Command — Detailed Usage", time.Second).Text()
+			if !strings.Contains(reply, "*launch* — Launch a cluster") || !strings.Contains(reply, "_Usage:_ `launch <cluster_type> <size>`") {
+				t.Errorf("reply %q, want the description and usage of launch", reply)
+			}
+		})
+	}
+}
+
+func TestHelpOfAdminCommandForEveryone(t *testing.T) {
+	cfg := config.Default()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	useHistory(t, 10)
+	api, server := newAPI(t)
+
+	HandleMessageEvent(api, "T1", message("UOTHER", "C1", "errors help"))
+
+	server.WaitForMessage("*errors* — Show the most recent errors", time.Second)
+	for _, call := range server.Messages() {
+		if strings.Contains(call.Text(), "restricted to bot administrators") {
+			t.Errorf("help of an admin command denied to a non-admin")
+		}
+	}
+}
+
+func TestHelpOfUnknownCommand(t *testing.T) {
+	useConfig(t, config.Default())
+	useHistory(t, 10)
+	api, server := newAPI(t)
+
+	HandleMessageEvent(api, "T1", message("U1", "C1", "help frobnicate"))
+
+	server.WaitForMessage("Unknown command: *frobnicate*", time.Second)
+}
+
+func TestHelpShowsExamples(t *testing.T) {
+	useConfig(t, config.Default())
+	useHistory(t, 10)
+	api, server := newAPI(t)
+
+	HandleMessageEvent(api, "T1", message("U1", "C1", "help list"))
+	reply := server.WaitForMessage("*list* — ", time.Second).Text()
+	if !strings.Contains(reply, "_Examples:_\n• `list`\n• `list --by-owner`\n") {
+		t.Errorf("reply %q, want the examples of list, one per line", reply)
+	}
+
+	HandleMessageEvent(api, "T1", message("U1", "C1", "help ping"))
+	if reply := server.WaitForMessage("*ping* — ", time.Second).Text(); strings.Contains(reply, "Examples") {
+		t.Errorf("reply %q lists examples for a command without any", reply)
+	}
+}
+
+func TestExamplesInvokeTheirCommand(t *testing.T) {
+	for name, cmd := range commandRegistry {
+		for _, example := range cmd.Examples {
+			if fields := strings.Fields(example); len(fields) == 0 || fields[0] != name {
+				t.Errorf("example %q of %s does not invoke %s", example, name, name)
+			}
+		}
+	}
+	for _, name := range []string{"launch", "list", "done"} {
+		if len(commandRegistry[name].Examples) == 0 {
+			t.Errorf("%s has no examples", name)
+		}
+	}
+}
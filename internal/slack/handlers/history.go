@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// defaultHistoryEntries is how many entries `history` prints when no count is given.
+const defaultHistoryEntries = 10
+
+// Outcomes recorded for each dispatched command.
+const (
+	outcomeExecuted = "executed"
+	outcomeFailed   = "failed"
+	outcomeDenied   = "denied"
+	outcomeUnknown  = "unknown command"
+
+	outcomeMaintenance = "refused (maintenance)"
+	outcomeNotReady    = "refused (backend not ready)"
+	outcomeCooldown    = "refused (cooldown)"
+)
+
+// HistoryEntry records a single command received by the bot.
+type HistoryEntry struct {
+	User    string
+	Command string
+	Args    []string
+	Time    time.Time
+	Outcome string
+}
+
+// History is a fixed-size ring buffer of recent commands.
+// Once full, adding an entry evicts the oldest one.
+// It is safe for concurrent use.
+type History struct {
+	mu      sync.Mutex
+	entries []HistoryEntry
+	next    int
+	full    bool
+}
+
+// NewHistory creates a History that keeps at most size entries.
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = 1
+	}
+	return &History{entries: make([]HistoryEntry, size)}
+}
+
+// Add appends an entry, evicting the oldest one if the buffer is full.
+func (h *History) Add(entry HistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Last returns up to n of the most recent entries, newest first.
+func (h *History) Last(n int) []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	count := h.next
+	if h.full {
+		count = len(h.entries)
+	}
+	if n > count {
+		n = count
+	}
+
+	result := make([]HistoryEntry, 0, n)
+	for i := 1; i <= n; i++ {
+		idx := (h.next - i + len(h.entries)) % len(h.entries)
+		result = append(result, h.entries[idx])
+	}
+	return result
+}
+
+var (
+	historyOnce sync.Once
+	history     *History
+)
+
+// commandHistory returns the shared history buffer, sized from the configuration
+// the first time it is used.
+func commandHistory() *History {
+	historyOnce.Do(func() {
+		history = NewHistory(config.Get().HistorySize)
+	})
+	return history
+}
+
+// recordCommand stores a dispatched command in the shared history buffer.
+func recordCommand(event *slackevents.MessageEvent, cmd string, args []string, outcome string) {
+	commandHistory().Add(HistoryEntry{
+		User:    event.User,
+		Command: cmd,
+		Args:    args,
+		Time:    time.Now(),
+		Outcome: outcome,
+	})
+}
+
+// commandOutcome is the outcome of a command whose handler returned err:
+// failed, with the category the error was reported under, or executed.
+func commandOutcome(err error) string {
+	if err != nil {
+		return outcomeFailed + " (" + commands.CategoryOf(err).String() + ")"
+	}
+	return outcomeExecuted
+}
+
+// formatHistory renders history entries as a Slack-friendly list.
+func formatHistory(entries []HistoryEntry) string {
+	templates := config.Get().Messages
+	if len(entries) == 0 {
+		return templates.HistoryEmpty
+	}
+
+	var b strings.Builder
+	b.WriteString(messages.Render(templates.HistoryHeader, messages.Data{"Count": len(entries)}))
+	for _, e := range entries {
+		b.WriteString(messages.Render(templates.HistoryEntry, messages.Data{
+			"Time":    e.Time.Format("2006-01-02 15:04:05"),
+			"User":    e.User,
+			"Command": strings.TrimSpace(e.Command + " " + strings.Join(e.Args, " ")),
+			"Outcome": e.Outcome,
+		}))
+	}
+	return b.String()
+}
+
+// handleHistory prints the last N commands received by the bot.
+// An optional argument selects how many entries to show.
+func handleHistory(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	n := defaultHistoryEntries
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed <= 0 {
+			return &commands.CommandError{Message: messages.Render(config.Get().Messages.HistoryInvalidCount, messages.Data{"Count": args[0]})}
+		}
+		n = parsed
+	}
+
+	respond.Text(api, event.Channel, formatHistory(commandHistory().Last(n)))
+	return nil
+}
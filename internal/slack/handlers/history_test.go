@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+)
+
+func TestHistoryEvictsOldest(t *testing.T) {
+	h := NewHistory(3)
+	for i := 1; i <= 5; i++ {
+		h.Add(HistoryEntry{Command: fmt.Sprintf("cmd%d", i)})
+	}
+
+	var got []string
+	for _, entry := range h.Last(10) {
+		got = append(got, entry.Command)
+	}
+	if want := []string{"cmd5", "cmd4", "cmd3"}; !slices.Equal(got, want) {
+		t.Errorf("Last(10) = %v, want %v", got, want)
+	}
+}
+
+func TestHistoryLast(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		added int
+		n     int
+		want  []string
+	}{
+		{name: "empty", size: 3, added: 0, n: 5, want: nil},
+		{name: "partially filled", size: 3, added: 2, n: 5, want: []string{"cmd2", "cmd1"}},
+		{name: "fewer than stored", size: 3, added: 3, n: 2, want: []string{"cmd3", "cmd2"}},
+		{name: "exactly full", size: 3, added: 3, n: 3, want: []string{"cmd3", "cmd2", "cmd1"}},
+		{name: "wrapped", size: 3, added: 4, n: 3, want: []string{"cmd4", "cmd3", "cmd2"}},
+		{name: "invalid size keeps one", size: 0, added: 2, n: 5, want: []string{"cmd2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHistory(tt.size)
+			for i := 1; i <= tt.added; i++ {
+				h.Add(HistoryEntry{Command: fmt.Sprintf("cmd%d", i)})
+			}
+			var got []string
+			for _, entry := range h.Last(tt.n) {
+				got = append(got, entry.Command)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Last(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatHistory(t *testing.T) {
+	useConfig(t, config.Default())
+
+	if got, want := formatHistory(nil), config.Default().Messages.HistoryEmpty; got != want {
+		t.Errorf("formatHistory(nil) = %q, want %q", got, want)
+	}
+
+	at := time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC)
+	got := formatHistory([]HistoryEntry{
+		{User: "U2", Command: "done", Args: []string{"spoticus-k8s-abcde"}, Time: at.Add(time.Minute), Outcome: outcomeFailed},
+		{User: "U1", Command: "list", Time: at, Outcome: outcomeExecuted},
+	})
+	want := "🕘 *Command History* (last 2)\n\n" +
+		"• 2025-07-01 09:31:00 <@U2> `done spoticus-k8s-abcde` — failed\n" +
+		"• 2025-07-01 09:30:00 <@U1> `list` — executed\n"
+	if got != want {
+		t.Errorf("formatHistory() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHandleMessageEventRecordsOutcomeAfterHandler(t *testing.T) {
+	useConfig(t, config.Default())
+	api, _ := newAPI(t)
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "succeeds", want: outcomeExecuted},
+		{name: "fails", err: errors.New("boom"), want: "failed (internal)"},
+		{name: "fails validation", err: &commands.CommandError{Category: commands.CategoryValidation, Message: "❌ Unknown size"}, want: "failed (validation)"},
+		{name: "fails in the backend", err: &commands.CommandError{Category: commands.CategoryBackend, Message: "❌ Failed"}, want: "failed (backend)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := useHistory(t, 10)
+			var during []HistoryEntry
+			useCommand(t, "probe", Command{Handler: func(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+				during = h.Last(10)
+				return tt.err
+			}})
+
+			HandleMessageEvent(api, "T1", message("U1", "C1", "probe now"))
+
+			if len(during) != 0 {
+				t.Errorf("command recorded before its handler returned: %+v", during)
+			}
+			entries := h.Last(10)
+			if len(entries) != 1 {
+				t.Fatalf("recorded %d entries, want 1", len(entries))
+			}
+			if got := entries[0]; got.Outcome != tt.want || got.Command != "probe" || got.User != "U1" || !slices.Equal(got.Args, []string{"now"}) {
+				t.Errorf("recorded %+v, want outcome %q for probe now from U1", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/slack-go/slack"
+
+	"github.com/flacatus/spoticus/internal/slack/commands"
+	"github.com/flacatus/spoticus/internal/tracing"
+)
+
+// ActionHandler handles a click on an interactive element, e.g. a button,
+// of a message the bot posted.
+type ActionHandler func(ctx context.Context, api *slack.Client, callback slack.InteractionCallback, action *slack.BlockAction)
+
+// actionRegistry maps the action IDs of the bot's interactive elements to
+// their handlers.
+var actionRegistry = map[string]ActionHandler{
+	commands.ActionListPrev: commands.HandleListPage,
+	commands.ActionListNext: commands.HandleListPage,
+
+	commands.ActionFailedRetry:  commands.HandleFailedAction,
+	commands.ActionFailedDelete: commands.HandleFailedAction,
+}
+
+// HandleInteraction routes the block actions of an interaction to their
+// handlers. Other interactions, and actions the bot does not know, are
+// ignored.
+func HandleInteraction(api *slack.Client, callback slack.InteractionCallback) {
+	if callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+	for _, action := range callback.ActionCallback.BlockActions {
+		handler, ok := actionRegistry[action.ActionID]
+		if !ok {
+			log.Printf("Ignoring unknown action %q from user %s", action.ActionID, callback.User.ID)
+			continue
+		}
+
+		ctx, span := tracing.Start(context.Background(), "action "+action.ActionID,
+			tracing.AttrUser.String(callback.User.ID),
+			tracing.AttrChannel.String(callback.Container.ChannelID))
+		ctx, ok = commands.WithTeam(ctx, callback.Team.ID)
+		if !ok {
+			log.Printf("Dropping action %q from user %s without a workspace ID under tenant isolation", action.ActionID, callback.User.ID)
+			span.End()
+			continue
+		}
+		handler(ctx, api, callback, action)
+		span.End()
+	}
+}
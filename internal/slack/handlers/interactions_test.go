@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+)
+
+func TestHandleInteractionRoutesBlockActions(t *testing.T) {
+	useConfig(t, config.Default())
+	api, _ := newAPI(t)
+	var handled []string
+	previous := actionRegistry["probe_action"]
+	actionRegistry["probe_action"] = func(ctx context.Context, api *slack.Client, callback slack.InteractionCallback, action *slack.BlockAction) {
+		handled = append(handled, action.Value)
+	}
+	t.Cleanup(func() {
+		if previous == nil {
+			delete(actionRegistry, "probe_action")
+		} else {
+			actionRegistry["probe_action"] = previous
+		}
+	})
+
+	callback := slack.InteractionCallback{
+		Type: slack.InteractionTypeBlockActions,
+		User: slack.User{ID: "U1"},
+		Team: slack.Team{ID: "T1"},
+	}
+	callback.ActionCallback.BlockActions = []*slack.BlockAction{
+		{ActionID: "probe_action", Value: "first"},
+		{ActionID: "unknown_action", Value: "ignored"},
+		{ActionID: "probe_action", Value: "second"},
+	}
+	HandleInteraction(api, callback)
+	if len(handled) != 2 || handled[0] != "first" || handled[1] != "second" {
+		t.Errorf("handled %q, want the two known actions in order", handled)
+	}
+
+	handled = nil
+	callback.Type = slack.InteractionTypeViewSubmission
+	HandleInteraction(api, callback)
+	if len(handled) != 0 {
+		t.Errorf("handled %q for a view submission, want it ignored", handled)
+	}
+}
+
+func TestListPageButtonsAreRouted(t *testing.T) {
+	for _, action := range []string{commands.ActionListPrev, commands.ActionListNext} {
+		if actionRegistry[action] == nil {
+			t.Errorf("action %s has no handler", action)
+		}
+	}
+}
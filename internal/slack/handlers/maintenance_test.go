@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// useMaintenance turns maintenance mode on through the admin command, on fake
+// Kubernetes clients, until the test ends.
+func useMaintenance(t *testing.T) {
+	t.Helper()
+	kube := slacktest.NewKube()
+	commands.SetKubernetesClientFactory(func() (*commands.KubernetesClients, error) {
+		return &commands.KubernetesClients{KubeClient: kube.KubeClient, CrClient: kube.CrClient, DynamicClient: kube.DynamicClient}, nil
+	})
+	t.Cleanup(func() { commands.SetKubernetesClientFactory(nil) })
+
+	api, _ := newAPI(t)
+	if err := commands.HandleMaintenance(context.Background(), api, message("UADMIN", "C1", "maintenance on"), []string{"on"}); err != nil {
+		t.Fatalf("turning maintenance on: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := commands.HandleMaintenance(context.Background(), api, message("UADMIN", "C1", "maintenance off"), []string{"off"}); err != nil {
+			t.Errorf("turning maintenance off: %v", err)
+		}
+	})
+}
+
+func TestMaintenanceModeRefusesMutatingCommands(t *testing.T) {
+	tests := []struct {
+		command     string
+		wantRefused bool
+	}{
+		{command: "launch k8s medium", wantRefused: true},
+		{command: "done spoticus-k8s-abcde", wantRefused: true},
+		{command: "cleanup", wantRefused: true},
+		{command: "list"},
+		{command: "status spoticus-k8s-abcde"},
+		{command: "help"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Admins = []string{"UADMIN"}
+			useConfig(t, cfg)
+			useHistory(t, 10)
+			useCooldowns(t)
+			useMaintenance(t)
+			api, server := newAPI(t)
+
+			HandleMessageEvent(api, "T1", message("UADMIN", "C1", tt.command))
+
+			messages := server.Messages()
+			if len(messages) == 0 {
+				t.Fatalf("%q got no reply", tt.command)
+			}
+			refused := strings.Contains(messages[0].Text(), "is in maintenance mode, so")
+			if refused != tt.wantRefused {
+				t.Errorf("%q refused = %v, want %v; replied %q", tt.command, refused, tt.wantRefused, messages[0].Text())
+			}
+		})
+	}
+}
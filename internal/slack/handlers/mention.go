@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// mentionPrefixPattern matches the mentions addressing the bot at the start of
+// a message: user mentions (<@U123>, <@U123|name>), user-group mentions
+// (<!subteam^S123>, <!subteam^S123|@team>) and special mentions (<!here>),
+// together with the punctuation people type after them ("@spoticus: list").
+var mentionPrefixPattern = regexp.MustCompile(`^(?:\s*[(\[]?<(?:@|!)[^>]*>[)\]]?[\s:,;.!?]*)+`)
+
+// groupMentionPattern matches user-group and special mentions anywhere in the text.
+// These never carry a command argument, unlike user mentions (`quota @alice`).
+var groupMentionPattern = regexp.MustCompile(`<!(?:subteam\^|here|channel|everyone)[^>]*>`)
+
+// commandText prepares the text of a message for tokenizing. Only the first
+// line with anything besides mentions is the command, so that text pasted on
+// the following lines (e.g. a log excerpt) does not become arguments.
+func commandText(text string) string {
+	for _, line := range strings.FieldsFunc(text, isLineBreak) {
+		if line = commandLine(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// isLineBreak reports whether r ends a line, including the Unicode line and
+// paragraph separators.
+func isLineBreak(r rune) bool {
+	switch r {
+	case '\n', '\r', '\v', '\f', '\u0085', '\u2028', '\u2029':
+		return true
+	}
+	return false
+}
+
+// commandLine normalizes a line of a message: every Unicode space (tab,
+// non-breaking space, ...) becomes a plain space, and it drops invisible
+// control and formatting characters (e.g. zero-width spaces pasted along
+// with a command), the mentions addressing the bot and any group mentions.
+func commandLine(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, text)
+	text = mentionPrefixPattern.ReplaceAllString(text, "")
+	text = groupMentionPattern.ReplaceAllString(text, " ")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+)
+
+func TestCommandText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain command", text: "list", want: "list"},
+		{name: "user mention", text: "<@UBOT> list", want: "list"},
+		{name: "labelled user mention", text: "<@UBOT|spoticus> list", want: "list"},
+		{name: "mention with colon", text: "<@UBOT>: launch k8s medium", want: "launch k8s medium"},
+		{name: "mention with comma", text: "<@UBOT>, status spoticus-k8s-a", want: "status spoticus-k8s-a"},
+		{name: "parenthesized mention", text: "(<@UBOT>) list", want: "list"},
+		{name: "user-group mention", text: "<!subteam^S123|@platform> list", want: "list"},
+		{name: "group then bot mention", text: "<!subteam^S123> <@UBOT> list", want: "list"},
+		{name: "special mention", text: "<!here> list", want: "list"},
+		{name: "user mention argument kept", text: "<@UBOT> quota <@U2>", want: "quota <@U2>"},
+		{name: "zero-width space", text: "<@UBOT> \u200blist", want: "list"},
+		{name: "non-breaking space", text: "<@UBOT> launch\u00a0k8s", want: "launch k8s"},
+		{name: "tab", text: "launch\tk8s", want: "launch k8s"},
+		{name: "pasted lines ignored", text: "<@UBOT> logs spoticus-k8s-a\nerror: boom", want: "logs spoticus-k8s-a"},
+		{name: "command on the next line", text: "<@UBOT>\nlist", want: "list"},
+		{name: "windows line endings", text: "status spoticus-k8s-a\r\nplease", want: "status spoticus-k8s-a"},
+		{name: "unicode line separator", text: "list\u2028--by-owner", want: "list"},
+		{name: "ideographic and narrow spaces", text: "launch\u3000k8s\u202fmedium", want: "launch k8s medium"},
+		{name: "blank lines before the command", text: "\n \u00a0\n\tlist", want: "list"},
+		{name: "mention only", text: "<@UBOT>", want: ""},
+		{name: "empty", text: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandText(tt.text); got != tt.want {
+				t.Errorf("commandText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandTextDropsGroupMentionsAmongArguments(t *testing.T) {
+	got := strings.Fields(commandText("<@UBOT> launch <!subteam^S123|@platform> k8s <!channel> medium"))
+	if want := []string{"launch", "k8s", "medium"}; !slices.Equal(got, want) {
+		t.Errorf("command fields = %q, want %q", got, want)
+	}
+}
+
+func TestHandleMessageEventTokenizesFirstLine(t *testing.T) {
+	useConfig(t, config.Default())
+	useHistory(t, 10)
+	api, _ := newAPI(t)
+	var got [][]string
+	useCommand(t, "probe", Command{Handler: func(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+		got = append(got, args)
+		return nil
+	}})
+
+	for _, text := range []string{
+		"probe\u00a0a\tb",
+		"<@UBOT>  probe   a  b",
+		"probe a b\nstack trace:\n\tat main.go:12",
+		"<@UBOT>\r\nprobe\u2003a b",
+	} {
+		HandleMessageEvent(api, "T1", message("U1", "C1", text))
+	}
+
+	if len(got) != 4 {
+		t.Fatalf("handler ran %d times, want 4", len(got))
+	}
+	for i, args := range got {
+		if want := []string{"a", "b"}; !slices.Equal(args, want) {
+			t.Errorf("message %d: args %q, want %q", i, args, want)
+		}
+	}
+}
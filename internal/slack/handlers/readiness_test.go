@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/health"
+)
+
+// useBackendReady records a backend probe that failed, or succeeded when
+// ready, until the test ends.
+func useBackendReady(t *testing.T, ready bool) {
+	t.Helper()
+	if ready {
+		health.Default().SetBackend(nil)
+	} else {
+		health.Default().SetBackend(errors.New("connection refused"))
+	}
+	t.Cleanup(func() { health.Default().SetBackend(nil) })
+}
+
+func TestBackendCommandsGatedOnReadiness(t *testing.T) {
+	tests := []struct {
+		name        string
+		backend     bool
+		ready       bool
+		wantRefused bool
+	}{
+		{name: "backend command while not ready", backend: true, wantRefused: true},
+		{name: "backend command while ready", backend: true, ready: true},
+		{name: "other command while not ready"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, config.Default())
+			h := useHistory(t, 10)
+			useCooldowns(t)
+			useBackendReady(t, tt.ready)
+			api, server := newAPI(t)
+			ran := false
+			useCommand(t, "probe", Command{Backend: tt.backend, Handler: func(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+				ran = true
+				return nil
+			}})
+
+			HandleMessageEvent(api, "T1", message("U1", "C1", "probe"))
+
+			if ran == tt.wantRefused {
+				t.Errorf("handler ran = %v, want %v", ran, !tt.wantRefused)
+			}
+			if !tt.wantRefused {
+				return
+			}
+			messages := server.Messages()
+			if len(messages) != 1 || !strings.Contains(messages[0].Text(), "so *probe* is unavailable for now") {
+				t.Errorf("replies %v, want the backend not ready", messages)
+			}
+			if entries := h.Last(10); len(entries) != 1 || entries[0].Outcome != outcomeNotReady {
+				t.Errorf("recorded %+v, want outcome %q", entries, outcomeNotReady)
+			}
+		})
+	}
+}
+
+func TestClusterCommandsNeedBackend(t *testing.T) {
+	for _, name := range []string{"launch", "list", "status", "done", "cleanup"} {
+		if !commandRegistry[name].Backend {
+			t.Errorf("%s is not gated on the backend readiness", name)
+		}
+	}
+	for _, name := range []string{"help", "ping", "uptime"} {
+		if commandRegistry[name].Backend {
+			t.Errorf("%s is gated on the backend readiness, want it available to diagnose the backend", name)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/config"
+)
+
+func TestReliabilityIsAdminOnly(t *testing.T) {
+	cfg := config.Default()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	useHistory(t, 10)
+	useCooldowns(t)
+	api, server := newAPI(t)
+
+	HandleMessageEvent(api, "T1", message("UOTHER", "C1", "reliability"))
+	server.WaitForMessage("restricted to bot administrators", time.Second)
+
+	HandleMessageEvent(api, "T1", message("UADMIN", "C1", "reliability"))
+	server.WaitForMessage("Launch Reliability", time.Second)
+}
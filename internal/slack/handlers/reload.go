@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/messages"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+	"github.com/flacatus/spoticus/internal/slack/respond"
+)
+
+// handleReload re-reads the configuration and swaps it in if it is valid,
+// reporting the settings that changed. An invalid configuration is rejected
+// and the previous one stays in effect.
+func handleReload(ctx context.Context, api *slack.Client, event *slackevents.MessageEvent, args []string) error {
+	changes, err := config.Reload()
+	if err != nil {
+		return &commands.CommandError{
+			Message: messages.Render(config.Get().Messages.ReloadFailed, messages.Data{"Error": err}),
+			Log:     "reloading the configuration: " + err.Error(),
+			Err:     err,
+		}
+	}
+
+	log.Printf("Config reloaded by %s: %d change(s)", event.User, len(changes))
+
+	// Render with the freshly loaded templates, since they may have just changed.
+	templates := config.Get().Messages
+	if len(changes) == 0 {
+		respond.Text(api, event.Channel, templates.ReloadUnchanged)
+		return nil
+	}
+	respond.Text(api, event.Channel, messages.Render(templates.ReloadChanged, messages.Data{"Changes": changes}))
+	return nil
+}
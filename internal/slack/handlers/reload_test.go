@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+)
+
+// useConfigFile loads the configuration from a file holding data, making it
+// the current one until the test ends, and returns the file's path.
+func useConfigFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SPOTICUS_CONFIG", path)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	useConfig(t, cfg)
+	return path
+}
+
+func TestReloadReportsChanges(t *testing.T) {
+	path := useConfigFile(t, "quota:\n  default: 2\n")
+	if err := os.WriteFile(path, []byte("quota:\n  default: 4\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	api, server := newAPI(t)
+
+	if err := handleReload(context.Background(), api, message("UADMIN", "C1", "reload"), nil); err != nil {
+		t.Fatalf("handleReload: %v", err)
+	}
+	reply := server.WaitForMessage("Configuration reloaded", time.Second)
+	if !strings.Contains(reply.Text(), "• quota: ") {
+		t.Errorf("reply %q does not list the quota change", reply.Text())
+	}
+	if got := config.Get().Quota.Default; got != 4 {
+		t.Errorf("quota after reload = %d, want 4", got)
+	}
+}
+
+func TestReloadReportsNoChanges(t *testing.T) {
+	useConfigFile(t, "quota:\n  default: 2\n")
+	api, server := newAPI(t)
+
+	if err := handleReload(context.Background(), api, message("UADMIN", "C1", "reload"), nil); err != nil {
+		t.Fatalf("handleReload: %v", err)
+	}
+	server.WaitForMessage("No changes detected", time.Second)
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	path := useConfigFile(t, "quota:\n  default: 2\n")
+	previous := config.Get()
+	if err := os.WriteFile(path, []byte("quota:\n  default: 2\ndefaultOwner: not-a-user\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	api, server := newAPI(t)
+
+	err := handleReload(context.Background(), api, message("UADMIN", "C1", "reload"), nil)
+	var cmdErr *commands.CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Message, "keeping the current configuration") || !strings.Contains(cmdErr.Message, "defaultOwner") {
+		t.Errorf("handleReload() = %v, want the validation error", err)
+	}
+	if config.Get() != previous {
+		t.Errorf("the configuration was replaced, want the previous one kept")
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("posted %d messages, want the error left to the dispatcher", len(messages))
+	}
+}
+
+func TestReloadIsAdminOnly(t *testing.T) {
+	cfg := config.Default()
+	cfg.Admins = []string{"UADMIN"}
+	useConfig(t, cfg)
+	useHistory(t, 10)
+	api, server := newAPI(t)
+
+	HandleMessageEvent(api, "T1", message("UOTHER", "C1", "reload"))
+
+	server.WaitForMessage("restricted to bot administrators", time.Second)
+	if config.Get() != cfg {
+		t.Errorf("a non-admin reloaded the configuration")
+	}
+}
@@ -0,0 +1,44 @@
+package slack
+
+import (
+	"sync"
+)
+
+// workerPool runs submitted tasks concurrently on a fixed number of workers,
+// buffering at most queueSize pending tasks. Task ordering is not preserved.
+type workerPool struct {
+	queue chan func()
+	wg    sync.WaitGroup
+}
+
+// newWorkerPool starts a pool with the given number of workers and queue capacity.
+func newWorkerPool(workers, queueSize int) *workerPool {
+	p := &workerPool{queue: make(chan func(), queueSize)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for task := range p.queue {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// TrySubmit queues the task without blocking.
+// It returns false if the queue is full and the task was not accepted.
+func (p *workerPool) TrySubmit(task func()) bool {
+	select {
+	case p.queue <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops accepting tasks and waits for the queued ones to finish.
+func (p *workerPool) Close() {
+	close(p.queue)
+	p.wg.Wait()
+}
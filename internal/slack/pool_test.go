@@ -0,0 +1,79 @@
+package slack
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolRunsTasksConcurrently(t *testing.T) {
+	const workers = 3
+	pool := newWorkerPool(workers, workers)
+	defer pool.Close()
+
+	var running sync.WaitGroup
+	running.Add(workers)
+	release := make(chan struct{})
+	for range workers {
+		if !pool.TrySubmit(func() {
+			running.Done()
+			<-release
+		}) {
+			t.Fatal("task refused by an idle pool")
+		}
+	}
+
+	started := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(started)
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the %d tasks did not run at the same time", workers)
+	}
+	close(release)
+}
+
+func TestWorkerPoolBoundsQueue(t *testing.T) {
+	pool := newWorkerPool(1, 2)
+	release := make(chan struct{})
+	busy := make(chan struct{})
+	pool.TrySubmit(func() {
+		close(busy)
+		<-release
+	})
+	<-busy
+
+	var ran atomic.Int32
+	task := func() { ran.Add(1) }
+	if !pool.TrySubmit(task) || !pool.TrySubmit(task) {
+		t.Fatal("tasks refused with room left in the queue")
+	}
+	if pool.TrySubmit(task) {
+		t.Error("task accepted with the queue full, want it refused")
+	}
+
+	close(release)
+	pool.Close()
+	if got := ran.Load(); got != 2 {
+		t.Errorf("%d queued tasks ran, want the 2 accepted ones", got)
+	}
+}
+
+func TestWorkerPoolCloseWaitsForQueuedTasks(t *testing.T) {
+	pool := newWorkerPool(2, 10)
+	var ran atomic.Int32
+	for range 10 {
+		pool.TrySubmit(func() {
+			time.Sleep(time.Millisecond)
+			ran.Add(1)
+		})
+	}
+	pool.Close()
+	if got := ran.Load(); got != 10 {
+		t.Errorf("%d tasks ran before Close returned, want 10", got)
+	}
+}
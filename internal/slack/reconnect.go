@@ -0,0 +1,73 @@
+package slack
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/config"
+)
+
+// ErrReconnectTimeout is returned by Run when the bot could not reconnect to
+// Slack within the configured reconnect.maxElapsed. Exiting lets the
+// supervisor restart it with a fresh process.
+var ErrReconnectTimeout = errors.New("could not reconnect to slack")
+
+// reconnectDelay returns how long to wait before the next connection attempt
+// after the given number of consecutive failures (at least one): the initial
+// wait, multiplied for every further failure up to the maximum interval.
+func reconnectDelay(policy config.Reconnect, failures int) time.Duration {
+	delay := float64(policy.Initial)
+	ceiling := float64(max(policy.MaxInterval, policy.Initial))
+	for i := 1; i < failures && delay < ceiling; i++ {
+		delay *= policy.Multiplier
+	}
+	return time.Duration(min(delay, ceiling))
+}
+
+// reconnectBackoff tracks the consecutive connection failures since the last
+// successful connection. It is shared by the event loop, which reports
+// successes, and the loop running the event source, which reports failures.
+type reconnectBackoff struct {
+	policy config.Reconnect
+
+	mu       sync.Mutex
+	failures int
+	since    time.Time
+}
+
+// success resets the backoff after a successful connection.
+func (b *reconnectBackoff) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.since = time.Time{}
+}
+
+// failure records a failed connection at now and returns how long to wait
+// before the next attempt. It returns false once the failures have lasted
+// longer than the policy's maximum elapsed time, or would by the end of the
+// wait.
+func (b *reconnectBackoff) failure(now time.Time) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures == 0 {
+		b.since = now
+	}
+	b.failures++
+	delay := reconnectDelay(b.policy, b.failures)
+	if now.Add(delay).Sub(b.since) > time.Duration(b.policy.MaxElapsed) {
+		return 0, false
+	}
+	return delay, true
+}
+
+// elapsed returns how long the connection has been failing at now.
+func (b *reconnectBackoff) elapsed(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures == 0 {
+		return 0
+	}
+	return now.Sub(b.since).Round(time.Second)
+}
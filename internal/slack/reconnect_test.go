@@ -0,0 +1,125 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestReconnectDelay(t *testing.T) {
+	policy := config.Reconnect{
+		Initial:     config.Duration(time.Second),
+		MaxInterval: config.Duration(10 * time.Second),
+		Multiplier:  2,
+		MaxElapsed:  config.Duration(time.Minute),
+	}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: time.Second},
+		{failures: 2, want: 2 * time.Second},
+		{failures: 3, want: 4 * time.Second},
+		{failures: 4, want: 8 * time.Second},
+		{failures: 5, want: 10 * time.Second},
+		{failures: 50, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := reconnectDelay(policy, tt.failures); got != tt.want {
+			t.Errorf("reconnectDelay() after %d failures = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+
+	constant := policy
+	constant.Multiplier = 1
+	if got := reconnectDelay(constant, 5); got != time.Second {
+		t.Errorf("reconnectDelay() with a multiplier of 1 = %s, want the initial wait", got)
+	}
+	fractional := policy
+	fractional.Multiplier = 1.5
+	if got := reconnectDelay(fractional, 3); got != 2250*time.Millisecond {
+		t.Errorf("reconnectDelay() with a multiplier of 1.5 = %s, want 2.25s", got)
+	}
+}
+
+func TestReconnectBackoffGivesUpAfterMaxElapsed(t *testing.T) {
+	backoff := &reconnectBackoff{policy: config.Reconnect{
+		Initial:     config.Duration(time.Second),
+		MaxInterval: config.Duration(4 * time.Second),
+		Multiplier:  2,
+		MaxElapsed:  config.Duration(10 * time.Second),
+	}}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	now := start
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		delay, ok := backoff.failure(now)
+		if !ok || delay != want {
+			t.Fatalf("failure() at +%s = %s, %v, want %s and a retry", now.Sub(start), delay, ok, want)
+		}
+		now = now.Add(delay)
+	}
+	// The next wait of 4s would end 11s after the first failure.
+	if delay, ok := backoff.failure(now); ok {
+		t.Errorf("failure() at +%s = %s, want no retry past the maximum elapsed time", now.Sub(start), delay)
+	}
+	if got := backoff.elapsed(now); got != 7*time.Second {
+		t.Errorf("elapsed() = %s, want 7s", got)
+	}
+
+	backoff.success()
+	if got := backoff.elapsed(now); got != 0 {
+		t.Errorf("elapsed() after a success = %s, want 0", got)
+	}
+	if delay, ok := backoff.failure(now.Add(time.Hour)); !ok || delay != time.Second {
+		t.Errorf("failure() after a success = %s, %v, want the schedule restarted", delay, ok)
+	}
+}
+
+// failingSource is an EventSource that can never connect.
+type failingSource struct {
+	*ChannelSource
+}
+
+func (failingSource) RunContext(context.Context) error {
+	return errors.New("dial tcp: connection refused")
+}
+
+func TestRunContextFailsAfterMaxElapsed(t *testing.T) {
+	cfg := testConfig()
+	cfg.Reconnect = config.Reconnect{
+		Initial:     config.Duration(time.Millisecond),
+		MaxInterval: config.Duration(5 * time.Millisecond),
+		Multiplier:  2,
+		MaxElapsed:  config.Duration(50 * time.Millisecond),
+	}
+	previous := config.Get()
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(previous) })
+	commands.SetKubernetesClientFactory(func() (*commands.KubernetesClients, error) {
+		kube := slacktest.NewKube()
+		return &commands.KubernetesClients{KubeClient: kube.KubeClient, CrClient: kube.CrClient, DynamicClient: kube.DynamicClient}, nil
+	})
+	t.Cleanup(func() { commands.SetKubernetesClientFactory(nil) })
+
+	bot, err := NewWithSource(slacktest.NewServer(t).Client(), failingSource{NewChannelSource(10)})
+	if err != nil {
+		t.Fatalf("NewWithSource: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- bot.RunContext(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrReconnectTimeout) {
+			t.Errorf("RunContext() = %v, want ErrReconnectTimeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext kept retrying past the maximum elapsed time")
+	}
+}
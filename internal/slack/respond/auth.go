@@ -0,0 +1,54 @@
+package respond
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+// authErrors are the Slack API errors meaning the token is no longer valid,
+// e.g. because it was rotated or the app was uninstalled.
+var authErrors = map[string]bool{
+	"invalid_auth":     true,
+	"not_authed":       true,
+	"token_revoked":    true,
+	"token_expired":    true,
+	"account_inactive": true,
+}
+
+var (
+	authFailureMu      sync.Mutex
+	authFailureHandler func(error)
+)
+
+// IsAuthError reports whether err is a Slack API error caused by an invalid token.
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var resp slack.SlackErrorResponse
+	if errors.As(err, &resp) {
+		return authErrors[resp.Err]
+	}
+	return authErrors[err.Error()]
+}
+
+// OnAuthError registers fn to be called whenever a post fails because Slack
+// rejected the token. Retrying is pointless in that case, so the run loop uses
+// it to shut down instead of logging the same failure forever.
+func OnAuthError(fn func(error)) {
+	authFailureMu.Lock()
+	defer authFailureMu.Unlock()
+	authFailureHandler = fn
+}
+
+// reportAuthError notifies the registered handler, if any, of an auth failure.
+func reportAuthError(err error) {
+	authFailureMu.Lock()
+	fn := authFailureHandler
+	authFailureMu.Unlock()
+	if fn != nil {
+		fn(err)
+	}
+}
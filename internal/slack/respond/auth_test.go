@@ -0,0 +1,51 @@
+package respond
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestIsAuthError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil},
+		{name: "invalid auth", err: slack.SlackErrorResponse{Err: "invalid_auth"}, want: true},
+		{name: "token revoked", err: slack.SlackErrorResponse{Err: "token_revoked"}, want: true},
+		{name: "wrapped token expired", err: fmt.Errorf("posting: %w", slack.SlackErrorResponse{Err: "token_expired"}), want: true},
+		{name: "plain error string", err: errors.New("not_authed"), want: true},
+		{name: "other API error", err: slack.SlackErrorResponse{Err: "channel_not_found"}},
+		{name: "rate limited", err: &slack.RateLimitedError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAuthError(tt.err); got != tt.want {
+				t.Errorf("IsAuthError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostReportsAuthError(t *testing.T) {
+	var reported error
+	OnAuthError(func(err error) { reported = err })
+	t.Cleanup(func() { OnAuthError(nil) })
+	server := slacktest.NewServer(t)
+	server.Handle("chat.postMessage", func(url.Values) map[string]any {
+		return map[string]any{"ok": false, "error": "invalid_auth"}
+	})
+
+	Text(server.Client(), "C1", "hello")
+
+	if !IsAuthError(reported) {
+		t.Errorf("reported %v, want the auth error", reported)
+	}
+}
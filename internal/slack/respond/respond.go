@@ -0,0 +1,148 @@
+// Package respond centralizes how the bot posts messages back to Slack.
+//
+// Every handler goes through Post (or Update) so that Slack rate limiting (HTTP 429)
+// is handled consistently: the post is retried after the delay Slack asks
+// for, a bounded number of times, instead of the message being dropped.
+package respond
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// maxPostAttempts bounds how many times a message is sent when Slack rate-limits the bot.
+	maxPostAttempts = 3
+
+	// maxRetryAfter caps how long a single rate-limit wait may block the handler.
+	maxRetryAfter = 30 * time.Second
+)
+
+// Post sends a message to the channel, retrying after the requested delay when
+// Slack responds with a rate-limit error. It returns the channel and timestamp
+// of the posted message, like slack.Client.PostMessage.
+func Post(api *slack.Client, channel string, options ...slack.MsgOption) (string, string, error) {
+	var respChannel, timestamp string
+	err := withRetry("posting to "+channel, func() error {
+		var err error
+		respChannel, timestamp, err = api.PostMessage(channel, options...)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return respChannel, timestamp, nil
+}
+
+// Update replaces the text of a message the bot posted earlier, retrying on
+// rate limits like Post.
+func Update(api *slack.Client, channel, timestamp, text string) error {
+	return UpdateMessage(api, channel, timestamp, slack.MsgOptionText(text, false))
+}
+
+// UpdateMessage replaces a message the bot posted earlier with the given
+// options, e.g. to change its blocks, retrying on rate limits like Post.
+func UpdateMessage(api *slack.Client, channel, timestamp string, options ...slack.MsgOption) error {
+	return withRetry("updating a message in "+channel, func() error {
+		_, _, _, err := api.UpdateMessage(channel, timestamp, options...)
+		return err
+	})
+}
+
+// Ephemeral posts a plain text message only user can see in the channel,
+// retrying on rate limits like Post, and logs any failure.
+func Ephemeral(api *slack.Client, channel, user, text string) {
+	err := withRetry("posting an ephemeral message to "+channel, func() error {
+		_, err := api.PostEphemeral(channel, user, slack.MsgOptionText(text, false))
+		return err
+	})
+	if err != nil {
+		log.Printf("Error posting ephemeral message to %s in %s: %v", user, channel, err)
+	}
+}
+
+// Permalink returns the permanent link to a message, retrying on rate limits
+// like Post.
+func Permalink(api *slack.Client, channel, timestamp string) (string, error) {
+	var permalink string
+	err := withRetry("getting a permalink in "+channel, func() error {
+		var err error
+		permalink, err = api.GetPermalink(&slack.PermalinkParameters{Channel: channel, Ts: timestamp})
+		return err
+	})
+	return permalink, err
+}
+
+// ErrFileTooLarge is returned by Upload for content above the size limit.
+var ErrFileTooLarge = errors.New("file too large to upload")
+
+// Upload uploads a file like slack.Client.UploadFileV2Context, refusing
+// content larger than maxBytes and giving up after timeout. A timeout is
+// reported as context.DeadlineExceeded. Uploads are not retried, since a
+// partial one may already have been shared.
+func Upload(ctx context.Context, api *slack.Client, params slack.UploadFileV2Parameters, timeout time.Duration, maxBytes int) error {
+	if len(params.Content) > maxBytes {
+		return fmt.Errorf("%w: %d bytes, the limit is %d", ErrFileTooLarge, len(params.Content), maxBytes)
+	}
+	params.FileSize = len(params.Content)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_, err := api.UploadFileV2Context(ctx, params)
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("uploading %s: %w", params.Filename, ctx.Err())
+	}
+	if IsAuthError(err) {
+		reportAuthError(err)
+	}
+	return err
+}
+
+// withRetry runs call, retrying after the requested delay when Slack
+// rate-limits it, and reports auth failures to the registered handler.
+func withRetry(action string, call func() error) error {
+	var err error
+	for attempt := 1; attempt <= maxPostAttempts; attempt++ {
+		if err = call(); err == nil {
+			return nil
+		}
+
+		var rateLimited *slack.RateLimitedError
+		if !errors.As(err, &rateLimited) || attempt == maxPostAttempts {
+			break
+		}
+
+		wait := min(rateLimited.RetryAfter, maxRetryAfter)
+		log.Printf("Slack rate limited %s, retrying in %s (attempt %d/%d)",
+			action, wait, attempt, maxPostAttempts)
+		time.Sleep(wait)
+	}
+	if IsAuthError(err) {
+		reportAuthError(err)
+	}
+	return err
+}
+
+// Text posts a plain text message to the channel and logs any failure.
+func Text(api *slack.Client, channel, text string) {
+	if _, _, err := Post(api, channel, slack.MsgOptionText(text, false)); err != nil {
+		log.Printf("Error posting message to %s: %v", channel, err)
+	}
+}
+
+// Thread posts a plain text reply in the thread started by threadTS and logs
+// any failure. An empty threadTS posts to the channel itself.
+func Thread(api *slack.Client, channel, threadTS, text string) {
+	options := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if threadTS != "" {
+		options = append(options, slack.MsgOptionTS(threadTS))
+	}
+	if _, _, err := Post(api, channel, options...); err != nil {
+		log.Printf("Error posting message to %s: %v", channel, err)
+	}
+}
@@ -0,0 +1,134 @@
+package respond
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+func TestPostRetriesWhenRateLimited(t *testing.T) {
+	server := slacktest.NewServer(t)
+	server.RateLimit("chat.postMessage", 1)
+
+	channel, ts, err := Post(server.Client(), "C1", slack.MsgOptionText("hello", false))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if channel != "C1" || ts == "" {
+		t.Errorf("Post() = %q, %q, want the posted message", channel, ts)
+	}
+	if calls := server.Calls("chat.postMessage"); len(calls) != 2 {
+		t.Errorf("made %d calls, want the rate-limited one retried once", len(calls))
+	}
+}
+
+func TestPostGivesUpAfterMaxAttempts(t *testing.T) {
+	server := slacktest.NewServer(t)
+	server.RateLimit("chat.postMessage", maxPostAttempts)
+
+	_, _, err := Post(server.Client(), "C1", slack.MsgOptionText("hello", false))
+	var rateLimited *slack.RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		t.Errorf("Post() error = %v, want the rate limit error", err)
+	}
+	if calls := server.Calls("chat.postMessage"); len(calls) != maxPostAttempts {
+		t.Errorf("made %d calls, want %d", len(calls), maxPostAttempts)
+	}
+}
+
+func TestUpdateRetriesWhenRateLimited(t *testing.T) {
+	server := slacktest.NewServer(t)
+	server.RateLimit("chat.update", 2)
+
+	if err := Update(server.Client(), "C1", "1700000000.000001", "edited"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if calls := server.Calls("chat.update"); len(calls) != 3 {
+		t.Errorf("made %d calls, want 3", len(calls))
+	}
+}
+
+func TestWithRetryDoesNotRetryOtherErrors(t *testing.T) {
+	calls := 0
+	err := withRetry("testing", func() error {
+		calls++
+		return errors.New("channel_not_found")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("withRetry() = %v after %d calls, want the error after one", err, calls)
+	}
+}
+
+func TestWithRetryWaitsAsAsked(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	err := withRetry("testing", func() error {
+		calls++
+		if calls == 1 {
+			return &slack.RateLimitedError{RetryAfter: 50 * time.Millisecond}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("withRetry() = %v after %d calls, want success after 2", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("retried after %s, want at least the 50ms Slack asked for", elapsed)
+	}
+}
+
+func TestWithRetryReportsAuthErrors(t *testing.T) {
+	var reported error
+	OnAuthError(func(err error) { reported = err })
+	t.Cleanup(func() { OnAuthError(nil) })
+
+	if err := withRetry("testing", func() error { return slack.SlackErrorResponse{Err: "token_revoked"} }); err == nil {
+		t.Fatal("withRetry() succeeded, want the auth error")
+	}
+	if !IsAuthError(reported) {
+		t.Errorf("reported %v, want the auth error", reported)
+	}
+}
+
+func TestUploadRejectsOversizedContent(t *testing.T) {
+	server := slacktest.NewServer(t)
+
+	err := Upload(context.Background(), server.Client(), slack.UploadFileV2Parameters{Channel: "C1", Filename: "big.csv", Content: "0123456789"}, time.Second, 9)
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Upload() error = %v, want ErrFileTooLarge", err)
+	}
+	if calls := server.Calls(); len(calls) != 0 {
+		t.Errorf("made %d calls for an oversized file, want none", len(calls))
+	}
+}
+
+func TestUploadTimesOut(t *testing.T) {
+	server := slacktest.NewServer(t)
+	server.Handle("files.getUploadURLExternal", func(url.Values) map[string]any {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+
+	err := Upload(context.Background(), server.Client(), slack.UploadFileV2Parameters{Channel: "C1", Filename: "slow.csv", Content: "a,b"}, 20*time.Millisecond, 1024)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Upload() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestUploadSetsFileSize(t *testing.T) {
+	server := slacktest.NewServer(t)
+
+	if err := Upload(context.Background(), server.Client(), slack.UploadFileV2Parameters{Channel: "C1", Filename: "ok.csv", Content: "a,b"}, time.Second, 3); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	calls := server.Calls("files.getUploadURLExternal")
+	if len(calls) != 1 || calls[0].Values.Get("length") != "3" {
+		t.Errorf("upload URL requests %v, want one for 3 bytes", calls)
+	}
+}
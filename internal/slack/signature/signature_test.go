@@ -0,0 +1,160 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A signed app_mention event delivered by Slack, as used by slack-go's own
+// verifier tests.
+const (
+	exampleSecret    = "e6b19c573432dcc6b075501d51b51bb8"
+	exampleTimestamp = "1531431954"
+	exampleBody      = `{"token":"aF5ynEYQH0dFN9imlgcADxDB","team_id":"XXXXXXXXX","api_app_id":"YYYYYYYYY","event":{"type":"app_mention","user":"AAAAAAAAA","text":"<@EEEEEEEEE> hello world","client_msg_id":"477cc591-ch73-a14z-4db8-g0cd76321bec","ts":"1531431954.000073","channel":"TTTTTTTTT","event_ts":"1531431954.000073"},"type":"event_callback","event_id":"TvBP7LRED7","event_time":1531431954,"authed_users":["EEEEEEEEE"]}`
+	exampleSignature = "v0=adada4ed31709aef585c2580ca3267678c6a8eaeb7e0c1aca3ee57b656886b2c"
+)
+
+// sign computes the X-Slack-Signature of body sent at timestamp.
+func sign(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSignMatchesExample(t *testing.T) {
+	if got := sign(exampleSecret, exampleTimestamp, exampleBody); got != exampleSignature {
+		t.Fatalf("sign() = %s, want the example's %s", got, exampleSignature)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      string
+		want      int
+	}{
+		{
+			name:      "valid",
+			secret:    exampleSecret,
+			timestamp: now,
+			signature: sign(exampleSecret, now, exampleBody),
+			body:      exampleBody,
+			want:      http.StatusOK,
+		},
+		{
+			name:      "example is expired",
+			secret:    exampleSecret,
+			timestamp: exampleTimestamp,
+			signature: exampleSignature,
+			body:      exampleBody,
+			want:      http.StatusUnauthorized,
+		},
+		{
+			name:      "expired",
+			secret:    exampleSecret,
+			timestamp: stale,
+			signature: sign(exampleSecret, stale, exampleBody),
+			body:      exampleBody,
+			want:      http.StatusUnauthorized,
+		},
+		{
+			name:      "tampered body",
+			secret:    exampleSecret,
+			timestamp: now,
+			signature: sign(exampleSecret, now, exampleBody),
+			body:      strings.Replace(exampleBody, "hello world", "goodbye", 1),
+			want:      http.StatusUnauthorized,
+		},
+		{
+			name:      "wrong secret",
+			secret:    exampleSecret,
+			timestamp: now,
+			signature: sign("another-secret", now, exampleBody),
+			body:      exampleBody,
+			want:      http.StatusUnauthorized,
+		},
+		{
+			name:      "signed for another timestamp",
+			secret:    exampleSecret,
+			timestamp: now,
+			signature: sign(exampleSecret, exampleTimestamp, exampleBody),
+			body:      exampleBody,
+			want:      http.StatusUnauthorized,
+		},
+		{
+			name:      "missing signature",
+			secret:    exampleSecret,
+			timestamp: now,
+			body:      exampleBody,
+			want:      http.StatusUnauthorized,
+		},
+		{
+			name:      "no secret configured",
+			timestamp: now,
+			signature: sign("", now, exampleBody),
+			body:      exampleBody,
+			want:      http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			handler := Verify(tt.secret, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("reading the restored body: %v", err)
+				}
+				received = string(body)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(tt.body))
+			req.Header.Set("X-Slack-Request-Timestamp", tt.timestamp)
+			if tt.signature != "" {
+				req.Header.Set("X-Slack-Signature", tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && received != tt.body {
+				t.Errorf("handler received body %q, want it restored as %q", received, tt.body)
+			}
+			if tt.want != http.StatusOK && received != "" {
+				t.Errorf("handler reached by a rejected request")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsOversizedBody(t *testing.T) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	body := strings.Repeat("a", maxBodyBytes+1)
+	called := false
+	handler := Verify(exampleSecret, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", now)
+	req.Header.Set("X-Slack-Signature", sign(exampleSecret, now, body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized || called {
+		t.Errorf("status = %d (handler called: %t), want 401 without calling the handler", rec.Code, called)
+	}
+}
@@ -1,7 +1,18 @@
 package slack
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/health"
+	"github.com/flacatus/spoticus/internal/slack/commands"
 	"github.com/flacatus/spoticus/internal/slack/events"
+	"github.com/flacatus/spoticus/internal/slack/respond"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
@@ -12,16 +23,19 @@ import (
 // It also initializes the bot with the necessary tokens.
 // The bot listens for events and processes commands.
 type Slack struct {
-	// socketmode.Client is used to handle events from Slack in real-time.
+	// source delivers the events from Slack in real-time, normally the socket mode client.
 	// slack.Client is used to interact with the Slack API.
 	// events.Bot is used to handle incoming events and route them to the appropriate handlers.
-	client *socketmode.Client
+	source EventSource
 
 	// api is the Slack API client used to send messages and interact with Slack.
 	api *slack.Client
 
 	// bot is the bot instance that handles events and commands.
 	bot *events.Bot
+
+	// health records the connection state and event counters reported by `uptime`.
+	health *health.Tracker
 }
 
 // New creates a new Slack bot instance with the provided bot and app tokens.
@@ -29,30 +43,189 @@ type Slack struct {
 // Returns a pointer to the Slack instance or an error if initialization fails.
 func New(botToken, appToken string) (*Slack, error) {
 	api := slack.New(botToken, slack.OptionAppLevelToken(appToken))
-	client := socketmode.New(api)
+	return NewWithSource(api, socketModeSource{socketmode.New(api)})
+}
 
-	bot, err := events.NewBot(api, client)
+// NewWithSource creates a Slack bot instance that receives its events from
+// source instead of a socket mode connection, e.g. a ChannelSource in tests.
+func NewWithSource(api *slack.Client, source EventSource) (*Slack, error) {
+	bot, err := events.NewBot(api)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Slack{client: client, api: api, bot: bot}, nil
+	return &Slack{source: source, api: api, bot: bot, health: health.Default()}, nil
 }
 
+// ErrInvalidAuth is returned by Run when Slack rejects the bot or app token,
+// typically because it was rotated or revoked. The bot cannot recover on its
+// own: it must be restarted with valid tokens.
+var ErrInvalidAuth = errors.New("slack rejected the bot credentials")
+
+// ErrAppRevoked is returned by Run when the app was uninstalled from the
+// workspace or its bot tokens were revoked. Without tenant isolation the bot
+// serves a single workspace, so there is nothing left to process until it is
+// reinstalled.
+var ErrAppRevoked = errors.New("slack app access revoked")
+
 // Run starts the Slack bot and listens for events.
-func (s *Slack) Run() {
+func (s *Slack) Run() error {
+	return s.RunContext(context.Background())
+}
+
+// RunContext starts the Slack bot and listens for events until ctx is cancelled.
+//
+// Events are acknowledged as soon as they arrive and then handed to a bounded
+// worker pool, so a slow command does not hold up the ones behind it. When
+// the pool's queue is full, new events are dropped with a warning. A handler
+// that panics is recovered and logged, and the bot carries on.
+//
+// If Slack rejects the tokens, while connecting or when posting a reply, the
+// bot stops and RunContext returns an error wrapping ErrInvalidAuth; if the
+// app is uninstalled or its bot tokens revoked, it returns one wrapping
+// ErrAppRevoked. Other
+// connection failures are retried with the reconnect backoff policy; once
+// they have lasted longer than its maximum elapsed time, RunContext returns
+// an error wrapping ErrReconnectTimeout.
+func (s *Slack) RunContext(ctx context.Context) error {
+	cfg := config.Get()
+	pool := newWorkerPool(cfg.EventWorkers, cfg.EventQueueSize)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	respond.OnAuthError(func(err error) {
+		cancel(fmt.Errorf("%w: %v", ErrInvalidAuth, err))
+	})
+	defer respond.OnAuthError(nil)
+	s.bot.OnRevoked(func(reason string) {
+		cancel(fmt.Errorf("%w: %s", ErrAppRevoked, reason))
+	})
+
+	backoff := &reconnectBackoff{policy: cfg.Reconnect}
+	lost := make(chan struct{}, 1)
+
+	// Scheduled launches are only run by the instance processing events.
+	commands.RestoreMaintenance()
+	go commands.RestoreScheduledLaunches(s.api)
+	go commands.RestorePendingDeletions(s.api)
+
 	go func() {
-		for evt := range s.client.Events {
-			if evt.Type == socketmode.EventTypeEventsAPI {
-				s.client.Ack(*evt.Request)
+		defer pool.Close()
+		for evt := range s.source.Events() {
+			switch evt.Type {
+			case socketmode.EventTypeConnecting:
+				s.health.Connecting()
+			case socketmode.EventTypeConnected:
+				s.health.Connected()
+				backoff.success()
+			case socketmode.EventTypeConnectionError:
+				s.health.Disconnected()
+				select {
+				case lost <- struct{}{}:
+				default:
+				}
+			case socketmode.EventTypeDisconnect:
+				s.health.Disconnected()
+			case socketmode.EventTypeInvalidAuth:
+				s.health.Disconnected()
+				cancel(fmt.Errorf("%w: invalid app token", ErrInvalidAuth))
+			case socketmode.EventTypeEventsAPI:
+				if evt.Request != nil {
+					s.source.Ack(*evt.Request)
+				}
 
 				eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
 				if !ok {
 					continue
 				}
-				s.bot.HandleEvent(eventsAPIEvent)
+				if !pool.TrySubmit(func() {
+					dispatchSafely(eventsAPIEvent.InnerEvent.Type+" event", func() { s.bot.HandleEvent(eventsAPIEvent) })
+				}) {
+					log.Printf("⚠️ Event queue full (%d pending), dropping %s event", cfg.EventQueueSize, eventsAPIEvent.InnerEvent.Type)
+					continue
+				}
+				s.health.EventProcessed()
+			case socketmode.EventTypeInteractive:
+				if evt.Request != nil {
+					s.source.Ack(*evt.Request)
+				}
+
+				callback, ok := evt.Data.(slack.InteractionCallback)
+				if !ok {
+					continue
+				}
+				if !pool.TrySubmit(func() {
+					dispatchSafely(string(callback.Type)+" interaction", func() { s.bot.HandleInteraction(callback) })
+				}) {
+					log.Printf("⚠️ Event queue full (%d pending), dropping %s interaction", cfg.EventQueueSize, callback.Type)
+					continue
+				}
+				s.health.EventProcessed()
 			}
 		}
 	}()
-	s.client.Run()
+	for {
+		err := s.runSource(ctx, lost)
+		if cause := context.Cause(ctx); errors.Is(cause, ErrInvalidAuth) || errors.Is(cause, ErrAppRevoked) {
+			return cause
+		}
+		if respond.IsAuthError(err) {
+			return fmt.Errorf("%w: %v", ErrInvalidAuth, err)
+		}
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+
+		delay, ok := backoff.failure(time.Now())
+		if !ok {
+			return fmt.Errorf("%w within %s: %v", ErrReconnectTimeout, backoff.elapsed(time.Now()), err)
+		}
+		log.Printf("🔌 Connection to Slack failed: %v; reconnecting in %s", err, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// dispatchSafely runs the handler of an event, recovering from a panic in it
+// so that one faulty event does not take the whole bot down. The panic is
+// logged with its stack trace.
+func dispatchSafely(what string, handle func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("💥 Recovered from a panic handling a %s: %v\n%s", what, r, debug.Stack())
+		}
+	}()
+	handle()
+}
+
+// errConnectionLost is returned by runSource when the event source reported
+// a connection error, so that the retry follows the reconnect policy rather
+// than the source's own.
+var errConnectionLost = errors.New("connection error")
+
+// runSource runs the event source until it stops or a connection error is
+// signalled on lost, in which case the source is stopped first.
+func (s *Slack) runSource(ctx context.Context, lost <-chan struct{}) error {
+	// Drop a signal left over from the previous attempt.
+	select {
+	case <-lost:
+	default:
+	}
+
+	attempt, stop := context.WithCancel(ctx)
+	defer stop()
+	done := make(chan error, 1)
+	go func() { done <- s.source.RunContext(attempt) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-lost:
+		stop()
+		<-done
+		return errConnectionLost
+	}
 }
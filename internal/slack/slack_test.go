@@ -0,0 +1,259 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/flacatus/spoticus/internal/config"
+	"github.com/flacatus/spoticus/internal/health"
+	"github.com/flacatus/spoticus/internal/slack/commands"
+	"github.com/flacatus/spoticus/internal/slack/slacktest"
+)
+
+// startBot runs the bot on a ChannelSource against the fake Slack API and
+// Kubernetes clients, until the test ends.
+func startBot(t *testing.T, cfg *config.Config, kube *slacktest.Kube) (*ChannelSource, *slacktest.Server) {
+	t.Helper()
+	previous := config.Get()
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(previous) })
+	commands.SetKubernetesClientFactory(func() (*commands.KubernetesClients, error) {
+		return &commands.KubernetesClients{
+			KubeClient:    kube.KubeClient,
+			CrClient:      kube.CrClient,
+			DynamicClient: kube.DynamicClient,
+		}, nil
+	})
+	t.Cleanup(func() { commands.SetKubernetesClientFactory(nil) })
+	health.Default().SetBackend(nil)
+
+	server := slacktest.NewServer(t)
+	source := NewChannelSource(10)
+	bot, err := NewWithSource(server.Client(), source)
+	if err != nil {
+		t.Fatalf("NewWithSource: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bot.RunContext(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("RunContext: %v", err)
+		}
+	})
+	return source, server
+}
+
+// testConfig is the default configuration, polling fast enough for tests.
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Polling = config.Polling{Interval: config.Duration(10 * time.Millisecond), MaxBackoff: config.Duration(10 * time.Millisecond)}
+	return cfg
+}
+
+func TestChannelSourceLaunchesCluster(t *testing.T) {
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase("Ready"))
+	source, server := startBot(t, testConfig(), kube)
+
+	source.PushMessage("T1", "U1", "C1", "launch k8s medium")
+
+	server.WaitForMessage("Launching", 5*time.Second)
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	var kinds maptApi.KindList
+	if err := kube.CrClient.List(context.Background(), &kinds); err != nil {
+		t.Fatalf("listing the MAPT clusters: %v", err)
+	}
+	if len(kinds.Items) != 1 {
+		t.Fatalf("got %d MAPT Kind clusters, want 1", len(kinds.Items))
+	}
+	cluster := kinds.Items[0]
+	if cluster.Namespace != "default" {
+		t.Errorf("cluster created in namespace %q, want default", cluster.Namespace)
+	}
+	if got := cluster.Annotations; len(got) == 0 {
+		t.Errorf("cluster has no launch metadata annotations")
+	}
+	if acked := source.Acked(); len(acked) != 1 {
+		t.Errorf("acknowledged %v, want the one envelope", acked)
+	}
+}
+
+func TestChannelSourceUnknownCommandShowsHelp(t *testing.T) {
+	source, server := startBot(t, testConfig(), slacktest.NewKube())
+
+	source.PushMessage("T1", "U1", "C1", "frobnicate")
+
+	server.WaitForMessage("launch", 5*time.Second)
+}
+
+func TestChannelSourceScopesLaunchToEnvelopeTeam(t *testing.T) {
+	cfg := testConfig()
+	cfg.TenantIsolation = true
+	kube := slacktest.NewKubeWithInterceptor(slacktest.ReportPhase("Ready"))
+	source, server := startBot(t, cfg, kube)
+
+	// Another user than the other launches, to be clear of their cooldown.
+	source.PushMessage("TA", "U2", "C1", "launch k8s medium")
+	server.WaitForMessage("is ready", 5*time.Second)
+
+	var kinds maptApi.KindList
+	if err := kube.CrClient.List(context.Background(), &kinds); err != nil {
+		t.Fatalf("listing the MAPT clusters: %v", err)
+	}
+	if len(kinds.Items) != 1 {
+		t.Fatalf("got %d MAPT Kind clusters, want 1", len(kinds.Items))
+	}
+	if got := kinds.Items[0].Labels["spoticus.io/tenant"]; got != "TA" {
+		t.Errorf("cluster labelled with tenant %q, want TA", got)
+	}
+}
+
+func TestRunContextStopsWhenAppUninstalled(t *testing.T) {
+	source, done := runBot(t, slacktest.NewServer(t))
+
+	source.Push(socketmode.Event{
+		Type: socketmode.EventTypeEventsAPI,
+		Data: slackevents.EventsAPIEvent{
+			Type:   slackevents.CallbackEvent,
+			TeamID: "T1",
+			InnerEvent: slackevents.EventsAPIInnerEvent{
+				Type: string(slackevents.AppUninstalled),
+				Data: &slackevents.AppUninstalledEvent{Type: string(slackevents.AppUninstalled)},
+			},
+		},
+		Request: &socketmode.Request{EnvelopeID: "uninstall"},
+	})
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrAppRevoked) {
+			t.Errorf("RunContext() = %v, want ErrAppRevoked", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext did not stop after the app was uninstalled")
+	}
+}
+
+// runBot runs the bot on a ChannelSource against the Slack API server, and
+// returns the channel RunContext's result is sent to.
+func runBot(t *testing.T, server *slacktest.Server) (*ChannelSource, <-chan error) {
+	t.Helper()
+	previous := config.Get()
+	config.Set(testConfig())
+	t.Cleanup(func() { config.Set(previous) })
+	commands.SetKubernetesClientFactory(func() (*commands.KubernetesClients, error) {
+		kube := slacktest.NewKube()
+		return &commands.KubernetesClients{KubeClient: kube.KubeClient, CrClient: kube.CrClient, DynamicClient: kube.DynamicClient}, nil
+	})
+	t.Cleanup(func() { commands.SetKubernetesClientFactory(nil) })
+
+	source := NewChannelSource(10)
+	bot, err := NewWithSource(server.Client(), source)
+	if err != nil {
+		t.Fatalf("NewWithSource: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- bot.RunContext(context.Background()) }()
+	return source, done
+}
+
+func TestRunContextStopsWhenTokenRevoked(t *testing.T) {
+	server := slacktest.NewServer(t)
+	server.Handle("chat.postMessage", func(url.Values) map[string]any {
+		return map[string]any{"ok": false, "error": "token_revoked"}
+	})
+	source, done := runBot(t, server)
+
+	source.PushMessage("T1", "U3", "C1", "frobnicate")
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrInvalidAuth) {
+			t.Errorf("RunContext() = %v, want ErrInvalidAuth", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext did not stop after Slack rejected the token")
+	}
+}
+
+func TestRunContextStopsOnInvalidAppToken(t *testing.T) {
+	source, done := runBot(t, slacktest.NewServer(t))
+
+	source.Push(socketmode.Event{Type: socketmode.EventTypeInvalidAuth})
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrInvalidAuth) {
+			t.Errorf("RunContext() = %v, want ErrInvalidAuth", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext did not stop after the app token was rejected")
+	}
+}
+
+func TestRunContextTracksConnectionAndEvents(t *testing.T) {
+	source, server := startBot(t, testConfig(), slacktest.NewKube())
+	before := health.Default().Snapshot()
+
+	source.Push(socketmode.Event{Type: socketmode.EventTypeConnecting})
+	source.Push(socketmode.Event{Type: socketmode.EventTypeConnected})
+	source.Push(socketmode.Event{Type: socketmode.EventTypeConnected})
+	source.PushMessage("T1", "U4", "C1", "uptime")
+
+	text := server.WaitForMessage("Slack connection: connected", 5*time.Second).Text()
+	after := health.Default().Snapshot()
+	if after.Reconnects < before.Reconnects+1 {
+		t.Errorf("reconnects went from %d to %d, want the second connection counted", before.Reconnects, after.Reconnects)
+	}
+	if after.Events < before.Events+1 {
+		t.Errorf("events went from %d to %d, want the message counted", before.Events, after.Events)
+	}
+	if !strings.Contains(text, "Reconnects: ") || !strings.Contains(text, "(last ") {
+		t.Errorf("uptime %q does not report the last reconnect", text)
+	}
+}
+
+func TestDispatchSafelyRecoversFromPanic(t *testing.T) {
+	ran := false
+	dispatchSafely("message event", func() {
+		ran = true
+		panic("nil map")
+	})
+	if !ran {
+		t.Error("dispatchSafely did not run the handler")
+	}
+}
+
+func TestRunContextSurvivesPanickingHandler(t *testing.T) {
+	kube := slacktest.NewKube()
+	source, server := startBot(t, testConfig(), kube)
+	// Wait for the startup, which reads the cluster too, to be over.
+	source.PushMessage("T1", "U1", "C1", "frobnicate")
+	server.WaitForMessage("launch", 5*time.Second)
+
+	var panicked atomic.Bool
+	commands.SetKubernetesClientFactory(func() (*commands.KubernetesClients, error) {
+		if panicked.CompareAndSwap(false, true) {
+			panic("kubeconfig went away")
+		}
+		return &commands.KubernetesClients{KubeClient: kube.KubeClient, CrClient: kube.CrClient, DynamicClient: kube.DynamicClient}, nil
+	})
+
+	source.PushMessage("T1", "U1", "C1", "list")
+	source.PushMessage("T1", "U1", "C1", "list")
+	server.WaitForMessage("No MAPT clusters currently running", 5*time.Second)
+	if !panicked.Load() {
+		t.Error("no handler panicked")
+	}
+}
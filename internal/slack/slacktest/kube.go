@@ -0,0 +1,78 @@
+package slacktest
+
+import (
+	"context"
+
+	maptApi "github.com/flacatus/mapt-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// NewScheme returns a scheme that knows the Kubernetes and MAPT types, like
+// the bot's own. The fake clients register types in their scheme as they
+// serve calls, so each Kube gets a scheme of its own.
+func NewScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(maptApi.AddToScheme(scheme))
+	return scheme
+}
+
+// Kube bundles fake Kubernetes clients. CrClient holds the MAPT resources and
+// the bot's state; KubeClient answers the typed API calls (events, quotas,
+// access reviews, logs), and can be given reactors.
+type Kube struct {
+	CrClient      crclient.WithWatch
+	KubeClient    *kubefake.Clientset
+	DynamicClient *dynamicfake.FakeDynamicClient
+}
+
+// NewKube returns fake clients whose controller-runtime client holds objects.
+func NewKube(objects ...crclient.Object) *Kube {
+	return newKube(func(builder *crfake.ClientBuilder) *crfake.ClientBuilder {
+		return builder.WithObjects(objects...)
+	})
+}
+
+// NewKubeWithInterceptor is like NewKube, with the calls to the
+// controller-runtime client going through funcs, e.g. to fail them or to set
+// the status of the MAPT resources as the operator would.
+func NewKubeWithInterceptor(funcs interceptor.Funcs, objects ...crclient.Object) *Kube {
+	return newKube(func(builder *crfake.ClientBuilder) *crfake.ClientBuilder {
+		return builder.WithObjects(objects...).WithInterceptorFuncs(funcs)
+	})
+}
+
+// newKube builds the fake clients on a fresh scheme, with configure setting up
+// the controller-runtime client.
+func newKube(configure func(*crfake.ClientBuilder) *crfake.ClientBuilder) *Kube {
+	scheme := NewScheme()
+	return &Kube{
+		CrClient:      configure(crfake.NewClientBuilder().WithScheme(scheme)).Build(),
+		KubeClient:    kubefake.NewClientset(),
+		DynamicClient: dynamicfake.NewSimpleDynamicClient(scheme),
+	}
+}
+
+// ReportPhase makes the MAPT resources read unstructured report the phase in
+// their status, as if the operator had reached it.
+func ReportPhase(phase string) interceptor.Funcs {
+	return interceptor.Funcs{
+		Get: func(ctx context.Context, client crclient.WithWatch, key crclient.ObjectKey, obj crclient.Object, opts ...crclient.GetOption) error {
+			if err := client.Get(ctx, key, obj, opts...); err != nil {
+				return err
+			}
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				return unstructured.SetNestedField(u.Object, phase, "status", "phase")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,219 @@
+// Package slacktest provides fakes of the Slack Web API and of the Kubernetes
+// clients the bot uses, to exercise commands end to end in tests.
+package slacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// Call is a request the bot made to the Slack Web API.
+type Call struct {
+	// Method is the API method, e.g. "chat.postMessage".
+	Method string
+	// Values are the form values of the request.
+	Values url.Values
+}
+
+// Channel returns the channel the call targets.
+func (c Call) Channel() string { return c.Values.Get("channel") }
+
+// Text returns the text of a posted or updated message, including the text
+// of its blocks.
+func (c Call) Text() string {
+	text := c.Values.Get("text")
+	if blocks := c.Values.Get("blocks"); blocks != "" {
+		text += "\n" + blocks
+	}
+	return text
+}
+
+// Server is a fake Slack Web API recording the calls made to it. Messages are
+// accepted and given increasing timestamps; other methods succeed with an
+// empty response unless a handler is registered with Handle.
+type Server struct {
+	t      testing.TB
+	server *httptest.Server
+
+	mu         sync.Mutex
+	calls      []Call
+	ts         int
+	handlers   map[string]func(url.Values) map[string]any
+	rateLimits map[string]int
+	notify     chan struct{}
+}
+
+// NewServer starts a fake Slack Web API, closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		t:          t,
+		handlers:   map[string]func(url.Values) map[string]any{},
+		rateLimits: map[string]int{},
+		notify:     make(chan struct{}),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// Client returns a Slack client talking to the fake API.
+func (s *Server) Client() *slack.Client {
+	return slack.New("xoxb-test", slack.OptionAPIURL(s.server.URL+"/"))
+}
+
+// Handle makes the API method respond with the fields returned by respond,
+// alongside "ok": true unless they set it.
+func (s *Server) Handle(method string, respond func(url.Values) map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = respond
+}
+
+// RateLimit makes the next times calls to the API method fail with HTTP 429,
+// asking to retry right away.
+func (s *Server) RateLimit(method string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimits[method] = times
+}
+
+// Calls returns the calls made to the API methods, or to all of them when none
+// is given, in the order they were made.
+func (s *Server) Calls(methods ...string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var calls []Call
+	for _, call := range s.calls {
+		if len(methods) == 0 || slices.Contains(methods, call.Method) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Messages returns the messages posted, updated or sent ephemerally.
+func (s *Server) Messages() []Call {
+	return s.Calls("chat.postMessage", "chat.update", "chat.postEphemeral")
+}
+
+// WaitForMessage waits until a message containing text has been posted, and
+// returns it. The test fails if none is within the timeout.
+func (s *Server) WaitForMessage(text string, timeout time.Duration) Call {
+	s.t.Helper()
+	call, ok := s.Wait(func(call Call) bool {
+		return isMessage(call.Method) && strings.Contains(call.Text(), text)
+	}, timeout)
+	if !ok {
+		s.t.Fatalf("no message containing %q within %s; got:\n%s", text, timeout, s.transcript())
+	}
+	return call
+}
+
+// Wait waits for a call matching match, reporting whether there was one
+// within the timeout.
+func (s *Server) Wait(match func(Call) bool, timeout time.Duration) (Call, bool) {
+	deadline := time.After(timeout)
+	for {
+		s.mu.Lock()
+		for _, call := range s.calls {
+			if match(call) {
+				s.mu.Unlock()
+				return call, true
+			}
+		}
+		notify := s.notify
+		s.mu.Unlock()
+		select {
+		case <-notify:
+		case <-deadline:
+			return Call{}, false
+		}
+	}
+}
+
+// transcript lists the messages posted so far, for failure reports.
+func (s *Server) transcript() string {
+	var b strings.Builder
+	for _, call := range s.Messages() {
+		fmt.Fprintf(&b, "  %s %s: %s\n", call.Method, call.Channel(), call.Values.Get("text"))
+	}
+	return b.String()
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := strings.TrimPrefix(r.URL.Path, "/")
+	if method == "upload" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	s.mu.Lock()
+	call := Call{Method: method, Values: r.Form}
+	s.calls = append(s.calls, call)
+	close(s.notify)
+	s.notify = make(chan struct{})
+	if s.rateLimits[method] > 0 {
+		s.rateLimits[method]--
+		s.mu.Unlock()
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	response := map[string]any{"ok": true}
+	switch method {
+	case "chat.postMessage", "chat.update":
+		s.ts++
+		ts := call.Values.Get("ts")
+		if ts == "" {
+			ts = fmt.Sprintf("1700000000.%06d", s.ts)
+		}
+		response["channel"] = call.Channel()
+		response["ts"] = ts
+	case "chat.postEphemeral":
+		s.ts++
+		response["message_ts"] = fmt.Sprintf("1700000000.%06d", s.ts)
+	case "chat.getPermalink":
+		response["channel"] = call.Channel()
+		response["permalink"] = "https://slack.test/archives/" + call.Channel() + "/p" + strings.ReplaceAll(call.Values.Get("message_ts"), ".", "")
+	case "conversations.open":
+		response["channel"] = map[string]any{"id": "D" + call.Values.Get("users")}
+	case "auth.test":
+		response["user_id"] = "UBOT"
+		response["team_id"] = "T0001"
+	case "files.getUploadURLExternal":
+		response["upload_url"] = s.server.URL + "/upload"
+		response["file_id"] = "F0001"
+	case "files.completeUploadExternal":
+		response["files"] = []map[string]any{{"id": "F0001"}}
+	}
+	if handler, ok := s.handlers[method]; ok {
+		for key, value := range handler(call.Values) {
+			response[key] = value
+		}
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.t.Errorf("encoding the response to %s: %v", method, err)
+	}
+}
+
+func isMessage(method string) bool {
+	return method == "chat.postMessage" || method == "chat.update" || method == "chat.postEphemeral"
+}
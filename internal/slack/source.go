@@ -0,0 +1,105 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// EventSource delivers socket mode events to the bot and acknowledges them.
+//
+// In production it is the socket mode client. A ChannelSource can be used
+// instead to push synthetic events through the whole routing pipeline; pair
+// it with a slack.Client pointed at a fake API (slack.OptionAPIURL) to
+// observe the replies.
+type EventSource interface {
+	// Events returns the channel events are delivered on.
+	Events() <-chan socketmode.Event
+
+	// Ack acknowledges an event so Slack does not redeliver it.
+	Ack(req socketmode.Request, payload ...interface{})
+
+	// RunContext delivers events until ctx is cancelled or the source fails.
+	RunContext(ctx context.Context) error
+}
+
+// socketModeSource adapts the socket mode client to EventSource.
+type socketModeSource struct {
+	*socketmode.Client
+}
+
+// Events returns the socket mode client's event channel.
+func (s socketModeSource) Events() <-chan socketmode.Event {
+	return s.Client.Events
+}
+
+// ChannelSource is an in-memory EventSource fed with Push.
+type ChannelSource struct {
+	events chan socketmode.Event
+	nextID atomic.Int64
+
+	mu    sync.Mutex
+	acked []string
+}
+
+// NewChannelSource creates a ChannelSource buffering up to size events.
+func NewChannelSource(size int) *ChannelSource {
+	return &ChannelSource{events: make(chan socketmode.Event, size)}
+}
+
+// Push delivers an event to the bot.
+func (c *ChannelSource) Push(evt socketmode.Event) {
+	c.events <- evt
+}
+
+// PushMessage delivers a message event as if the user had posted text in the
+// channel of the workspace with the team ID.
+func (c *ChannelSource) PushMessage(team, user, channel, text string) {
+	id := fmt.Sprintf("envelope-%d", c.nextID.Add(1))
+	c.Push(socketmode.Event{
+		Type: socketmode.EventTypeEventsAPI,
+		Data: slackevents.EventsAPIEvent{
+			Type:   slackevents.CallbackEvent,
+			TeamID: team,
+			InnerEvent: slackevents.EventsAPIInnerEvent{
+				Type: string(slackevents.Message),
+				Data: &slackevents.MessageEvent{
+					Type:    string(slackevents.Message),
+					User:    user,
+					Channel: channel,
+					Text:    text,
+				},
+			},
+		},
+		Request: &socketmode.Request{EnvelopeID: id},
+	})
+}
+
+// Events returns the channel pushed events are delivered on.
+func (c *ChannelSource) Events() <-chan socketmode.Event {
+	return c.events
+}
+
+// Ack records the acknowledged envelope.
+func (c *ChannelSource) Ack(req socketmode.Request, payload ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.acked = append(c.acked, req.EnvelopeID)
+}
+
+// Acked returns the envelope IDs acknowledged so far, in order.
+func (c *ChannelSource) Acked() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.acked...)
+}
+
+// RunContext blocks until ctx is cancelled.
+func (c *ChannelSource) RunContext(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
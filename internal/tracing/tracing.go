@@ -0,0 +1,78 @@
+// Package tracing instruments command handling with OpenTelemetry spans.
+//
+// Tracing is opt-in: until Setup is called the global tracer provider is the
+// OpenTelemetry no-op provider, so the spans started by Start cost nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by the bot.
+const instrumentationName = "github.com/flacatus/spoticus"
+
+// Span attributes recorded on command and Kubernetes spans.
+const (
+	AttrCommand   = attribute.Key("spoticus.command")
+	AttrUser      = attribute.Key("spoticus.user")
+	AttrChannel   = attribute.Key("spoticus.channel")
+	AttrCluster   = attribute.Key("spoticus.cluster")
+	AttrOperation = attribute.Key("k8s.operation")
+	AttrKind      = attribute.Key("k8s.kind")
+	AttrObject    = attribute.Key("k8s.object")
+)
+
+// Setup installs a tracer provider exporting spans over OTLP/HTTP. An empty
+// endpoint uses the standard OTEL_EXPORTER_OTLP_* environment variables, or
+// http://localhost:4318 if none is set. The returned function flushes the
+// pending spans and stops the exporter.
+func Setup(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName("spoticus")))
+	if err != nil {
+		return nil, fmt.Errorf("building trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SetCluster records the cluster a command operates on on the span in ctx.
+func SetCluster(ctx context.Context, name string) {
+	trace.SpanFromContext(ctx).SetAttributes(AttrCluster.String(name))
+}